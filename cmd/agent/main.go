@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dandantas/raven/internal/agent"
+	"github.com/dandantas/raven/internal/config"
+)
+
+func main() {
+	// Load configuration
+	cfg := config.LoadAgent()
+
+	// Initialize logger
+	config.InitLogger(&config.Config{
+		LogLevel:  cfg.LogLevel,
+		LogFormat: cfg.LogFormat,
+	})
+
+	if cfg.Region == "" {
+		slog.Error("AGENT_REGION is required")
+		os.Exit(1)
+	}
+
+	// Stop polling on SIGINT/SIGTERM
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	agent.New(cfg).Run(ctx)
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dandantas/raven/internal/config"
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// benchResult is the outcome of one benchmark scenario
+type benchResult struct {
+	Name       string  `json:"name"`
+	Ops        int     `json:"ops"`
+	Errors     int     `json:"errors"`
+	Seconds    float64 `json:"seconds"`
+	OpsPerSec  float64 `json:"ops_per_sec"`
+	P50Ms      float64 `json:"p50_ms"`
+	P95Ms      float64 `json:"p95_ms"`
+	P99Ms      float64 `json:"p99_ms"`
+	MaxMs      float64 `json:"max_ms"`
+	ServerOps  int64   `json:"server_ops,omitempty"` // MongoDB operations counted by serverStatus, when permitted
+	Note       string  `json:"note,omitempty"`
+	latencies  []time.Duration
+	startedAt  time.Time
+	serverBase int64
+}
+
+// runBench measures the scheduler's database paths against the seeded health checks:
+// the due-checks query of a tick, lock acquisition by competing pods and batched
+// execution history inserts
+func runBench(ctx context.Context, db *database.MongoDB, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	ticks := flags.Int("ticks", 20, "Due-check queries to time")
+	pods := flags.Int("pods", 4, "Simulated scheduler pods competing for locks")
+	executions := flags.Int("executions", 10000, "Execution histories to insert")
+	writers := flags.Int("writers", 16, "Concurrent execution history writers")
+	batchSize := flags.Int("batch-size", cfg.ExecutionBatchSize, "Execution writer batch size")
+	asJSON := flags.Bool("json", false, "Print results as JSON")
+	flags.Parse(args)
+
+	healthCheckRepo := database.NewHealthCheckRepository(db)
+	lockRepo := database.NewLockRepository(db)
+
+	// Every seeded check is due a minute from now, whatever its spread
+	dueAt := time.Now().UTC().Add(time.Minute)
+	configs, err := healthCheckRepo.FindScheduledChecks(ctx, dueAt)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return fmt.Errorf("no scheduled checks are due; run loadgen seed first")
+	}
+
+	results := []*benchResult{
+		benchTicks(ctx, db, healthCheckRepo, dueAt, *ticks),
+		benchLocks(ctx, db, lockRepo, configs, *pods),
+		benchWrites(ctx, db, configs, *executions, *writers, *batchSize),
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{"configs": len(configs), "results": results})
+	}
+
+	fmt.Printf("Due health checks: %d\n\n", len(configs))
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "SCENARIO\tOPS\tERRORS\tOPS/SEC\tP50 MS\tP95 MS\tP99 MS\tMAX MS\tSERVER OPS\tNOTE")
+	for _, r := range results {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t%d\t%s\n",
+			r.Name, r.Ops, r.Errors, r.OpsPerSec, r.P50Ms, r.P95Ms, r.P99Ms, r.MaxMs, r.ServerOps, r.Note)
+	}
+	return table.Flush()
+}
+
+// benchTicks times the query each tick runs to find due checks
+func benchTicks(ctx context.Context, db *database.MongoDB, repo *database.HealthCheckRepository, dueAt time.Time, ticks int) *benchResult {
+	result := startBench(ctx, db, "tick_find_due")
+
+	var found int
+	for range ticks {
+		start := time.Now()
+		configs, err := repo.FindScheduledChecks(ctx, dueAt)
+		result.record(time.Since(start), err)
+		found = len(configs)
+	}
+
+	result.finish(ctx, db)
+	result.Note = fmt.Sprintf("%d due per tick", found)
+	return result
+}
+
+// benchLocks has every simulated pod try to lock every due check, as concurrent ticks do,
+// and verifies each check is locked by exactly one pod
+func benchLocks(ctx context.Context, db *database.MongoDB, repo *database.LockRepository, configs []model.HealthCheckConfig, pods int) *benchResult {
+	result := startBench(ctx, db, "lock_acquire")
+
+	var mu sync.Mutex
+	owners := make(map[primitive.ObjectID]int, len(configs))
+	var contended atomic.Int64
+
+	var wg sync.WaitGroup
+	for pod := range pods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			podID := fmt.Sprintf("%spod-%d", seedPrefix, pod)
+
+			// Pods see the due checks in different orders, like independent ticks
+			order := rand.Perm(len(configs))
+			for _, i := range order {
+				id := configs[i].ID
+				start := time.Now()
+				acquired, err := repo.AcquireLock(ctx, id, podID, time.Minute)
+
+				mu.Lock()
+				result.record(time.Since(start), err)
+				if acquired {
+					owners[id]++
+				} else if err == nil {
+					contended.Add(1)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	result.finish(ctx, db)
+
+	var duplicates int
+	for _, count := range owners {
+		if count > 1 {
+			duplicates++
+		}
+	}
+	result.Note = fmt.Sprintf("%d locked, %d contended, %d locked twice", len(owners), contended.Load(), duplicates)
+
+	// Release the locks so a running scheduler isn't held up
+	for pod := range pods {
+		repo.ReleaseAllLocks(ctx, fmt.Sprintf("%spod-%d", seedPrefix, pod))
+	}
+	return result
+}
+
+// benchWrites inserts synthetic execution histories through the batched execution writer
+func benchWrites(ctx context.Context, db *database.MongoDB, configs []model.HealthCheckConfig, executions, writers, batchSize int) *benchResult {
+	result := startBench(ctx, db, "execution_insert")
+
+	writer := database.NewExecutionWriter(database.NewExecutionRepository(db), batchSize, time.Second)
+	writer.Start()
+
+	var next atomic.Int64
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				n := int(next.Add(1)) - 1
+				if n >= executions {
+					return
+				}
+				config := configs[n%len(configs)]
+
+				start := time.Now()
+				err := writer.Write(ctx, syntheticExecution(config, n))
+
+				mu.Lock()
+				result.record(time.Since(start), err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Buffered writes only count once they are flushed
+	start := time.Now()
+	writer.Stop(ctx)
+	result.finish(ctx, db)
+	result.Note = fmt.Sprintf("batch size %d, final flush %s", batchSize, time.Since(start).Round(time.Millisecond))
+	return result
+}
+
+// syntheticExecution returns an execution history like a healthy scheduled run of config
+func syntheticExecution(config model.HealthCheckConfig, n int) *model.ExecutionHistory {
+	now := time.Now().UTC()
+	return &model.ExecutionHistory{
+		ID:            primitive.NewObjectID(),
+		CorrelationID: fmt.Sprintf("%s%d-%s", seedPrefix, n, primitive.NewObjectID().Hex()),
+		ConfigID:      config.ID,
+		ConfigName:    config.Name,
+		ExecutedAt:    now,
+		DurationMs:    50,
+		Request:       model.ExecutionRequest{URL: config.Target.URL, Method: config.Target.Method},
+		Response: model.ExecutionResponse{
+			StatusCode: 200,
+			Body:       `{"status":"ok"}`,
+		},
+		RulesEvaluation: []model.RuleEvaluation{{RuleName: "status_ok", ExtractedValue: "ok"}},
+		AlertsTriggered: make([]model.AlertTriggered, 0),
+		Status:          "success",
+		Metadata: model.ExecutionMetadata{
+			TriggerType: model.TriggerScheduled,
+			TriggeredBy: seedTag,
+			PodID:       seedPrefix + "bench",
+		},
+	}
+}
+
+// startBench starts timing a scenario
+func startBench(ctx context.Context, db *database.MongoDB, name string) *benchResult {
+	return &benchResult{Name: name, startedAt: time.Now(), serverBase: serverOps(ctx, db)}
+}
+
+// record adds an operation's latency and outcome
+func (r *benchResult) record(latency time.Duration, err error) {
+	r.Ops++
+	if err != nil {
+		r.Errors++
+	}
+	r.latencies = append(r.latencies, latency)
+}
+
+// finish computes throughput and latency percentiles
+func (r *benchResult) finish(ctx context.Context, db *database.MongoDB) {
+	elapsed := time.Since(r.startedAt)
+	r.Seconds = elapsed.Seconds()
+	if r.Seconds > 0 {
+		r.OpsPerSec = float64(r.Ops) / r.Seconds
+	}
+	if r.serverBase >= 0 {
+		if ops := serverOps(ctx, db); ops >= 0 {
+			r.ServerOps = ops - r.serverBase
+		}
+	}
+
+	slices.Sort(r.latencies)
+	r.P50Ms = latencyPercentile(r.latencies, 0.50)
+	r.P95Ms = latencyPercentile(r.latencies, 0.95)
+	r.P99Ms = latencyPercentile(r.latencies, 0.99)
+	r.MaxMs = latencyPercentile(r.latencies, 1)
+}
+
+// latencyPercentile returns the p-th percentile of sorted latencies in milliseconds
+func latencyPercentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := min(int(p*float64(len(sorted))), len(sorted)-1)
+	return float64(sorted[i].Microseconds()) / 1000
+}
+
+// serverOps returns the server's total operation count from serverStatus, or -1 when the
+// user isn't allowed to run it
+func serverOps(ctx context.Context, db *database.MongoDB) int64 {
+	var status struct {
+		Opcounters bson.M `bson:"opcounters"`
+	}
+	if err := db.Database.RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&status); err != nil {
+		return -1
+	}
+
+	var total int64
+	for _, count := range status.Opcounters {
+		switch n := count.(type) {
+		case int32:
+			total += int64(n)
+		case int64:
+			total += n
+		}
+	}
+	return total
+}
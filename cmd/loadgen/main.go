@@ -0,0 +1,102 @@
+// Command loadgen seeds Raven's database with synthetic health checks, simulates the
+// endpoints they call and benchmarks the scheduler's database paths at scale.
+//
+// Usage:
+//
+//	loadgen seed    -configs 5000 -target http://localhost:9090
+//	loadgen target  -addr :9090 -latency 50ms -jitter 20ms -error-rate 0.01
+//	loadgen bench   -pods 4 -ticks 20 -executions 10000
+//	loadgen cleanup
+//
+// MongoDB is configured with the server's MONGO_* environment variables. Everything loadgen
+// creates is tagged, so cleanup only removes synthetic data.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dandantas/raven/internal/config"
+	"github.com/dandantas/raven/internal/database"
+)
+
+// seedTag marks health checks created by loadgen
+const seedTag = "loadgen"
+
+// seedPrefix starts the name of every health check created by loadgen
+const seedPrefix = "loadgen-"
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	config.InitLogger(cfg)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	command, args := os.Args[1], os.Args[2:]
+	var err error
+	switch command {
+	case "target":
+		err = runTarget(ctx, args)
+	case "seed", "bench", "cleanup":
+		var db *database.MongoDB
+		db, err = connect(ctx, cfg)
+		if err != nil {
+			break
+		}
+		defer db.Disconnect(context.Background())
+
+		switch command {
+		case "seed":
+			err = runSeed(ctx, db, args)
+		case "bench":
+			err = runBench(ctx, db, cfg, args)
+		case "cleanup":
+			err = runCleanup(ctx, db)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		slog.Error("loadgen failed", "command", command, "error", err)
+		os.Exit(1)
+	}
+}
+
+// usage prints the available commands
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: loadgen <command> [flags]
+
+Commands:
+  seed     Create synthetic scheduled health checks
+  target   Serve simulated endpoints for the seeded checks
+  bench    Measure tick latency, lock contention and MongoDB throughput
+  cleanup  Remove everything loadgen created
+
+Run "loadgen <command> -h" for the flags of a command.`)
+}
+
+// connect connects to MongoDB with the server's settings
+func connect(ctx context.Context, cfg *config.Config) (*database.MongoDB, error) {
+	return database.Connect(ctx, cfg.MongoURI, cfg.MongoDatabase, database.ConnectOptions{
+		Timeout:                cfg.MongoTimeout,
+		MaxPoolSize:            uint64(cfg.MongoMaxPoolSize),
+		MinPoolSize:            uint64(cfg.MongoMinPoolSize),
+		MaxConnIdleTime:        cfg.MongoMaxConnIdleTime,
+		ConnectTimeout:         cfg.MongoConnectTimeout,
+		SocketTimeout:          cfg.MongoSocketTimeout,
+		ServerSelectionTimeout: cfg.MongoServerSelectionTimeout,
+		Compressors:            cfg.MongoCompressors,
+	})
+}
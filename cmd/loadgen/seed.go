@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seedBatchSize is the number of health checks inserted per round-trip
+const seedBatchSize = 1000
+
+// runSeed inserts synthetic scheduled health checks calling the simulated target. Their next
+// runs are spread over the schedule's first minute so ticks see a steady number of due checks.
+func runSeed(ctx context.Context, db *database.MongoDB, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	count := flags.Int("configs", 1000, "Number of health checks to create")
+	target := flags.String("target", "http://localhost:9090", "Base URL of the simulated target")
+	schedule := flags.String("schedule", "* * * * *", "Cron schedule of the created checks")
+	due := flags.Bool("due", false, "Make every check due immediately instead of spreading them over a minute")
+	flags.Parse(args)
+
+	if _, err := model.ParseSchedule(*schedule); err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	collection := db.GetCollection(database.CollectionHealthCheckConfigs)
+	existing, err := collection.CountDocuments(ctx, bson.M{"metadata.tags": seedTag})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	start := time.Now()
+	batch := make([]interface{}, 0, seedBatchSize)
+	for i := range *count {
+		n := int(existing) + i
+		next := now
+		if !*due {
+			next = now.Add(time.Duration(n%60) * time.Second)
+		}
+		batch = append(batch, seedConfig(n, strings.TrimRight(*target, "/"), *schedule, now, next))
+
+		if len(batch) == seedBatchSize || i == *count-1 {
+			if _, err := collection.InsertMany(ctx, batch); err != nil {
+				return fmt.Errorf("failed to insert health checks: %w", err)
+			}
+			batch = batch[:0]
+		}
+	}
+
+	slog.Info("Seeded health checks",
+		"count", *count,
+		"total", int(existing)+*count,
+		"duration", time.Since(start),
+	)
+	return nil
+}
+
+// seedConfig returns the n-th synthetic health check
+func seedConfig(n int, target, schedule string, now, next time.Time) *model.HealthCheckConfig {
+	return &model.HealthCheckConfig{
+		ID:      primitive.NewObjectID(),
+		Name:    fmt.Sprintf("%s%06d", seedPrefix, n),
+		Enabled: true,
+		Target: model.Target{
+			URL:     fmt.Sprintf("%s/check/%d", target, n),
+			Method:  "GET",
+			Timeout: 10,
+		},
+		Rules: []model.Rule{{
+			Name:          "status_ok",
+			Expression:    "$.status",
+			Operator:      "ne",
+			ExpectedValue: "ok",
+			AlertOnMatch:  true,
+		}},
+		Webhook: model.Webhook{
+			URL:    target + "/webhook",
+			Method: "POST",
+		},
+		Metadata: model.Metadata{
+			CreatedAt: now,
+			UpdatedAt: now,
+			CreatedBy: seedTag,
+			Tags:      []string{seedTag},
+		},
+		Schedule:         schedule,
+		ScheduleEnabled:  true,
+		NextScheduledRun: next,
+	}
+}
+
+// runCleanup removes the seeded health checks with their locks, executions and alerts
+func runCleanup(ctx context.Context, db *database.MongoDB) error {
+	configs := db.GetCollection(database.CollectionHealthCheckConfigs)
+
+	var ids []primitive.ObjectID
+	cursor, err := configs.Find(ctx, bson.M{"metadata.tags": seedTag})
+	if err != nil {
+		return err
+	}
+	var seeded []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &seeded); err != nil {
+		return err
+	}
+	for _, config := range seeded {
+		ids = append(ids, config.ID)
+	}
+
+	deletes := []struct {
+		collection string
+		filter     bson.M
+	}{
+		{database.CollectionScheduleLocks, bson.M{"config_id": bson.M{"$in": ids}}},
+		{database.CollectionExecutionHistory, bson.M{"config_name": bson.M{"$regex": "^" + seedPrefix}}},
+		{database.CollectionAlertLogs, bson.M{"config_id": bson.M{"$in": ids}}},
+		{database.CollectionStateTransitions, bson.M{"config_id": bson.M{"$in": ids}}},
+		{database.CollectionDowntimes, bson.M{"config_id": bson.M{"$in": ids}}},
+		{database.CollectionHealthCheckConfigs, bson.M{"metadata.tags": seedTag}},
+	}
+	for _, d := range deletes {
+		result, err := db.GetCollection(d.collection).DeleteMany(ctx, d.filter)
+		if err != nil {
+			return fmt.Errorf("failed to clean %s: %w", d.collection, err)
+		}
+		slog.Info("Removed synthetic documents", "collection", d.collection, "count", result.DeletedCount)
+	}
+	return nil
+}
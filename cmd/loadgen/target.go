@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// runTarget serves simulated health check endpoints with configurable latency and errors.
+// Seeded checks call GET /check/{id}, and alerts are posted to POST /webhook.
+func runTarget(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("target", flag.ExitOnError)
+	addr := flags.String("addr", ":9090", "Listen address")
+	latency := flags.Duration("latency", 50*time.Millisecond, "Mean response latency")
+	jitter := flags.Duration("jitter", 20*time.Millisecond, "Maximum deviation from the mean latency")
+	errorRate := flags.Float64("error-rate", 0.01, "Fraction of calls answered with 503 and an unhealthy body")
+	flags.Parse(args)
+
+	var checks, failures, alerts atomic.Int64
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /check/{id}", func(w http.ResponseWriter, r *http.Request) {
+		checks.Add(1)
+
+		delay := *latency
+		if *jitter > 0 {
+			delay += time.Duration(rand.Int64N(int64(2**jitter))) - *jitter
+		}
+		select {
+		case <-time.After(max(delay, 0)):
+		case <-r.Context().Done():
+			return
+		}
+
+		status, body := http.StatusOK, "ok"
+		if rand.Float64() < *errorRate {
+			failures.Add(1)
+			status, body = http.StatusServiceUnavailable, "degraded"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"id": r.PathValue("id"), "status": body})
+	})
+	mux.HandleFunc("POST /webhook", func(w http.ResponseWriter, r *http.Request) {
+		alerts.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	server := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	// Report call rates every 10 seconds
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				slog.Info("Simulated target traffic",
+					"checks_per_sec", float64(checks.Swap(0))/10,
+					"failures", failures.Swap(0),
+					"alerts", alerts.Swap(0),
+				)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	slog.Info("Serving simulated targets",
+		"addr", *addr,
+		"latency", *latency,
+		"jitter", *jitter,
+		"error_rate", *errorRate,
+	)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -9,13 +9,20 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/dandantas/raven/internal/clock"
 	"github.com/dandantas/raven/internal/config"
 	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/deploy"
 	"github.com/dandantas/raven/internal/handler"
+	"github.com/dandantas/raven/internal/metrics"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/probe"
 	"github.com/dandantas/raven/internal/scheduler"
 	"github.com/dandantas/raven/internal/service"
 	"github.com/dandantas/raven/internal/webhook"
+	"github.com/dandantas/raven/internal/worker"
 	"github.com/dandantas/raven/pkg/middleware"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const version = "1.0.0"
@@ -34,7 +41,16 @@ func main() {
 	defer cancel()
 
 	// Connect to MongoDB
-	db, err := database.Connect(ctx, cfg.MongoURI, cfg.MongoDatabase, cfg.MongoTimeout)
+	db, err := database.Connect(ctx, cfg.MongoURI, cfg.MongoDatabase, database.ConnectOptions{
+		Timeout:                cfg.MongoTimeout,
+		MaxPoolSize:            uint64(cfg.MongoMaxPoolSize),
+		MinPoolSize:            uint64(cfg.MongoMinPoolSize),
+		MaxConnIdleTime:        cfg.MongoMaxConnIdleTime,
+		ConnectTimeout:         cfg.MongoConnectTimeout,
+		SocketTimeout:          cfg.MongoSocketTimeout,
+		ServerSelectionTimeout: cfg.MongoServerSelectionTimeout,
+		Compressors:            cfg.MongoCompressors,
+	})
 	if err != nil {
 		slog.Error("Failed to connect to MongoDB", "error", err)
 		os.Exit(1)
@@ -45,9 +61,27 @@ func main() {
 		}
 	}()
 
-	// Create indexes
-	if err := database.CreateIndexes(ctx, db); err != nil {
-		slog.Error("Failed to create indexes", "error", err)
+	// Route history list queries according to the configured read preference
+	if err := db.SetHistoryReadPreference(cfg.MongoHistoryReadPreference); err != nil {
+		slog.Error("Invalid MongoDB history read preference", "error", err)
+		os.Exit(1)
+	}
+
+	// Create missing indexes and update changed ones
+	if err := database.EnsureIndexes(ctx, db); err != nil {
+		slog.Error("Failed to reconcile indexes", "error", err)
+		os.Exit(1)
+	}
+
+	// Backfill existing documents for schema changes
+	if err := database.RunMigrations(ctx, db, cfg.PodID); err != nil {
+		slog.Error("Failed to run migrations", "error", err)
+		os.Exit(1)
+	}
+
+	// Create the metric samples time-series collection
+	if err := database.EnsureMetricsCollection(ctx, db, cfg.MetricsRetention); err != nil {
+		slog.Error("Failed to create metrics collection", "error", err)
 		os.Exit(1)
 	}
 
@@ -56,38 +90,235 @@ func main() {
 	executionRepo := database.NewExecutionRepository(db)
 	alertRepo := database.NewAlertRepository(db)
 	lockRepo := database.NewLockRepository(db)
+	suiteRepo := database.NewSuiteRepository(db)
+	agentScheduleRepo := database.NewAgentScheduleRepository(db)
+	channelRepo := database.NewChannelRepository(db)
+	authProfileRepo := database.NewAuthProfileRepository(db)
+	metricRepo := database.NewMetricRepository(db)
+	heartbeatRepo := database.NewHeartbeatRepository(db)
+	stateTransitionRepo := database.NewStateTransitionRepository(db)
+	downtimeRepo := database.NewDowntimeRepository(db)
+	budgetRepo := database.NewBudgetRepository(db)
+	auditRepo := database.NewAuditRepository(db)
+
+	// Retry execution history and alert log writes that fail, e.g. during a failover
+	retryQueue := database.NewRetryQueue(cfg.PersistenceRetryQueueSize, cfg.PersistenceRetryMaxBackoff)
+	retryQueue.Start()
+	alertRepo.SetRetryQueue(retryQueue)
+
+	// Parse the webhook destination allow-list
+	webhookPolicy, err := webhook.NewDestinationPolicy(cfg.WebhookAllowedDestinations)
+	if err != nil {
+		slog.Error("Invalid webhook allow-list", "error", err)
+		os.Exit(1)
+	}
+
+	// Load plugins first, so profiles and default webhooks may use their notification types
+	pluginRegistry, err := loadPlugins(cfg)
+	if err != nil {
+		slog.Error("Failed to load plugins", "error", err)
+		os.Exit(1)
+	}
+
+	// Load webhook profiles and the global default webhook
+	webhookProfiles, err := webhook.LoadProfiles(cfg.WebhookProfilesFile)
+	if err != nil {
+		slog.Error("Failed to load webhook profiles", "error", err)
+		os.Exit(1)
+	}
+
+	var defaultWebhook *model.Webhook
+	if cfg.DefaultWebhookURL != "" {
+		defaultWebhook = &model.Webhook{URL: cfg.DefaultWebhookURL, Method: cfg.DefaultWebhookMethod}
+	}
+
+	webhookResolver, err := webhook.NewResolver(defaultWebhook, webhookProfiles, webhookPolicy)
+	if err != nil {
+		slog.Error("Invalid webhook configuration", "error", err)
+		os.Exit(1)
+	}
+	webhookResolver.SetChannelStore(channelRepo)
+
+	// Parse the notification channel told about exceeded execution budgets
+	var adminChannel primitive.ObjectID
+	if cfg.AdminChannelID != "" {
+		adminChannel, err = primitive.ObjectIDFromHex(cfg.AdminChannelID)
+		if err != nil {
+			slog.Error("Invalid admin channel ID", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Resolve shared target credentials from stored auth profiles
+	credentials := probe.NewCredentials(authProfileRepo)
+
+	// Optionally push metric samples to Prometheus
+	var remoteWriter *metrics.RemoteWriter
+	if cfg.PrometheusRemoteWriteURL != "" {
+		remoteWriter = metrics.NewRemoteWriter(cfg.PrometheusRemoteWriteURL, cfg.DefaultWebhookTimeout)
+	}
 
 	// Initialize services
-	healthCheckService := service.NewHealthCheckService(healthCheckRepo)
+	healthCheckService := service.NewHealthCheckService(healthCheckRepo, webhookResolver)
+	healthCheckService.SetCredentials(credentials)
+	healthCheckService.SetStateTransitions(stateTransitionRepo)
+	healthCheckService.SetAudit(auditRepo)
 	executionService := service.NewExecutionService(executionRepo)
 	alertService := service.NewAlertService(alertRepo)
+	statsService := service.NewStatsService(executionRepo, alertRepo, downtimeRepo, healthCheckRepo)
+	channelService := service.NewChannelService(channelRepo, webhookPolicy)
+	authProfileService := service.NewAuthProfileService(authProfileRepo)
+	metricGauges := metrics.NewGauges()
+	metricService := service.NewMetricService(metricRepo, remoteWriter, metricGauges)
 
 	// Initialize HTTP client and webhook dispatcher
-	httpClient := service.NewHTTPClient(cfg.DefaultAPITimeout)
+	httpClient := probe.NewHTTPClient(cfg.DefaultAPITimeout)
 	webhookDispatcher := webhook.NewDispatcher(cfg.DefaultWebhookTimeout)
+	webhookDispatcher.SetPolicy(webhookPolicy)
+	halfOpenWaitSeconds := int(cfg.CircuitBreakerHalfOpenWait.Seconds())
+	webhookDispatcher.SetCircuitBreakerDefaults(model.CircuitBreakerConfig{
+		FailureThreshold:    cfg.CircuitBreakerFailureThreshold,
+		SuccessThreshold:    cfg.CircuitBreakerSuccessThreshold,
+		TimeoutSeconds:      int(cfg.CircuitBreakerTimeout.Seconds()),
+		HalfOpenMaxProbes:   cfg.CircuitBreakerHalfOpenProbes,
+		HalfOpenWaitSeconds: &halfOpenWaitSeconds,
+	})
+	webhookDispatcher.SetPlugins(pluginRegistry)
+	if cfg.SMTPHost != "" {
+		webhookDispatcher.SetSMTP(webhook.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	}
+	ackLinks := webhook.NewAckLinks(cfg.AlertAckSecret, cfg.PublicURL, cfg.AlertAckLinkTTL)
+	webhookDispatcher.SetAckLinks(ackLinks)
+	alertService.SetAckLinks(ackLinks)
+
+	// Initialize alert queue
+	alertQueue := service.NewAlertQueue(webhookDispatcher, alertRepo, executionRepo, cfg.AlertDispatchWorkers, cfg.AlertQueueSize)
+	deliveryMetrics := metrics.NewDeliveryMetrics()
+	alertQueue.SetMetrics(deliveryMetrics)
+	alertQueue.Start()
+	alertService.SetDelivery(alertQueue, webhookResolver)
+
+	// Initialize batched execution history writer
+	executionWriter := database.NewExecutionWriter(executionRepo, cfg.ExecutionBatchSize, cfg.ExecutionFlushInterval)
+	executionWriter.SetMaxDocumentBytes(cfg.ExecutionMaxDocumentBytes)
+	executionWriter.SetRetryQueue(retryQueue)
+	executionWriter.Start()
 
 	// Initialize executor
 	executor := service.NewExecutor(
 		httpClient,
-		webhookDispatcher,
+		alertQueue,
 		healthCheckRepo,
+		executionWriter,
 		executionRepo,
 		alertRepo,
+		webhookResolver,
 	)
+	executor.SetLocation(cfg.PodID, cfg.Region)
+	executor.SetCredentials(credentials)
+	// Probes identify themselves so monitored services can allowlist them
+	targetUserAgent := cfg.TargetUserAgent
+	if targetUserAgent == "" {
+		targetUserAgent = "Raven/" + version
+	}
+	executor.SetProbeOptions(probe.Options{
+		HeaderTimeout:       cfg.TargetHeaderTimeout,
+		BodyTimeout:         cfg.TargetBodyTimeout,
+		RetryOnNetworkError: cfg.TargetRetryOnNetworkError,
+		RetryBudget:         cfg.TargetRetryBudget,
+		RetryBackoff:        cfg.TargetRetryBackoff,
+		RetryOnStatus:       cfg.TargetRetryOnStatus,
+		UserAgent:           targetUserAgent,
+		DefaultHeaders:      cfg.TargetDefaultHeaders,
+	})
+	var browser *probe.Browser
+	if cfg.BrowserEnabled {
+		browser = probe.NewBrowser(cfg.BrowserExecPath, cfg.BrowserRemoteURL)
+		executor.SetBrowser(browser)
+	}
+	executor.SetPlugins(pluginRegistry)
+	executor.SetMetrics(metricService)
+	executor.SetStateTransitions(stateTransitionRepo)
+	executor.SetDowntimes(downtimeRepo)
+	executor.SetBudgets(budgetRepo, cfg.ExecutionDailyBudget, adminChannel)
+	executor.SetAudit(auditRepo)
+	executor.SetBodySampleRate(cfg.ExecutionBodySampleRate)
+
+	// Initialize suite service
+	suiteService := service.NewSuiteService(suiteRepo, executor, alertRepo, alertQueue, webhookResolver)
+
+	// Initialize heartbeat service
+	heartbeatService := service.NewHeartbeatService(heartbeatRepo, alertRepo, alertQueue, webhookResolver)
+
+	// Initialize trigger token service
+	triggerService := service.NewTriggerService(healthCheckRepo, cfg.TriggerSecret)
+
+	// Post-deploy verification reports back as commit statuses when a token is configured
+	deployService := service.NewDeployService(healthCheckRepo, suiteService, cfg.DeployCheckTagPrefix)
+	statusClient := &http.Client{Timeout: cfg.DefaultWebhookTimeout}
+	if cfg.GitHubToken != "" {
+		deployService.SetReporter(deploy.ProviderGitHub, deploy.NewGitHubReporter(cfg.GitHubAPIURL, cfg.GitHubToken, cfg.DeployStatusContext, statusClient))
+	}
+	if cfg.GitLabToken != "" {
+		deployService.SetReporter(deploy.ProviderGitLab, deploy.NewGitLabReporter(cfg.GitLabURL, cfg.GitLabToken, cfg.DeployStatusContext, statusClient))
+	}
+
+	// Initialize agent service
+	agentService := service.NewAgentService(healthCheckRepo, agentScheduleRepo, executor, cfg.AgentLeaseTTL)
+
+	// Initialize worker pool for concurrent batch executions
+	workerPool := worker.NewWorkerPool(cfg.WorkerPoolSize, cfg.MaxConcurrentJobs)
+	workerPool.SetExecutor(func(ctx context.Context, job worker.Job) (interface{}, error) {
+		return executor.Execute(ctx, job.ConfigID, job.CorrelationID, service.ExecuteOptions{
+			BatchID:              job.BatchID,
+			RequestCorrelationID: job.RequestCorrelationID,
+			TriggerType:          model.TriggerAPI,
+			TriggeredBy:          job.TriggeredBy,
+		})
+	})
+	workerPool.Start()
 
 	// Initialize async executor
 	asyncExecutor := service.NewAsyncExecutor(executor)
 
 	// Initialize scheduler
-	sched := scheduler.NewScheduler(cfg, executor, lockRepo, healthCheckRepo)
+	sched := scheduler.NewScheduler(cfg, executor, lockRepo, healthCheckRepo, suiteRepo, suiteService, heartbeatService)
+	schedulerMetrics := metrics.NewSchedulerMetrics()
+	sched.SetMetrics(schedulerMetrics)
+	var simulatedClock *clock.Simulated
+	if cfg.SchedulerSimulatedClock {
+		// Test only: schedules are fast-forwarded through the clock admin endpoint
+		simulatedClock = clock.NewSimulated(time.Now().UTC())
+		sched.SetClock(simulatedClock)
+		executor.SetClock(simulatedClock)
+		slog.Warn("Scheduler is running on a simulated clock", "now", simulatedClock.Now())
+	}
 	sched.Start(ctx)
 
 	// Initialize handlers
 	healthCheckHandler := handler.NewHealthCheckHandler(healthCheckService)
-	executionHandler := handler.NewExecutionHandler(executor, asyncExecutor)
+	executionHandler := handler.NewExecutionHandler(executor, asyncExecutor, workerPool, cfg.BatchMaxConcurrency)
 	historyHandler := handler.NewHistoryHandler(executionService)
 	alertHandler := handler.NewAlertHandler(alertService)
-	healthHandler := handler.NewHealthHandler(db, version)
+	healthHandler := handler.NewHealthHandler(db, retryQueue, sched, version)
+	statsHandler := handler.NewStatsHandler(statsService)
+	suiteHandler := handler.NewSuiteHandler(suiteService)
+	agentHandler := handler.NewAgentHandler(agentService, cfg.AgentToken)
+	channelHandler := handler.NewChannelHandler(channelService)
+	authProfileHandler := handler.NewAuthProfileHandler(authProfileService)
+	metricHandler := handler.NewMetricHandler(metricService)
+	triggerHandler := handler.NewTriggerHandler(triggerService, executor, asyncExecutor)
+	deployHandler := handler.NewDeployHandler(deployService, cfg.GitHubWebhookSecret, cfg.GitLabWebhookSecret)
+	heartbeatHandler := handler.NewHeartbeatHandler(heartbeatService)
+	indexHandler := handler.NewIndexHandler(db)
+	schedulerHandler := handler.NewSchedulerHandler(sched, simulatedClock)
+	circuitBreakerHandler := handler.NewCircuitBreakerHandler(webhookDispatcher.CircuitBreakers())
 
 	// Create CORS config
 	corsConfig := middleware.CORSConfig{
@@ -105,8 +336,35 @@ func main() {
 		historyHandler,
 		alertHandler,
 		healthHandler,
+		statsHandler,
+		suiteHandler,
+		agentHandler,
+		channelHandler,
+		authProfileHandler,
+		metricHandler,
+		triggerHandler,
+		deployHandler,
+		heartbeatHandler,
+		indexHandler,
+		schedulerHandler,
+		circuitBreakerHandler,
+		metrics.Handler(metricGauges, schedulerMetrics, deliveryMetrics),
 		corsConfig,
+		cfg.APIKeys,
 	)
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		slog.Error("Invalid trusted proxies", "error", err)
+		os.Exit(1)
+	}
+	router.SetTrustedProxies(trustedProxies)
+	router.SetAdminListener(cfg.AdminPort != "")
+	router.SetAdminKeys(cfg.AdminAPIKeys)
+	router.SetLogging(middleware.LoggingConfig{
+		CaptureBodies: cfg.LogRequestBodies,
+		MaxBodyBytes:  cfg.LogBodyMaxBytes,
+		RedactFields:  cfg.LogRedactFields,
+	})
 
 	// Create HTTP server
 	server := &http.Server{
@@ -116,10 +374,67 @@ func main() {
 		WriteTimeout: cfg.HTTPWriteTimeout,
 	}
 
+	// Terminate TLS natively when a certificate or autocert domains are configured
+	var redirectServer *http.Server
+	if tlsEnabled(cfg) {
+		tlsConfig, certManager, err := buildTLSConfig(cfg)
+		if err != nil {
+			slog.Error("Invalid TLS configuration", "error", err)
+			os.Exit(1)
+		}
+		server.TLSConfig = tlsConfig
+
+		if cfg.HTTPRedirectPort != "" {
+			var redirect http.Handler = httpsRedirect(cfg.HTTPPort)
+			if certManager != nil {
+				// Also answer ACME HTTP-01 challenges on the plain listener
+				redirect = certManager.HTTPHandler(redirect)
+			}
+			redirectServer = &http.Server{
+				Addr:         ":" + cfg.HTTPRedirectPort,
+				Handler:      redirect,
+				ReadTimeout:  cfg.HTTPReadTimeout,
+				WriteTimeout: cfg.HTTPWriteTimeout,
+			}
+			go func() {
+				slog.Info("Starting HTTP redirect server", "port", cfg.HTTPRedirectPort)
+				if err := redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					slog.Error("HTTP redirect server error", "error", err)
+					os.Exit(1)
+				}
+			}()
+		}
+	}
+
+	// Serve operational endpoints on the admin port so the API port can be public
+	var adminServer *http.Server
+	if cfg.AdminPort != "" {
+		adminServer = &http.Server{
+			Addr:        ":" + cfg.AdminPort,
+			Handler:     router.AdminHandler(),
+			ReadTimeout: cfg.HTTPReadTimeout,
+			// No write timeout: CPU profiles and traces stream for their requested duration
+		}
+		go func() {
+			slog.Info("Starting admin server", "port", cfg.AdminPort)
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("Admin server error", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// Start server in goroutine
 	go func() {
-		slog.Info("Starting HTTP server", "port", cfg.HTTPPort)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			slog.Info("Starting HTTPS server", "port", cfg.HTTPPort, "min_tls_version", cfg.TLSMinVersion)
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			slog.Info("Starting HTTP server", "port", cfg.HTTPPort)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			slog.Error("HTTP server error", "error", err)
 			os.Exit(1)
 		}
@@ -145,6 +460,32 @@ func main() {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		slog.Error("HTTP server shutdown error", "error", err)
 	}
+	if redirectServer != nil {
+		redirectServer.Shutdown(shutdownCtx)
+	}
+	if adminServer != nil {
+		adminServer.Shutdown(shutdownCtx)
+	}
+
+	// Stop worker pool
+	workerPool.Stop()
+
+	// Flush buffered execution history
+	slog.Info("Stopping execution writer...")
+	executionWriter.Stop(shutdownCtx)
+
+	// Drain pending alerts
+	slog.Info("Stopping alert queue...")
+	alertQueue.Stop(shutdownCtx)
+
+	// Last attempt at writes that failed earlier
+	slog.Info("Stopping persistence retry queue...")
+	retryQueue.Stop(shutdownCtx)
+
+	browser.Close()
+	if err := pluginRegistry.Close(); err != nil {
+		slog.Error("Failed to close plugins", "error", err)
+	}
 
 	slog.Info("Raven Alert Service stopped")
 }
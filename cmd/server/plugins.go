@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/dandantas/raven/internal/config"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/probe"
+	"github.com/dandantas/raven/internal/webhook"
+	"github.com/dandantas/raven/pkg/plugins"
+)
+
+// loadPlugins loads the configured Go, exec and gRPC plugins and makes their check and
+// notification types valid
+func loadPlugins(cfg *config.Config) (*plugins.Registry, error) {
+	registry := plugins.NewRegistry()
+
+	for _, path := range cfg.PluginFiles {
+		if err := registry.Load(path); err != nil {
+			return registry, err
+		}
+	}
+	for checkType, command := range cfg.CheckPluginsExec {
+		executor, err := plugins.NewExecCheckExecutor(command)
+		if err != nil {
+			return registry, err
+		}
+		if err := registry.RegisterCheckExecutor(checkType, executor); err != nil {
+			return registry, err
+		}
+	}
+	for checkType, target := range cfg.CheckPluginsGRPC {
+		executor, err := plugins.NewGRPCCheckExecutor(target)
+		if err != nil {
+			return registry, err
+		}
+		if err := registry.RegisterCheckExecutor(checkType, executor); err != nil {
+			executor.Close()
+			return registry, err
+		}
+	}
+
+	for webhookType, command := range cfg.AlertPluginsExec {
+		sender, err := plugins.NewExecAlertSender(command)
+		if err != nil {
+			return registry, err
+		}
+		if err := registry.RegisterAlertSender(webhookType, sender); err != nil {
+			return registry, err
+		}
+	}
+	for webhookType, target := range cfg.AlertPluginsGRPC {
+		sender, err := plugins.NewGRPCAlertSender(target)
+		if err != nil {
+			return registry, err
+		}
+		if err := registry.RegisterAlertSender(webhookType, sender); err != nil {
+			sender.Close()
+			return registry, err
+		}
+	}
+
+	for _, checkType := range registry.CheckTypes() {
+		if err := model.RegisterCheckType(checkType, probe.PluginValidator(registry.CheckExecutor(checkType))); err != nil {
+			return registry, err
+		}
+		slog.Info("Loaded check type plugin", "type", checkType)
+	}
+	for _, webhookType := range registry.AlertSenderTypes() {
+		if err := model.RegisterWebhookType(webhookType, webhook.PluginValidator(registry.AlertSender(webhookType))); err != nil {
+			return registry, err
+		}
+		slog.Info("Loaded notification type plugin", "type", webhookType)
+	}
+	return registry, nil
+}
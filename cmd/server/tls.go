@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/dandantas/raven/internal/config"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsVersions maps TLS_MIN_VERSION values to crypto/tls constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsEnabled reports whether the API server should terminate TLS itself
+func tlsEnabled(cfg *config.Config) bool {
+	return cfg.TLSCertFile != "" || len(cfg.TLSAutocertDomains) > 0
+}
+
+// buildTLSConfig returns the TLS configuration for the API server and, when autocert
+// is used, the certificate manager that must also answer ACME HTTP challenges
+func buildTLSConfig(cfg *config.Config) (*tls.Config, *autocert.Manager, error) {
+	if cfg.TLSCertFile != "" && len(cfg.TLSAutocertDomains) > 0 {
+		return nil, nil, errors.New("TLS_CERT_FILE and TLS_AUTOCERT_DOMAINS are mutually exclusive")
+	}
+
+	minVersion, ok := tlsVersions[cfg.TLSMinVersion]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid TLS_MIN_VERSION: %s (must be 1.0, 1.1, 1.2 or 1.3)", cfg.TLSMinVersion)
+	}
+
+	cipherSuites, err := parseCipherSuites(cfg.TLSCipherSuites)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tlsConfig *tls.Config
+	var manager *autocert.Manager
+	if len(cfg.TLSAutocertDomains) > 0 {
+		manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLSAutocertDomains...),
+			Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+			Email:      cfg.TLSAutocertEmail,
+		}
+		tlsConfig = manager.TLSConfig()
+	} else {
+		if cfg.TLSKeyFile == "" {
+			return nil, nil, errors.New("TLS_KEY_FILE is required with TLS_CERT_FILE")
+		}
+		certificate, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			NextProtos:   []string{"h2", "http/1.1"},
+		}
+	}
+
+	tlsConfig.MinVersion = minVersion
+	tlsConfig.CipherSuites = cipherSuites
+
+	return tlsConfig, manager, nil
+}
+
+// parseCipherSuites resolves cipher suite names such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256.
+// An empty list keeps the Go defaults; TLS 1.3 suites are not configurable.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS cipher suite: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// httpsRedirect redirects plain HTTP requests to the HTTPS listener
+func httpsRedirect(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
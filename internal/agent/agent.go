@@ -0,0 +1,192 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dandantas/raven/internal/config"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/probe"
+	"github.com/google/uuid"
+)
+
+// Agent pulls due checks for its region from the Raven API, probes the targets
+// locally and reports the results back
+type Agent struct {
+	cfg         *config.AgentConfig
+	apiClient   *http.Client
+	probeClient *http.Client
+}
+
+// New creates a new agent
+func New(cfg *config.AgentConfig) *Agent {
+	return &Agent{
+		cfg:         cfg,
+		apiClient:   &http.Client{Timeout: 30 * time.Second},
+		probeClient: probe.NewHTTPClient(cfg.DefaultTimeout),
+	}
+}
+
+// Run polls for due checks until the context is cancelled
+func (a *Agent) Run(ctx context.Context) {
+	slog.Info("Starting agent",
+		"agent_id", a.cfg.AgentID,
+		"region", a.cfg.Region,
+		"api_url", a.cfg.APIURL,
+		"poll_interval", a.cfg.PollInterval,
+	)
+
+	ticker := time.NewTicker(a.cfg.PollInterval)
+	defer ticker.Stop()
+
+	// Poll immediately on start
+	a.poll(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			a.poll(ctx)
+		case <-ctx.Done():
+			slog.Info("Agent stopped", "agent_id", a.cfg.AgentID)
+			return
+		}
+	}
+}
+
+// poll leases due checks, runs them and reports the results
+func (a *Agent) poll(ctx context.Context) {
+	var lease model.AgentLeaseResponse
+	err := a.post(ctx, "/api/v1/agents/lease", model.AgentLeaseRequest{
+		AgentID: a.cfg.AgentID,
+		Region:  a.cfg.Region,
+		Max:     a.cfg.MaxLease,
+	}, &lease)
+	if err != nil {
+		slog.Error("Failed to lease checks", "error", err)
+		return
+	}
+
+	if len(lease.Checks) == 0 {
+		slog.Debug("No checks due", "region", a.cfg.Region)
+		return
+	}
+
+	slog.Info("Leased checks", "count", len(lease.Checks), "lease_seconds", lease.LeaseSeconds)
+
+	results := make([]model.AgentResult, len(lease.Checks))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, a.cfg.Concurrency)
+	for i, check := range lease.Checks {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, check model.HealthCheckConfig) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = a.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	var report model.AgentReportResponse
+	err = a.post(ctx, "/api/v1/agents/results", model.AgentReport{
+		AgentID: a.cfg.AgentID,
+		Region:  a.cfg.Region,
+		Results: results,
+	}, &report)
+	if err != nil {
+		slog.Error("Failed to report results", "count", len(results), "error", err)
+		return
+	}
+
+	slog.Info("Reported results",
+		"accepted", report.Accepted,
+		"rejected", report.Rejected,
+	)
+	for _, reportErr := range report.Errors {
+		slog.Warn("Result rejected", "error", reportErr)
+	}
+}
+
+// runCheck probes the target of a single check
+func (a *Agent) runCheck(ctx context.Context, check model.HealthCheckConfig) model.AgentResult {
+	correlationID := uuid.New().String()
+
+	// Agents only make the target call, so the execution limit bounds the probe
+	if check.MaxExecutionSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(check.MaxExecutionSeconds)*time.Second)
+		defer cancel()
+	}
+
+	opts := probe.Options{
+		UserAgent:      a.cfg.TargetUserAgent,
+		DefaultHeaders: a.cfg.TargetDefaultHeaders,
+	}
+	if !check.DisableProbeHeaders {
+		opts.CheckID = check.ID.Hex()
+		opts.CorrelationID = correlationID
+	}
+
+	start := time.Now()
+	request, response, _ := probe.Call(ctx, a.probeClient, check.Target, opts)
+	duration := time.Since(start)
+
+	slog.Info("Probed target",
+		"correlation_id", correlationID,
+		"config_name", check.Name,
+		"status_code", response.StatusCode,
+		"duration_ms", duration.Milliseconds(),
+	)
+
+	return model.AgentResult{
+		ConfigID:      check.ID.Hex(),
+		CorrelationID: correlationID,
+		DurationMs:    duration.Milliseconds(),
+		Request:       request,
+		Response:      response,
+	}
+}
+
+// post sends a JSON request to the Raven API and decodes the JSON response into out
+func (a *Agent) post(ctx context.Context, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := strings.TrimSuffix(a.cfg.APIURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if a.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.cfg.Token)
+	}
+
+	resp, err := a.apiClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
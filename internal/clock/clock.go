@@ -0,0 +1,183 @@
+// Package clock abstracts wall-clock time so the scheduler can be driven by a simulated
+// clock that tests fast-forward deterministically.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and schedules tickers and timers
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Ticker delivers ticks at intervals
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer is a pending function call scheduled with AfterFunc
+type Timer interface {
+	Stop() bool
+}
+
+// Real is the wall clock
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// Simulated is a clock that only moves when advanced. Tickers and timers fire as the
+// simulated time passes their deadlines.
+type Simulated struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is a ticker or timer pending on a simulated clock
+type waiter struct {
+	clock  *Simulated
+	at     time.Time
+	period time.Duration // Zero for timers
+	c      chan time.Time
+	f      func()
+}
+
+// NewSimulated creates a simulated clock starting at start
+func NewSimulated(start time.Time) *Simulated {
+	return &Simulated{now: start}
+}
+
+// Now returns the simulated time
+func (c *Simulated) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker creates a ticker that ticks every d of simulated time
+func (c *Simulated) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &waiter{clock: c, at: c.now.Add(d), period: d, c: make(chan time.Time, 1)}
+	c.waiters = append(c.waiters, w)
+	return simulatedTicker{w}
+}
+
+// AfterFunc calls f in its own goroutine once d of simulated time has passed
+func (c *Simulated) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	w := &waiter{clock: c, at: c.now.Add(d), f: f}
+	c.waiters = append(c.waiters, w)
+	c.mu.Unlock()
+
+	if d <= 0 {
+		c.Advance(0)
+	}
+	return simulatedTimer{w}
+}
+
+// Advance moves the simulated time forward by d, firing due tickers and timers in
+// deadline order, and returns the new time
+func (c *Simulated) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	return c.setLocked(c.now.Add(d))
+}
+
+// Set moves the simulated time to t, firing due tickers and timers. Time never moves backwards.
+func (c *Simulated) Set(t time.Time) time.Time {
+	c.mu.Lock()
+	if t.Before(c.now) {
+		t = c.now
+	}
+	return c.setLocked(t)
+}
+
+// setLocked moves the time to t and fires due waiters; it unlocks the clock
+func (c *Simulated) setLocked(t time.Time) time.Time {
+	c.now = t
+
+	type firing struct {
+		at time.Time
+		w  *waiter
+	}
+	var due []firing
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(t) {
+			pending = append(pending, w)
+			continue
+		}
+		due = append(due, firing{w.at, w})
+		if w.period > 0 {
+			// Like time.Ticker, ticks missed while the clock jumps are dropped
+			for !w.at.After(t) {
+				w.at = w.at.Add(w.period)
+			}
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].at.Before(due[j].at) })
+	for _, d := range due {
+		w := d.w
+		if w.f != nil {
+			go w.f()
+			continue
+		}
+		select {
+		case w.c <- t:
+		default:
+			// The previous tick hasn't been received yet
+		}
+	}
+	return t
+}
+
+// remove takes the waiter off the clock, reporting whether it was still pending
+func (w *waiter) remove() bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, pending := range c.waiters {
+		if pending == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// simulatedTicker is a Ticker on a simulated clock
+type simulatedTicker struct{ *waiter }
+
+func (t simulatedTicker) C() <-chan time.Time { return t.c }
+
+func (t simulatedTicker) Stop() { t.remove() }
+
+// simulatedTimer is a Timer on a simulated clock
+type simulatedTimer struct{ *waiter }
+
+func (t simulatedTimer) Stop() bool { return t.remove() }
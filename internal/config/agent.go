@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgentConfig holds the configuration of a remote probing agent
+type AgentConfig struct {
+	APIURL         string
+	Token          string
+	AgentID        string
+	Region         string
+	PollInterval   time.Duration
+	Concurrency    int
+	MaxLease       int
+	DefaultTimeout time.Duration
+
+	// Target request identification
+	TargetUserAgent      string
+	TargetDefaultHeaders map[string]string
+
+	// Logging Configuration
+	LogLevel  string
+	LogFormat string
+}
+
+// LoadAgent reads agent configuration from environment variables with sensible defaults
+func LoadAgent() *AgentConfig {
+	// Agents are identified by hostname unless configured explicitly
+	agentID, err := os.Hostname()
+	if err != nil {
+		agentID = uuid.New().String()
+	}
+
+	return &AgentConfig{
+		APIURL:         getEnv("RAVEN_API_URL", "http://localhost:8080"),
+		Token:          getEnv("AGENT_TOKEN", ""),
+		AgentID:        getEnv("AGENT_ID", agentID),
+		Region:         getEnv("AGENT_REGION", ""),
+		PollInterval:   getDurationEnv("AGENT_POLL_INTERVAL_SEC", 15) * time.Second,
+		Concurrency:    getIntEnv("AGENT_CONCURRENCY", 5),
+		MaxLease:       getIntEnv("AGENT_MAX_LEASE", 20),
+		DefaultTimeout: getDurationEnv("DEFAULT_API_TIMEOUT_SEC", 30) * time.Second,
+
+		TargetUserAgent:      getEnv("TARGET_USER_AGENT", "Raven-Agent"),
+		TargetDefaultHeaders: getMapEnv("TARGET_DEFAULT_HEADERS"),
+
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+		LogFormat: getEnv("LOG_FORMAT", "json"),
+	}
+}
@@ -4,33 +4,116 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Config holds all application configuration
 type Config struct {
+	// Instance Configuration
+	PodID  string // Identifies this instance in locks and execution history
+	Region string // Region label recorded on executions run by this instance
+
 	// MongoDB Configuration
 	MongoURI      string
 	MongoDatabase string
 	MongoTimeout  time.Duration
 
+	MongoHistoryReadPreference string
+
+	// MongoDB Pool Configuration
+	MongoMaxPoolSize            int
+	MongoMinPoolSize            int
+	MongoMaxConnIdleTime        time.Duration
+	MongoConnectTimeout         time.Duration
+	MongoSocketTimeout          time.Duration
+	MongoServerSelectionTimeout time.Duration
+	MongoCompressors            []string
+
 	// HTTP Server Configuration
 	HTTPPort         string
 	HTTPReadTimeout  time.Duration
 	HTTPWriteTimeout time.Duration
+	AdminPort        string // Serves health, metrics and pprof separately when set
+
+	// TLS Configuration
+	TLSCertFile         string
+	TLSKeyFile          string
+	TLSAutocertDomains  []string
+	TLSAutocertCacheDir string
+	TLSAutocertEmail    string
+	TLSMinVersion       string
+	TLSCipherSuites     []string
+	HTTPRedirectPort    string // Plain HTTP listener redirecting to HTTPS; empty disables it
 
 	// Worker Pool Configuration
 	WorkerPoolSize    int
 	MaxConcurrentJobs int
 
+	// Batch Execution Configuration
+	BatchMaxConcurrency int
+
+	// Alert Dispatch Configuration
+	AlertDispatchWorkers           int
+	AlertQueueSize                 int
+	CircuitBreakerFailureThreshold int // Failed deliveries in a row that stop deliveries to a host
+	CircuitBreakerSuccessThreshold int // Successful trial deliveries that resume them
+	CircuitBreakerTimeout          time.Duration
+	CircuitBreakerHalfOpenProbes   int           // Deliveries let through at once while a breaker is half-open
+	CircuitBreakerHalfOpenWait     time.Duration // How long other deliveries wait for a probe; 0 fails them at once
+
+	// Execution History Persistence Configuration
+	ExecutionBatchSize        int
+	ExecutionFlushInterval    time.Duration
+	ExecutionBodySampleRate   int // Store the response body of 1 in N successful runs; failures always keep it
+	ExecutionMaxDocumentBytes int // Truncate bodies of larger executions; 0 = MongoDB's 16MB limit
+
+	// Persistence Retry Configuration
+	PersistenceRetryQueueSize  int           // Failed execution and alert log writes kept for retry
+	PersistenceRetryMaxBackoff time.Duration // Longest wait between retries of a failed write
+
 	// Logging Configuration
-	LogLevel  string
-	LogFormat string
+	LogLevel         string
+	LogFormat        string
+	LogRequestBodies bool
+	LogBodyMaxBytes  int
+	LogRedactFields  []string
 
 	// Timeout Configuration
 	DefaultAPITimeout     time.Duration
 	DefaultWebhookTimeout time.Duration
 
+	// Target Call Configuration
+	TargetHeaderTimeout       time.Duration // Limit on waiting for target response headers; zero disables it
+	TargetBodyTimeout         time.Duration // Limit on reading target response bodies; zero disables it
+	TargetRetryOnNetworkError bool
+	TargetRetryBudget         int
+	TargetRetryBackoff        time.Duration
+	TargetRetryOnStatus       []int
+	TargetUserAgent           string            // Defaults to Raven/<version>
+	TargetDefaultHeaders      map[string]string // Sent with every target request unless the target overrides them
+
+	// Headless Chrome for browser checks; disabled by default
+	BrowserEnabled   bool
+	BrowserExecPath  string // Chrome binary; found on the PATH when empty
+	BrowserRemoteURL string // DevTools WebSocket URL of a running browser, used instead of launching one
+
+	// Plugins adding custom check and notification types
+	PluginFiles      []string          // Go plugins to load
+	CheckPluginsExec map[string]string // Check type to the command of its exec plugin
+	CheckPluginsGRPC map[string]string // Check type to the address of its gRPC plugin
+	AlertPluginsExec map[string]string // Webhook type to the command of its exec plugin
+	AlertPluginsGRPC map[string]string // Webhook type to the address of its gRPC plugin
+
+	// SMTP server for email webhooks; disabled unless the host is set
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
 	// CORS Configuration
 	CORSAllowedOrigins   string
 	CORSAllowedMethods   string
@@ -38,38 +121,168 @@ type Config struct {
 	CORSAllowCredentials bool
 	CORSMaxAge           int
 
+	// Trusted proxies whose X-Forwarded-For/X-Real-IP headers are honored
+	TrustedProxies []string
+
+	// Secret that signs trigger token URLs; empty disables trigger tokens
+	TriggerSecret string
+
+	// One-click acknowledge links in alert payloads; disabled unless both the secret and public URL are set
+	AlertAckSecret  string
+	AlertAckLinkTTL time.Duration
+	PublicURL       string
+
+	// Deployment integrations
+	DeployCheckTagPrefix string
+	DeployStatusContext  string
+	GitHubWebhookSecret  string
+	GitHubToken          string
+	GitHubAPIURL         string
+	GitLabWebhookSecret  string
+	GitLabToken          string
+	GitLabURL            string
+
+	// Daily execution budget shared by all checks (zero is unlimited) and the notification
+	// channel told when a budget is exceeded
+	ExecutionDailyBudget int
+	AdminChannelID       string
+
+	// Webhook Configuration
+	WebhookAllowedDestinations []string
+	DefaultWebhookURL          string
+	DefaultWebhookMethod       string
+	WebhookProfilesFile        string
+
 	// Scheduler Configuration
-	SchedulerEnabled      bool
-	SchedulerTickInterval time.Duration
-	SchedulerLockTTL      time.Duration
-	SchedulerConcurrency  int
+	SchedulerEnabled          bool
+	SchedulerTickInterval     time.Duration
+	SchedulerLockTTL          time.Duration
+	SchedulerConcurrency      int
+	SchedulerWatchChanges     bool
+	SchedulerStartupDelay     time.Duration // Wait before the first tick after startup
+	SchedulerStartupJitter    time.Duration // Random extra wait per pod, up to this much
+	SchedulerBacklogThreshold int           // Late due checks above which the scheduler counts as behind; zero disables
+	SchedulerBacklogAlert     bool          // Notify the admin channel when the scheduler falls behind
+	SchedulerSimulatedClock   bool          // Test only: time only moves through the clock admin endpoint
+
+	// API Key Configuration
+	APIKeys      map[string]string // Key name -> secret, used to identify callers
+	AdminAPIKeys []string          // Names of API keys allowed to call /api/v1/admin endpoints
+
+	// Agent Configuration
+	AgentToken    string
+	AgentLeaseTTL time.Duration
+
+	// Metrics Configuration
+	MetricsRetention         time.Duration // How long extracted metric samples are kept
+	PrometheusRemoteWriteURL string        // Optional Prometheus remote-write endpoint for metric samples
 }
 
 // Load reads configuration from environment variables with sensible defaults
 func Load() *Config {
 	return &Config{
+		// Instance
+		PodID:  getEnv("POD_NAME", defaultPodID()),
+		Region: getEnv("REGION", ""),
+
 		// MongoDB
 		MongoURI:      getEnv("MONGO_URI", "mongodb://localhost:27017/raven_alert?authSource=admin"),
 		MongoDatabase: getEnv("MONGO_DATABASE", "raven_alert"),
 		MongoTimeout:  getDurationEnv("MONGO_TIMEOUT_SEC", 10) * time.Second,
 
+		MongoHistoryReadPreference: getEnv("MONGO_HISTORY_READ_PREFERENCE", "primary"),
+
+		// MongoDB Pool
+		MongoMaxPoolSize:            getIntEnv("MONGO_MAX_POOL_SIZE", 100),
+		MongoMinPoolSize:            getIntEnv("MONGO_MIN_POOL_SIZE", 10),
+		MongoMaxConnIdleTime:        getDurationEnv("MONGO_MAX_CONN_IDLE_SEC", 30) * time.Second,
+		MongoConnectTimeout:         getDurationEnv("MONGO_CONNECT_TIMEOUT_SEC", 10) * time.Second,
+		MongoSocketTimeout:          getDurationEnv("MONGO_SOCKET_TIMEOUT_SEC", 30) * time.Second,
+		MongoServerSelectionTimeout: getDurationEnv("MONGO_SERVER_SELECTION_TIMEOUT_SEC", 10) * time.Second,
+		MongoCompressors:            getListEnv("MONGO_COMPRESSORS", "zstd,snappy"),
+
 		// HTTP Server
 		HTTPPort:         getEnv("HTTP_PORT", "8080"),
 		HTTPReadTimeout:  getDurationEnv("HTTP_READ_TIMEOUT_SEC", 30) * time.Second,
 		HTTPWriteTimeout: getDurationEnv("HTTP_WRITE_TIMEOUT_SEC", 30) * time.Second,
+		AdminPort:        getEnv("ADMIN_PORT", ""),
+
+		// TLS
+		TLSCertFile:         getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:          getEnv("TLS_KEY_FILE", ""),
+		TLSAutocertDomains:  getListEnv("TLS_AUTOCERT_DOMAINS", ""),
+		TLSAutocertCacheDir: getEnv("TLS_AUTOCERT_CACHE_DIR", "autocert-cache"),
+		TLSAutocertEmail:    getEnv("TLS_AUTOCERT_EMAIL", ""),
+		TLSMinVersion:       getEnv("TLS_MIN_VERSION", "1.2"),
+		TLSCipherSuites:     getListEnv("TLS_CIPHER_SUITES", ""),
+		HTTPRedirectPort:    getEnv("HTTP_REDIRECT_PORT", ""),
 
 		// Worker Pool
 		WorkerPoolSize:    getIntEnv("WORKER_POOL_SIZE", 10),
 		MaxConcurrentJobs: getIntEnv("MAX_CONCURRENT_JOBS", 1000),
 
+		// Batch Execution
+		BatchMaxConcurrency: getIntEnv("BATCH_MAX_CONCURRENCY", 10),
+
+		// Alert Dispatch
+		AlertDispatchWorkers:           getIntEnv("ALERT_DISPATCH_WORKERS", 5),
+		AlertQueueSize:                 getIntEnv("ALERT_QUEUE_SIZE", 1000),
+		CircuitBreakerFailureThreshold: getIntEnv("CIRCUIT_BREAKER_FAILURE_THRESHOLD", 5),
+		CircuitBreakerSuccessThreshold: getIntEnv("CIRCUIT_BREAKER_SUCCESS_THRESHOLD", 2),
+		CircuitBreakerTimeout:          getDurationEnv("CIRCUIT_BREAKER_TIMEOUT_SEC", 60) * time.Second,
+		CircuitBreakerHalfOpenProbes:   getIntEnv("CIRCUIT_BREAKER_HALF_OPEN_PROBES", 1),
+		CircuitBreakerHalfOpenWait:     getDurationEnv("CIRCUIT_BREAKER_HALF_OPEN_WAIT_SEC", 10) * time.Second,
+
+		// Execution History Persistence
+		ExecutionBatchSize:        getIntEnv("EXECUTION_BATCH_SIZE", 50),
+		ExecutionFlushInterval:    getDurationEnv("EXECUTION_FLUSH_INTERVAL_MS", 1000) * time.Millisecond,
+		ExecutionBodySampleRate:   getIntEnv("EXECUTION_BODY_SAMPLE_RATE", 1),
+		ExecutionMaxDocumentBytes: getIntEnv("EXECUTION_MAX_DOCUMENT_BYTES", 0),
+
+		// Persistence Retry
+		PersistenceRetryQueueSize:  getIntEnv("PERSISTENCE_RETRY_QUEUE_SIZE", 1000),
+		PersistenceRetryMaxBackoff: getDurationEnv("PERSISTENCE_RETRY_MAX_BACKOFF_SEC", 300) * time.Second,
+
 		// Logging
-		LogLevel:  getEnv("LOG_LEVEL", "info"),
-		LogFormat: getEnv("LOG_FORMAT", "json"),
+		LogLevel:         getEnv("LOG_LEVEL", "info"),
+		LogFormat:        getEnv("LOG_FORMAT", "json"),
+		LogRequestBodies: getBoolEnv("LOG_REQUEST_BODIES", false),
+		LogBodyMaxBytes:  getIntEnv("LOG_BODY_MAX_BYTES", 4096),
+		LogRedactFields:  getListEnv("LOG_REDACT_FIELDS", ""),
 
 		// Timeouts
 		DefaultAPITimeout:     getDurationEnv("DEFAULT_API_TIMEOUT_SEC", 30) * time.Second,
 		DefaultWebhookTimeout: getDurationEnv("DEFAULT_WEBHOOK_TIMEOUT_SEC", 10) * time.Second,
 
+		// Target calls
+		TargetHeaderTimeout:       getDurationEnv("TARGET_HEADER_TIMEOUT_SEC", 0) * time.Second,
+		TargetBodyTimeout:         getDurationEnv("TARGET_BODY_TIMEOUT_SEC", 0) * time.Second,
+		TargetRetryOnNetworkError: getBoolEnv("TARGET_RETRY_ON_NETWORK_ERROR", false),
+		TargetRetryBudget:         getIntEnv("TARGET_RETRY_BUDGET", 2),
+		TargetRetryBackoff:        getDurationEnv("TARGET_RETRY_BACKOFF_MS", 500) * time.Millisecond,
+		TargetRetryOnStatus:       getIntListEnv("TARGET_RETRY_ON_STATUS", ""),
+		TargetUserAgent:           getEnv("TARGET_USER_AGENT", ""),
+		TargetDefaultHeaders:      getMapEnv("TARGET_DEFAULT_HEADERS"),
+
+		// Browser checks
+		BrowserEnabled:   getBoolEnv("BROWSER_ENABLED", false),
+		BrowserExecPath:  getEnv("BROWSER_EXEC_PATH", ""),
+		BrowserRemoteURL: getEnv("BROWSER_REMOTE_URL", ""),
+
+		// Plugins
+		PluginFiles:      getListEnv("PLUGIN_FILES", ""),
+		CheckPluginsExec: getMapEnv("CHECK_PLUGINS_EXEC"),
+		CheckPluginsGRPC: getMapEnv("CHECK_PLUGINS_GRPC"),
+		AlertPluginsExec: getMapEnv("ALERT_PLUGINS_EXEC"),
+		AlertPluginsGRPC: getMapEnv("ALERT_PLUGINS_GRPC"),
+
+		// Email webhooks
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getIntEnv("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", ""),
+
 		// CORS
 		CORSAllowedOrigins:   getEnv("CORS_ALLOWED_ORIGINS", "*"),
 		CORSAllowedMethods:   getEnv("CORS_ALLOWED_METHODS", "GET, POST, PUT, DELETE, OPTIONS, PATCH"),
@@ -77,14 +290,74 @@ func Load() *Config {
 		CORSAllowCredentials: getBoolEnv("CORS_ALLOW_CREDENTIALS", true),
 		CORSMaxAge:           getIntEnv("CORS_MAX_AGE", 3600),
 
+		// Trusted proxies
+		TrustedProxies: getListEnv("TRUSTED_PROXIES", ""),
+
+		// Trigger tokens
+		TriggerSecret: getEnv("TRIGGER_SECRET", ""),
+
+		// Acknowledge links
+		AlertAckSecret:  getEnv("ALERT_ACK_SECRET", ""),
+		AlertAckLinkTTL: getDurationEnv("ALERT_ACK_LINK_TTL_HOURS", 168) * time.Hour,
+		PublicURL:       getEnv("PUBLIC_URL", ""),
+
+		// Deployment integrations
+		DeployCheckTagPrefix: getEnv("DEPLOY_CHECK_TAG_PREFIX", "service:"),
+		DeployStatusContext:  getEnv("DEPLOY_STATUS_CONTEXT", "raven/post-deploy"),
+		GitHubWebhookSecret:  getEnv("GITHUB_WEBHOOK_SECRET", ""),
+		GitHubToken:          getEnv("GITHUB_TOKEN", ""),
+		GitHubAPIURL:         getEnv("GITHUB_API_URL", "https://api.github.com"),
+		GitLabWebhookSecret:  getEnv("GITLAB_WEBHOOK_SECRET", ""),
+		GitLabToken:          getEnv("GITLAB_TOKEN", ""),
+		GitLabURL:            getEnv("GITLAB_URL", "https://gitlab.com"),
+
+		// Execution budgets
+		ExecutionDailyBudget: getIntEnv("EXECUTION_DAILY_BUDGET", 0),
+		AdminChannelID:       getEnv("ADMIN_CHANNEL_ID", ""),
+
+		// Webhooks
+		WebhookAllowedDestinations: getListEnv("WEBHOOK_ALLOWED_DESTINATIONS", ""),
+		DefaultWebhookURL:          getEnv("DEFAULT_WEBHOOK_URL", ""),
+		DefaultWebhookMethod:       getEnv("DEFAULT_WEBHOOK_METHOD", "POST"),
+		WebhookProfilesFile:        getEnv("WEBHOOK_PROFILES_FILE", ""),
+
 		// Scheduler
-		SchedulerEnabled:      getBoolEnv("SCHEDULER_ENABLED", true),
-		SchedulerTickInterval: getDurationEnv("SCHEDULER_TICK_INTERVAL_SEC", 60) * time.Second,
-		SchedulerLockTTL:      getDurationEnv("SCHEDULER_LOCK_TTL_SEC", 300) * time.Second,
-		SchedulerConcurrency:  getIntEnv("SCHEDULER_CONCURRENCY", 10),
+		SchedulerEnabled:          getBoolEnv("SCHEDULER_ENABLED", true),
+		SchedulerTickInterval:     getDurationEnv("SCHEDULER_TICK_INTERVAL_SEC", 60) * time.Second,
+		SchedulerLockTTL:          getDurationEnv("SCHEDULER_LOCK_TTL_SEC", 300) * time.Second,
+		SchedulerConcurrency:      getIntEnv("SCHEDULER_CONCURRENCY", 10),
+		SchedulerWatchChanges:     getBoolEnv("SCHEDULER_WATCH_CHANGES", true),
+		SchedulerStartupDelay:     getDurationEnv("SCHEDULER_STARTUP_DELAY_SEC", 0) * time.Second,
+		SchedulerStartupJitter:    getDurationEnv("SCHEDULER_STARTUP_JITTER_SEC", 0) * time.Second,
+		SchedulerBacklogThreshold: getIntEnv("SCHEDULER_BACKLOG_THRESHOLD", 0),
+		SchedulerBacklogAlert:     getBoolEnv("SCHEDULER_BACKLOG_ALERT", false),
+		SchedulerSimulatedClock:   getBoolEnv("SCHEDULER_SIMULATED_CLOCK", false),
+
+		// API keys
+		APIKeys:      getMapEnv("API_KEYS"),
+		AdminAPIKeys: getListEnv("ADMIN_API_KEYS", ""),
+
+		// Agents
+		AgentToken:    getEnv("AGENT_TOKEN", ""),
+		AgentLeaseTTL: getDurationEnv("AGENT_LEASE_TTL_SEC", 120) * time.Second,
+
+		// Metrics
+		MetricsRetention:         getDurationEnv("METRICS_RETENTION_DAYS", 30) * 24 * time.Hour,
+		PrometheusRemoteWriteURL: getEnv("PROMETHEUS_REMOTE_WRITE_URL", ""),
 	}
 }
 
+// defaultPodID returns the hostname (the pod name in Kubernetes), or a random UUID
+func defaultPodID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		id := uuid.New().String()
+		log.Printf("Failed to get hostname, using UUID as pod ID: %s", id)
+		return id
+	}
+	return hostname
+}
+
 // Helper functions
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -113,6 +386,31 @@ func getDurationEnv(key string, defaultValue int) time.Duration {
 	return time.Duration(defaultValue)
 }
 
+func getListEnv(key, defaultValue string) []string {
+	value := getEnv(key, defaultValue)
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getIntListEnv parses a comma-separated list of integers, skipping invalid entries
+func getIntListEnv(key, defaultValue string) []int {
+	var items []int
+	for _, item := range getListEnv(key, defaultValue) {
+		intVal, err := strconv.Atoi(item)
+		if err != nil {
+			log.Printf("Warning: Invalid integer %q in %s, ignoring it", item, key)
+			continue
+		}
+		items = append(items, intVal)
+	}
+	return items
+}
+
 func getBoolEnv(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolVal, err := strconv.ParseBool(value); err == nil {
@@ -122,3 +420,17 @@ func getBoolEnv(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getMapEnv parses a comma-separated list of name:value pairs
+func getMapEnv(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range getListEnv(key, "") {
+		name, value, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || value == "" {
+			log.Printf("Warning: Invalid entry in %s, expected name:value", key)
+			continue
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return result
+}
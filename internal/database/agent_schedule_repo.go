@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AgentScheduleRepository handles per-region schedule state for agent-probed checks
+type AgentScheduleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAgentScheduleRepository creates a new agent schedule repository
+func NewAgentScheduleRepository(db *MongoDB) *AgentScheduleRepository {
+	return &AgentScheduleRepository{
+		collection: db.GetCollection(CollectionAgentSchedules),
+	}
+}
+
+// ErrLeaseNotHeld is returned by Complete when the agent holds no live lease on the check
+var ErrLeaseNotHeld = errors.New("check is not leased to the agent")
+
+// EnsureSchedules creates the schedule state of region checks that have none yet, in a single
+// bulk write, so new checks are due at once
+func (r *AgentScheduleRepository) EnsureSchedules(ctx context.Context, configIDs []primitive.ObjectID, region string) error {
+	if len(configIDs) == 0 {
+		return nil
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, 0, len(configIDs))
+	for _, id := range configIDs {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"config_id": id, "region": region}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{"config_id": id, "region": region}}).
+			SetUpsert(true))
+	}
+
+	_, err := r.collection.BulkWrite(ctxTimeout, models, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		// Concurrent lease requests may create the same schedule
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Code != duplicateKeyCode {
+				return fmt.Errorf("failed to create agent schedules: %w", err)
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create agent schedules: %w", err)
+	}
+
+	return nil
+}
+
+// LeaseDue leases up to max of the given checks that are due in a region and not leased by
+// another agent, oldest due first. Each lease is claimed atomically, so concurrent agents
+// never share a check; the checks leased before an error are returned with it.
+func (r *AgentScheduleRepository) LeaseDue(ctx context.Context, configIDs []primitive.ObjectID, region, agentID string, now time.Time, ttl time.Duration, max int) ([]primitive.ObjectID, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Filter: the check is due and the previous lease (if any) has expired
+	filter := bson.M{
+		"config_id": bson.M{"$in": configIDs},
+		"region":    region,
+		"$and": []bson.M{
+			{"$or": []bson.M{
+				{"next_run": bson.M{"$lte": now}},
+				{"next_run": bson.M{"$exists": false}},
+			}},
+			{"$or": []bson.M{
+				{"leased_until": bson.M{"$lt": now}},
+				{"leased_until": bson.M{"$exists": false}},
+			}},
+		},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"agent_id":     agentID,
+			"leased_until": now.Add(ttl),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_run", Value: 1}}).
+		SetProjection(bson.M{"config_id": 1})
+
+	leased := make([]primitive.ObjectID, 0, max)
+	for len(leased) < max {
+		var schedule model.AgentSchedule
+		err := r.collection.FindOneAndUpdate(ctxTimeout, filter, update, opts).Decode(&schedule)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return leased, fmt.Errorf("failed to lease check: %w", err)
+		}
+		leased = append(leased, schedule.ConfigID)
+	}
+
+	return leased, nil
+}
+
+// Complete records a finished run and releases the lease. It returns ErrLeaseNotHeld unless
+// the agent holds an unexpired lease on the check, so results from agents that never leased
+// the check, or whose lease passed to another agent, are rejected.
+func (r *AgentScheduleRepository) Complete(ctx context.Context, configID primitive.ObjectID, region, agentID string, lastRun, nextRun time.Time) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"config_id":    configID,
+		"region":       region,
+		"agent_id":     agentID,
+		"leased_until": bson.M{"$gt": lastRun},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"last_run": lastRun,
+			"next_run": nextRun,
+		},
+		"$unset": bson.M{
+			"agent_id":     "",
+			"leased_until": "",
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctxTimeout, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to complete agent schedule: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrLeaseNotHeld
+	}
+
+	return nil
+}
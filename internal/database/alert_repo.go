@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/dandantas/raven/internal/model"
@@ -15,16 +17,25 @@ import (
 
 // AlertRepository handles alert log operations
 type AlertRepository struct {
-	collection *mongo.Collection
+	collection     *mongo.Collection
+	readCollection *mongo.Collection // Uses the history read preference for list queries
+	retry          *RetryQueue       // Buffers alert logs whose write failed; nil reports the error
 }
 
 // NewAlertRepository creates a new alert repository
 func NewAlertRepository(db *MongoDB) *AlertRepository {
 	return &AlertRepository{
-		collection: db.GetCollection(CollectionAlertLogs),
+		collection:     db.GetCollection(CollectionAlertLogs),
+		readCollection: db.GetHistoryCollection(CollectionAlertLogs),
 	}
 }
 
+// SetRetryQueue buffers alert logs whose insert or update fails for retry instead of
+// returning the error, so alerts are still delivered during a database outage
+func (r *AlertRepository) SetRetryQueue(queue *RetryQueue) {
+	r.retry = queue
+}
+
 // Create inserts a new alert log
 func (r *AlertRepository) Create(ctx context.Context, alert *model.AlertLog) error {
 	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -42,7 +53,11 @@ func (r *AlertRepository) Create(ctx context.Context, alert *model.AlertLog) err
 
 	_, err := r.collection.InsertOne(ctxTimeout, alert)
 	if err != nil {
-		return fmt.Errorf("failed to create alert log: %w", err)
+		err = fmt.Errorf("failed to create alert log: %w", err)
+		if r.retry == nil || mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		r.retryLater(alert, err)
 	}
 
 	return nil
@@ -65,37 +80,134 @@ func (r *AlertRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*
 	return &alert, nil
 }
 
-// List retrieves alert logs with filtering and pagination
-func (r *AlertRepository) List(ctx context.Context, filter bson.M, page, limit int) ([]model.AlertLog, int64, error) {
+// List retrieves a page of alert logs with filtering, the total when page.Count is set, and
+// the cursor of the next page if there is one
+func (r *AlertRepository) List(ctx context.Context, filter bson.M, sort bson.D, page Page) ([]model.AlertLog, int64, string, error) {
 	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Count total documents
-	total, err := r.collection.CountDocuments(ctxTimeout, filter)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count alert logs: %w", err)
+	var total int64
+	if page.Count {
+		var err error
+		total, err = r.readCollection.CountDocuments(ctxTimeout, filter)
+		if err != nil {
+			return nil, 0, "", fmt.Errorf("failed to count alert logs: %w", err)
+		}
 	}
 
-	// Calculate pagination
-	skip := (page - 1) * limit
+	// Pages are ordered by _id within equal sort values, so cursors have a stable position
+	sort = sortOrDefault(sort, bson.D{{Key: "created_at", Value: -1}})
+	_, _, keyset := keysetSort(sort)
+
+	// One extra document tells whether there is a next page
 	opts := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
+		SetLimit(int64(page.Limit + 1)).
+		SetSort(keyset)
+	if page.Cursor != "" {
+		after, err := afterCursor(sort, page.Cursor)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		filter = bson.M{"$and": []bson.M{filter, after}}
+	} else {
+		opts.SetSkip(int64((page.Number - 1) * page.Limit))
+	}
 
 	// Find documents
-	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	cursor, err := r.readCollection.Find(ctxTimeout, filter, opts)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list alert logs: %w", err)
+		return nil, 0, "", fmt.Errorf("failed to list alert logs: %w", err)
 	}
 	defer cursor.Close(ctxTimeout)
 
-	var alerts []model.AlertLog
-	if err := cursor.All(ctxTimeout, &alerts); err != nil {
-		return nil, 0, fmt.Errorf("failed to decode alert logs: %w", err)
+	var documents []bson.Raw
+	if err := cursor.All(ctxTimeout, &documents); err != nil {
+		return nil, 0, "", fmt.Errorf("failed to decode alert logs: %w", err)
 	}
 
-	return alerts, total, nil
+	var next string
+	if len(documents) > page.Limit {
+		documents = documents[:page.Limit]
+		if next, err = encodeCursor(sort, documents[len(documents)-1]); err != nil {
+			return nil, 0, "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	alerts := make([]model.AlertLog, len(documents))
+	for i, document := range documents {
+		if err := bson.Unmarshal(document, &alerts[i]); err != nil {
+			return nil, 0, "", fmt.Errorf("failed to decode alert log: %w", err)
+		}
+	}
+
+	return alerts, total, next, nil
+}
+
+// alertExportTimeout bounds streaming an alert export
+const alertExportTimeout = 10 * time.Minute
+
+// Export streams the alert logs matching filter to fn, oldest first, stopping at the first
+// error fn returns
+func (r *AlertRepository) Export(ctx context.Context, filter bson.M, fn func(*model.AlertExportRow) error) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, alertExportTimeout)
+	defer cancel()
+
+	sizeOf := func(field string) bson.M {
+		return bson.M{"$size": bson.M{"$ifNull": bson.A{field, bson.A{}}}}
+	}
+
+	// Attempts and comments are reduced to counts and the last attempt, so large response
+	// bodies and notes aren't read
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{
+			"created_at":            1,
+			"completed_at":          1,
+			"config_id":             1,
+			"execution_id":          1,
+			"correlation_id":        1,
+			"source":                1,
+			"dedup_key":             1,
+			"rule_name":             1,
+			"severity":              1,
+			"tags":                  1,
+			"message":               "$payload.text",
+			"webhook_host":          1,
+			"final_status":          1,
+			"delivery_latency_ms":   1,
+			"attempts_count":        sizeOf("$attempts"),
+			"last_attempt":          bson.M{"$arrayElemAt": bson.A{"$attempts", -1}},
+			"occurrences":           1,
+			"acknowledgment_status": 1,
+			"acknowledged_by":       1,
+			"acknowledged_at":       1,
+			"classification":        1,
+			"comments_count":        sizeOf("$comments"),
+		}}},
+	}
+
+	cursor, err := r.readCollection.Aggregate(ctxTimeout, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return fmt.Errorf("failed to export alert logs: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	for cursor.Next(ctxTimeout) {
+		var row model.AlertExportRow
+		if err := cursor.Decode(&row); err != nil {
+			return fmt.Errorf("failed to decode alert log: %w", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to export alert logs: %w", err)
+	}
+
+	return nil
 }
 
 // Update updates an alert log
@@ -106,16 +218,81 @@ func (r *AlertRepository) Update(ctx context.Context, id primitive.ObjectID, ale
 	alert.ID = id
 	result, err := r.collection.ReplaceOne(ctxTimeout, bson.M{"_id": id}, alert)
 	if err != nil {
-		return fmt.Errorf("failed to update alert log: %w", err)
+		err = fmt.Errorf("failed to update alert log: %w", err)
+		if r.retry == nil {
+			return err
+		}
+		r.retryLater(alert, err)
+		return nil
 	}
 
 	if result.MatchedCount == 0 {
+		// The insert may still be waiting for a retry; save this version instead
+		if r.retry != nil && r.retry.Pending(RetryKindAlertLog, id.Hex()) {
+			r.retryLater(alert, errAlertLogNotInserted)
+			return nil
+		}
 		return fmt.Errorf("alert log not found")
 	}
 
 	return nil
 }
 
+// retryLater buffers a copy of the alert log, which delivery keeps updating, for an upsert
+func (r *AlertRepository) retryLater(alert *model.AlertLog, cause error) {
+	snapshot := *alert
+	snapshot.Attempts = slices.Clone(alert.Attempts)
+
+	r.retry.Add(RetryKindAlertLog, snapshot.ID.Hex(), func(ctx context.Context) error {
+		ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		_, err := r.collection.ReplaceOne(ctxTimeout, bson.M{"_id": snapshot.ID}, &snapshot, options.Replace().SetUpsert(true))
+		return err
+	}, cause)
+}
+
+// errAlertLogNotInserted fails a queued update until the alert log's own insert succeeds
+var errAlertLogNotInserted = errors.New("alert log insert is pending retry")
+
+// updateOne applies an update to an alert log, describing a failure with action. An alert log
+// whose insert is waiting in the retry queue isn't found yet, so the update is queued to run
+// once it is saved.
+func (r *AlertRepository) updateOne(ctx context.Context, id primitive.ObjectID, update bson.M, action string) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+	if r.retry == nil || !r.retry.Pending(RetryKindAlertLog, id.Hex()) {
+		return fmt.Errorf("alert log not found")
+	}
+
+	// Each update gets its own key, so later ones don't replace it
+	key := id.Hex() + "/" + primitive.NewObjectID().Hex()
+	r.retry.Add(RetryKindAlertLogUpdate, key, func(ctx context.Context) error {
+		ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id}, update)
+		switch {
+		case err != nil:
+			return err
+		case result.MatchedCount > 0:
+			return nil
+		case r.retry.Pending(RetryKindAlertLog, id.Hex()):
+			return errAlertLogNotInserted
+		default:
+			// The insert was dropped from the queue, so there is nothing left to update
+			slog.Error("Dropping alert log update, the alert log was never saved", "alert_id", id.Hex())
+			return nil
+		}
+	}, errAlertLogNotInserted)
+	return nil
+}
+
 // AddAttempt adds a new attempt to an existing alert log
 func (r *AlertRepository) AddAttempt(ctx context.Context, id primitive.ObjectID, attempt model.AlertAttempt) error {
 	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -127,16 +304,7 @@ func (r *AlertRepository) AddAttempt(ctx context.Context, id primitive.ObjectID,
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id}, update)
-	if err != nil {
-		return fmt.Errorf("failed to add attempt: %w", err)
-	}
-
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("alert log not found")
-	}
-
-	return nil
+	return r.updateOne(ctxTimeout, id, update, "failed to add attempt")
 }
 
 // UpdateStatus updates the final status and completion time of an alert log
@@ -151,39 +319,231 @@ func (r *AlertRepository) UpdateStatus(ctx context.Context, id primitive.ObjectI
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id}, update)
-	if err != nil {
-		return fmt.Errorf("failed to update status: %w", err)
+	return r.updateOne(ctxTimeout, id, update, "failed to update status")
+}
+
+// AcknowledgeAlert marks an alert as acknowledged, recording its classification when one is given
+func (r *AlertRepository) AcknowledgeAlert(ctx context.Context, id primitive.ObjectID, acknowledgedBy string, acknowledgedAt time.Time, classification string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	set := bson.M{
+		"acknowledgment_status": "acknowledged",
+		"acknowledged_by":       acknowledgedBy,
+		"acknowledged_at":       acknowledgedAt,
+	}
+	if classification != "" {
+		set["classification"] = classification
 	}
+	update := bson.M{"$set": set}
 
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("alert log not found")
+	return r.updateOne(ctxTimeout, id, update, "failed to acknowledge alert")
+}
+
+// AddComment appends a comment to an alert
+func (r *AlertRepository) AddComment(ctx context.Context, id primitive.ObjectID, comment model.AlertComment) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$push": bson.M{"comments": comment},
 	}
 
-	return nil
+	return r.updateOne(ctxTimeout, id, update, "failed to add alert comment")
 }
 
-// AcknowledgeAlert marks an alert as acknowledged
-func (r *AlertRepository) AcknowledgeAlert(ctx context.Context, id primitive.ObjectID, acknowledgedBy string, acknowledgedAt time.Time) error {
+// RecordOccurrence bumps the occurrence count of the open alert matching an ingest
+// dedup key. It returns nil when no unacknowledged alert matches.
+func (r *AlertRepository) RecordOccurrence(ctx context.Context, source, dedupKey string, seenAt time.Time) (*model.AlertLog, error) {
 	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	filter := bson.M{
+		"source":                source,
+		"dedup_key":             dedupKey,
+		"acknowledgment_status": bson.M{"$ne": "acknowledged"},
+	}
 	update := bson.M{
-		"$set": bson.M{
-			"acknowledgment_status": "acknowledged",
-			"acknowledged_by":       acknowledgedBy,
-			"acknowledged_at":       acknowledgedAt,
-		},
+		"$inc": bson.M{"occurrences": 1},
+		"$set": bson.M{"last_seen_at": seenAt},
 	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetReturnDocument(options.After)
 
-	result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id}, update)
+	var alert model.AlertLog
+	err := r.collection.FindOneAndUpdate(ctxTimeout, filter, update, opts).Decode(&alert)
 	if err != nil {
-		return fmt.Errorf("failed to acknowledge alert: %w", err)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to record alert occurrence: %w", err)
 	}
 
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("alert log not found")
+	return &alert, nil
+}
+
+// NoiseByConfig counts alerts, acknowledgments, noise classifications and failed
+// deliveries per health check
+func (r *AlertRepository) NoiseByConfig(ctx context.Context, filter bson.M) ([]model.NoisyCheck, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	countIf := func(field, value string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{field, value}}, 1, 0}}}
 	}
 
-	return nil
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":             configIDString,
+			"alerts":          bson.M{"$sum": 1},
+			"acknowledged":    countIf("$acknowledgment_status", "acknowledged"),
+			"noise":           countIf("$classification", model.AlertClassificationNoise),
+			"delivery_failed": countIf("$final_status", "failed"),
+		}}},
+	}
+
+	cursor, err := r.readCollection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate alert noise: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	checks := make([]model.NoisyCheck, 0)
+	if err := cursor.All(ctxTimeout, &checks); err != nil {
+		return nil, fmt.Errorf("failed to decode alert noise: %w", err)
+	}
+
+	return checks, nil
+}
+
+// NoiseByRule counts alerts and their classifications per health check rule
+func (r *AlertRepository) NoiseByRule(ctx context.Context, filter bson.M) ([]model.NoisyRule, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	countClassified := func(classification string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$classification", classification}}, 1, 0}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"config_id": configIDString, "rule_name": "$rule_name"},
+			"alerts": bson.M{"$sum": 1},
+			"real":   countClassified(model.AlertClassificationReal),
+			"noise":  countClassified(model.AlertClassificationNoise),
+			"test":   countClassified(model.AlertClassificationTest),
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":       0,
+			"config_id": "$_id.config_id",
+			"rule_name": "$_id.rule_name",
+			"alerts":    1,
+			"real":      1,
+			"noise":     1,
+			"test":      1,
+		}}},
+	}
+
+	cursor, err := r.readCollection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate rule noise: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	rules := make([]model.NoisyRule, 0)
+	if err := cursor.All(ctxTimeout, &rules); err != nil {
+		return nil, fmt.Errorf("failed to decode rule noise: %w", err)
+	}
+
+	return rules, nil
+}
+
+// ClassifiedCorrelationIDs returns the correlation IDs of the newest alerts matching filter,
+// up to limit, grouped by classification
+func (r *AlertRepository) ClassifiedCorrelationIDs(ctx context.Context, filter bson.M, limit int) (map[string][]string, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetProjection(bson.M{"correlation_id": 1, "classification": 1})
+
+	cursor, err := r.readCollection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find classified alerts: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var alerts []model.AlertLog
+	if err := cursor.All(ctxTimeout, &alerts); err != nil {
+		return nil, fmt.Errorf("failed to decode classified alerts: %w", err)
+	}
+
+	ids := make(map[string][]string)
+	for _, alert := range alerts {
+		ids[alert.Classification] = append(ids[alert.Classification], alert.CorrelationID)
+	}
+
+	return ids, nil
+}
+
+// Stats computes alert counts by delivery status, acknowledgment status, config and
+// time bucket in a single aggregation
+func (r *AlertRepository) Stats(ctx context.Context, filter bson.M, bucket string, topConfigs int) (*model.AlertStats, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	// Alerts created before acknowledgment tracking have no status and count as open
+	ackStatus := bson.M{"$ifNull": bson.A{
+		bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$acknowledgment_status", ""}}, nil, "$acknowledgment_status"}},
+		"open",
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$facet", Value: bson.M{
+			"total":                    bson.A{bson.M{"$count": "count"}},
+			"by_final_status":          groupCountStage("$final_status", 0),
+			"by_acknowledgment_status": groupCountStage(ackStatus, 0),
+			"by_config":                groupCountStage(configIDString, topConfigs),
+			"by_time":                  timeBucketStage("created_at", bucket),
+		}}},
+	}
+
+	cursor, err := r.readCollection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate alert stats: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var results []struct {
+		Total                  []facetCount        `bson:"total"`
+		ByFinalStatus          []model.CountBucket `bson:"by_final_status"`
+		ByAcknowledgmentStatus []model.CountBucket `bson:"by_acknowledgment_status"`
+		ByConfig               []model.CountBucket `bson:"by_config"`
+		ByTime                 []model.CountBucket `bson:"by_time"`
+	}
+	if err := cursor.All(ctxTimeout, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode alert stats: %w", err)
+	}
+
+	stats := &model.AlertStats{
+		ByFinalStatus:          []model.CountBucket{},
+		ByAcknowledgmentStatus: []model.CountBucket{},
+		ByConfig:               []model.CountBucket{},
+		ByTime:                 []model.CountBucket{},
+	}
+	if len(results) > 0 {
+		stats.Total = facetTotal(results[0].Total)
+		stats.ByFinalStatus = append(stats.ByFinalStatus, results[0].ByFinalStatus...)
+		stats.ByAcknowledgmentStatus = append(stats.ByAcknowledgmentStatus, results[0].ByAcknowledgmentStatus...)
+		stats.ByConfig = append(stats.ByConfig, results[0].ByConfig...)
+		stats.ByTime = append(stats.ByTime, results[0].ByTime...)
+	}
+
+	return stats, nil
 }
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditRepository handles the health check audit log
+type AuditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *MongoDB) *AuditRepository {
+	return &AuditRepository{
+		collection: db.GetCollection(CollectionAuditLog),
+	}
+}
+
+// Create inserts an audit entry
+func (r *AuditRepository) Create(ctx context.Context, entry *model.AuditEntry) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctxTimeout, entry); err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves audit entries, newest first, with pagination
+func (r *AuditRepository) List(ctx context.Context, filter bson.M, page, limit int) ([]model.AuditEntry, int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctxTimeout, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	// Calculate pagination
+	skip := (page - 1) * limit
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	entries := make([]model.AuditEntry, 0)
+	if err := cursor.All(ctxTimeout, &entries); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode audit entries: %w", err)
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuthProfileRepository handles auth profile operations
+type AuthProfileRepository struct {
+	collection *mongo.Collection
+	configs    *mongo.Collection
+}
+
+// NewAuthProfileRepository creates a new auth profile repository
+func NewAuthProfileRepository(db *MongoDB) *AuthProfileRepository {
+	return &AuthProfileRepository{
+		collection: db.GetCollection(CollectionAuthProfiles),
+		configs:    db.GetCollection(CollectionHealthCheckConfigs),
+	}
+}
+
+// Create inserts a new auth profile
+func (r *AuthProfileRepository) Create(ctx context.Context, profile *model.AuthProfile) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Ensure ID is generated if not set
+	if profile.ID.IsZero() {
+		profile.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctxTimeout, profile)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("auth profile with name '%s' already exists", profile.Name)
+		}
+		return fmt.Errorf("failed to create auth profile: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an auth profile by ID
+func (r *AuthProfileRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*model.AuthProfile, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var profile model.AuthProfile
+	err := r.collection.FindOne(ctxTimeout, bson.M{"_id": id}).Decode(&profile)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("auth profile not found")
+		}
+		return nil, fmt.Errorf("failed to get auth profile: %w", err)
+	}
+
+	return &profile, nil
+}
+
+// List retrieves auth profiles with pagination
+func (r *AuthProfileRepository) List(ctx context.Context, page, limit int) ([]model.AuthProfile, int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctxTimeout, bson.M{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count auth profiles: %w", err)
+	}
+
+	// Calculate pagination
+	skip := (page - 1) * limit
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctxTimeout, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list auth profiles: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	profiles := make([]model.AuthProfile, 0)
+	if err := cursor.All(ctxTimeout, &profiles); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode auth profiles: %w", err)
+	}
+
+	return profiles, total, nil
+}
+
+// Update replaces an existing auth profile
+func (r *AuthProfileRepository) Update(ctx context.Context, id primitive.ObjectID, profile *model.AuthProfile) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	profile.ID = id
+	result, err := r.collection.ReplaceOne(ctxTimeout, bson.M{"_id": id}, profile)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("auth profile with name '%s' already exists", profile.Name)
+		}
+		return fmt.Errorf("failed to update auth profile: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("auth profile not found")
+	}
+
+	return nil
+}
+
+// Delete deletes an auth profile
+func (r *AuthProfileRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctxTimeout, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete auth profile: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("auth profile not found")
+	}
+
+	return nil
+}
+
+// CountReferences counts the health checks that authenticate with a profile
+func (r *AuthProfileRepository) CountReferences(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	count, err := r.configs.CountDocuments(ctxTimeout, bson.M{"target.auth.profile_id": id})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count auth profile references: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// budgetCounterRetention is how long daily counters are kept after their day starts
+const budgetCounterRetention = 48 * time.Hour
+
+// BudgetRepository counts executions against daily budgets. Counters are shared by all
+// pods and expire after their day.
+type BudgetRepository struct {
+	collection *mongo.Collection
+}
+
+// NewBudgetRepository creates a new budget repository
+func NewBudgetRepository(db *MongoDB) *BudgetRepository {
+	return &BudgetRepository{
+		collection: db.GetCollection(CollectionExecutionBudgets),
+	}
+}
+
+// Consume atomically adds one execution to the counter of key for the UTC day of at and
+// returns the new count
+func (r *BudgetRepository) Consume(ctx context.Context, key string, at time.Time) (int64, error) {
+	return r.add(ctx, key, at, 1)
+}
+
+// Release gives back an execution consumed from the counter of key for the UTC day of at
+func (r *BudgetRepository) Release(ctx context.Context, key string, at time.Time) error {
+	_, err := r.add(ctx, key, at, -1)
+	return err
+}
+
+// add increments a daily counter, creating it on first use
+func (r *BudgetRepository) add(ctx context.Context, key string, at time.Time, delta int64) (int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	day := at.UTC().Truncate(24 * time.Hour)
+
+	update := bson.M{
+		"$inc":         bson.M{"count": delta},
+		"$setOnInsert": bson.M{"key": key, "day": day, "expires_at": day.Add(budgetCounterRetention)},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var counter struct {
+		Count int64 `bson:"count"`
+	}
+	id := key + ":" + day.Format(time.DateOnly)
+	if err := r.collection.FindOneAndUpdate(ctxTimeout, bson.M{"_id": id}, update, opts).Decode(&counter); err != nil {
+		return 0, fmt.Errorf("failed to update execution budget: %w", err)
+	}
+
+	return counter.Count, nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrChangeStreamsUnsupported is returned when the deployment doesn't support change streams
+// (e.g. a standalone server instead of a replica set)
+var ErrChangeStreamsUnsupported = errors.New("change streams are not supported by this deployment")
+
+// ConfigChange represents a change to a health check configuration document
+type ConfigChange struct {
+	OperationType string                   // "insert", "update", "replace", "delete"
+	ConfigID      primitive.ObjectID       // ID of the changed configuration
+	Config        *model.HealthCheckConfig // Current document (nil for deletes)
+}
+
+// changeEvent represents the subset of a change stream event we decode
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *model.HealthCheckConfig `bson:"fullDocument"`
+}
+
+// Watch opens a change stream on health check configurations and calls onChange
+// for each change until the context is cancelled or the stream fails
+func (r *HealthCheckRepository) Watch(ctx context.Context, onChange func(ConfigChange)) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}},
+		}}},
+	}
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+
+	stream, err := r.collection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		if isChangeStreamUnsupported(err) {
+			return ErrChangeStreamsUnsupported
+		}
+		return fmt.Errorf("failed to open change stream: %w", err)
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var event changeEvent
+		if err := stream.Decode(&event); err != nil {
+			return fmt.Errorf("failed to decode change event: %w", err)
+		}
+
+		onChange(ConfigChange{
+			OperationType: event.OperationType,
+			ConfigID:      event.DocumentKey.ID,
+			Config:        event.FullDocument,
+		})
+	}
+
+	if err := stream.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("change stream failed: %w", err)
+	}
+
+	return nil
+}
+
+// isChangeStreamUnsupported reports whether an error means change streams can't be used
+func isChangeStreamUnsupported(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		// 40573: The $changeStream stage is only supported on replica sets
+		return cmdErr.Code == 40573
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChannelRepository handles notification channel operations
+type ChannelRepository struct {
+	collection      *mongo.Collection
+	configs         *mongo.Collection
+	suites          *mongo.Collection
+	alertCollection *mongo.Collection
+}
+
+// NewChannelRepository creates a new notification channel repository
+func NewChannelRepository(db *MongoDB) *ChannelRepository {
+	return &ChannelRepository{
+		collection:      db.GetCollection(CollectionChannels),
+		configs:         db.GetCollection(CollectionHealthCheckConfigs),
+		suites:          db.GetCollection(CollectionSuites),
+		alertCollection: db.GetHistoryCollection(CollectionAlertLogs),
+	}
+}
+
+// Create inserts a new notification channel
+func (r *ChannelRepository) Create(ctx context.Context, channel *model.NotificationChannel) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Ensure ID is generated if not set
+	if channel.ID.IsZero() {
+		channel.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctxTimeout, channel)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("channel with name '%s' already exists", channel.Name)
+		}
+		return fmt.Errorf("failed to create channel: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a notification channel by ID
+func (r *ChannelRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*model.NotificationChannel, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var channel model.NotificationChannel
+	err := r.collection.FindOne(ctxTimeout, bson.M{"_id": id}).Decode(&channel)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("channel not found")
+		}
+		return nil, fmt.Errorf("failed to get channel: %w", err)
+	}
+
+	return &channel, nil
+}
+
+// List retrieves notification channels with pagination
+func (r *ChannelRepository) List(ctx context.Context, page, limit int) ([]model.NotificationChannel, int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctxTimeout, bson.M{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count channels: %w", err)
+	}
+
+	// Calculate pagination
+	skip := (page - 1) * limit
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctxTimeout, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list channels: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	channels := make([]model.NotificationChannel, 0)
+	if err := cursor.All(ctxTimeout, &channels); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode channels: %w", err)
+	}
+
+	return channels, total, nil
+}
+
+// Update replaces an existing notification channel
+func (r *ChannelRepository) Update(ctx context.Context, id primitive.ObjectID, channel *model.NotificationChannel) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	channel.ID = id
+	result, err := r.collection.ReplaceOne(ctxTimeout, bson.M{"_id": id}, channel)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("channel with name '%s' already exists", channel.Name)
+		}
+		return fmt.Errorf("failed to update channel: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("channel not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a notification channel
+func (r *ChannelRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctxTimeout, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete channel: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("channel not found")
+	}
+
+	return nil
+}
+
+// CountReferences counts the health checks and suites that send alerts through a channel
+func (r *ChannelRepository) CountReferences(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"webhook.channel_id": id}
+
+	configs, err := r.configs.CountDocuments(ctxTimeout, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count channel references: %w", err)
+	}
+
+	suites, err := r.suites.CountDocuments(ctxTimeout, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count channel references: %w", err)
+	}
+
+	return configs + suites, nil
+}
+
+// Stats aggregates delivery statistics of the alerts sent through a channel since the given time
+func (r *ChannelRepository) Stats(ctx context.Context, id primitive.ObjectID, since time.Time, bucket string) (*model.ChannelStats, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"channel_id": id,
+			"created_at": bson.M{"$gte": since},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"total":           bson.A{bson.M{"$count": "count"}},
+			"by_final_status": groupCountStage("$final_status", 0),
+			"by_time":         timeBucketStage("created_at", bucket),
+			"delivery": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":          nil,
+					"avg_attempts": bson.M{"$avg": bson.M{"$size": bson.M{"$ifNull": bson.A{"$attempts", bson.A{}}}}},
+					"last_delivered_at": bson.M{"$max": bson.M{"$cond": bson.A{
+						bson.M{"$eq": bson.A{"$final_status", "delivered"}}, "$completed_at", nil,
+					}}},
+				}},
+			},
+		}}},
+	}
+
+	cursor, err := r.alertCollection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate channel stats: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var results []struct {
+		Total         []facetCount        `bson:"total"`
+		ByFinalStatus []model.CountBucket `bson:"by_final_status"`
+		ByTime        []model.CountBucket `bson:"by_time"`
+		Delivery      []struct {
+			AvgAttempts     float64   `bson:"avg_attempts"`
+			LastDeliveredAt time.Time `bson:"last_delivered_at"`
+		} `bson:"delivery"`
+	}
+	if err := cursor.All(ctxTimeout, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode channel stats: %w", err)
+	}
+
+	stats := &model.ChannelStats{
+		ChannelID:     id.Hex(),
+		ByFinalStatus: []model.CountBucket{},
+		ByTime:        []model.CountBucket{},
+	}
+	if len(results) > 0 {
+		stats.Total = facetTotal(results[0].Total)
+		stats.ByFinalStatus = append(stats.ByFinalStatus, results[0].ByFinalStatus...)
+		stats.ByTime = append(stats.ByTime, results[0].ByTime...)
+		if len(results[0].Delivery) > 0 {
+			stats.AvgAttempts = results[0].Delivery[0].AvgAttempts
+			if !results[0].Delivery[0].LastDeliveredAt.IsZero() {
+				stats.LastDeliveredAt = results[0].Delivery[0].LastDeliveredAt.Format(time.RFC3339)
+			}
+		}
+	}
+
+	return stats, nil
+}
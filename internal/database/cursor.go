@@ -0,0 +1,120 @@
+package database
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidCursor is returned for a cursor that wasn't issued for the same sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page selects one page of list results, either by number or by continuing after the
+// cursor of the previous page
+type Page struct {
+	Number int
+	Limit  int
+	Cursor string // Continues after the previous page; Number is ignored when set
+	Count  bool   // Count all matches, which is slow on large collections
+}
+
+// cursorValueTypes lists the BSON types a cursor value may have for each keyset sort field.
+// Cursors come back from clients unsigned and their value goes into the query, so any other
+// type, such as a document of query operators, is rejected. Null stands for a missing field.
+var cursorValueTypes = map[string][]bsontype.Type{
+	"created_at":   {bsontype.DateTime},
+	"final_status": {bsontype.String},
+	"severity":     {bsontype.String},
+}
+
+// pageCursor is the position of the last document of a page in its sort order
+type pageCursor struct {
+	Key       string             `bson:"k"`
+	Direction int                `bson:"d"`
+	Value     bson.RawValue      `bson:"v"`
+	ID        primitive.ObjectID `bson:"id"`
+}
+
+// keysetSort returns the sort field and direction, with _id as tiebreaker so every document
+// has a unique position
+func keysetSort(sort bson.D) (string, int, bson.D) {
+	key := sort[0].Key
+	direction, _ := sort[0].Value.(int)
+	if direction == 0 {
+		direction = 1
+	}
+	return key, direction, bson.D{{Key: key, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+// encodeCursor returns the cursor of the page ending with last
+func encodeCursor(sort bson.D, last bson.Raw) (string, error) {
+	key, direction, _ := keysetSort(sort)
+	id, ok := last.Lookup("_id").ObjectIDOK()
+	if !ok {
+		return "", errors.New("document has no _id")
+	}
+
+	value, err := last.LookupErr(key)
+	if err != nil {
+		value = bson.RawValue{Type: bsontype.Null}
+	}
+
+	encoded, err := bson.Marshal(pageCursor{Key: key, Direction: direction, Value: value, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// afterCursor returns the filter matching the documents that follow the cursor in the sort
+// order. Documents missing the sort field sort before all others, as in MongoDB.
+func afterCursor(sort bson.D, token string) (bson.M, error) {
+	encoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor pageCursor
+	if err := bson.Unmarshal(encoded, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	key, direction, _ := keysetSort(sort)
+	if cursor.Key != key || cursor.Direction != direction || !validCursorValue(key, cursor.Value) {
+		return nil, ErrInvalidCursor
+	}
+
+	operator := "$gt"
+	if direction < 0 {
+		operator = "$lt"
+	}
+	sameValue := bson.M{key: cursor.Value, "_id": bson.M{operator: cursor.ID}}
+
+	if cursor.Value.Type == bsontype.Null || cursor.Value.Type == bsontype.Undefined {
+		sameValue[key] = nil
+		if direction < 0 {
+			return sameValue, nil
+		}
+		return bson.M{"$or": []bson.M{sameValue, {key: bson.M{"$ne": nil}}}}, nil
+	}
+
+	after := []bson.M{sameValue, {key: bson.M{operator: cursor.Value}}}
+	if direction < 0 {
+		after = append(after, bson.M{key: nil})
+	}
+	return bson.M{"$or": after}, nil
+}
+
+// validCursorValue reports whether a cursor value has a type the sort field can hold
+func validCursorValue(key string, value bson.RawValue) bool {
+	if value.Type == bsontype.Null || value.Type == bsontype.Undefined {
+		return true
+	}
+	if err := value.Validate(); err != nil {
+		return false
+	}
+	return slices.Contains(cursorValueTypes[key], value.Type)
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MaxDocumentBytes is MongoDB's 16MB document limit, less headroom for the insert command
+const MaxDocumentBytes = 16*1024*1024 - 16*1024
+
+// maxTruncatedValueBytes is the largest extracted rule value kept once bodies alone can't
+// bring an execution under the limit
+const maxTruncatedValueBytes = 1024
+
+// truncationMarker ends a body cut to fit the document size limit
+const truncationMarker = "\n...[truncated: %d bytes omitted]"
+
+// fitDocument truncates the request and response bodies of an execution, largest first,
+// until its BSON encoding fits in maxBytes, then oversized rule values. Truncated executions
+// are flagged. An error is returned when the execution still doesn't fit.
+func fitDocument(execution *model.ExecutionHistory, maxBytes int) error {
+	size, err := bsonSize(execution)
+	if err != nil {
+		return err
+	}
+	if size <= maxBytes {
+		return nil
+	}
+
+	originalSize := size
+	execution.Truncated = true
+
+	bodies := []*string{&execution.Response.Body, &execution.Response.TransformedBody, &execution.Request.Body}
+	slices.SortStableFunc(bodies, func(a, b *string) int { return len(*b) - len(*a) })
+	for _, body := range bodies {
+		if size, err = bsonSize(execution); err != nil || size <= maxBytes {
+			break
+		}
+		// Leave room for the marker, whose length depends on the omitted byte count
+		keep := len(*body) - (size - maxBytes) - len(truncationMarker) - 20
+		*body = truncateBody(*body, keep)
+	}
+	if err != nil {
+		return err
+	}
+
+	if size, err = bsonSize(execution); err == nil && size > maxBytes {
+		for i := range execution.RulesEvaluation {
+			execution.RulesEvaluation[i].ExtractedValue = truncateValue(execution.RulesEvaluation[i].ExtractedValue)
+		}
+		size, err = bsonSize(execution)
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Warn("Truncated execution history to fit the document size limit",
+		"correlation_id", execution.CorrelationID,
+		"config_name", execution.ConfigName,
+		"original_bytes", originalSize,
+		"bytes", size,
+		"max_bytes", maxBytes,
+	)
+	if size > maxBytes {
+		return fmt.Errorf("execution history is %d bytes after truncation, over the %d byte limit", size, maxBytes)
+	}
+	return nil
+}
+
+// truncateBody keeps the first keep bytes of body, cut on a character boundary, followed by a marker
+func truncateBody(body string, keep int) string {
+	if keep >= len(body) {
+		return body
+	}
+	keep = max(keep, 0)
+	for keep > 0 && !utf8.RuneStart(body[keep]) {
+		keep--
+	}
+	return body[:keep] + fmt.Sprintf(truncationMarker, len(body)-keep)
+}
+
+// truncateValue replaces an extracted value whose encoding exceeds maxTruncatedValueBytes with a marker
+func truncateValue(value interface{}) interface{} {
+	data, err := bson.Marshal(bson.M{"v": value})
+	if err != nil || len(data) <= maxTruncatedValueBytes {
+		return value
+	}
+	return fmt.Sprintf("[truncated: %d bytes omitted]", len(data))
+}
+
+// bsonSize returns the size of the execution's BSON encoding
+func bsonSize(execution *model.ExecutionHistory) (int, error) {
+	data, err := bson.Marshal(execution)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode execution history: %w", err)
+	}
+	return len(data), nil
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DowntimeRepository handles health check downtime intervals
+type DowntimeRepository struct {
+	collection *mongo.Collection
+}
+
+// NewDowntimeRepository creates a new downtime repository
+func NewDowntimeRepository(db *MongoDB) *DowntimeRepository {
+	return &DowntimeRepository{
+		collection: db.GetCollection(CollectionDowntimes),
+	}
+}
+
+// Open inserts a downtime that has not ended yet
+func (r *DowntimeRepository) Open(ctx context.Context, downtime *model.Downtime) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if downtime.ID.IsZero() {
+		downtime.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctxTimeout, downtime); err != nil {
+		return fmt.Errorf("failed to open downtime: %w", err)
+	}
+
+	return nil
+}
+
+// Close ends the open downtimes of a health check at endedAt
+func (r *DowntimeRepository) Close(ctx context.Context, configID primitive.ObjectID, endedAt time.Time, correlationID string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"config_id": configID,
+		"ended_at":  nil,
+	}
+	// Pipeline update so the duration is computed from the stored start time
+	update := bson.A{
+		bson.M{"$set": bson.M{
+			"ended_at":           endedAt,
+			"duration_ms":        bson.M{"$subtract": bson.A{endedAt, "$started_at"}},
+			"end_correlation_id": correlationID,
+		}},
+	}
+
+	if _, err := r.collection.UpdateMany(ctxTimeout, filter, update); err != nil {
+		return fmt.Errorf("failed to close downtime: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves downtimes, newest first, with pagination
+func (r *DowntimeRepository) List(ctx context.Context, filter bson.M, page, limit int) ([]model.Downtime, int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctxTimeout, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count downtimes: %w", err)
+	}
+
+	// Calculate pagination
+	skip := (page - 1) * limit
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "started_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list downtimes: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	downtimes := make([]model.Downtime, 0)
+	if err := cursor.All(ctxTimeout, &downtimes); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode downtimes: %w", err)
+	}
+
+	return downtimes, total, nil
+}
+
+// FindOverlapping retrieves the downtimes that overlap [from, to], optionally for one health check
+func (r *DowntimeRepository) FindOverlapping(ctx context.Context, configID primitive.ObjectID, from, to time.Time) ([]model.Downtime, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"started_at": bson.M{"$lt": to},
+		"$or": bson.A{
+			bson.M{"ended_at": nil},
+			bson.M{"ended_at": bson.M{"$gt": from}},
+		},
+	}
+	if !configID.IsZero() {
+		filter["config_id"] = configID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "started_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find downtimes: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	downtimes := make([]model.Downtime, 0)
+	if err := cursor.All(ctxTimeout, &downtimes); err != nil {
+		return nil, fmt.Errorf("failed to decode downtimes: %w", err)
+	}
+
+	return downtimes, nil
+}
@@ -15,13 +15,15 @@ import (
 
 // ExecutionRepository handles execution history operations
 type ExecutionRepository struct {
-	collection *mongo.Collection
+	collection     *mongo.Collection
+	readCollection *mongo.Collection // Uses the history read preference for list queries
 }
 
 // NewExecutionRepository creates a new execution repository
 func NewExecutionRepository(db *MongoDB) *ExecutionRepository {
 	return &ExecutionRepository{
-		collection: db.GetCollection(CollectionExecutionHistory),
+		collection:     db.GetCollection(CollectionExecutionHistory),
+		readCollection: db.GetHistoryCollection(CollectionExecutionHistory),
 	}
 }
 
@@ -43,6 +45,52 @@ func (r *ExecutionRepository) Create(ctx context.Context, execution *model.Execu
 	return nil
 }
 
+// Save inserts or replaces an execution history by ID, so a retried insert that already
+// landed isn't duplicated
+func (r *ExecutionRepository) Save(ctx context.Context, execution *model.ExecutionHistory) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if execution.ID.IsZero() {
+		execution.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.ReplaceOne(ctxTimeout, bson.M{"_id": execution.ID}, execution, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save execution history: %w", err)
+	}
+
+	return nil
+}
+
+// CreateMany inserts multiple execution history records in a single round-trip
+func (r *ExecutionRepository) CreateMany(ctx context.Context, executions []*model.ExecutionHistory) error {
+	if len(executions) == 0 {
+		return nil
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	docs := make([]interface{}, len(executions))
+	for i, execution := range executions {
+		// Ensure ID is generated if not set
+		if execution.ID.IsZero() {
+			execution.ID = primitive.NewObjectID()
+		}
+		docs[i] = execution
+	}
+
+	// Unordered so a single bad document doesn't block the rest of the batch
+	opts := options.InsertMany().SetOrdered(false)
+	_, err := r.collection.InsertMany(ctxTimeout, docs, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create execution histories: %w", err)
+	}
+
+	return nil
+}
+
 // GetByCorrelationID retrieves an execution history by correlation ID
 func (r *ExecutionRepository) GetByCorrelationID(ctx context.Context, correlationID string) (*model.ExecutionHistory, error) {
 	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -60,13 +108,28 @@ func (r *ExecutionRepository) GetByCorrelationID(ctx context.Context, correlatio
 	return &execution, nil
 }
 
-// List retrieves execution history with filtering and pagination
-func (r *ExecutionRepository) List(ctx context.Context, filter bson.M, page, limit int) ([]model.ExecutionHistory, int64, error) {
+// executionSummaryProjection limits list queries to the fields needed by ExecutionSummary,
+// avoiding decoding full request/response bodies
+var executionSummaryProjection = bson.M{
+	"correlation_id":            1,
+	"config_id":                 1,
+	"config_name":               1,
+	"batch_id":                  1,
+	"executed_at":               1,
+	"duration_ms":               1,
+	"status":                    1,
+	"alerts_triggered.alert_id": 1,
+	"metadata":                  1,
+}
+
+// List retrieves execution history summaries with filtering and pagination.
+// Only summary fields are fetched; use GetByCorrelationID for full documents.
+func (r *ExecutionRepository) List(ctx context.Context, filter bson.M, sort bson.D, page, limit int) ([]model.ExecutionHistory, int64, error) {
 	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Count total documents
-	total, err := r.collection.CountDocuments(ctxTimeout, filter)
+	total, err := r.readCollection.CountDocuments(ctxTimeout, filter)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count executions: %w", err)
 	}
@@ -76,10 +139,11 @@ func (r *ExecutionRepository) List(ctx context.Context, filter bson.M, page, lim
 	opts := options.Find().
 		SetSkip(int64(skip)).
 		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "executed_at", Value: -1}})
+		SetSort(sortOrDefault(sort, bson.D{{Key: "executed_at", Value: -1}})).
+		SetProjection(executionSummaryProjection)
 
 	// Find documents
-	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	cursor, err := r.readCollection.Find(ctxTimeout, filter, opts)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list executions: %w", err)
 	}
@@ -115,3 +179,337 @@ func (r *ExecutionRepository) UpdateAlertTriggered(ctx context.Context, correlat
 
 	return nil
 }
+
+// UpdateAlertDeliveryStatus updates the delivery status of a triggered alert in the execution history
+func (r *ExecutionRepository) UpdateAlertDeliveryStatus(ctx context.Context, executionID, alertID primitive.ObjectID, status string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":                       executionID,
+		"alerts_triggered.alert_id": alertID,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"alerts_triggered.$.delivery_status": status,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctxTimeout, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update alert delivery status: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("execution not found")
+	}
+
+	return nil
+}
+
+// Stats computes execution counts by status, config and time bucket in a single aggregation
+func (r *ExecutionRepository) Stats(ctx context.Context, filter bson.M, bucket string, topConfigs int) (*model.ExecutionStats, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$facet", Value: bson.M{
+			"total":     bson.A{bson.M{"$count": "count"}},
+			"by_status": groupCountStage("$status", 0),
+			"by_config": groupCountStage(configIDString, topConfigs),
+			"by_time":   timeBucketStage("executed_at", bucket),
+		}}},
+	}
+
+	cursor, err := r.readCollection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate execution stats: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var results []struct {
+		Total    []facetCount        `bson:"total"`
+		ByStatus []model.CountBucket `bson:"by_status"`
+		ByConfig []model.CountBucket `bson:"by_config"`
+		ByTime   []model.CountBucket `bson:"by_time"`
+	}
+	if err := cursor.All(ctxTimeout, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode execution stats: %w", err)
+	}
+
+	stats := &model.ExecutionStats{
+		ByStatus: []model.CountBucket{},
+		ByConfig: []model.CountBucket{},
+		ByTime:   []model.CountBucket{},
+	}
+	if len(results) > 0 {
+		stats.Total = facetTotal(results[0].Total)
+		stats.ByStatus = append(stats.ByStatus, results[0].ByStatus...)
+		stats.ByConfig = append(stats.ByConfig, results[0].ByConfig...)
+		stats.ByTime = append(stats.ByTime, results[0].ByTime...)
+	}
+
+	return stats, nil
+}
+
+// ListByBatchID retrieves execution history summaries for all members of a batch
+func (r *ExecutionRepository) ListByBatchID(ctx context.Context, batchID string) ([]model.ExecutionHistory, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "executed_at", Value: 1}}).
+		SetProjection(executionSummaryProjection)
+
+	cursor, err := r.collection.Find(ctxTimeout, bson.M{"batch_id": batchID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batch executions: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var executions []model.ExecutionHistory
+	if err := cursor.All(ctxTimeout, &executions); err != nil {
+		return nil, fmt.Errorf("failed to decode batch executions: %w", err)
+	}
+
+	return executions, nil
+}
+
+// LatestByConfigIDs retrieves the most recent execution of each config, keyed by config ID.
+// Only the fields needed to judge the execution's health are returned.
+func (r *ExecutionRepository) LatestByConfigIDs(ctx context.Context, configIDs []primitive.ObjectID) (map[primitive.ObjectID]model.ExecutionHistory, error) {
+	latest := make(map[primitive.ObjectID]model.ExecutionHistory, len(configIDs))
+	if len(configIDs) == 0 {
+		return latest, nil
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"config_id": bson.M{"$in": configIDs}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "config_id", Value: 1}, {Key: "executed_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    "$config_id",
+			"latest": bson.M{"$first": "$$ROOT"},
+		}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$latest"}}},
+		{{Key: "$project", Value: bson.M{
+			"config_id":                       1,
+			"correlation_id":                  1,
+			"executed_at":                     1,
+			"status":                          1,
+			"rules_evaluation.matched":        1,
+			"rules_evaluation.alert_on_match": 1,
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find latest executions: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var executions []model.ExecutionHistory
+	if err := cursor.All(ctxTimeout, &executions); err != nil {
+		return nil, fmt.Errorf("failed to decode latest executions: %w", err)
+	}
+
+	for _, execution := range executions {
+		latest[execution.ConfigID] = execution
+	}
+
+	return latest, nil
+}
+
+// LatestByRegion retrieves the most recent agent execution of a config per region since the given time
+func (r *ExecutionRepository) LatestByRegion(ctx context.Context, configID primitive.ObjectID, since time.Time) (map[string]model.ExecutionHistory, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	match := bson.M{
+		"config_id":       configID,
+		"metadata.region": bson.M{"$exists": true, "$ne": ""},
+	}
+	if !since.IsZero() {
+		match["executed_at"] = bson.M{"$gte": since}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "executed_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    "$metadata.region",
+			"latest": bson.M{"$first": "$$ROOT"},
+		}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$latest"}}},
+		{{Key: "$project", Value: bson.M{
+			"config_id":                       1,
+			"correlation_id":                  1,
+			"executed_at":                     1,
+			"status":                          1,
+			"metadata":                        1,
+			"rules_evaluation.matched":        1,
+			"rules_evaluation.alert_on_match": 1,
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find latest executions by region: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var executions []model.ExecutionHistory
+	if err := cursor.All(ctxTimeout, &executions); err != nil {
+		return nil, fmt.Errorf("failed to decode latest executions by region: %w", err)
+	}
+
+	latest := make(map[string]model.ExecutionHistory, len(executions))
+	for _, execution := range executions {
+		latest[execution.Metadata.Region] = execution
+	}
+
+	return latest, nil
+}
+
+// RuleValues returns the numeric values a rule extracted in a config's most recent executions,
+// newest first. A zero since or limit leaves that bound off.
+func (r *ExecutionRepository) RuleValues(ctx context.Context, configID primitive.ObjectID, ruleName string, since time.Time, limit int) ([]float64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	match := bson.M{
+		"config_id":                  configID,
+		"rules_evaluation.rule_name": ruleName,
+	}
+	if !since.IsZero() {
+		match["executed_at"] = bson.M{"$gte": since}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "executed_at", Value: -1}}}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$unwind", Value: "$rules_evaluation"}},
+		bson.D{{Key: "$match", Value: bson.M{
+			"rules_evaluation.rule_name":       ruleName,
+			"rules_evaluation.extracted_value": bson.M{"$type": "number"},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":   0,
+			"value": bson.M{"$toDouble": "$rules_evaluation.extracted_value"},
+		}}},
+	)
+
+	cursor, err := r.collection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate rule values: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var results []struct {
+		Value float64 `bson:"value"`
+	}
+	if err := cursor.All(ctxTimeout, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode rule values: %w", err)
+	}
+
+	values := make([]float64, 0, len(results))
+	for _, result := range results {
+		values = append(values, result.Value)
+	}
+
+	return values, nil
+}
+
+// RuleValuesByCorrelationIDs returns the numeric values a rule extracted in the given executions
+func (r *ExecutionRepository) RuleValuesByCorrelationIDs(ctx context.Context, ruleName string, correlationIDs []string) ([]float64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"correlation_id":             bson.M{"$in": correlationIDs},
+			"rules_evaluation.rule_name": ruleName,
+		}}},
+		{{Key: "$unwind", Value: "$rules_evaluation"}},
+		{{Key: "$match", Value: bson.M{
+			"rules_evaluation.rule_name":       ruleName,
+			"rules_evaluation.extracted_value": bson.M{"$type": "number"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":   0,
+			"value": bson.M{"$toDouble": "$rules_evaluation.extracted_value"},
+		}}},
+	}
+
+	cursor, err := r.readCollection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate rule values: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var results []struct {
+		Value float64 `bson:"value"`
+	}
+	if err := cursor.All(ctxTimeout, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode rule values: %w", err)
+	}
+
+	values := make([]float64, 0, len(results))
+	for _, result := range results {
+		values = append(values, result.Value)
+	}
+
+	return values, nil
+}
+
+// LastRuleFingerprint returns the fingerprint a change rule recorded in the config's most
+// recent execution that has one, or an empty string when there is none
+func (r *ExecutionRepository) LastRuleFingerprint(ctx context.Context, configID primitive.ObjectID, ruleName string) (string, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"config_id":                  configID,
+			"rules_evaluation.rule_name": ruleName,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "executed_at", Value: -1}}}},
+		{{Key: "$unwind", Value: "$rules_evaluation"}},
+		{{Key: "$match", Value: bson.M{
+			"rules_evaluation.rule_name":       ruleName,
+			"rules_evaluation.extracted_value": bson.M{"$type": "string"},
+		}}},
+		{{Key: "$limit", Value: 1}},
+		{{Key: "$project", Value: bson.M{
+			"_id":   0,
+			"value": "$rules_evaluation.extracted_value",
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctxTimeout, pipeline)
+	if err != nil {
+		return "", fmt.Errorf("failed to aggregate rule fingerprint: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var results []struct {
+		Value string `bson:"value"`
+	}
+	if err := cursor.All(ctxTimeout, &results); err != nil {
+		return "", fmt.Errorf("failed to decode rule fingerprint: %w", err)
+	}
+
+	if len(results) == 0 {
+		return "", nil
+	}
+	return results[0].Value, nil
+}
@@ -0,0 +1,352 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxDuplicateRetries is the number of times an insert is retried with a new
+// correlation ID after a duplicate-key error
+const maxDuplicateRetries = 3
+
+// duplicateKeyCode is the MongoDB error code for unique index violations
+const duplicateKeyCode = 11000
+
+// maxFailedBatches bounds the failed inserts kept for the next flush when there is no retry
+// queue, in batches
+const maxFailedBatches = 10
+
+// ErrWriterStopped is returned for executions written after the writer was stopped
+var ErrWriterStopped = errors.New("execution writer is stopped")
+
+// ExecutionWriter buffers execution history inserts and persists them in batches.
+// A batch is flushed when it reaches the configured size, when the flush interval
+// elapses, or when the writer is stopped.
+type ExecutionWriter struct {
+	repo             *ExecutionRepository
+	batchSize        int
+	flushInterval    time.Duration
+	maxDocumentBytes int
+	retry            *RetryQueue // Buffers executions whose insert failed; nil reports the error
+
+	mu      sync.Mutex
+	buffer  []*model.ExecutionHistory
+	failed  []*model.ExecutionHistory // Failed inserts saved again on the next flush when there is no retry queue
+	stopped bool
+
+	flushChan chan struct{}
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewExecutionWriter creates a new batched execution writer.
+// A batch size of 1 or less disables batching and writes through directly.
+func NewExecutionWriter(repo *ExecutionRepository, batchSize int, flushInterval time.Duration) *ExecutionWriter {
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	return &ExecutionWriter{
+		repo:             repo,
+		batchSize:        batchSize,
+		flushInterval:    flushInterval,
+		maxDocumentBytes: MaxDocumentBytes,
+		buffer:           make([]*model.ExecutionHistory, 0, max(batchSize, 1)),
+		flushChan:        make(chan struct{}, 1),
+		stopChan:         make(chan struct{}),
+	}
+}
+
+// SetMaxDocumentBytes caps the size of stored executions below MongoDB's document limit.
+// Larger executions have their bodies truncated. Zero keeps MongoDB's limit.
+func (w *ExecutionWriter) SetMaxDocumentBytes(maxBytes int) {
+	if maxBytes <= 0 || maxBytes > MaxDocumentBytes {
+		maxBytes = MaxDocumentBytes
+	}
+	w.maxDocumentBytes = maxBytes
+}
+
+// Start begins the background flush loop
+func (w *ExecutionWriter) Start() {
+	if !w.batching() {
+		return
+	}
+
+	slog.Info("Starting execution writer",
+		"batch_size", w.batchSize,
+		"flush_interval", w.flushInterval,
+	)
+
+	w.wg.Add(1)
+	go w.run()
+}
+
+// Stop stops the flush loop and persists any buffered executions. Later writes fail with
+// ErrWriterStopped.
+func (w *ExecutionWriter) Stop(ctx context.Context) {
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+
+	if !w.batching() {
+		return
+	}
+
+	close(w.stopChan)
+	w.wg.Wait()
+
+	if err := w.Flush(ctx); err != nil {
+		slog.Error("Failed to flush execution writer on shutdown", "error", err)
+	}
+
+	slog.Info("Execution writer stopped")
+}
+
+// SetRetryQueue buffers executions whose insert fails for retry instead of dropping them
+func (w *ExecutionWriter) SetRetryQueue(queue *RetryQueue) {
+	w.retry = queue
+}
+
+// Write queues an execution history for insertion, truncating it first when it is too
+// large to store. An oversized execution in a batch would fail the whole insert.
+func (w *ExecutionWriter) Write(ctx context.Context, execution *model.ExecutionHistory) error {
+	if err := fitDocument(execution, w.maxDocumentBytes); err != nil {
+		return err
+	}
+
+	if !w.batching() {
+		return w.insert(ctx, execution)
+	}
+
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return ErrWriterStopped
+	}
+	w.buffer = append(w.buffer, execution)
+	full := len(w.buffer) >= w.batchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flushChan <- struct{}{}:
+		default:
+			// A flush is already pending
+		}
+	}
+
+	return nil
+}
+
+// WriteNow inserts an execution history at once, bypassing the batch. A correlation ID that
+// collides with an existing execution is replaced with a derived one before it returns, so
+// the caller can read the final ID from the execution.
+func (w *ExecutionWriter) WriteNow(ctx context.Context, execution *model.ExecutionHistory) error {
+	if w.isStopped() {
+		return ErrWriterStopped
+	}
+	if err := fitDocument(execution, w.maxDocumentBytes); err != nil {
+		return err
+	}
+
+	return w.insert(ctx, execution)
+}
+
+// Flush persists all buffered executions immediately, and saves again the ones whose insert
+// failed before. Executions that fail again are kept for the next flush.
+func (w *ExecutionWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	batch, failed := w.buffer, w.failed
+	w.failed = nil
+	if len(batch) > 0 {
+		w.buffer = make([]*model.ExecutionHistory, 0, w.batchSize)
+	}
+	w.mu.Unlock()
+
+	return errors.Join(w.saveFailed(ctx, failed), w.insertBatch(ctx, batch))
+}
+
+// saveFailed saves executions whose insert failed before. Their batch may have been written
+// in part, so they are replaced by ID rather than inserted.
+func (w *ExecutionWriter) saveFailed(ctx context.Context, executions []*model.ExecutionHistory) error {
+	var lost int
+	var lastErr error
+	for _, execution := range executions {
+		if err := w.save(ctx, execution); err != nil {
+			lastErr = err
+			if !w.retryLater(execution, err) {
+				lost++
+			}
+		}
+	}
+	if lost > 0 {
+		return fmt.Errorf("failed to save %d execution histories: %w", lost, lastErr)
+	}
+	return nil
+}
+
+// insertBatch inserts a batch of executions, keeping the ones that fail for a retry
+func (w *ExecutionWriter) insertBatch(ctx context.Context, batch []*model.ExecutionHistory) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := w.repo.CreateMany(ctx, batch); err != nil {
+		var bulkErr mongo.BulkWriteException
+		if !errors.As(err, &bulkErr) {
+			// Part of an unordered batch may have been written; retries replace by ID
+			var failed int
+			for _, execution := range batch {
+				if !w.retryLater(execution, err) {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to save %d execution histories: %w", failed, err)
+			}
+			return nil
+		}
+
+		// Retry duplicate correlation IDs individually, report anything else
+		var failed int
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Index >= len(batch) {
+				failed++
+				continue
+			}
+			if writeErr.Code != duplicateKeyCode {
+				if !w.retryLater(batch[writeErr.Index], writeErr) {
+					failed++
+				}
+				continue
+			}
+			if err := w.createWithRetry(ctx, batch[writeErr.Index]); err != nil && !w.retryLater(batch[writeErr.Index], err) {
+				slog.Error("Failed to save execution history",
+					"correlation_id", batch[writeErr.Index].CorrelationID,
+					"error", err.Error(),
+				)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to save %d of %d execution histories: %w", failed, len(batch), err)
+		}
+	}
+
+	slog.Debug("Flushed execution history batch", "count", len(batch))
+	return nil
+}
+
+// insert writes a single execution, buffering it for retry when the insert fails
+func (w *ExecutionWriter) insert(ctx context.Context, execution *model.ExecutionHistory) error {
+	if w.isStopped() {
+		return ErrWriterStopped
+	}
+	if err := w.createWithRetry(ctx, execution); err != nil && !w.retryLater(execution, err) {
+		return err
+	}
+	return nil
+}
+
+// createWithRetry inserts a single execution, assigning a derived correlation ID
+// when the original collides with an existing execution
+func (w *ExecutionWriter) createWithRetry(ctx context.Context, execution *model.ExecutionHistory) error {
+	originalID := execution.CorrelationID
+
+	for attempt := 1; ; attempt++ {
+		err := w.repo.Create(ctx, execution)
+		if err == nil || !mongo.IsDuplicateKeyError(err) || attempt > maxDuplicateRetries {
+			return err
+		}
+
+		execution.CorrelationID = fmt.Sprintf("%s-r%d", originalID, attempt)
+		slog.Warn("Duplicate correlation ID, retrying with derived ID",
+			"original_correlation_id", originalID,
+			"correlation_id", execution.CorrelationID,
+			"attempt", attempt,
+		)
+	}
+}
+
+// retryLater buffers an execution whose insert failed in the retry queue, or without one
+// until the next flush. It returns false when the execution can't be kept: the writer
+// doesn't batch or is stopped, or too many inserts failed already.
+func (w *ExecutionWriter) retryLater(execution *model.ExecutionHistory, cause error) bool {
+	if execution.ID.IsZero() {
+		execution.ID = primitive.NewObjectID()
+	}
+
+	if w.retry != nil {
+		w.retry.Add(RetryKindExecution, execution.ID.Hex(), func(ctx context.Context) error {
+			return w.save(ctx, execution)
+		}, cause)
+		return true
+	}
+
+	if !w.batching() {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped || len(w.failed) >= w.batchSize*maxFailedBatches {
+		return false
+	}
+	w.failed = append(w.failed, execution)
+	return true
+}
+
+// save writes an execution that may already be stored in part by its ID. A save that
+// collides with another execution's correlation ID is inserted with a derived one.
+func (w *ExecutionWriter) save(ctx context.Context, execution *model.ExecutionHistory) error {
+	err := w.repo.Save(ctx, execution)
+	if mongo.IsDuplicateKeyError(err) {
+		return w.createWithRetry(ctx, execution)
+	}
+	return err
+}
+
+// isStopped reports whether the writer was stopped
+func (w *ExecutionWriter) isStopped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stopped
+}
+
+// batching reports whether inserts are buffered
+func (w *ExecutionWriter) batching() bool {
+	return w.batchSize > 1
+}
+
+// run is the background flush loop
+func (w *ExecutionWriter) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.flushLogged()
+		case <-w.flushChan:
+			w.flushLogged()
+		case <-w.stopChan:
+			return
+		}
+	}
+}
+
+// flushLogged flushes the buffer and logs any error
+func (w *ExecutionWriter) flushLogged() {
+	if err := w.Flush(context.Background()); err != nil {
+		slog.Error("Failed to flush execution history batch", "error", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/dandantas/raven/internal/model"
@@ -13,19 +14,26 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrHealthCheckNotFound is returned by GetByID when no health check has the ID
+var ErrHealthCheckNotFound = errors.New("health check not found")
+
 // HealthCheckRepository handles health check configuration operations
 type HealthCheckRepository struct {
+	db         *MongoDB
 	collection *mongo.Collection
 }
 
 // NewHealthCheckRepository creates a new health check repository
 func NewHealthCheckRepository(db *MongoDB) *HealthCheckRepository {
 	return &HealthCheckRepository{
+		db:         db,
 		collection: db.GetCollection(CollectionHealthCheckConfigs),
 	}
 }
 
-// Create inserts a new health check configuration
+// Create inserts a new health check configuration. Its schedule state (next_scheduled_run)
+// is part of the document and schedule locks are created on first use, so a scheduled
+// check is never visible half-created.
 func (r *HealthCheckRepository) Create(ctx context.Context, config *model.HealthCheckConfig) error {
 	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -55,7 +63,7 @@ func (r *HealthCheckRepository) GetByID(ctx context.Context, id primitive.Object
 	err := r.collection.FindOne(ctxTimeout, bson.M{"_id": id}).Decode(&config)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, fmt.Errorf("health check not found")
+			return nil, ErrHealthCheckNotFound
 		}
 		return nil, fmt.Errorf("failed to get health check: %w", err)
 	}
@@ -80,8 +88,76 @@ func (r *HealthCheckRepository) GetByName(ctx context.Context, name string) (*mo
 	return &config, nil
 }
 
-// List retrieves health check configurations with filtering and pagination
-func (r *HealthCheckRepository) List(ctx context.Context, filter bson.M, page, limit int) ([]model.HealthCheckConfig, int64, error) {
+// NameCollation compares names ignoring case; queries must use it to be served by idx_name_ci
+var NameCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// maxNameMatches bounds the health checks returned for one name ignoring case
+const maxNameMatches = 10
+
+// FindByName retrieves the health checks whose name equals name ignoring case
+func (r *HealthCheckRepository) FindByName(ctx context.Context, name string) ([]model.HealthCheckConfig, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetCollation(NameCollation).SetLimit(maxNameMatches)
+	cursor, err := r.collection.Find(ctxTimeout, bson.M{"name": name}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find health checks by name: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var configs []model.HealthCheckConfig
+	if err := cursor.All(ctxTimeout, &configs); err != nil {
+		return nil, fmt.Errorf("failed to decode health checks: %w", err)
+	}
+
+	return configs, nil
+}
+
+// SearchByName retrieves summaries of up to limit health checks whose name contains query
+// ignoring case, ordered by name
+func (r *HealthCheckRepository) SearchByName(ctx context.Context, query string, limit int) ([]model.HealthCheckConfig, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"name": primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}}
+	opts := options.Find().
+		SetCollation(NameCollation).
+		SetSort(bson.D{{Key: "name", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(healthCheckListProjection)
+
+	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search health checks by name: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var configs []model.HealthCheckConfig
+	if err := cursor.All(ctxTimeout, &configs); err != nil {
+		return nil, fmt.Errorf("failed to decode health checks: %w", err)
+	}
+
+	return configs, nil
+}
+
+// healthCheckListProjection limits list queries to the fields needed by HealthCheckListItem
+var healthCheckListProjection = bson.M{
+	"name":               1,
+	"description":        1,
+	"enabled":            1,
+	"target.url":         1,
+	"rules.name":         1,
+	"metadata":           1,
+	"schedule":           1,
+	"schedule_enabled":   1,
+	"last_scheduled_run": 1,
+	"next_scheduled_run": 1,
+}
+
+// List retrieves health check configuration summaries with filtering and pagination.
+// Only list fields are fetched; use GetByID for full documents.
+func (r *HealthCheckRepository) List(ctx context.Context, filter bson.M, sort bson.D, page, limit int) ([]model.HealthCheckConfig, int64, error) {
 	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -96,7 +172,8 @@ func (r *HealthCheckRepository) List(ctx context.Context, filter bson.M, page, l
 	opts := options.Find().
 		SetSkip(int64(skip)).
 		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "metadata.created_at", Value: -1}})
+		SetSort(sortOrDefault(sort, bson.D{{Key: "metadata.created_at", Value: -1}})).
+		SetProjection(healthCheckListProjection)
 
 	// Find documents
 	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
@@ -153,13 +230,15 @@ func (r *HealthCheckRepository) FindScheduledChecks(ctx context.Context, now tim
 	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	// Find enabled health checks with scheduling enabled and next_scheduled_run <= now
+	// Find enabled health checks with scheduling enabled and next_scheduled_run <= now.
+	// Checks assigned to regions are probed by agents instead.
 	filter := bson.M{
 		"enabled":          true,
 		"schedule_enabled": true,
 		"next_scheduled_run": bson.M{
 			"$lte": now,
 		},
+		"regions.0": bson.M{"$exists": false},
 	}
 
 	cursor, err := r.collection.Find(ctxTimeout, filter)
@@ -199,3 +278,282 @@ func (r *HealthCheckRepository) UpdateScheduledRun(ctx context.Context, id primi
 
 	return nil
 }
+
+// MarkDue moves a health check's next scheduled run to at, so the next tick of any pod runs it
+func (r *HealthCheckRepository) MarkDue(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id}, bson.M{"$set": bson.M{"next_scheduled_run": at}})
+	if err != nil {
+		return fmt.Errorf("failed to mark health check due: %w", err)
+	}
+	return nil
+}
+
+// RecordRun updates the rolling run counters of a health check after an execution
+func (r *HealthCheckRepository) RecordRun(ctx context.Context, id primitive.ObjectID, healthy bool, status string, executedAt time.Time) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	failed := 0
+	if !healthy {
+		failed = 1
+	}
+
+	update := bson.M{
+		"$inc": bson.M{
+			"run_stats.total_runs":  1,
+			"run_stats.failed_runs": failed,
+		},
+		"$push": bson.M{
+			"run_stats.recent": bson.M{
+				"$each":  bson.A{healthy},
+				"$slice": -model.RecentRunsSize,
+			},
+		},
+		"$set": bson.M{
+			"run_stats.last_run_at": executedAt,
+			"run_stats.last_status": status,
+		},
+	}
+
+	if _, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id}, update); err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+
+	return nil
+}
+
+// RecordReachability extends the check's unreachable streak after a run that couldn't reach
+// the target and returns it, or ends the streak after one that could and returns nil
+func (r *HealthCheckRepository) RecordReachability(ctx context.Context, id primitive.ObjectID, unreachable bool, at time.Time) (*model.UnreachableStreak, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !unreachable {
+		filter := bson.M{"_id": id, "unreachable": bson.M{"$exists": true}}
+		if _, err := r.collection.UpdateOne(ctxTimeout, filter, bson.M{"$unset": bson.M{"unreachable": ""}}); err != nil {
+			return nil, fmt.Errorf("failed to reset unreachable streak: %w", err)
+		}
+		return nil, nil
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"unreachable.runs": 1},
+		"$min": bson.M{"unreachable.since": at},
+	}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"unreachable": 1})
+
+	var config model.HealthCheckConfig
+	if err := r.collection.FindOneAndUpdate(ctxTimeout, bson.M{"_id": id}, update, opts).Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to record unreachable run: %w", err)
+	}
+
+	return config.Unreachable, nil
+}
+
+// AutoDisable disables an enabled health check, recording why. It returns false when the
+// check was already disabled, so concurrent executions disable it once.
+func (r *HealthCheckRepository) AutoDisable(ctx context.Context, id primitive.ObjectID, reason string, at time.Time) (bool, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"enabled":         false,
+			"disabled_reason": reason,
+			"disabled_at":     at,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id, "enabled": true}, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to disable health check: %w", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// Disable disables an enabled health check, reporting whether it was enabled
+func (r *HealthCheckRepository) Disable(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id, "enabled": true}, bson.M{"$set": bson.M{"enabled": false}})
+	if err != nil {
+		return false, fmt.Errorf("failed to disable health check: %w", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// SetSchedule changes a health check's schedule unless the config was updated since version,
+// reporting whether it was changed. A zero nextRun keeps the next run as it is.
+func (r *HealthCheckRepository) SetSchedule(ctx context.Context, id primitive.ObjectID, version time.Time, schedule string, enabled bool, nextRun, updatedAt time.Time) (bool, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	set := bson.M{
+		"schedule_enabled":    enabled,
+		"metadata.updated_at": updatedAt,
+	}
+	if schedule != "" {
+		set["schedule"] = schedule
+	}
+	if !nextRun.IsZero() {
+		set["next_scheduled_run"] = nextRun
+	}
+
+	result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id, "metadata.updated_at": version}, bson.M{"$set": set})
+	if err != nil {
+		return false, fmt.Errorf("failed to set schedule: %w", err)
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// Enable re-enables a health check, clearing its automatic disable and unreachable streak
+func (r *HealthCheckRepository) Enable(ctx context.Context, id primitive.ObjectID) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set":   bson.M{"enabled": true},
+		"$unset": bson.M{"disabled_reason": "", "disabled_at": "", "unreachable": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to enable health check: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("health check not found")
+	}
+
+	return nil
+}
+
+// TransitionState moves a health check to state unless it is already in it. It returns
+// the config as it was before, or nil when the state didn't change, so concurrent
+// executions record each transition once.
+func (r *HealthCheckRepository) TransitionState(ctx context.Context, id primitive.ObjectID, state string, at time.Time) (*model.HealthCheckConfig, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":   id,
+		"state": bson.M{"$ne": state},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"state":       state,
+			"state_since": at,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.Before).
+		SetProjection(bson.M{"state": 1, "state_since": 1})
+
+	var previous model.HealthCheckConfig
+	err := r.collection.FindOneAndUpdate(ctxTimeout, filter, update, opts).Decode(&previous)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update state: %w", err)
+	}
+
+	return &previous, nil
+}
+
+// SetTriggerNonce replaces the nonce that trigger tokens for a health check are signed with
+func (r *HealthCheckRepository) SetTriggerNonce(ctx context.Context, id primitive.ObjectID, nonce string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id}, bson.M{"$set": bson.M{"trigger_nonce": nonce}})
+	if err != nil {
+		return fmt.Errorf("failed to update trigger nonce: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("health check not found")
+	}
+
+	return nil
+}
+
+// FindByTag retrieves enabled health checks carrying a tag
+func (r *HealthCheckRepository) FindByTag(ctx context.Context, tag string) ([]model.HealthCheckConfig, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctxTimeout, bson.M{"enabled": true, "metadata.tags": tag})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find health checks by tag: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var configs []model.HealthCheckConfig
+	if err := cursor.All(ctxTimeout, &configs); err != nil {
+		return nil, fmt.Errorf("failed to decode health checks: %w", err)
+	}
+
+	return configs, nil
+}
+
+// FindIDsByTags retrieves the IDs of up to limit health checks carrying all tags, enabled or not
+func (r *HealthCheckRepository) FindIDsByTags(ctx context.Context, tags []string, limit int) ([]primitive.ObjectID, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctxTimeout, bson.M{"metadata.tags": bson.M{"$all": tags}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find health checks by tags: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var configs []model.HealthCheckConfig
+	if err := cursor.All(ctxTimeout, &configs); err != nil {
+		return nil, fmt.Errorf("failed to decode health checks: %w", err)
+	}
+
+	ids := make([]primitive.ObjectID, len(configs))
+	for i, config := range configs {
+		ids[i] = config.ID
+	}
+	return ids, nil
+}
+
+// FindByRegion retrieves enabled scheduled health checks assigned to a region
+func (r *HealthCheckRepository) FindByRegion(ctx context.Context, region string) ([]model.HealthCheckConfig, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"enabled":          true,
+		"schedule_enabled": true,
+		"regions":          region,
+	}
+
+	cursor, err := r.collection.Find(ctxTimeout, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find region checks: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var configs []model.HealthCheckConfig
+	if err := cursor.All(ctxTimeout, &configs); err != nil {
+		return nil, fmt.Errorf("failed to decode region checks: %w", err)
+	}
+
+	return configs, nil
+}
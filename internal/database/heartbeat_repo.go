@@ -0,0 +1,220 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HeartbeatRepository handles heartbeat monitor operations
+type HeartbeatRepository struct {
+	collection *mongo.Collection
+}
+
+// NewHeartbeatRepository creates a new heartbeat repository
+func NewHeartbeatRepository(db *MongoDB) *HeartbeatRepository {
+	return &HeartbeatRepository{
+		collection: db.GetCollection(CollectionHeartbeats),
+	}
+}
+
+// Create inserts a new heartbeat
+func (r *HeartbeatRepository) Create(ctx context.Context, heartbeat *model.Heartbeat) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Ensure ID is generated if not set
+	if heartbeat.ID.IsZero() {
+		heartbeat.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctxTimeout, heartbeat)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("heartbeat with name '%s' already exists", heartbeat.Name)
+		}
+		return fmt.Errorf("failed to create heartbeat: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a heartbeat by ID
+func (r *HeartbeatRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*model.Heartbeat, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var heartbeat model.Heartbeat
+	err := r.collection.FindOne(ctxTimeout, bson.M{"_id": id}).Decode(&heartbeat)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("heartbeat not found")
+		}
+		return nil, fmt.Errorf("failed to get heartbeat: %w", err)
+	}
+
+	return &heartbeat, nil
+}
+
+// List retrieves heartbeats with pagination
+func (r *HeartbeatRepository) List(ctx context.Context, filter bson.M, page, limit int) ([]model.Heartbeat, int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctxTimeout, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count heartbeats: %w", err)
+	}
+
+	// Calculate pagination
+	skip := (page - 1) * limit
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "metadata.created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list heartbeats: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	heartbeats := make([]model.Heartbeat, 0)
+	if err := cursor.All(ctxTimeout, &heartbeats); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode heartbeats: %w", err)
+	}
+
+	return heartbeats, total, nil
+}
+
+// Update replaces an existing heartbeat
+func (r *HeartbeatRepository) Update(ctx context.Context, id primitive.ObjectID, heartbeat *model.Heartbeat) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	heartbeat.ID = id
+	result, err := r.collection.ReplaceOne(ctxTimeout, bson.M{"_id": id}, heartbeat)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("heartbeat with name '%s' already exists", heartbeat.Name)
+		}
+		return fmt.Errorf("failed to update heartbeat: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("heartbeat not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a heartbeat
+func (r *HeartbeatRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctxTimeout, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete heartbeat: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("heartbeat not found")
+	}
+
+	return nil
+}
+
+// Ping records a ping, pushes the deadline out by interval plus grace and marks the
+// heartbeat up. It returns the heartbeat as it was before the ping.
+func (r *HeartbeatRepository) Ping(ctx context.Context, id primitive.ObjectID, now time.Time) (*model.Heartbeat, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// The deadline depends on the stored interval, so it is computed server-side
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"status":       model.HeartbeatStatusUp,
+			"last_ping_at": now,
+			"expected_by": bson.M{"$add": bson.A{
+				now,
+				bson.M{"$multiply": bson.A{
+					bson.M{"$add": bson.A{"$interval_seconds", "$grace_seconds"}},
+					1000,
+				}},
+			}},
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	var previous model.Heartbeat
+	err := r.collection.FindOneAndUpdate(ctxTimeout, bson.M{"_id": id}, update, opts).Decode(&previous)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("heartbeat not found")
+		}
+		return nil, fmt.Errorf("failed to record heartbeat ping: %w", err)
+	}
+
+	return &previous, nil
+}
+
+// FindOverdue retrieves enabled heartbeats that are up but whose deadline has passed
+func (r *HeartbeatRepository) FindOverdue(ctx context.Context, now time.Time) ([]model.Heartbeat, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"enabled":     true,
+		"status":      model.HeartbeatStatusUp,
+		"expected_by": bson.M{"$lte": now},
+	}
+
+	cursor, err := r.collection.Find(ctxTimeout, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find overdue heartbeats: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	heartbeats := make([]model.Heartbeat, 0)
+	if err := cursor.All(ctxTimeout, &heartbeats); err != nil {
+		return nil, fmt.Errorf("failed to decode heartbeats: %w", err)
+	}
+
+	return heartbeats, nil
+}
+
+// MarkDown flips an overdue heartbeat to down. It only matches while the heartbeat is
+// still up with the same deadline, so exactly one pod claims each missed ping.
+func (r *HeartbeatRepository) MarkDown(ctx context.Context, id primitive.ObjectID, expectedBy, now time.Time) (bool, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":         id,
+		"status":      model.HeartbeatStatusUp,
+		"expected_by": expectedBy,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":        model.HeartbeatStatusDown,
+			"last_alert_at": now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctxTimeout, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark heartbeat down: %w", err)
+	}
+
+	return result.ModifiedCount > 0, nil
+}
@@ -0,0 +1,430 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kinds of index drift
+const (
+	IndexMissing = "missing" // Desired index doesn't exist
+	IndexChanged = "changed" // Index exists with other keys or options
+	IndexExtra   = "extra"   // Index exists but isn't desired; never dropped automatically
+)
+
+// MongoDB error codes handled while reconciling indexes
+const (
+	unauthorizedCode      = 13
+	namespaceNotFoundCode = 26
+	indexNotFoundCode     = 27
+)
+
+// IndexDrift is a difference between a desired and an existing index
+type IndexDrift struct {
+	Collection string `json:"collection"`
+	Index      string `json:"index"`
+	Kind       string `json:"kind"`               // "missing", "changed" or "extra"
+	Desired    string `json:"desired,omitempty"`  // Keys and options, e.g. "{name: 1} unique"
+	Existing   string `json:"existing,omitempty"` // Keys and options of the existing index
+	Action     string `json:"action,omitempty"`   // "created", "modified" or "recreated" when reconciled
+	Error      string `json:"error,omitempty"`    // Why the drift couldn't be reconciled
+}
+
+// existingIndex is an index as listed by the server
+type existingIndex struct {
+	Name                    string          `bson:"name"`
+	Keys                    bson.Raw        `bson:"key"`
+	Unique                  bool            `bson:"unique,omitempty"`
+	Sparse                  bool            `bson:"sparse,omitempty"`
+	ExpireAfterSeconds      *int32          `bson:"expireAfterSeconds,omitempty"`
+	Collation               *indexCollation `bson:"collation,omitempty"`
+	PartialFilterExpression bson.Raw        `bson:"partialFilterExpression,omitempty"`
+
+	spec bson.Raw // The full specification, used to restore the index
+}
+
+// indexCollation is the collation of an existing index, with every option the server reports
+type indexCollation struct {
+	Locale          string `bson:"locale"`
+	CaseLevel       bool   `bson:"caseLevel"`
+	CaseFirst       string `bson:"caseFirst"`
+	Strength        int    `bson:"strength"`
+	NumericOrdering bool   `bson:"numericOrdering"`
+	Alternate       string `bson:"alternate"`
+	MaxVariable     string `bson:"maxVariable"`
+	Normalization   bool   `bson:"normalization"`
+	Backwards       bool   `bson:"backwards"`
+}
+
+// IndexReport lists the drift between desired and existing indexes
+type IndexReport struct {
+	CheckedAt time.Time    `json:"checked_at"`
+	InSync    bool         `json:"in_sync"` // No missing or changed index is left
+	Drift     []IndexDrift `json:"drift"`
+}
+
+// ReconcileIndexes compares the desired indexes of every collection with the existing ones.
+// When apply is set, missing indexes are created and changed ones are modified in place or
+// dropped and recreated, restoring the old index if the new one can't be built. Indexes
+// that can't be reconciled, for example because the user is read-only, are reported rather
+// than failing. An error is only returned when indexes can't be listed.
+func ReconcileIndexes(ctx context.Context, db *MongoDB, apply bool) (*IndexReport, error) {
+	report := &IndexReport{CheckedAt: time.Now().UTC(), InSync: true, Drift: make([]IndexDrift, 0)}
+
+	for _, desired := range desiredIndexes() {
+		drift, err := diffIndexes(ctx, db.GetCollection(desired.collection), desired)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s indexes: %w", desired.collection, err)
+		}
+
+		for i := range drift {
+			if apply && drift[i].Kind != IndexExtra {
+				reconcileIndex(ctx, db, desired, &drift[i])
+			}
+			if drift[i].Kind != IndexExtra && drift[i].Action == "" {
+				report.InSync = false
+			}
+		}
+		report.Drift = append(report.Drift, drift...)
+	}
+
+	return report, nil
+}
+
+// EnsureIndexes reconciles indexes at startup and logs drift that is left
+func EnsureIndexes(ctx context.Context, db *MongoDB) error {
+	slog.Info("Reconciling MongoDB indexes")
+
+	report, err := ReconcileIndexes(ctx, db, true)
+	if hasErrorCode(err, unauthorizedCode) {
+		// Read-only users can still run Raven; indexes are then managed by an administrator
+		slog.Warn("Not authorized to list indexes, skipping index reconciliation", "error", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, drift := range report.Drift {
+		switch {
+		case drift.Error != "":
+			slog.Warn("Failed to reconcile index",
+				"collection", drift.Collection,
+				"index", drift.Index,
+				"kind", drift.Kind,
+				"error", drift.Error,
+			)
+		case drift.Kind == IndexExtra:
+			slog.Info("Found index not managed by Raven",
+				"collection", drift.Collection,
+				"index", drift.Index,
+				"keys", drift.Existing,
+			)
+		}
+	}
+
+	if report.InSync {
+		slog.Info("MongoDB indexes are in sync")
+	}
+	return nil
+}
+
+// diffIndexes returns the drift between a collection's desired and existing indexes
+func diffIndexes(ctx context.Context, collection *mongo.Collection, desired collectionIndexes) ([]IndexDrift, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	specs, err := listIndexes(ctxTimeout, collection)
+	if err != nil && !hasErrorCode(err, namespaceNotFoundCode) {
+		return nil, err
+	}
+
+	existing := make(map[string]*existingIndex, len(specs))
+	for _, spec := range specs {
+		existing[spec.Name] = spec
+	}
+
+	var drift []IndexDrift
+	wanted := make(map[string]bool, len(desired.indexes))
+	for _, index := range desired.indexes {
+		name := *index.Options.Name
+		wanted[name] = true
+
+		want := describeDesiredIndex(index)
+		spec, ok := existing[name]
+		if !ok {
+			drift = append(drift, IndexDrift{Collection: desired.collection, Index: name, Kind: IndexMissing, Desired: want})
+			continue
+		}
+		if have := describeExistingIndex(spec); have != want {
+			drift = append(drift, IndexDrift{Collection: desired.collection, Index: name, Kind: IndexChanged, Desired: want, Existing: have})
+		}
+	}
+
+	for _, spec := range specs {
+		if spec.Name != "_id_" && !wanted[spec.Name] {
+			drift = append(drift, IndexDrift{Collection: desired.collection, Index: spec.Name, Kind: IndexExtra, Existing: describeExistingIndex(spec)})
+		}
+	}
+	return drift, nil
+}
+
+// listIndexes returns the existing indexes of a collection with their full specifications
+func listIndexes(ctx context.Context, collection *mongo.Collection) ([]*existingIndex, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var indexes []*existingIndex
+	for cursor.Next(ctx) {
+		var index existingIndex
+		if err := cursor.Decode(&index); err != nil {
+			return nil, fmt.Errorf("failed to decode index: %w", err)
+		}
+		index.spec = append(bson.Raw(nil), cursor.Current...)
+		indexes = append(indexes, &index)
+	}
+	return indexes, cursor.Err()
+}
+
+// reconcileIndex creates a missing index or replaces a changed one, recording the outcome
+func reconcileIndex(ctx context.Context, db *MongoDB, desired collectionIndexes, drift *IndexDrift) {
+	collection := db.GetCollection(desired.collection)
+	var model mongo.IndexModel
+	for _, index := range desired.indexes {
+		if *index.Options.Name == drift.Index {
+			model = index
+		}
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	var err error
+	switch {
+	case drift.Kind == IndexMissing:
+		if _, err = collection.Indexes().CreateOne(ctxTimeout, model); err == nil {
+			drift.Action = "created"
+		}
+	case onlyTTLDiffers(drift):
+		// TTL changes don't need a rebuild
+		err = db.Database.RunCommand(ctxTimeout, bson.D{
+			{Key: "collMod", Value: desired.collection},
+			{Key: "index", Value: bson.D{
+				{Key: "name", Value: drift.Index},
+				{Key: "expireAfterSeconds", Value: *model.Options.ExpireAfterSeconds},
+			}},
+		}).Err()
+		if err == nil {
+			drift.Action = "modified"
+		}
+	default:
+		err = recreateIndex(ctxTimeout, collection, model)
+		if err == nil {
+			drift.Action = "recreated"
+		}
+	}
+
+	if err != nil {
+		if hasErrorCode(err, unauthorizedCode) {
+			err = fmt.Errorf("not authorized to change indexes: %w", err)
+		}
+		drift.Error = err.Error()
+		return
+	}
+
+	slog.Info("Reconciled index",
+		"collection", drift.Collection,
+		"index", drift.Index,
+		"action", drift.Action,
+		"keys", drift.Desired,
+	)
+}
+
+// recreateIndex drops an index and creates it with its desired keys and options. If the new
+// index can't be built, for example because existing documents violate a new unique
+// constraint, the old index is restored from its full specification.
+func recreateIndex(ctx context.Context, collection *mongo.Collection, model mongo.IndexModel) error {
+	name := *model.Options.Name
+
+	specs, err := listIndexes(ctx, collection)
+	if err != nil {
+		return err
+	}
+	var previous *existingIndex
+	for _, spec := range specs {
+		if spec.Name == name {
+			previous = spec
+		}
+	}
+
+	if _, err := collection.Indexes().DropOne(ctx, name); err != nil && !hasErrorCode(err, indexNotFoundCode) {
+		return err
+	}
+
+	_, createErr := collection.Indexes().CreateOne(ctx, model)
+	if createErr == nil || previous == nil {
+		return createErr
+	}
+
+	if err := restoreIndex(ctx, collection, previous); err != nil {
+		return fmt.Errorf("%w (restoring the previous index also failed: %v)", createErr, err)
+	}
+	return fmt.Errorf("%w (previous index restored)", createErr)
+}
+
+// restoreIndex creates an index from the specification it was listed with, keeping every
+// option such as its collation and partial filter
+func restoreIndex(ctx context.Context, collection *mongo.Collection, index *existingIndex) error {
+	elements, err := index.spec.Elements()
+	if err != nil {
+		return err
+	}
+
+	// The version and namespace are set by the server
+	var spec bson.D
+	for _, element := range elements {
+		if key := element.Key(); key != "v" && key != "ns" {
+			spec = append(spec, bson.E{Key: key, Value: element.Value()})
+		}
+	}
+
+	return collection.Database().RunCommand(ctx, bson.D{
+		{Key: "createIndexes", Value: collection.Name()},
+		{Key: "indexes", Value: bson.A{spec}},
+	}).Err()
+}
+
+// onlyTTLDiffers reports whether a changed index differs only in its TTL
+func onlyTTLDiffers(drift *IndexDrift) bool {
+	desiredKeys, desiredTTL := splitTTL(drift.Desired)
+	existingKeys, existingTTL := splitTTL(drift.Existing)
+	return desiredKeys == existingKeys && desiredTTL != "" && existingTTL != ""
+}
+
+// splitTTL separates the TTL option from an index description
+func splitTTL(description string) (string, string) {
+	rest, ttl, _ := strings.Cut(description, " ttl=")
+	return rest, ttl
+}
+
+// describeDesiredIndex renders an index model's keys and options for comparison
+func describeDesiredIndex(index mongo.IndexModel) string {
+	var keys []string
+	for _, key := range index.Keys.(bson.D) {
+		keys = append(keys, fmt.Sprintf("%s: %v", key.Key, key.Value))
+	}
+
+	opts := index.Options
+	var partial bson.Raw
+	if opts.PartialFilterExpression != nil {
+		partial, _ = bson.Marshal(opts.PartialFilterExpression)
+	}
+	return describeIndex(keys, opts.Unique != nil && *opts.Unique, opts.Sparse != nil && *opts.Sparse, opts.Collation, partial, opts.ExpireAfterSeconds)
+}
+
+// describeExistingIndex renders an existing index's keys and options for comparison
+func describeExistingIndex(spec *existingIndex) string {
+	var keys []string
+	elements, _ := spec.Keys.Elements()
+	for _, element := range elements {
+		value := element.Value()
+		if number, ok := value.AsInt64OK(); ok {
+			keys = append(keys, fmt.Sprintf("%s: %d", element.Key(), number))
+		} else if kind, ok := value.StringValueOK(); ok {
+			keys = append(keys, fmt.Sprintf("%s: %s", element.Key(), kind))
+		} else {
+			keys = append(keys, fmt.Sprintf("%s: %s", element.Key(), value))
+		}
+	}
+
+	var collation *options.Collation
+	if c := spec.Collation; c != nil {
+		collation = &options.Collation{
+			Locale:          c.Locale,
+			CaseLevel:       c.CaseLevel,
+			CaseFirst:       c.CaseFirst,
+			Strength:        c.Strength,
+			NumericOrdering: c.NumericOrdering,
+			Alternate:       c.Alternate,
+			MaxVariable:     c.MaxVariable,
+			Normalization:   c.Normalization,
+			Backwards:       c.Backwards,
+		}
+	}
+
+	return describeIndex(keys, spec.Unique, spec.Sparse, collation, spec.PartialFilterExpression, spec.ExpireAfterSeconds)
+}
+
+// describeIndex renders index keys and options, e.g. "{expires_at: 1} unique ttl=0". The TTL
+// comes last, so onlyTTLDiffers can split it off.
+func describeIndex(keys []string, unique, sparse bool, collation *options.Collation, partial bson.Raw, ttl *int32) string {
+	description := "{" + strings.Join(keys, ", ") + "}"
+	if unique {
+		description += " unique"
+	}
+	if sparse {
+		description += " sparse"
+	}
+	if c := describeCollation(collation); c != "" {
+		description += " collation=" + c
+	}
+	if len(partial) > 0 {
+		description += " partial=" + partial.String()
+	}
+	if ttl != nil {
+		description += fmt.Sprintf(" ttl=%d", *ttl)
+	}
+	return description
+}
+
+// describeCollation renders the options of a collation that differ from the server's defaults,
+// e.g. "{locale: en, strength: 2}", or "" for the simple binary collation
+func describeCollation(c *options.Collation) string {
+	if c == nil || c.Locale == "" || c.Locale == "simple" {
+		return ""
+	}
+
+	fields := []string{"locale: " + c.Locale}
+	if c.Strength != 0 && c.Strength != 3 {
+		fields = append(fields, fmt.Sprintf("strength: %d", c.Strength))
+	}
+	if c.CaseLevel {
+		fields = append(fields, "caseLevel: true")
+	}
+	if c.CaseFirst != "" && c.CaseFirst != "off" {
+		fields = append(fields, "caseFirst: "+c.CaseFirst)
+	}
+	if c.NumericOrdering {
+		fields = append(fields, "numericOrdering: true")
+	}
+	if c.Alternate != "" && c.Alternate != "non-ignorable" {
+		fields = append(fields, "alternate: "+c.Alternate)
+		if c.MaxVariable != "" && c.MaxVariable != "punct" {
+			fields = append(fields, "maxVariable: "+c.MaxVariable)
+		}
+	}
+	if c.Normalization {
+		fields = append(fields, "normalization: true")
+	}
+	if c.Backwards {
+		fields = append(fields, "backwards: true")
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// hasErrorCode reports whether err is a server error with the code
+func hasErrorCode(err error, code int) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(code)
+}
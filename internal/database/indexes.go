@@ -1,51 +1,51 @@
 package database
 
 import (
-	"context"
-	"log/slog"
-	"time"
+	"sort"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// CreateIndexes creates all necessary indexes for the collections
-func CreateIndexes(ctx context.Context, db *MongoDB) error {
-	slog.Info("Creating MongoDB indexes")
-
-	// Health Check Configs Indexes
-	if err := createHealthCheckConfigIndexes(ctx, db); err != nil {
-		return err
-	}
-
-	// Execution History Indexes
-	if err := createExecutionHistoryIndexes(ctx, db); err != nil {
-		return err
-	}
-
-	// Alert Logs Indexes
-	if err := createAlertLogsIndexes(ctx, db); err != nil {
-		return err
-	}
+// collectionIndexes are the indexes a collection should have
+type collectionIndexes struct {
+	collection string
+	indexes    []mongo.IndexModel
+}
 
-	// Schedule Locks Indexes
-	if err := createScheduleLocksIndexes(ctx, db); err != nil {
-		return err
+// desiredIndexes returns the indexes of every collection, reconciled at startup
+func desiredIndexes() []collectionIndexes {
+	return []collectionIndexes{
+		{CollectionHealthCheckConfigs, healthCheckConfigIndexes()},
+		{CollectionExecutionHistory, executionHistoryIndexes()},
+		{CollectionAlertLogs, alertLogsIndexes()},
+		{CollectionScheduleLocks, scheduleLocksIndexes()},
+		{CollectionSuites, suitesIndexes()},
+		{CollectionSuiteResults, suiteResultsIndexes()},
+		{CollectionAgentSchedules, agentSchedulesIndexes()},
+		{CollectionChannels, channelsIndexes()},
+		{CollectionAuthProfiles, authProfilesIndexes()},
+		{CollectionHeartbeats, heartbeatsIndexes()},
+		{CollectionStateTransitions, stateTransitionsIndexes()},
+		{CollectionDowntimes, downtimesIndexes()},
+		{CollectionExecutionBudgets, executionBudgetsIndexes()},
+		{CollectionAuditLog, auditLogIndexes()},
 	}
-
-	slog.Info("Successfully created all MongoDB indexes")
-	return nil
 }
 
-func createHealthCheckConfigIndexes(ctx context.Context, db *MongoDB) error {
-	collection := db.GetCollection(CollectionHealthCheckConfigs)
-
+func healthCheckConfigIndexes() []mongo.IndexModel {
 	indexes := []mongo.IndexModel{
 		{
 			Keys:    bson.D{{Key: "name", Value: 1}},
 			Options: options.Index().SetUnique(true).SetName("idx_name_unique"),
 		},
+		{
+			// Serves case-insensitive lookups by name
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetCollation(NameCollation).SetName("idx_name_ci"),
+		},
 		{
 			Keys:    bson.D{{Key: "enabled", Value: 1}},
 			Options: options.Index().SetName("idx_enabled"),
@@ -75,23 +75,24 @@ func createHealthCheckConfigIndexes(ctx context.Context, db *MongoDB) error {
 			},
 			Options: options.Index().SetName("idx_schedule_enabled_enabled"),
 		},
+		{
+			Keys:    bson.D{{Key: "regions", Value: 1}},
+			Options: options.Index().SetSparse(true).SetName("idx_regions"),
+		},
+		{
+			Keys:    bson.D{{Key: "webhook.channel_id", Value: 1}},
+			Options: options.Index().SetSparse(true).SetName("idx_webhook_channel_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "target.auth.profile_id", Value: 1}},
+			Options: options.Index().SetSparse(true).SetName("idx_target_auth_profile_id"),
+		},
 	}
 
-	ctxTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	_, err := collection.Indexes().CreateMany(ctxTimeout, indexes)
-	if err != nil {
-		return err
-	}
-
-	slog.Info("Created health_check_configs indexes")
-	return nil
+	return append(indexes, sortIndexes(HealthCheckSortFields)...)
 }
 
-func createExecutionHistoryIndexes(ctx context.Context, db *MongoDB) error {
-	collection := db.GetCollection(CollectionExecutionHistory)
-
+func executionHistoryIndexes() []mongo.IndexModel {
 	indexes := []mongo.IndexModel{
 		{
 			Keys:    bson.D{{Key: "correlation_id", Value: 1}},
@@ -115,23 +116,48 @@ func createExecutionHistoryIndexes(ctx context.Context, db *MongoDB) error {
 			},
 			Options: options.Index().SetName("idx_status_executed_at"),
 		},
+		{
+			Keys: bson.D{
+				{Key: "config_id", Value: 1},
+				{Key: "metadata.region", Value: 1},
+				{Key: "executed_at", Value: -1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_config_id_region_executed_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "metadata.trigger_type", Value: 1},
+				{Key: "executed_at", Value: -1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_trigger_type_executed_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "metadata.triggered_by", Value: 1},
+				{Key: "executed_at", Value: -1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_triggered_by_executed_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "metadata.pod_id", Value: 1},
+				{Key: "executed_at", Value: -1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_pod_id_executed_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "batch_id", Value: 1},
+				{Key: "executed_at", Value: 1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_batch_id_executed_at"),
+		},
 	}
 
-	ctxTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	_, err := collection.Indexes().CreateMany(ctxTimeout, indexes)
-	if err != nil {
-		return err
-	}
-
-	slog.Info("Created execution_history indexes")
-	return nil
+	return append(indexes, sortIndexes(ExecutionSortFields)...)
 }
 
-func createAlertLogsIndexes(ctx context.Context, db *MongoDB) error {
-	collection := db.GetCollection(CollectionAlertLogs)
-
+func alertLogsIndexes() []mongo.IndexModel {
 	indexes := []mongo.IndexModel{
 		{
 			Keys:    bson.D{{Key: "execution_id", Value: 1}},
@@ -159,24 +185,53 @@ func createAlertLogsIndexes(ctx context.Context, db *MongoDB) error {
 			},
 			Options: options.Index().SetName("idx_acknowledgment_status_created_at"),
 		},
+		{
+			Keys: bson.D{
+				{Key: "channel_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_channel_id_created_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "severity", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_severity_created_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "rule_name", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_rule_name_created_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "webhook_host", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_webhook_host_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "tags", Value: 1}},
+			Options: options.Index().SetSparse(true).SetName("idx_tags"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "source", Value: 1},
+				{Key: "dedup_key", Value: 1},
+				{Key: "acknowledgment_status", Value: 1},
+			},
+			Options: options.Index().SetSparse(true).SetName("idx_source_dedup_key_ack"),
+		},
 	}
 
-	ctxTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	_, err := collection.Indexes().CreateMany(ctxTimeout, indexes)
-	if err != nil {
-		return err
-	}
-
-	slog.Info("Created alert_logs indexes")
-	return nil
+	return append(indexes, sortIndexes(AlertSortFields)...)
 }
 
-func createScheduleLocksIndexes(ctx context.Context, db *MongoDB) error {
-	collection := db.GetCollection(CollectionScheduleLocks)
-
-	indexes := []mongo.IndexModel{
+func scheduleLocksIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
 		{
 			Keys:    bson.D{{Key: "config_id", Value: 1}},
 			Options: options.Index().SetUnique(true).SetName("idx_config_id_unique"),
@@ -190,15 +245,153 @@ func createScheduleLocksIndexes(ctx context.Context, db *MongoDB) error {
 			Options: options.Index().SetName("idx_locked_by"),
 		},
 	}
+}
+
+func suitesIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("idx_name_unique"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "schedule_enabled", Value: 1},
+				{Key: "next_scheduled_run", Value: 1},
+			},
+			Options: options.Index().SetName("idx_schedule_enabled_next_run"),
+		},
+	}
+}
 
-	ctxTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+func suiteResultsIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "suite_id", Value: 1},
+				{Key: "executed_at", Value: -1},
+			},
+			Options: options.Index().SetName("idx_suite_id_executed_at"),
+		},
+	}
+}
 
-	_, err := collection.Indexes().CreateMany(ctxTimeout, indexes)
-	if err != nil {
-		return err
+func agentSchedulesIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "config_id", Value: 1},
+				{Key: "region", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetName("idx_config_id_region_unique"),
+		},
 	}
+}
 
-	slog.Info("Created schedule_locks indexes")
-	return nil
+func channelsIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("idx_name_unique"),
+		},
+	}
+}
+
+func authProfilesIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("idx_name_unique"),
+		},
+	}
+}
+
+func heartbeatsIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("idx_name_unique"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "enabled", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "expected_by", Value: 1},
+			},
+			Options: options.Index().SetName("idx_enabled_status_expected_by"),
+		},
+	}
+}
+
+func stateTransitionsIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "config_id", Value: 1},
+				{Key: "at", Value: -1},
+			},
+			Options: options.Index().SetName("idx_config_id_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "at", Value: -1}},
+			Options: options.Index().SetName("idx_at"),
+		},
+	}
+}
+
+func downtimesIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "config_id", Value: 1},
+				{Key: "started_at", Value: -1},
+			},
+			Options: options.Index().SetName("idx_config_id_started_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "started_at", Value: 1},
+				{Key: "ended_at", Value: 1},
+			},
+			Options: options.Index().SetName("idx_started_at_ended_at"),
+		},
+	}
+}
+
+func executionBudgetsIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("idx_expires_at_ttl"),
+		},
+	}
+}
+
+func auditLogIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "config_id", Value: 1},
+				{Key: "at", Value: -1},
+			},
+			Options: options.Index().SetName("idx_config_id_at"),
+		},
+	}
+}
+
+// sortIndexes returns one index per sortable field, matching the sort documents built by ParseSort
+func sortIndexes(fields map[string]string) []mongo.IndexModel {
+	keys := make([]string, 0, len(fields))
+	for _, key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	indexes := make([]mongo.IndexModel, 0, len(keys))
+	for _, key := range keys {
+		indexes = append(indexes, mongo.IndexModel{
+			Keys:    bson.D{{Key: key, Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName("idx_sort_" + strings.ReplaceAll(key, ".", "_")),
+		})
+	}
+	return indexes
 }
@@ -62,8 +62,9 @@ func (r *LockRepository) AcquireLock(ctx context.Context, configID primitive.Obj
 	err := r.collection.FindOneAndUpdate(ctxTimeout, filter, update, opts).Decode(&result)
 
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			// Lock is already held by another pod and hasn't expired
+		if err == mongo.ErrNoDocuments || mongo.IsDuplicateKeyError(err) {
+			// Lock is already held by another pod and hasn't expired; the upsert then
+			// collides with the held lock on the unique config_id index
 			return false, nil
 		}
 		return false, fmt.Errorf("failed to acquire lock: %w", err)
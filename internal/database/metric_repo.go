@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxMetricPoints caps the points returned for a single series query
+const maxMetricPoints = 10000
+
+// MetricRepository handles extracted metric samples
+type MetricRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMetricRepository creates a new metric repository
+func NewMetricRepository(db *MongoDB) *MetricRepository {
+	return &MetricRepository{
+		collection: db.GetCollection(CollectionMetricSamples),
+	}
+}
+
+// EnsureMetricsCollection creates the metric samples time-series collection if it doesn't exist yet
+func EnsureMetricsCollection(ctx context.Context, db *MongoDB, retention time.Duration) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	opts := options.CreateCollection().SetTimeSeriesOptions(
+		options.TimeSeries().
+			SetTimeField("timestamp").
+			SetMetaField("meta").
+			SetGranularity("minutes"),
+	)
+	if retention > 0 {
+		opts.SetExpireAfterSeconds(int64(retention.Seconds()))
+	}
+
+	err := db.Database.CreateCollection(ctxTimeout, CollectionMetricSamples, opts)
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Name == "NamespaceExists") {
+		return fmt.Errorf("failed to create metric samples collection: %w", err)
+	}
+
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "meta.config_id", Value: 1},
+			{Key: "meta.name", Value: 1},
+			{Key: "timestamp", Value: -1},
+		},
+		Options: options.Index().SetName("idx_config_id_name_timestamp"),
+	}
+	if _, err := db.GetCollection(CollectionMetricSamples).Indexes().CreateOne(ctxTimeout, index); err != nil {
+		return fmt.Errorf("failed to create metric samples index: %w", err)
+	}
+
+	slog.Info("Created metric_samples time-series collection")
+	return nil
+}
+
+// Insert stores metric samples
+func (r *MetricRepository) Insert(ctx context.Context, samples []model.MetricSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	docs := make([]interface{}, len(samples))
+	for i := range samples {
+		docs[i] = samples[i]
+	}
+
+	if _, err := r.collection.InsertMany(ctxTimeout, docs); err != nil {
+		return fmt.Errorf("failed to insert metric samples: %w", err)
+	}
+
+	return nil
+}
+
+// Series retrieves the samples of one metric of a health check since the given time, oldest first
+func (r *MetricRepository) Series(ctx context.Context, configID primitive.ObjectID, name string, since time.Time) ([]model.MetricPoint, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"meta.config_id": configID,
+		"meta.name":      name,
+		"timestamp":      bson.M{"$gte": since},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: 1}}).
+		SetLimit(maxMetricPoints).
+		SetProjection(bson.M{"_id": 0, "timestamp": 1, "value": 1, "region": "$meta.region"})
+
+	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metric samples: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	points := make([]model.MetricPoint, 0)
+	if err := cursor.All(ctxTimeout, &points); err != nil {
+		return nil, fmt.Errorf("failed to decode metric samples: %w", err)
+	}
+
+	return points, nil
+}
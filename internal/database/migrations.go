@@ -0,0 +1,280 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrationLockTTL is how long a migration's claim lasts unless its instance renews it; an
+// instance that dies mid-migration is taken over after this
+const migrationLockTTL = 10 * time.Minute
+
+// migrationLockRenewInterval is how often a running migration renews its claim
+const migrationLockRenewInterval = migrationLockTTL / 3
+
+// errMigrationClaimLost is returned when another instance took over a running migration
+var errMigrationClaimLost = errors.New("migration was claimed by another instance")
+
+// Migration states recorded in the schema_version collection
+const (
+	migrationRunning = "running"
+	migrationApplied = "applied"
+)
+
+// Migration is a versioned change to existing documents, such as backfilling a new field.
+// Migrations run once, in version order, before the server starts. They must be idempotent:
+// an instance that dies mid-migration leaves it to be run again.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *MongoDB) error
+}
+
+// migrations lists every migration in version order. Append new ones with the next version;
+// never renumber or change a migration once released.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Backfill acknowledgment_status on alert logs created before it was set",
+		Up: func(ctx context.Context, db *MongoDB) error {
+			_, err := db.GetCollection(CollectionAlertLogs).UpdateMany(ctx,
+				bson.M{"$or": []bson.M{
+					{"acknowledgment_status": bson.M{"$exists": false}},
+					{"acknowledgment_status": ""},
+				}},
+				bson.M{"$set": bson.M{"acknowledgment_status": "open"}},
+			)
+			return err
+		},
+	},
+}
+
+// schemaVersion is a migration's record in the schema_version collection
+type schemaVersion struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	Status      string    `bson:"status"` // "running" or "applied"
+	Owner       string    `bson:"owner"`  // Instance running or having applied the migration
+	ExpiresAt   time.Time `bson:"expires_at,omitempty"`
+	AppliedAt   time.Time `bson:"applied_at,omitempty"`
+	DurationMs  int64     `bson:"duration_ms,omitempty"`
+}
+
+// RunMigrations applies the migrations not yet recorded in the schema_version collection.
+// Each migration is claimed by one instance; the others wait for it to be applied.
+func RunMigrations(ctx context.Context, db *MongoDB, podID string) error {
+	collection := db.GetCollection(CollectionSchemaVersion)
+
+	latest, err := latestSchemaVersion(ctx, collection)
+	if err != nil {
+		return err
+	}
+	if known := migrations[len(migrations)-1].Version; latest > known {
+		// A newer release migrated the database; older code keeps working on its fields
+		slog.Warn("Database schema is newer than this release",
+			"schema_version", latest,
+			"latest_known_version", known,
+		)
+	}
+
+	for _, migration := range migrations {
+		if err := runMigration(ctx, db, collection, migration, podID); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+	}
+	return nil
+}
+
+// runMigration applies a migration unless it is already applied, waiting while another
+// instance holds it
+func runMigration(ctx context.Context, db *MongoDB, collection *mongo.Collection, migration Migration, podID string) error {
+	for {
+		var record schemaVersion
+		err := collection.FindOne(ctx, bson.M{"_id": migration.Version}).Decode(&record)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		if record.Status == migrationApplied {
+			return nil
+		}
+
+		claimed, err := claimMigration(ctx, collection, migration, podID)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			break
+		}
+
+		slog.Info("Waiting for migration run by another instance",
+			"version", migration.Version,
+			"owner", record.Owner,
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+
+	slog.Info("Applying migration",
+		"version", migration.Version,
+		"description", migration.Description,
+	)
+	start := time.Now()
+
+	// Keep the claim while the migration runs, and stop it once the claim can't be renewed
+	runCtx, release := holdMigration(ctx, collection, migration.Version, podID)
+	err := migration.Up(runCtx, db)
+	if claimErr := release(); claimErr != nil {
+		// Another instance may be running the migration now, so leave its record alone
+		return fmt.Errorf("stopped migration: %w", claimErr)
+	}
+	if err != nil {
+		// Release the claim so the next start retries the migration
+		if _, releaseErr := collection.DeleteOne(context.WithoutCancel(ctx), bson.M{"_id": migration.Version, "owner": podID}); releaseErr != nil {
+			slog.Error("Failed to release migration", "version", migration.Version, "error", releaseErr)
+		}
+		return err
+	}
+
+	duration := time.Since(start)
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": migration.Version, "owner": podID},
+		bson.M{
+			"$set": bson.M{
+				"status":      migrationApplied,
+				"applied_at":  time.Now().UTC(),
+				"duration_ms": duration.Milliseconds(),
+			},
+			"$unset": bson.M{"expires_at": ""},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("failed to record migration: %w", errMigrationClaimLost)
+	}
+
+	slog.Info("Applied migration",
+		"version", migration.Version,
+		"duration", duration,
+	)
+	return nil
+}
+
+// claimMigration marks a migration as running by this instance unless another instance holds
+// an unexpired claim or it is applied. Returns true if the migration was claimed.
+func claimMigration(ctx context.Context, collection *mongo.Collection, migration Migration, podID string) (bool, error) {
+	now := time.Now().UTC()
+
+	filter := bson.M{
+		"_id":    migration.Version,
+		"status": migrationRunning,
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$lt": now}},       // Abandoned by an instance that died
+			{"expires_at": bson.M{"$exists": false}}, // Never claimed
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"description": migration.Description,
+			"status":      migrationRunning,
+			"owner":       podID,
+			"expires_at":  now.Add(migrationLockTTL),
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		// The upsert collides with a record that is applied or claimed by another instance
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// holdMigration renews this instance's claim on a running migration until release is called.
+// When the claim can't be renewed, the returned context is cancelled so the migration stops
+// before another instance takes it over, and release returns why.
+func holdMigration(ctx context.Context, collection *mongo.Collection, version int, podID string) (context.Context, func() error) {
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	var renewErr error
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(migrationLockRenewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				if err := renewMigration(runCtx, collection, version, podID); err != nil {
+					renewErr = err
+					slog.Error("Failed to renew migration claim, stopping the migration",
+						"version", version,
+						"error", err,
+					)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return runCtx, func() error {
+		close(done)
+		<-stopped
+		cancel()
+		return renewErr
+	}
+}
+
+// renewMigration extends this instance's claim on a running migration
+func renewMigration(ctx context.Context, collection *mongo.Collection, version int, podID string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctxTimeout,
+		bson.M{"_id": version, "owner": podID, "status": migrationRunning},
+		bson.M{"$set": bson.M{"expires_at": time.Now().UTC().Add(migrationLockTTL)}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errMigrationClaimLost
+	}
+	return nil
+}
+
+// latestSchemaVersion returns the highest applied migration version, or 0
+func latestSchemaVersion(ctx context.Context, collection *mongo.Collection) (int, error) {
+	var record schemaVersion
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	err := collection.FindOne(ctx, bson.M{"status": migrationApplied}, opts).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return record.Version, nil
+}
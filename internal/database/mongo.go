@@ -8,34 +8,58 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 // MongoDB represents a MongoDB connection
 type MongoDB struct {
 	Client   *mongo.Client
 	Database *mongo.Database
+
+	// historyReadPref is applied to analytic-style history queries
+	historyReadPref *readpref.ReadPref
+}
+
+// ConnectOptions holds MongoDB connection pool and timeout settings
+type ConnectOptions struct {
+	Timeout                time.Duration // Overall timeout for connect and ping
+	MaxPoolSize            uint64
+	MinPoolSize            uint64
+	MaxConnIdleTime        time.Duration
+	ConnectTimeout         time.Duration
+	SocketTimeout          time.Duration
+	ServerSelectionTimeout time.Duration
+	Compressors            []string // e.g. "zstd", "snappy", "zlib"
 }
 
 // Connect establishes a connection to MongoDB with proper configuration
-func Connect(ctx context.Context, uri, database string, timeout time.Duration) (*MongoDB, error) {
-	slog.Info("Connecting to MongoDB", "database", database)
+func Connect(ctx context.Context, uri, database string, connectOpts ConnectOptions) (*MongoDB, error) {
+	slog.Info("Connecting to MongoDB",
+		"database", database,
+		"max_pool_size", connectOpts.MaxPoolSize,
+		"min_pool_size", connectOpts.MinPoolSize,
+		"compressors", connectOpts.Compressors,
+	)
 
 	// Create context with timeout
-	connectCtx, cancel := context.WithTimeout(ctx, timeout)
+	connectCtx, cancel := context.WithTimeout(ctx, connectOpts.Timeout)
 	defer cancel()
 
 	// Configure client options with connection pooling
 	clientOptions := options.Client().
 		ApplyURI(uri).
-		SetMaxPoolSize(100).
-		SetMinPoolSize(10).
-		SetMaxConnIdleTime(30 * time.Second).
-		SetConnectTimeout(10 * time.Second).
-		SetSocketTimeout(30 * time.Second).
-		SetServerSelectionTimeout(10 * time.Second).
+		SetMaxPoolSize(connectOpts.MaxPoolSize).
+		SetMinPoolSize(connectOpts.MinPoolSize).
+		SetMaxConnIdleTime(connectOpts.MaxConnIdleTime).
+		SetConnectTimeout(connectOpts.ConnectTimeout).
+		SetSocketTimeout(connectOpts.SocketTimeout).
+		SetServerSelectionTimeout(connectOpts.ServerSelectionTimeout).
 		SetRetryWrites(true).
-		SetRetryReads(true).
-		SetCompressors([]string{"snappy"})
+		SetRetryReads(true)
+
+	if len(connectOpts.Compressors) > 0 {
+		clientOptions.SetCompressors(connectOpts.Compressors)
+	}
 
 	// Connect to MongoDB
 	client, err := mongo.Connect(connectCtx, clientOptions)
@@ -78,10 +102,53 @@ func (m *MongoDB) GetCollection(name string) *mongo.Collection {
 	return m.Database.Collection(name)
 }
 
+// SetHistoryReadPreference sets the read preference used for history list queries
+// (e.g. "secondaryPreferred"), keeping them off the primary used by scheduler writes
+func (m *MongoDB) SetHistoryReadPreference(mode string) error {
+	if mode == "" {
+		m.historyReadPref = nil
+		return nil
+	}
+
+	readMode, err := readpref.ModeFromString(mode)
+	if err != nil {
+		return fmt.Errorf("invalid read preference %q: %w", mode, err)
+	}
+
+	rp, err := readpref.New(readMode)
+	if err != nil {
+		return fmt.Errorf("invalid read preference %q: %w", mode, err)
+	}
+
+	m.historyReadPref = rp
+	slog.Info("Configured history read preference", "mode", mode)
+	return nil
+}
+
+// GetHistoryCollection returns a collection by name using the history read preference
+func (m *MongoDB) GetHistoryCollection(name string) *mongo.Collection {
+	if m.historyReadPref == nil {
+		return m.GetCollection(name)
+	}
+	return m.Database.Collection(name, options.Collection().SetReadPreference(m.historyReadPref))
+}
+
 // Collection names
 const (
 	CollectionHealthCheckConfigs = "health_check_configs"
 	CollectionExecutionHistory   = "execution_history"
 	CollectionAlertLogs          = "alert_logs"
 	CollectionScheduleLocks      = "schedule_locks"
+	CollectionSuites             = "suites"
+	CollectionSuiteResults       = "suite_results"
+	CollectionAgentSchedules     = "agent_schedules"
+	CollectionChannels           = "notification_channels"
+	CollectionAuthProfiles       = "auth_profiles"
+	CollectionMetricSamples      = "metric_samples"
+	CollectionHeartbeats         = "heartbeats"
+	CollectionStateTransitions   = "state_transitions"
+	CollectionDowntimes          = "downtimes"
+	CollectionExecutionBudgets   = "execution_budgets"
+	CollectionAuditLog           = "audit_log"
+	CollectionSchemaVersion      = "schema_version"
 )
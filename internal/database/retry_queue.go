@@ -0,0 +1,279 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Backoff between retries of a failed write
+const (
+	retryInitialBackoff = time.Second
+	retryPollInterval   = time.Second
+)
+
+// Kinds of documents buffered by the retry queue
+const (
+	RetryKindExecution      = "execution_history"
+	RetryKindAlertLog       = "alert_log"
+	RetryKindAlertLogUpdate = "alert_log_update" // Change to an alert log whose insert is pending
+)
+
+// RetryQueue keeps documents whose write failed in memory and retries them with exponential
+// backoff, so a database outage doesn't silently lose executions and alert logs. When the
+// queue is full the oldest write is dropped and counted.
+type RetryQueue struct {
+	capacity   int
+	maxBackoff time.Duration
+
+	mu        sync.Mutex
+	pending   []*pendingWrite
+	recovered int64
+	dropped   int64
+	lastError string
+	lastDrop  time.Time
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// pendingWrite is a buffered write waiting for its next attempt
+type pendingWrite struct {
+	kind        string
+	key         string
+	write       func(ctx context.Context) error
+	version     int // Bumped when a newer write replaces this one
+	firstFailed time.Time
+	attempts    int
+	nextAttempt time.Time
+}
+
+// RetryQueueStats summarizes the retry queue for the health endpoint
+type RetryQueueStats struct {
+	Pending          int            `json:"pending"`
+	PendingByKind    map[string]int `json:"pending_by_kind,omitempty"`
+	OldestPendingSec int64          `json:"oldest_pending_seconds,omitempty"`
+	Recovered        int64          `json:"recovered"`
+	Dropped          int64          `json:"dropped"`
+	LastDroppedAt    *time.Time     `json:"last_dropped_at,omitempty"`
+	LastError        string         `json:"last_error,omitempty"`
+}
+
+// NewRetryQueue creates a retry queue holding at most capacity writes
+func NewRetryQueue(capacity int, maxBackoff time.Duration) *RetryQueue {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	if maxBackoff < retryInitialBackoff {
+		maxBackoff = 5 * time.Minute
+	}
+
+	return &RetryQueue{
+		capacity:   capacity,
+		maxBackoff: maxBackoff,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start begins the background retry loop
+func (q *RetryQueue) Start() {
+	slog.Info("Starting persistence retry queue",
+		"capacity", q.capacity,
+		"max_backoff", q.maxBackoff,
+	)
+
+	q.wg.Add(1)
+	go q.run()
+}
+
+// Stop stops the retry loop and makes a last attempt at every pending write
+func (q *RetryQueue) Stop(ctx context.Context) {
+	close(q.stopChan)
+	q.wg.Wait()
+
+	q.retry(ctx, true)
+
+	if stats := q.Stats(); stats.Pending > 0 {
+		slog.Error("Persistence retry queue stopped with unsaved documents",
+			"pending", stats.Pending,
+			"pending_by_kind", stats.PendingByKind,
+		)
+		return
+	}
+	slog.Info("Persistence retry queue stopped")
+}
+
+// Add buffers a failed write. A pending write with the same kind and key is replaced, so
+// the latest version of a document is the one saved.
+func (q *RetryQueue) Add(kind, key string, write func(ctx context.Context) error, cause error) {
+	now := time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.lastError = cause.Error()
+	for _, pending := range q.pending {
+		if pending.kind == kind && pending.key == key {
+			pending.write = write
+			pending.version++
+			return
+		}
+	}
+
+	if len(q.pending) >= q.capacity {
+		oldest := q.pending[0]
+		q.pending = q.pending[1:]
+		q.dropped++
+		q.lastDrop = now
+		slog.Error("Persistence retry queue is full, dropping oldest document",
+			"kind", oldest.kind,
+			"key", oldest.key,
+			"attempts", oldest.attempts,
+		)
+	}
+
+	q.pending = append(q.pending, &pendingWrite{
+		kind:        kind,
+		key:         key,
+		write:       write,
+		firstFailed: now,
+		attempts:    1,
+		nextAttempt: now.Add(retryInitialBackoff),
+	})
+	slog.Warn("Buffered failed write for retry",
+		"kind", kind,
+		"key", key,
+		"error", cause.Error(),
+	)
+}
+
+// Pending reports whether a write of the document is waiting to be retried
+func (q *RetryQueue) Pending(kind, key string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, pending := range q.pending {
+		if pending.kind == kind && pending.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats returns the pending, recovered and dropped writes
+func (q *RetryQueue) Stats() RetryQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := RetryQueueStats{
+		Pending:   len(q.pending),
+		Recovered: q.recovered,
+		Dropped:   q.dropped,
+		LastError: q.lastError,
+	}
+	if len(q.pending) > 0 {
+		stats.PendingByKind = make(map[string]int)
+		for _, pending := range q.pending {
+			stats.PendingByKind[pending.kind]++
+		}
+		stats.OldestPendingSec = int64(time.Since(q.pending[0].firstFailed).Seconds())
+	}
+	if !q.lastDrop.IsZero() {
+		lastDrop := q.lastDrop.UTC()
+		stats.LastDroppedAt = &lastDrop
+	}
+	return stats
+}
+
+// Healthy reports whether no write is pending and none has been dropped
+func (s RetryQueueStats) Healthy() bool {
+	return s.Pending == 0 && s.Dropped == 0
+}
+
+// run is the background retry loop
+func (q *RetryQueue) run() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			q.retry(context.Background(), false)
+		case <-q.stopChan:
+			return
+		}
+	}
+}
+
+// retry attempts the writes that are due, or every write when all is set, in the order
+// they failed
+func (q *RetryQueue) retry(ctx context.Context, all bool) {
+	now := time.Now()
+
+	q.mu.Lock()
+	var due []*pendingWrite
+	for _, pending := range q.pending {
+		if all || !now.Before(pending.nextAttempt) {
+			due = append(due, pending)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, pending := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		q.mu.Lock()
+		write, version := pending.write, pending.version
+		q.mu.Unlock()
+
+		err := write(ctx)
+
+		q.mu.Lock()
+		if err != nil {
+			pending.attempts++
+			backoff := min(retryInitialBackoff<<min(pending.attempts-1, 16), q.maxBackoff)
+			pending.nextAttempt = time.Now().Add(backoff)
+			q.lastError = err.Error()
+			q.mu.Unlock()
+
+			slog.Warn("Retry of failed write failed",
+				"kind", pending.kind,
+				"key", pending.key,
+				"attempts", pending.attempts,
+				"next_attempt_in", backoff,
+				"error", err.Error(),
+			)
+			continue
+		}
+		if pending.version != version {
+			// Replaced during the attempt; the newer write is retried right away
+			pending.nextAttempt = time.Time{}
+			q.mu.Unlock()
+			continue
+		}
+		q.remove(pending)
+		q.recovered++
+		q.mu.Unlock()
+
+		slog.Info("Saved document after retry",
+			"kind", pending.kind,
+			"key", pending.key,
+			"attempts", pending.attempts+1,
+		)
+	}
+}
+
+// remove drops a pending write; the caller holds the lock
+func (q *RetryQueue) remove(target *pendingWrite) {
+	for i, pending := range q.pending {
+		if pending == target {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return
+		}
+	}
+}
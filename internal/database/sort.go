@@ -0,0 +1,73 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Sortable fields per collection, keyed by API name
+var (
+	HealthCheckSortFields = map[string]string{
+		"name":       "name",
+		"created_at": "metadata.created_at",
+		"updated_at": "metadata.updated_at",
+	}
+	ExecutionSortFields = map[string]string{
+		"executed_at": "executed_at",
+		"duration":    "duration_ms",
+		"status":      "status",
+	}
+	AlertSortFields = map[string]string{
+		"created_at": "created_at",
+		"status":     "final_status",
+		"severity":   "severity",
+	}
+)
+
+// ParseSort converts a sort parameter ("field", "-field", "field:asc" or "field:desc")
+// into a sort document, allowing only the given fields. An empty value yields nil.
+func ParseSort(value string, allowed map[string]string) (bson.D, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	direction := 1
+	field := value
+	if strings.HasPrefix(field, "-") {
+		direction = -1
+		field = field[1:]
+	} else if name, order, ok := strings.Cut(field, ":"); ok {
+		field = name
+		switch strings.ToLower(order) {
+		case "asc":
+		case "desc":
+			direction = -1
+		default:
+			return nil, fmt.Errorf("invalid sort direction: %s (must be 'asc' or 'desc')", order)
+		}
+	}
+
+	key, ok := allowed[field]
+	if !ok {
+		names := make([]string, 0, len(allowed))
+		for name := range allowed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("invalid sort field: %s (must be one of %s)", field, strings.Join(names, ", "))
+	}
+
+	// Break ties on _id so pages are stable
+	return bson.D{{Key: key, Value: direction}, {Key: "_id", Value: direction}}, nil
+}
+
+// sortOrDefault returns the requested sort, or fallback when none was requested
+func sortOrDefault(requested bson.D, fallback bson.D) bson.D {
+	if len(requested) == 0 {
+		return fallback
+	}
+	return requested
+}
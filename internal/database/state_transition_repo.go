@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StateTransitionRepository handles health check state transition events
+type StateTransitionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewStateTransitionRepository creates a new state transition repository
+func NewStateTransitionRepository(db *MongoDB) *StateTransitionRepository {
+	return &StateTransitionRepository{
+		collection: db.GetCollection(CollectionStateTransitions),
+	}
+}
+
+// Create inserts a state transition
+func (r *StateTransitionRepository) Create(ctx context.Context, transition *model.StateTransition) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if transition.ID.IsZero() {
+		transition.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctxTimeout, transition); err != nil {
+		return fmt.Errorf("failed to create state transition: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves state transitions, newest first, with pagination
+func (r *StateTransitionRepository) List(ctx context.Context, filter bson.M, page, limit int) ([]model.StateTransition, int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctxTimeout, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count state transitions: %w", err)
+	}
+
+	// Calculate pagination
+	skip := (page - 1) * limit
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list state transitions: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	transitions := make([]model.StateTransition, 0)
+	if err := cursor.All(ctxTimeout, &transitions); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode state transitions: %w", err)
+	}
+
+	return transitions, total, nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Time bucket formats supported by count aggregations
+var timeBucketFormats = map[string]string{
+	"minute": "%Y-%m-%dT%H:%M:00Z",
+	"hour":   "%Y-%m-%dT%H:00:00Z",
+	"day":    "%Y-%m-%d",
+}
+
+// IsValidTimeBucket reports whether a time bucket name is supported
+func IsValidTimeBucket(bucket string) bool {
+	_, ok := timeBucketFormats[bucket]
+	return ok
+}
+
+// facetCount represents the decoded output of a $count facet
+type facetCount struct {
+	Count int64 `bson:"count"`
+}
+
+// facetTotal returns the total from a $count facet, which is empty when nothing matched
+func facetTotal(counts []facetCount) int64 {
+	if len(counts) == 0 {
+		return 0
+	}
+	return counts[0].Count
+}
+
+// groupCountStage builds a facet pipeline that counts documents grouped by a field expression
+func groupCountStage(field interface{}, limit int) bson.A {
+	pipeline := bson.A{
+		bson.M{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": limit})
+	}
+	return pipeline
+}
+
+// timeBucketStage builds a facet pipeline that counts documents per time bucket
+func timeBucketStage(timeField, bucket string) bson.A {
+	return bson.A{
+		bson.M{"$group": bson.M{
+			"_id": bson.M{"$dateToString": bson.M{
+				"format": timeBucketFormats[bucket],
+				"date":   "$" + timeField,
+			}},
+			"count": bson.M{"$sum": 1},
+		}},
+		bson.M{"$sort": bson.M{"_id": 1}},
+	}
+}
+
+// configIDString converts the config_id ObjectID to a string group key
+var configIDString = bson.M{"$toString": "$config_id"}
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SuiteRepository handles suite and suite result operations
+type SuiteRepository struct {
+	collection        *mongo.Collection
+	resultsCollection *mongo.Collection
+}
+
+// NewSuiteRepository creates a new suite repository
+func NewSuiteRepository(db *MongoDB) *SuiteRepository {
+	return &SuiteRepository{
+		collection:        db.GetCollection(CollectionSuites),
+		resultsCollection: db.GetCollection(CollectionSuiteResults),
+	}
+}
+
+// Create inserts a new suite
+func (r *SuiteRepository) Create(ctx context.Context, suite *model.Suite) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Ensure ID is generated if not set
+	if suite.ID.IsZero() {
+		suite.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctxTimeout, suite)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("suite with name '%s' already exists", suite.Name)
+		}
+		return fmt.Errorf("failed to create suite: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a suite by ID
+func (r *SuiteRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*model.Suite, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var suite model.Suite
+	err := r.collection.FindOne(ctxTimeout, bson.M{"_id": id}).Decode(&suite)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("suite not found")
+		}
+		return nil, fmt.Errorf("failed to get suite: %w", err)
+	}
+
+	return &suite, nil
+}
+
+// List retrieves suites with pagination
+func (r *SuiteRepository) List(ctx context.Context, filter bson.M, page, limit int) ([]model.Suite, int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctxTimeout, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count suites: %w", err)
+	}
+
+	// Calculate pagination
+	skip := (page - 1) * limit
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "metadata.created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list suites: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	suites := make([]model.Suite, 0)
+	if err := cursor.All(ctxTimeout, &suites); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode suites: %w", err)
+	}
+
+	return suites, total, nil
+}
+
+// Update replaces an existing suite
+func (r *SuiteRepository) Update(ctx context.Context, id primitive.ObjectID, suite *model.Suite) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	suite.ID = id
+	result, err := r.collection.ReplaceOne(ctxTimeout, bson.M{"_id": id}, suite)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("suite with name '%s' already exists", suite.Name)
+		}
+		return fmt.Errorf("failed to update suite: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("suite not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a suite
+func (r *SuiteRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctxTimeout, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete suite: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("suite not found")
+	}
+
+	return nil
+}
+
+// FindScheduledSuites retrieves suites that are due for scheduled execution
+func (r *SuiteRepository) FindScheduledSuites(ctx context.Context, now time.Time) ([]model.Suite, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"enabled":          true,
+		"schedule_enabled": true,
+		"next_scheduled_run": bson.M{
+			"$lte": now,
+		},
+	}
+
+	cursor, err := r.collection.Find(ctxTimeout, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find scheduled suites: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	var suites []model.Suite
+	if err := cursor.All(ctxTimeout, &suites); err != nil {
+		return nil, fmt.Errorf("failed to decode scheduled suites: %w", err)
+	}
+
+	return suites, nil
+}
+
+// UpdateScheduledRun updates the last and next scheduled run timestamps for a suite
+func (r *SuiteRepository) UpdateScheduledRun(ctx context.Context, id primitive.ObjectID, lastRun, nextRun time.Time) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"last_scheduled_run": lastRun,
+			"next_scheduled_run": nextRun,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctxTimeout, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update suite scheduled run: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("suite not found")
+	}
+
+	return nil
+}
+
+// CreateResult inserts a suite run result
+func (r *SuiteRepository) CreateResult(ctx context.Context, result *model.SuiteResult) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if result.ID.IsZero() {
+		result.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.resultsCollection.InsertOne(ctxTimeout, result); err != nil {
+		return fmt.Errorf("failed to create suite result: %w", err)
+	}
+
+	return nil
+}
+
+// ListResults retrieves results for a suite, most recent first
+func (r *SuiteRepository) ListResults(ctx context.Context, suiteID primitive.ObjectID, page, limit int) ([]model.SuiteResult, int64, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"suite_id": suiteID}
+
+	total, err := r.resultsCollection.CountDocuments(ctxTimeout, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count suite results: %w", err)
+	}
+
+	skip := (page - 1) * limit
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "executed_at", Value: -1}})
+
+	cursor, err := r.resultsCollection.Find(ctxTimeout, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list suite results: %w", err)
+	}
+	defer cursor.Close(ctxTimeout)
+
+	results := make([]model.SuiteResult, 0)
+	if err := cursor.All(ctxTimeout, &results); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode suite results: %w", err)
+	}
+
+	return results, total, nil
+}
@@ -0,0 +1,55 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Providers
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Commit states reported back to the provider
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+)
+
+// ErrIgnored is returned for valid webhook deliveries that are not successful deployments
+var ErrIgnored = errors.New("event ignored")
+
+// ErrUnauthorized is returned when a webhook delivery fails signature or token verification
+var ErrUnauthorized = errors.New("invalid webhook signature")
+
+// Event is a successful deployment reported by a source control provider
+type Event struct {
+	Provider    string `json:"provider"`
+	Repository  string `json:"repository"` // owner/name on GitHub, path_with_namespace on GitLab
+	ProjectID   int64  `json:"project_id,omitempty"`
+	Service     string `json:"service"` // Repository name; checks tagged for it are executed
+	SHA         string `json:"sha"`
+	Environment string `json:"environment,omitempty"`
+}
+
+// Reporter posts verification results back to the provider as commit statuses
+type Reporter interface {
+	Report(ctx context.Context, event *Event, state, description string) error
+}
+
+// doRequest sends a status request and treats non-2xx responses as errors
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.New("status request failed: " + resp.Status)
+	}
+	return nil
+}
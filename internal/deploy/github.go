@@ -0,0 +1,102 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// githubDeploymentStatus is the subset of the deployment_status event Raven reads
+type githubDeploymentStatus struct {
+	DeploymentStatus struct {
+		State string `json:"state"`
+	} `json:"deployment_status"`
+	Deployment struct {
+		SHA         string `json:"sha"`
+		Environment string `json:"environment"`
+	} `json:"deployment"`
+	Repository struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// ParseGitHub verifies a GitHub webhook delivery and extracts a successful deployment
+func ParseGitHub(header http.Header, body []byte, secret string) (*Event, error) {
+	signature := strings.TrimPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		return nil, ErrUnauthorized
+	}
+
+	if header.Get("X-GitHub-Event") != "deployment_status" {
+		return nil, ErrIgnored
+	}
+
+	var payload githubDeploymentStatus
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid GitHub payload: %w", err)
+	}
+	if payload.DeploymentStatus.State != "success" {
+		return nil, ErrIgnored
+	}
+	if payload.Deployment.SHA == "" || payload.Repository.FullName == "" {
+		return nil, fmt.Errorf("invalid GitHub payload: missing deployment sha or repository")
+	}
+
+	return &Event{
+		Provider:    ProviderGitHub,
+		Repository:  payload.Repository.FullName,
+		Service:     payload.Repository.Name,
+		SHA:         payload.Deployment.SHA,
+		Environment: payload.Deployment.Environment,
+	}, nil
+}
+
+// GitHubReporter posts commit statuses through the GitHub REST API
+type GitHubReporter struct {
+	apiURL  string
+	token   string
+	context string
+	client  *http.Client
+}
+
+// NewGitHubReporter creates a GitHub commit status reporter
+func NewGitHubReporter(apiURL, token, statusContext string, client *http.Client) *GitHubReporter {
+	return &GitHubReporter{
+		apiURL:  strings.TrimSuffix(apiURL, "/"),
+		token:   token,
+		context: statusContext,
+		client:  client,
+	}
+}
+
+// Report creates a commit status for the deployed SHA
+func (g *GitHubReporter) Report(ctx context.Context, event *Event, state, description string) error {
+	body, err := json.Marshal(map[string]string{
+		"state":       state, // pending, success and failure match GitHub's states
+		"context":     g.context,
+		"description": description,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", g.apiURL, event.Repository, event.SHA)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	return doRequest(g.client, req)
+}
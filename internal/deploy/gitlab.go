@@ -0,0 +1,98 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// gitlabDeployment is the subset of the deployment hook Raven reads
+type gitlabDeployment struct {
+	ObjectKind  string `json:"object_kind"`
+	Status      string `json:"status"`
+	Environment string `json:"environment"`
+	CommitURL   string `json:"commit_url"`
+	Project     struct {
+		ID                int64  `json:"id"`
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+}
+
+// ParseGitLab verifies a GitLab webhook delivery and extracts a successful deployment
+func ParseGitLab(header http.Header, body []byte, secret string) (*Event, error) {
+	if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+		return nil, ErrUnauthorized
+	}
+
+	var payload gitlabDeployment
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid GitLab payload: %w", err)
+	}
+	if payload.ObjectKind != "deployment" || payload.Status != "success" {
+		return nil, ErrIgnored
+	}
+
+	// The hook only carries the full SHA as the last segment of the commit URL
+	sha := path.Base(payload.CommitURL)
+	if payload.CommitURL == "" || payload.Project.ID == 0 {
+		return nil, fmt.Errorf("invalid GitLab payload: missing commit or project")
+	}
+
+	return &Event{
+		Provider:    ProviderGitLab,
+		Repository:  payload.Project.PathWithNamespace,
+		ProjectID:   payload.Project.ID,
+		Service:     path.Base(payload.Project.PathWithNamespace),
+		SHA:         sha,
+		Environment: payload.Environment,
+	}, nil
+}
+
+// GitLabReporter posts commit statuses through the GitLab REST API
+type GitLabReporter struct {
+	baseURL string
+	token   string
+	name    string
+	client  *http.Client
+}
+
+// NewGitLabReporter creates a GitLab commit status reporter
+func NewGitLabReporter(baseURL, token, statusName string, client *http.Client) *GitLabReporter {
+	return &GitLabReporter{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		name:    statusName,
+		client:  client,
+	}
+}
+
+// Report creates a commit status for the deployed SHA
+func (g *GitLabReporter) Report(ctx context.Context, event *Event, state, description string) error {
+	if state == StateFailure {
+		state = "failed" // GitLab's name for a failed status
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"state":       state,
+		"name":        g.name,
+		"description": description,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/v4/projects/%d/statuses/%s", g.baseURL, event.ProjectID, event.SHA)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	return doRequest(g.client, req)
+}
@@ -0,0 +1,733 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// scriptMaxAllocBytes bounds the memory a script run may allocate through operators, methods
+// and built-ins that build strings, ints and collections
+const scriptMaxAllocBytes = 64 << 20
+
+// allocBudgetKey is the thread-local key of a script run's allocation budget
+const allocBudgetKey = "raven.alloc_budget"
+
+// Sizes counted for values whose exact footprint isn't worth computing
+const (
+	valueSize  = 16            // One element of a list, tuple, dict or set
+	itemSize   = 2 * valueSize // One element added to a collection, with the room growing it leaves
+	stringSize = 32            // One string of a split result
+	jsonGrowth = 8             // Decoded values per byte of JSON text
+)
+
+var errScriptMemory = fmt.Errorf("script exceeded its memory limit of %d MiB", scriptMaxAllocBytes>>20)
+
+// allocBudget counts the bytes a script run may still allocate
+type allocBudget struct {
+	remaining int64
+}
+
+// budgetOf returns the thread's allocation budget, or nil when it has none
+func budgetOf(thread *starlark.Thread) *allocBudget {
+	budget, _ := thread.Local(allocBudgetKey).(*allocBudget)
+	return budget
+}
+
+// charge takes the estimated size of a result from the thread's budget before it is built
+func charge(thread *starlark.Thread, estimate func(limit int64) int64) error {
+	budget := budgetOf(thread)
+	if budget == nil {
+		return nil
+	}
+	size := estimate(budget.remaining)
+	if size > budget.remaining {
+		budget.remaining = 0
+		return errScriptMemory
+	}
+	budget.remaining -= size
+	return nil
+}
+
+// sizeFunc estimates the bytes a built-in call allocates, stopping once the estimate exceeds
+// limit. recv is the method's receiver, or nil for functions.
+type sizeFunc func(recv starlark.Value, args starlark.Tuple, kwargs []starlark.Tuple, limit int64) int64
+
+// checkedBuiltins are the built-ins scripts get in versions that count the memory they allocate
+var checkedBuiltins = []string{
+	"bytes", "dict", "enumerate", "fail", "getattr", "list", "print",
+	"repr", "reversed", "set", "sorted", "str", "tuple", "zip",
+}
+
+// builtinSizes estimate the results of checkedBuiltins
+var builtinSizes = map[string]sizeFunc{
+	"bytes": func(_ starlark.Value, args starlark.Tuple, _ []starlark.Tuple, _ int64) int64 {
+		return argSize(args, 0)
+	},
+	"dict":      dictSize,
+	"enumerate": collectionSize(3),
+	"fail":      formatArgsSize,
+	"list":      collectionSize(1),
+	"print":     formatArgsSize,
+	"repr":      formatArgsSize,
+	"reversed":  collectionSize(1),
+	"set":       collectionSize(1),
+	"sorted":    collectionSize(1),
+	"str":       strSize,
+	"tuple":     collectionSize(1),
+	"zip":       zipSize,
+}
+
+// methodSizes estimate the results of methods, by receiver type and name
+var methodSizes = map[string]sizeFunc{
+	"string.capitalize": receiverSize,
+	"string.format":     stringFormatSize,
+	"string.join":       joinSize,
+	"string.lower":      receiverSize,
+	"string.replace":    replaceSize,
+	"string.rsplit":     splitSize,
+	"string.split":      splitSize,
+	"string.splitlines": splitSize,
+	"string.title":      receiverSize,
+	"string.upper":      receiverSize,
+
+	"list.append": fixedSize(itemSize),
+	"list.extend": argsLenSize(valueSize),
+	"list.insert": fixedSize(itemSize),
+
+	"dict.items":      receiverLenSize(3),
+	"dict.keys":       receiverLenSize(1),
+	"dict.setdefault": fixedSize(itemSize),
+	"dict.update":     dictSize,
+	"dict.values":     receiverLenSize(1),
+
+	"set.add":                  fixedSize(itemSize),
+	"set.difference":           setOpSize,
+	"set.intersection":         setOpSize,
+	"set.symmetric_difference": setOpSize,
+	"set.union":                setOpSize,
+	"set.update":               argsLenSize(itemSize),
+}
+
+// moduleSizes estimate the results of module functions
+var moduleSizes = map[starlark.Value]sizeFunc{
+	json.Module.Members["encode"]: formatArgsSize,
+	json.Module.Members["decode"]: func(_ starlark.Value, args starlark.Tuple, _ []starlark.Tuple, _ int64) int64 {
+		return saturatingMul(argSize(args, 0), jsonGrowth)
+	},
+	json.Module.Members["indent"]: jsonIndentSize,
+}
+
+// checkedAttrNames are the names of the methods and module functions with an estimate.
+// compileScript routes lookups of them through attrHelper.
+var checkedAttrNames = newCheckedAttrNames()
+
+// newCheckedAttrNames builds checkedAttrNames
+func newCheckedAttrNames() map[string]bool {
+	names := make(map[string]bool)
+	for method := range methodSizes {
+		names[method[strings.IndexByte(method, '.')+1:]] = true
+	}
+	for name, member := range json.Module.Members {
+		if moduleSizes[member] != nil {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// nativeOps are Starlark functions applying the operations the helpers check, so results
+// and errors are exactly Starlark's: attr_<name>(x) is x.<name> for each checked name.
+var nativeOps = newNativeOps()
+
+// newNativeOps builds nativeOps
+func newNativeOps() starlark.StringDict {
+	var source strings.Builder
+	source.WriteString(`
+def iadd(x, y):
+    x += y
+    return x
+
+def ipipe(x, y):
+    x |= y
+    return x
+
+def slice(x, lo, hi, step):
+    return x[lo:hi:step]
+`)
+	names := make([]string, 0, len(checkedAttrNames))
+	for name := range checkedAttrNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&source, "\ndef attr_%s(x):\n    return x.%s\n", name, name)
+	}
+
+	thread := &starlark.Thread{Name: "native ops"}
+	ops, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, "ops", source.String(), nil)
+	if err != nil {
+		panic(fmt.Sprintf("evaluator: native ops: %v", err))
+	}
+	ops.Freeze()
+	return ops
+}
+
+// allocGlobals are predeclared for every script: the helpers compileScript routes
+// allocations through and the checked built-ins
+var allocGlobals = newAllocGlobals()
+
+// newAllocGlobals builds allocGlobals. Built-ins without an estimate keep their unchecked version.
+func newAllocGlobals() starlark.StringDict {
+	globals := starlark.StringDict{
+		binaryHelper: starlark.NewBuiltin(binaryHelper, scriptBinary),
+		attrHelper:   starlark.NewBuiltin(attrHelper, scriptAttr),
+		sliceHelper:  starlark.NewBuiltin(sliceHelper, scriptSlice),
+		itemHelper:   starlark.NewBuiltin(itemHelper, scriptItem),
+		spreadHelper: starlark.NewBuiltin(spreadHelper, scriptSpread),
+	}
+	for _, name := range checkedBuiltins {
+		builtin, _ := starlark.Universe[name].(*starlark.Builtin)
+		switch {
+		case name == "getattr":
+			globals[name] = starlark.NewBuiltin(name, scriptGetattr)
+		case builtinSizes[name] != nil && builtin != nil:
+			globals[name] = checkedBuiltin(builtin, builtinSizes[name])
+		default:
+			globals[name] = starlark.Universe[name]
+		}
+	}
+	return globals
+}
+
+// checkedBuiltin wraps a built-in so its result is counted against the script's budget
+func checkedBuiltin(builtin *starlark.Builtin, size sizeFunc) *starlark.Builtin {
+	return starlark.NewBuiltin(builtin.Name(), func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		err := charge(thread, func(limit int64) int64 {
+			return size(builtin.Receiver(), args, kwargs, limit)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", builtin.Name(), err)
+		}
+		return starlark.Call(thread, builtin, args, kwargs)
+	})
+}
+
+// binaryTokens maps the operators passed to binaryHelper to their tokens
+var binaryTokens = map[string]syntax.Token{
+	"+": syntax.PLUS, "+=": syntax.PLUS,
+	"*": syntax.STAR, "*=": syntax.STAR,
+	"%": syntax.PERCENT, "%=": syntax.PERCENT,
+	"<<": syntax.LTLT, "<<=": syntax.LTLT,
+	"|": syntax.PIPE, "|=": syntax.PIPE,
+	"-": syntax.MINUS, "-=": syntax.MINUS,
+	"&": syntax.AMP, "&=": syntax.AMP,
+	"^": syntax.CIRCUMFLEX, "^=": syntax.CIRCUMFLEX,
+}
+
+// scriptBinary implements binaryHelper, counting the strings, ints and collections operators
+// build. Like Starlark, += extends a list and |= updates a dict in place.
+func scriptBinary(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var op string
+	var x, y starlark.Value
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 3, &op, &x, &y); err != nil {
+		return nil, err
+	}
+
+	token, ok := binaryTokens[op]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %s", op)
+	}
+	inPlace := inPlaceUpdate(op, x)
+	err := charge(thread, func(limit int64) int64 {
+		if inPlace {
+			return growthSize(x, y)
+		}
+		return binarySize(token, x, y, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "+=":
+		return starlark.Call(thread, nativeOps["iadd"], starlark.Tuple{x, y}, nil)
+	case "|=":
+		return starlark.Call(thread, nativeOps["ipipe"], starlark.Tuple{x, y}, nil)
+	}
+	return starlark.Binary(token, x, y)
+}
+
+// inPlaceUpdate reports whether an augmented assignment may update x in place rather than
+// build a new value
+func inPlaceUpdate(op string, x starlark.Value) bool {
+	switch x.(type) {
+	case *starlark.List:
+		return op == "+="
+	case *starlark.Dict:
+		return op == "|="
+	}
+	return false
+}
+
+// growthSize counts the elements of y added to the list or dict x
+func growthSize(x, y starlark.Value) int64 {
+	size := saturatingMul(int64(max(starlark.Len(y), 0)), valueSize)
+	if _, ok := x.(*starlark.Dict); ok {
+		return saturatingMul(size, 3)
+	}
+	return size
+}
+
+// binarySize estimates the bytes an operator's result takes
+func binarySize(op syntax.Token, x, y starlark.Value, limit int64) int64 {
+	xi, xInt := x.(starlark.Int)
+	yi, yInt := y.(starlark.Int)
+	switch op {
+	case syntax.PLUS:
+		if xInt && yInt {
+			return bigIntSize(xi, yi)
+		}
+		return saturatingAdd(shallowSize(x), shallowSize(y))
+	case syntax.STAR:
+		switch {
+		case xInt && yInt:
+			return bigIntSize(xi, yi)
+		case yInt:
+			return saturatingMul(shallowSize(x), repeatCount(yi))
+		case xInt:
+			return saturatingMul(shallowSize(y), repeatCount(xi))
+		}
+	case syntax.PERCENT:
+		if format, ok := x.(starlark.String); ok {
+			return saturatingAdd(int64(len(format)), reprSize(y, limit))
+		}
+	case syntax.LTLT:
+		if xInt && yInt {
+			return saturatingAdd(intSize(xi), repeatCount(yi)/8)
+		}
+	case syntax.PIPE, syntax.MINUS, syntax.AMP, syntax.CIRCUMFLEX:
+		if xInt && yInt {
+			return bigIntSize(xi, yi)
+		}
+		size := saturatingMul(saturatingAdd(int64(max(starlark.Len(x), 0)), int64(max(starlark.Len(y), 0))), valueSize)
+		if _, ok := x.(*starlark.Dict); ok {
+			return saturatingMul(size, 3)
+		}
+		return size
+	}
+	return 0
+}
+
+// bigIntSize counts the result of arithmetic on ints once either of them is too large for
+// a machine word
+func bigIntSize(x, y starlark.Int) int64 {
+	if _, ok := x.Int64(); ok {
+		if _, ok := y.Int64(); ok {
+			return 0
+		}
+	}
+	return saturatingAdd(intSize(x), intSize(y))
+}
+
+// scriptAttr implements attrHelper, checking the methods and module functions it returns
+func scriptAttr(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x starlark.Value
+	var name string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 2, &x, &name); err != nil {
+		return nil, err
+	}
+
+	attr, ok := nativeOps["attr_"+name]
+	if !ok {
+		return nil, fmt.Errorf("%s: unchecked attribute %s", fn.Name(), name)
+	}
+	value, err := starlark.Call(thread, attr, starlark.Tuple{x}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return checkedMethod(value, name), nil
+}
+
+// scriptGetattr is getattr with the methods it returns checked like attribute lookups
+func scriptGetattr(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	value, err := starlark.Call(thread, starlark.Universe["getattr"], args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < 2 {
+		return value, nil
+	}
+	name, _ := starlark.AsString(args[1])
+	return checkedMethod(value, name), nil
+}
+
+// checkedMethod wraps a method or module function named name that builds strings or
+// collections, so it counts what it allocates. Other values are returned as they are.
+func checkedMethod(value starlark.Value, name string) starlark.Value {
+	builtin, ok := value.(*starlark.Builtin)
+	if !ok {
+		return value
+	}
+	size := moduleSizes[value]
+	if recv := builtin.Receiver(); recv != nil {
+		size = methodSizes[recv.Type()+"."+name]
+	}
+	if size == nil {
+		return value
+	}
+	return checkedBuiltin(builtin, size)
+}
+
+// scriptSlice implements sliceHelper. Slicing a list copies it, and so does slicing a string,
+// bytes or tuple with a step; the copy is counted once built, being no larger than x.
+func scriptSlice(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x starlark.Value
+	lo, hi, step := starlark.Value(starlark.None), starlark.Value(starlark.None), starlark.Value(starlark.None)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "x", &x, "lo?", &lo, "hi?", &hi, "step?", &step); err != nil {
+		return nil, err
+	}
+
+	value, err := starlark.Call(thread, nativeOps["slice"], starlark.Tuple{x, lo, hi, step}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := starlark.AsInt32(step); n == 1 || step == starlark.None {
+		if _, ok := x.(*starlark.List); !ok {
+			return value, nil
+		}
+	}
+	if err := charge(thread, func(int64) int64 { return shallowSize(value) }); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// scriptItem implements itemHelper, counting an element added to a collection
+func scriptItem(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x starlark.Value
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &x); err != nil {
+		return nil, err
+	}
+	if err := charge(thread, func(int64) int64 { return itemSize }); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// scriptSpread implements spreadHelper, counting the arguments a call copies out of x
+func scriptSpread(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x starlark.Value
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &x); err != nil {
+		return nil, err
+	}
+	err := charge(thread, func(int64) int64 {
+		return saturatingMul(int64(max(starlark.Len(x), 0)), valueSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// fixedSize counts the same size for every call
+func fixedSize(size int64) sizeFunc {
+	return func(starlark.Value, starlark.Tuple, []starlark.Tuple, int64) int64 {
+		return size
+	}
+}
+
+// argsLenSize counts size for each element of the positional arguments
+func argsLenSize(size int64) sizeFunc {
+	return func(_ starlark.Value, args starlark.Tuple, _ []starlark.Tuple, _ int64) int64 {
+		var elements int64
+		for i := range args {
+			elements = saturatingAdd(elements, argLen(args, i))
+		}
+		return saturatingMul(elements, size)
+	}
+}
+
+// collectionSize counts elements values for each element of the first argument
+func collectionSize(elements int64) sizeFunc {
+	return func(_ starlark.Value, args starlark.Tuple, _ []starlark.Tuple, _ int64) int64 {
+		return saturatingMul(argLen(args, 0), elements*valueSize)
+	}
+}
+
+// receiverSize counts the size of the receiver, for methods returning a copy of a string
+func receiverSize(recv starlark.Value, _ starlark.Tuple, _ []starlark.Tuple, _ int64) int64 {
+	return shallowSize(recv)
+}
+
+// receiverLenSize counts elements values for each element of the receiver
+func receiverLenSize(elements int64) sizeFunc {
+	return func(recv starlark.Value, _ starlark.Tuple, _ []starlark.Tuple, _ int64) int64 {
+		return saturatingMul(int64(max(starlark.Len(recv), 0)), elements*valueSize)
+	}
+}
+
+// dictSize counts the entries of dict's positional argument and keywords
+func dictSize(_ starlark.Value, args starlark.Tuple, kwargs []starlark.Tuple, _ int64) int64 {
+	return saturatingMul(saturatingAdd(argLen(args, 0), int64(len(kwargs))), 3*valueSize)
+}
+
+// zipSize counts a tuple for each element of the shortest argument
+func zipSize(_ starlark.Value, args starlark.Tuple, _ []starlark.Tuple, _ int64) int64 {
+	if len(args) == 0 {
+		return 0
+	}
+	shortest := int64(math.MaxInt64)
+	for i := range args {
+		shortest = min(shortest, argLen(args, i))
+	}
+	return saturatingMul(shortest, int64(len(args)+1)*valueSize)
+}
+
+// setOpSize counts the elements of a set and the other operands
+func setOpSize(recv starlark.Value, args starlark.Tuple, _ []starlark.Tuple, _ int64) int64 {
+	size := int64(max(starlark.Len(recv), 0))
+	for i := range args {
+		size = saturatingAdd(size, argLen(args, i))
+	}
+	return saturatingMul(size, valueSize)
+}
+
+// strSize counts the string form of str's argument; strings are returned as they are
+func strSize(recv starlark.Value, args starlark.Tuple, kwargs []starlark.Tuple, limit int64) int64 {
+	if len(args) == 1 {
+		if _, ok := args[0].(starlark.String); ok {
+			return 0
+		}
+	}
+	return formatArgsSize(recv, args, kwargs, limit)
+}
+
+// formatArgsSize counts the string forms of every argument
+func formatArgsSize(_ starlark.Value, args starlark.Tuple, kwargs []starlark.Tuple, limit int64) int64 {
+	var size int64
+	for _, arg := range args {
+		size = saturatingAdd(size, reprSize(arg, limit-size))
+		if size > limit {
+			return size
+		}
+	}
+	for _, kwarg := range kwargs {
+		size = saturatingAdd(size, reprSize(kwarg[1], limit-size))
+		if size > limit {
+			return size
+		}
+	}
+	return size
+}
+
+// stringFormatSize counts the format string and the string forms of its arguments
+func stringFormatSize(recv starlark.Value, args starlark.Tuple, kwargs []starlark.Tuple, limit int64) int64 {
+	return saturatingAdd(shallowSize(recv), formatArgsSize(recv, args, kwargs, limit))
+}
+
+// joinSize counts the joined strings and the separators between them
+func joinSize(recv starlark.Value, args starlark.Tuple, _ []starlark.Tuple, limit int64) int64 {
+	if len(args) == 0 {
+		return 0
+	}
+	iterable, ok := args[0].(starlark.Iterable)
+	if !ok {
+		return 0
+	}
+	separator := shallowSize(recv)
+
+	var size int64
+	iter := iterable.Iterate()
+	defer iter.Done()
+	var elem starlark.Value
+	for iter.Next(&elem) && size <= limit {
+		s, ok := elem.(starlark.String)
+		if !ok {
+			break // join fails on it
+		}
+		size = saturatingAdd(size, saturatingAdd(int64(len(s)), separator))
+	}
+	return size
+}
+
+// replaceSize counts the string with every occurrence replaced
+func replaceSize(recv starlark.Value, args starlark.Tuple, _ []starlark.Tuple, _ int64) int64 {
+	s, _ := recv.(starlark.String)
+	if len(args) < 2 {
+		return int64(len(s))
+	}
+	old, _ := args[0].(starlark.String)
+	replacement, _ := args[1].(starlark.String)
+	if len(replacement) <= len(old) {
+		return int64(len(s))
+	}
+	count := int64(strings.Count(string(s), string(old)))
+	return saturatingAdd(int64(len(s)), saturatingMul(count, int64(len(replacement)-len(old))))
+}
+
+// splitSize counts the strings a split produces
+func splitSize(recv starlark.Value, args starlark.Tuple, kwargs []starlark.Tuple, _ int64) int64 {
+	s, _ := recv.(starlark.String)
+	count := int64(len(s)/2 + 1) // Split on whitespace or line breaks
+	if len(args) > 0 {
+		if separator, ok := args[0].(starlark.String); ok && separator != "" {
+			count = int64(strings.Count(string(s), string(separator)) + 1)
+		}
+	}
+	return saturatingMul(count, stringSize)
+}
+
+// jsonIndentSize counts the indented text: each line is at most as deep as the text nests
+func jsonIndentSize(_ starlark.Value, args starlark.Tuple, kwargs []starlark.Tuple, _ int64) int64 {
+	if len(args) == 0 {
+		return 0
+	}
+	text, _ := args[0].(starlark.String)
+	var prefix int64
+	indent := int64(1) // A tab by default
+	for _, kwarg := range kwargs {
+		value, _ := kwarg[1].(starlark.String)
+		switch kwarg[0].(starlark.String) {
+		case "prefix":
+			prefix = int64(len(value))
+		case "indent":
+			indent = int64(len(value))
+		}
+	}
+
+	var lines, depth, maxDepth int64
+	for _, c := range string(text) {
+		switch c {
+		case '[', '{':
+			lines++
+			depth++
+			maxDepth = max(maxDepth, depth)
+		case ']', '}':
+			lines++
+			depth--
+		case ',':
+			lines++
+		}
+	}
+	perLine := saturatingAdd(prefix, saturatingMul(indent, maxDepth)) + 1
+	return saturatingAdd(int64(len(text)), saturatingMul(lines+1, perLine))
+}
+
+// shallowSize returns the bytes of a string or the element slots of a list or tuple
+func shallowSize(v starlark.Value) int64 {
+	switch v := v.(type) {
+	case starlark.String:
+		return int64(len(v))
+	case starlark.Bytes:
+		return int64(len(v))
+	case *starlark.List, starlark.Tuple:
+		return int64(starlark.Len(v)) * valueSize
+	}
+	return 0
+}
+
+// reprSize estimates the length of a value's string form, stopping once it exceeds limit
+func reprSize(v starlark.Value, limit int64) int64 {
+	var size int64
+	stack := []starlark.Value{v}
+	for len(stack) > 0 && size <= limit {
+		v, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		switch v := v.(type) {
+		case starlark.String:
+			size += int64(len(v)) + 2
+		case starlark.Bytes:
+			size += int64(len(v)) + 3
+		case starlark.Int:
+			size = saturatingAdd(size, intSize(v))
+		case *starlark.List, starlark.Tuple:
+			seq := v.(starlark.Indexable)
+			size += 2
+			for i := 0; i < seq.Len() && size <= limit; i++ {
+				size += 2
+				stack = append(stack, seq.Index(i))
+			}
+		case *starlark.Dict:
+			size += 2
+			for _, item := range v.Items() {
+				if size > limit {
+					break
+				}
+				size += 4
+				stack = append(stack, item[0], item[1])
+			}
+		case *starlark.Set:
+			size += 5
+			iter := v.Iterate()
+			var elem starlark.Value
+			for size <= limit && iter.Next(&elem) {
+				size += 2
+				stack = append(stack, elem)
+			}
+			iter.Done()
+		default:
+			size += valueSize
+		}
+	}
+	return size
+}
+
+// intSize returns the bytes an int's digits take, at least one machine word
+func intSize(x starlark.Int) int64 {
+	if _, ok := x.Int64(); ok {
+		return 8
+	}
+	return int64(x.BigInt().BitLen()/8 + 1)
+}
+
+// repeatCount returns a repetition count or shift, treating negative ones as zero
+func repeatCount(x starlark.Int) int64 {
+	n, ok := x.Int64()
+	if !ok {
+		if x.Sign() < 0 {
+			return 0
+		}
+		return math.MaxInt64
+	}
+	return max(n, 0)
+}
+
+// argSize returns the shallow size of the i-th positional argument
+func argSize(args starlark.Tuple, i int) int64 {
+	if i >= len(args) {
+		return 0
+	}
+	return shallowSize(args[i])
+}
+
+// argLen returns the length of the i-th positional argument, or 0 when it has none
+func argLen(args starlark.Tuple, i int) int64 {
+	if i >= len(args) {
+		return 0
+	}
+	return int64(max(starlark.Len(args[i]), 0))
+}
+
+// saturatingAdd adds sizes, capping at math.MaxInt64
+func saturatingAdd(a, b int64) int64 {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+// saturatingMul multiplies non-negative sizes, capping at math.MaxInt64
+func saturatingMul(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > math.MaxInt64/b {
+		return math.MaxInt64
+	}
+	return a * b
+}
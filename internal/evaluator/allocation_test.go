@@ -0,0 +1,342 @@
+package evaluator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.starlark.net/lib/json"
+	starlarkmath "go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+)
+
+// testResponse is the response envelope the tests' scripts see
+func testResponse() starlark.Value {
+	return toStarlark(map[string]interface{}{
+		"status": 200,
+		"body":   map[string]interface{}{"items": []interface{}{1, 2, 3}},
+	})
+}
+
+// TestCheckedScriptKeepsSemantics runs scripts both as Starlark compiles them and with the
+// allocation checks, and compares the results and errors
+func TestCheckedScriptKeepsSemantics(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"augmented index evaluated once", `
+calls = []
+d = {"a": 1}
+def target():
+    calls.append("target")
+    return d
+def key():
+    calls.append("key")
+    return "a"
+target()[key()] += len(calls)
+result = [d, calls]`},
+		{"augmented nested index", `
+n = [[0], [0]]
+i = [0]
+def next():
+    i[0] += 1
+    return i[0] - 1
+n[next()][0] += 5
+n[next()][0] -= 2
+result = [n, i]`},
+		{"augmented closure variable", `
+def count():
+    n = [0]
+    def inc():
+        n[0] += 1
+    inc()
+    inc()
+    return n[0]
+result = count()`},
+		{"augmented field", `
+r = response
+r.status += 1
+result = r`},
+		{"augmented local before assignment", `
+x = 1
+def f():
+    x += 1
+    return x
+result = f()`},
+		{"list += extends in place", `
+a = [1]
+b = a
+a += [2]
+a += (3,)
+a += a
+result = [a, b]`},
+		{"frozen list +=", `
+l = response["body"]["items"]
+l += [4]
+result = l`},
+		{"dict |= updates in place", `
+a = {"x": 1}
+b = a
+a |= {"y": 2}
+result = [a, b]`},
+		{"set augmented assignments copy", `
+a = set([1, 2, 3])
+b = a
+a |= set([4])
+a -= set([1])
+a &= set([2, 4, 9])
+a ^= set([7])
+result = [sorted(a), sorted(b)]`},
+		{"string and int augmented assignments", `
+s = "a"
+s += "b"
+s *= 3
+s %= ()
+n = 6
+n -= 1
+n *= 2
+n %= 7
+n <<= 2
+n |= 1
+n &= 13
+n ^= 4
+n += 1 << 70
+result = [s, n]`},
+		{"augmented assignment type error", `
+s = "a"
+s += 1
+result = s`},
+		{"operators", `result = [1 + 2, 7 - 3, 2 * 3.5, 10 % 4, 1 << 3, 6 | 1, 6 & 3, 6 ^ 3, "ab" * 2, [1] + [2], "%s-%d" % ("a", 1)]`},
+		{"set and dict operators", `result = [sorted(set([1, 2]) | set([3])), sorted(set([1, 2]) - set([1])), sorted(set([1, 2]) & set([2])), {"a": 1} | {"b": 2}]`},
+		{"concatenation type error", `result = "a" + 1`},
+		{"repetition type error", `result = [1] * "x"`},
+		{"format type error", `result = "%d" % "x"`},
+		{"union type error", `result = {} | 1`},
+		{"slices", `
+l = [0, 1, 2, 3, 4]
+s = "raven"
+result = [l[1:], l[::-1], l[:2], l[-2:], l[1:4:2], s[::2], s[1:3], tuple(l)[::2], l[:][0]]`},
+		{"zero slice step", `result = [1, 2][::0]`},
+		{"invalid slice bound", `result = "abc"["a":]`},
+		{"invalid slice operand", `result = (1)[1:]`},
+		{"comprehensions", `result = [[x * 2 for x in range(5) if x % 2], {k: v for k, v in [("a", 1), ("b", 2)]}, [x + y for x in "ab" for y in "cd"]]`},
+		{"index assignment", `
+d = {}
+l = [0, 0]
+d["k"] = 1
+l[1] = 5
+l[0], d["j"] = 2, 3
+for l[0] in range(3):
+    pass
+result = [d, l]`},
+		{"frozen index assignment", `response["body"]["items"][0] = 1`},
+		{"methods", `result = [",".join(["a", "b"]), "a-b".split("-"), "Ab".upper(), "a b".title(), "x".replace("x", "yy"), "{}!".format(1), sorted(response.keys()), len(response.items())]`},
+		{"method bound before call", `
+join = "-".join
+result = join(["a", "b"])`},
+		{"json functions", `result = [json.encode({"a": [1]}), json.decode("[1, 2]"), json.indent("[1]", indent="  ")]`},
+		{"missing method", `result = (1).join`},
+		{"method error", `result = ",".join([1])`},
+		{"frozen list method", `result = response["body"]["items"].append(4)`},
+		{"getattr", `result = [getattr("ab", "upper")(), getattr(1, "x", "fallback"), hasattr("a", "join")]`},
+		{"getattr error", `result = getattr(1, "x")`},
+		{"spread arguments", `
+def f(a, b, c = [0] + [1]):
+    return [a, b, c]
+result = [f(*[1, 2], **{"c": 3}), f(1, 2), (lambda x, y = "a" + "b": x + y)("c")]`},
+		{"spread error", `result = len(*1)`},
+		{"checked built-ins", `result = [list(range(3)), str([1, "a"]), repr("a"), sorted([3, 1]), dict(a = 1), tuple("ab".elems()), list(zip([1], [2])), list(enumerate("a"))]`},
+		{"fail", `fail("stop", 1)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, wantErr := runCompiled(t, tt.source, func(source string) (*starlark.Program, error) {
+				return model.CompileScript(source, model.RuleScriptGlobals)
+			})
+			got, gotErr := runCompiled(t, tt.source, compileRuleScript)
+			if !reflect.DeepEqual(got, want) || gotErr != wantErr {
+				t.Errorf("checked script = %v, %q; want %v, %q", got, gotErr, want, wantErr)
+			}
+		})
+	}
+}
+
+// runCompiled compiles and runs a rule script, returning its value or error message
+func runCompiled(t *testing.T, source string, compile func(string) (*starlark.Program, error)) (interface{}, string) {
+	t.Helper()
+	program, err := compile(source)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	value, err := runScript(context.Background(), "test", program, starlark.StringDict{"response": testResponse()})
+	if err != nil {
+		return nil, err.Error()
+	}
+	return value, ""
+}
+
+// TestScriptMemoryLimit checks that each construct building a large value counts it, by
+// running scripts that allocate a few MiB within the run's budget and past a smaller one
+func TestScriptMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"string repetition", `s = "x" * (4 << 20)`},
+		{"list repetition", `l = [0] * (256 << 10)`},
+		{"concatenation", `
+s = "x" * 1024
+for _ in range(12):
+    s = s + s`},
+		{"augmented concatenation", `
+s = "x" * 1024
+for _ in range(12):
+    s += s`},
+		{"list +=", `
+l = [0] * 1024
+for _ in range(8):
+    l += l`},
+		{"list extend", `
+l = [0] * 1024
+for _ in range(8):
+    l.extend(l)`},
+		{"append", `
+l = []
+for i in range(100000):
+    l.append(i)`},
+		{"insert", `
+l = []
+for i in range(50000):
+    l.insert(i, i)`},
+		{"set add", `
+s = set()
+for i in range(100000):
+    s.add(i)`},
+		{"index assignment", `
+d = {}
+for i in range(100000):
+    d[i] = i`},
+		{"list comprehension", `l = [0 for _ in range(100000)]`},
+		{"dict comprehension", `d = {i: i for i in range(100000)}`},
+		{"format operator", `
+s = "x" * 1024
+for _ in range(12):
+    s = "%s%s" % (s, s)`},
+		{"shift", `
+n = 1
+for _ in range(200):
+    n = n << 500`},
+		{"big int multiplication", `
+n = 1 << 500
+for _ in range(14):
+    n = n * n`},
+		{"big int addition", `
+n = int("f" * (256 << 10), 16)
+for _ in range(10):
+    n + n`},
+		{"set union", `
+s = set(range(10000))
+for _ in range(20):
+    s | s`},
+		{"set difference", `
+s = set(range(10000))
+for _ in range(20):
+    s - set()`},
+		{"dict union", `
+d = dict([(i, i) for i in range(10000)])
+for _ in range(10):
+    d | d`},
+		{"list slice", `
+l = list(range(10000))
+for _ in range(30):
+    l[:]`},
+		{"string slice with step", `
+s = "x" * (1 << 20)
+for _ in range(4):
+    s[::-1]`},
+		{"spread arguments", `
+l = list(range(10000))
+for _ in range(30):
+    max(*l)`},
+		{"join", `
+parts = ["x" * 1024] * 1024
+for _ in range(4):
+    "".join(parts)`},
+		{"replace", `
+s = "x" * 1024
+for _ in range(12):
+    s = s.replace("x", "xx")`},
+		{"upper", `
+s = "x" * (1 << 20)
+for _ in range(4):
+    s.upper()`},
+		{"format method", `
+s = "x" * (1 << 20)
+for _ in range(4):
+    "{}".format(s)`},
+		{"split", `
+s = "x," * (64 << 10)
+for _ in range(4):
+    s.split(",")`},
+		{"str", `
+l = list(range(100000))
+for _ in range(4):
+    str(l)`},
+		{"list of range", `l = list(range(300000))`},
+		{"sorted", `
+l = list(range(100000))
+for _ in range(3):
+    sorted(l)`},
+		{"dict items", `
+d = dict([(i, i) for i in range(10000)])
+for _ in range(10):
+    d.items()`},
+		{"json.encode", `
+l = list(range(100000))
+for _ in range(4):
+    json.encode(l)`},
+		{"getattr method", `
+s = "x" * (1 << 20)
+for _ in range(4):
+    getattr(s, "upper")()`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := runWithBudget(t, tt.source, scriptMaxAllocBytes); err != nil {
+				t.Fatalf("within the budget: %v", err)
+			}
+			err := runWithBudget(t, tt.source, 1<<20)
+			if err == nil || !strings.Contains(err.Error(), "memory limit") {
+				t.Errorf("past the budget: got %v, want the memory limit", err)
+			}
+		})
+	}
+}
+
+// runWithBudget runs a rule script with an allocation budget of budget bytes
+func runWithBudget(t *testing.T, source string, budget int64) error {
+	t.Helper()
+	program, err := compileRuleScript(source)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	thread := &starlark.Thread{Name: "test"}
+	thread.SetMaxExecutionSteps(scriptMaxSteps)
+	thread.SetLocal(allocBudgetKey, &allocBudget{remaining: budget})
+	globals := starlark.StringDict{
+		"response": testResponse(),
+		"json":     json.Module,
+		"math":     starlarkmath.Module,
+	}
+	for name, value := range allocGlobals {
+		globals[name] = value
+	}
+	_, err = program.Init(thread, globals)
+	return err
+}
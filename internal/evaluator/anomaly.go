@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// EvaluateAnomaly matches an anomaly rule when the current value deviates from the baseline
+// learned from previous values (newest first) by at least the expected number of standard deviations
+func (e *Evaluator) EvaluateAnomaly(rule model.Rule, current model.RuleEvaluation, previous []float64) model.RuleEvaluation {
+	result := current
+	result.Matched = false
+
+	// Nothing to compare when the current value couldn't be extracted
+	if result.Error != "" {
+		return result
+	}
+
+	value, err := CoerceToNumber(current.ExtractedValue)
+	if err != nil {
+		result.Error = fmt.Sprintf("anomaly requires a numeric value: %v", err)
+		return result
+	}
+
+	sigma := model.DefaultAnomalySigma
+	if rule.ExpectedValue != nil {
+		if sigma, err = CoerceToNumber(rule.ExpectedValue); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	baseline := rule.Baseline
+	if baseline == nil {
+		baseline = &model.Baseline{Method: model.BaselineStdDev}
+	}
+
+	var mu, sd float64
+	if baseline.Method == model.BaselineEWMA {
+		mu, sd = ewma(previous, baseline.Alpha)
+	} else {
+		mu, sd = meanStdDev(previous)
+	}
+
+	stats := &model.BaselineStats{
+		Method:  baseline.Method,
+		Mean:    mu,
+		StdDev:  sd,
+		Samples: len(previous),
+	}
+	result.Baseline = stats
+
+	// Too little history to tell normal from abnormal
+	if len(previous) == 0 || len(previous) < baseline.MinSamples {
+		return result
+	}
+
+	switch {
+	case sd > 0:
+		stats.Deviation = (value - mu) / sd
+	case value != mu:
+		// A perfectly flat baseline makes any change infinitely unusual
+		stats.Deviation = math.Copysign(math.MaxFloat64, value-mu)
+	}
+
+	result.Matched = math.Abs(stats.Deviation) >= sigma
+	return result
+}
+
+// meanStdDev returns the mean and population standard deviation of the values
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	mu := mean(values)
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mu) * (v - mu)
+	}
+	return mu, math.Sqrt(variance / float64(len(values)))
+}
+
+// ewma returns the exponentially weighted mean and standard deviation of values given newest first
+func ewma(values []float64, alpha float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	// Walk from oldest to newest so recent values weigh the most
+	mu := values[len(values)-1]
+	variance := 0.0
+	for i := len(values) - 2; i >= 0; i-- {
+		diff := values[i] - mu
+		mu += alpha * diff
+		variance = (1 - alpha) * (variance + alpha*diff*diff)
+	}
+	return mu, math.Sqrt(variance)
+}
@@ -0,0 +1,88 @@
+package evaluator
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/oliveagle/jsonpath"
+)
+
+// maxCachedExpressions bounds each compiled expression cache; a full cache is reset
+const maxCachedExpressions = 4096
+
+// expressionCache memoizes compiled expressions by their source text. Editing a rule
+// changes the text, so compiled forms never go stale when a config is updated.
+type expressionCache[T any] struct {
+	mu      sync.RWMutex
+	entries map[string]T
+	compile func(string) (T, error)
+}
+
+// newExpressionCache creates a cache using compile for misses
+func newExpressionCache[T any](compile func(string) (T, error)) *expressionCache[T] {
+	return &expressionCache[T]{
+		entries: make(map[string]T),
+		compile: compile,
+	}
+}
+
+// get returns the compiled form of expression, compiling it on first use.
+// Expressions that fail to compile are not cached.
+func (c *expressionCache[T]) get(expression string) (T, error) {
+	c.mu.RLock()
+	compiled, ok := c.entries[expression]
+	c.mu.RUnlock()
+	if ok {
+		return compiled, nil
+	}
+
+	compiled, err := c.compile(expression)
+	if err != nil {
+		return compiled, err
+	}
+
+	c.mu.Lock()
+	if len(c.entries) >= maxCachedExpressions {
+		c.entries = make(map[string]T)
+	}
+	c.entries[expression] = compiled
+	c.mu.Unlock()
+
+	return compiled, nil
+}
+
+var (
+	jsonpathCache   = newExpressionCache(jsonpath.Compile)
+	regexCache      = newExpressionCache(regexp.Compile)
+	scriptCache     = newExpressionCache(compileRuleScript)
+	hookScriptCache = newExpressionCache(compileHookScript)
+	jqCache         = newExpressionCache(model.CompileJQ)
+)
+
+// Precompile compiles the JSONPath expressions, scripts and regex patterns of rules into the
+// shared caches, so scheduled executions don't compile them again. It fails on the first invalid expression.
+func Precompile(rules []model.Rule) error {
+	for _, rule := range rules {
+		if rule.IsScript() {
+			if _, err := scriptCache.get(rule.Expression); err != nil {
+				return fmt.Errorf("rule %s: invalid script: %w", rule.Name, err)
+			}
+		} else if _, isHeader := rule.HeaderName(); !isHeader && !rule.IsRaw() && rule.Expression != "" {
+			if _, err := jsonpathCache.get(rule.Expression); err != nil {
+				return fmt.Errorf("rule %s: invalid JSONPath expression '%s': %w", rule.Name, rule.Expression, err)
+			}
+		}
+		if rule.Operator == "regex" {
+			pattern, ok := rule.ExpectedValue.(string)
+			if !ok {
+				continue
+			}
+			if _, err := regexCache.get(pattern); err != nil {
+				return fmt.Errorf("rule %s: invalid regex pattern '%s': %w", rule.Name, pattern, err)
+			}
+		}
+	}
+	return nil
+}
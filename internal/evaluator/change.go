@@ -0,0 +1,62 @@
+package evaluator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// fingerprintPrefix marks extracted values that are content hashes
+const fingerprintPrefix = "sha256:"
+
+// Fingerprint hashes a value for change detection. Strings are hashed as-is; other values
+// are hashed as JSON, whose object keys are sorted, so key order doesn't count as a change.
+func Fingerprint(value interface{}) (string, error) {
+	var data []byte
+	if s, ok := value.(string); ok {
+		data = []byte(s)
+	} else {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to fingerprint value: %w", err)
+		}
+		data = encoded
+	}
+
+	sum := sha256.Sum256(data)
+	return fingerprintPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// fingerprintEvaluation replaces the extracted value of a "changed" rule with its fingerprint
+func fingerprintEvaluation(result model.RuleEvaluation) model.RuleEvaluation {
+	if result.Error != "" {
+		return result
+	}
+	fingerprint, err := Fingerprint(result.ExtractedValue)
+	if err != nil {
+		result.Error = err.Error()
+		result.ExtractedValue = nil
+		return result
+	}
+	result.ExtractedValue = fingerprint
+	return result
+}
+
+// EvaluateChange matches a "changed" rule when its fingerprint differs from the one recorded
+// by the previous execution. The first execution only records a fingerprint.
+func (e *Evaluator) EvaluateChange(current model.RuleEvaluation, previous string) model.RuleEvaluation {
+	result := current
+	result.Matched = false
+
+	// Nothing to compare when the current value couldn't be extracted
+	if result.Error != "" || previous == "" {
+		return result
+	}
+
+	result.PreviousValue = previous
+	result.Matched = result.ExtractedValue != previous
+	return result
+}
@@ -1,14 +1,21 @@
 package evaluator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/dandantas/raven/internal/model"
-	"github.com/oliveagle/jsonpath"
+	"go.starlark.net/starlark"
 )
 
+// errBodyNotJSON is reported by JSONPath body rules when the response isn't JSON
+const errBodyNotJSON = "Response body is not valid JSON"
+
 // Evaluator evaluates rules against API responses
 type Evaluator struct{}
 
@@ -19,27 +26,47 @@ func NewEvaluator() *Evaluator {
 
 // EvaluateRule evaluates a single rule against a JSON response
 func (e *Evaluator) EvaluateRule(rule model.Rule, responseBody string) model.RuleEvaluation {
-	result := model.RuleEvaluation{
-		RuleName:      rule.Name,
-		Expression:    rule.Expression,
-		Operator:      rule.Operator,
-		ExpectedValue: rule.ExpectedValue,
-		Matched:       false,
+	return e.evaluateBody(rule, &bodyDocument{body: responseBody})
+}
+
+// bodyDocument decodes a response body on first use so every rule of an execution
+// shares one decoded document
+type bodyDocument struct {
+	body    string
+	decoded bool
+	value   interface{}
+	err     error
+}
+
+// get returns the decoded body, parsing it only once
+func (d *bodyDocument) get() (interface{}, error) {
+	if !d.decoded {
+		d.decoded = true
+		if d.err = json.Unmarshal([]byte(d.body), &d.value); d.err != nil {
+			slog.Error("Failed to parse JSON for rule evaluation", "error", d.err.Error())
+		}
 	}
+	return d.value, d.err
+}
 
-	// Parse JSON response
-	var jsonData interface{}
-	if err := json.Unmarshal([]byte(responseBody), &jsonData); err != nil {
+// evaluateBody evaluates a rule against the shared decoded body
+func (e *Evaluator) evaluateBody(rule model.Rule, body *bodyDocument) model.RuleEvaluation {
+	document, err := body.get()
+	if err != nil {
+		result := newRuleEvaluation(rule)
 		result.Error = fmt.Sprintf("Failed to parse JSON response: %v", err)
-		slog.Error("Failed to parse JSON for rule evaluation",
-			"rule", rule.Name,
-			"error", err.Error(),
-		)
 		return result
 	}
 
+	return e.EvaluateDocument(rule, document)
+}
+
+// EvaluateDocument evaluates a single rule against an already decoded JSON document
+func (e *Evaluator) EvaluateDocument(rule model.Rule, document interface{}) model.RuleEvaluation {
+	result := newRuleEvaluation(rule)
+
 	// Extract value using JSONPath
-	extractedValue, err := e.extractValue(jsonData, rule.Expression)
+	extractedValue, err := e.extractValue(document, rule.Expression)
 	if err != nil {
 		result.Error = err.Error()
 		slog.Debug("JSONPath extraction failed",
@@ -78,22 +105,207 @@ func (e *Evaluator) EvaluateRule(rule model.Rule, responseBody string) model.Rul
 	return result
 }
 
-// EvaluateRules evaluates all rules against a JSON response
-func (e *Evaluator) EvaluateRules(rules []model.Rule, responseBody string) []model.RuleEvaluation {
+// FailedEvaluation returns an unmatched evaluation of a rule that couldn't be evaluated
+func (e *Evaluator) FailedEvaluation(rule model.Rule, reason string) model.RuleEvaluation {
+	result := newRuleEvaluation(rule)
+	result.Error = reason
+	return result
+}
+
+// newRuleEvaluation creates an unmatched evaluation result for a rule
+func newRuleEvaluation(rule model.Rule) model.RuleEvaluation {
+	return model.RuleEvaluation{
+		RuleName:      rule.Name,
+		Source:        rule.Source,
+		Expression:    rule.Expression,
+		Operator:      rule.Operator,
+		ExpectedValue: rule.ExpectedValue,
+		Matched:       false,
+		AlertOnMatch:  rule.AlertOnMatch,
+	}
+}
+
+// envelopeDocument builds the response envelope, and its Starlark form for scripts, on first use
+type envelopeDocument struct {
+	response model.ExecutionResponse
+	duration time.Duration
+	value    map[string]interface{}
+	script   starlark.Value
+}
+
+// get returns the envelope
+func (d *envelopeDocument) get() map[string]interface{} {
+	if d.value == nil {
+		d.value = NewEnvelope(d.response, d.duration)
+	}
+	return d.value
+}
+
+// scriptValue returns the envelope as a frozen Starlark dict
+func (d *envelopeDocument) scriptValue() starlark.Value {
+	if d.script == nil {
+		d.script = toStarlark(d.get())
+	}
+	return d.script
+}
+
+// EvaluateResponse evaluates rules in order against the response body or headers,
+// stopping early once ctx is done. Rules evaluated before the cut-off are returned.
+// With evaluateEnvelope, JSONPath expressions are evaluated over the envelope instead of
+// the raw body; scripts always receive the envelope.
+func (e *Evaluator) EvaluateResponse(ctx context.Context, rules []model.Rule, response model.ExecutionResponse, duration time.Duration, evaluateEnvelope bool) []model.RuleEvaluation {
 	results := make([]model.RuleEvaluation, 0, len(rules))
+	body := &bodyDocument{body: response.Body}
+	envelope := &envelopeDocument{response: response, duration: duration}
 
 	for _, rule := range rules {
-		result := e.EvaluateRule(rule, responseBody)
+		if ctx.Err() != nil {
+			break
+		}
+
+		var result model.RuleEvaluation
+		switch headerName, isHeader := rule.HeaderName(); {
+		case isHeader:
+			result = e.EvaluateHeaderRule(rule, headerValue(response.Headers, headerName))
+		case rule.IsScript():
+			result = e.EvaluateScriptRule(ctx, rule, envelope.scriptValue())
+		case rule.IsRaw():
+			result = e.EvaluateRawRule(rule, response.Body)
+		case rule.Operator == model.OperatorChanged && rule.Expression == "":
+			// Without an expression the whole raw body is watched
+			result = newRuleEvaluation(rule)
+			result.ExtractedValue = response.Body
+		case evaluateEnvelope:
+			result = e.EvaluateDocument(rule, envelope.get())
+		case response.BodyNotJSON:
+			result = newRuleEvaluation(rule)
+			result.Error = errBodyNotJSON
+		default:
+			result = e.evaluateBody(rule, body)
+		}
+
+		// Change rules compare fingerprints rather than the values themselves
+		if rule.Operator == model.OperatorChanged {
+			result = fingerprintEvaluation(result)
+		}
 		results = append(results, result)
 	}
 
 	return results
 }
 
+// BodyNotJSON reports whether a non-empty response body fails to parse as JSON
+func BodyNotJSON(body string) bool {
+	return body != "" && !json.Valid([]byte(body))
+}
+
+// NewEnvelope synthesizes the {status, headers, body, duration_ms, size_bytes} document
+// rules are evaluated against. Bodies that aren't JSON are exposed as a string.
+func NewEnvelope(response model.ExecutionResponse, duration time.Duration) map[string]interface{} {
+	headers := make(map[string]interface{}, len(response.Headers))
+	for key, value := range response.Headers {
+		headers[key] = value
+	}
+
+	var body interface{}
+	if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+		body = response.Body
+	}
+
+	// Numbers are float64 like any other decoded JSON value
+	return map[string]interface{}{
+		"status":      float64(response.StatusCode),
+		"headers":     headers,
+		"body":        body,
+		"duration_ms": float64(duration.Milliseconds()),
+		"size_bytes":  float64(len(response.Body)),
+	}
+}
+
+// EvaluateRawRule evaluates a rule against the body as plain text, without JSON parsing.
+// The body checksum is recorded as the extracted value; contains, not_contains and regex
+// search the text while eq and ne compare the checksum, pinning the exact content.
+func (e *Evaluator) EvaluateRawRule(rule model.Rule, body string) model.RuleEvaluation {
+	result := newRuleEvaluation(rule)
+
+	// Change rules fingerprint the body themselves
+	if rule.Operator == model.OperatorChanged {
+		result.ExtractedValue = body
+		return result
+	}
+
+	checksum, err := Fingerprint(body)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.ExtractedValue = checksum
+
+	var subject interface{} = body
+	if rule.Operator == "eq" || rule.Operator == "ne" {
+		subject = checksum
+	}
+
+	matched, err := EvaluateOperator(rule.Operator, subject, rule.ExpectedValue)
+	if err != nil {
+		result.Error = err.Error()
+		slog.Debug("Raw rule evaluation failed",
+			"rule", rule.Name,
+			"operator", rule.Operator,
+			"error", err.Error(),
+		)
+		return result
+	}
+
+	result.Matched = matched
+	return result
+}
+
+// EvaluateHeaderRule evaluates a single rule against a response header value.
+// A missing header is evaluated as a nil value so "exists" and "ne" work as expected.
+func (e *Evaluator) EvaluateHeaderRule(rule model.Rule, value interface{}) model.RuleEvaluation {
+	result := model.RuleEvaluation{
+		RuleName:       rule.Name,
+		Source:         rule.Source,
+		Expression:     rule.Expression,
+		ExtractedValue: value,
+		Operator:       rule.Operator,
+		ExpectedValue:  rule.ExpectedValue,
+		AlertOnMatch:   rule.AlertOnMatch,
+	}
+
+	matched, err := EvaluateOperator(rule.Operator, value, rule.ExpectedValue)
+	if err != nil {
+		result.Error = err.Error()
+		slog.Debug("Header rule evaluation failed",
+			"rule", rule.Name,
+			"source", rule.Source,
+			"error", err.Error(),
+		)
+		return result
+	}
+
+	result.Matched = matched
+	return result
+}
+
+// headerValue looks up a response header by name, ignoring case
+func headerValue(headers map[string]string, name string) interface{} {
+	if value, ok := headers[http.CanonicalHeaderKey(name)]; ok {
+		return value
+	}
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return nil
+}
+
 // extractValue extracts a value from JSON using JSONPath expression
 func (e *Evaluator) extractValue(jsonData interface{}, expression string) (interface{}, error) {
-	// Compile JSONPath expression
-	pattern, err := jsonpath.Compile(expression)
+	// Compiled expressions are shared across executions
+	pattern, err := jsonpathCache.get(expression)
 	if err != nil {
 		return nil, fmt.Errorf("invalid JSONPath expression '%s': %w", expression, err)
 	}
@@ -2,7 +2,6 @@ package evaluator
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 )
 
@@ -24,10 +23,19 @@ func EvaluateOperator(operator string, extractedValue, expectedValue interface{}
 		return evaluateLessThanOrEqual(extractedValue, expectedValue)
 	case "contains":
 		return evaluateContains(extractedValue, expectedValue)
+	case "not_contains":
+		result, err := evaluateContains(extractedValue, expectedValue)
+		return !result, err
 	case "exists":
 		return evaluateExists(extractedValue)
 	case "regex":
 		return evaluateRegex(extractedValue, expectedValue)
+	case "anomaly":
+		// Needs the rule's history; matched later by EvaluateAnomaly
+		return false, nil
+	case "changed":
+		// Needs the previous fingerprint; matched later by EvaluateChange
+		return false, nil
 	default:
 		return false, fmt.Errorf("unknown operator: %s", operator)
 	}
@@ -103,7 +111,7 @@ func evaluateRegex(extracted, expected interface{}) (bool, error) {
 	extractedStr := CoerceToString(extracted)
 	patternStr := CoerceToString(expected)
 
-	re, err := regexp.Compile(patternStr)
+	re, err := regexCache.get(patternStr)
 	if err != nil {
 		return false, fmt.Errorf("invalid regex pattern '%s': %w", patternStr, err)
 	}
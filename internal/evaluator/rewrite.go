@@ -0,0 +1,347 @@
+package evaluator
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Helpers a compiled script calls to count what it allocates, named apart from a script's own
+// variables
+const (
+	binaryHelper = "_raven_binary" // _raven_binary(op, x, y) is x op y
+	attrHelper   = "_raven_attr"   // _raven_attr(x, name) is x.name
+	sliceHelper  = "_raven_slice"  // _raven_slice(x, lo=, hi=, step=) is x[lo:hi:step]
+	itemHelper   = "_raven_item"   // _raven_item(x) is x, stored as a new element
+	spreadHelper = "_raven_spread" // _raven_spread(x) is x, spread into call arguments
+)
+
+// tempPrefix names the variables augmented assignments evaluate their target's operands into
+const tempPrefix = "_raven_tmp"
+
+// Operators whose result can be much larger than their operands
+var binaryOps = map[syntax.Token]bool{
+	syntax.PLUS:       true,
+	syntax.STAR:       true,
+	syntax.PERCENT:    true,
+	syntax.LTLT:       true,
+	syntax.PIPE:       true,
+	syntax.MINUS:      true,
+	syntax.AMP:        true,
+	syntax.CIRCUMFLEX: true,
+}
+
+// augmentedOps maps augmented assignments to the operators they apply
+var augmentedOps = map[syntax.Token]syntax.Token{
+	syntax.PLUS_EQ:       syntax.PLUS,
+	syntax.STAR_EQ:       syntax.STAR,
+	syntax.PERCENT_EQ:    syntax.PERCENT,
+	syntax.LTLT_EQ:       syntax.LTLT,
+	syntax.PIPE_EQ:       syntax.PIPE,
+	syntax.MINUS_EQ:      syntax.MINUS,
+	syntax.AMP_EQ:        syntax.AMP,
+	syntax.CIRCUMFLEX_EQ: syntax.CIRCUMFLEX,
+}
+
+// compileScript compiles a script like model.CompileScript, routing everything that can
+// allocate in proportion to its operands through the helpers in allocGlobals
+func compileScript(source string, globals []string) (*starlark.Program, error) {
+	file, err := model.ParseScript(source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the unmodified script first so its errors name what the author wrote
+	isPredeclared := func(name string) bool {
+		return slices.Contains(globals, name)
+	}
+	if _, err := model.CompileScriptFile(file, isPredeclared); err != nil {
+		return nil, err
+	}
+
+	file, err = model.ParseScript(source)
+	if err != nil {
+		return nil, err
+	}
+	rw := &rewriter{}
+	file.Stmts = rw.stmts(file.Stmts)
+	return model.CompileScriptFile(file, func(name string) bool {
+		return isPredeclared(name) || allocGlobals[name] != nil
+	})
+}
+
+// rewriter rewrites a parsed script for compileScript. It keeps the order in which
+// expressions are evaluated and evaluates each one once.
+type rewriter struct {
+	temps int
+}
+
+// stmts rewrites a block, expanding augmented assignments into several statements
+func (rw *rewriter) stmts(stmts []syntax.Stmt) []syntax.Stmt {
+	rewritten := make([]syntax.Stmt, 0, len(stmts))
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *syntax.AssignStmt:
+			if op, ok := augmentedOps[s.Op]; ok {
+				rewritten = append(rewritten, rw.augmented(s, op)...)
+				continue
+			}
+			s.RHS = rw.expr(s.RHS)
+			if _, ok := unparen(s.LHS).(*syntax.IndexExpr); ok {
+				s.RHS = helperCall(itemHelper, s.OpPos, s.RHS)
+			}
+			rw.target(s.LHS)
+		case *syntax.DefStmt:
+			rw.params(s.Params)
+			s.Body = rw.stmts(s.Body)
+		case *syntax.ExprStmt:
+			s.X = rw.expr(s.X)
+		case *syntax.ForStmt:
+			s.X = rw.expr(s.X)
+			rw.target(s.Vars)
+			s.Body = rw.stmts(s.Body)
+		case *syntax.WhileStmt:
+			s.Cond = rw.expr(s.Cond)
+			s.Body = rw.stmts(s.Body)
+		case *syntax.IfStmt:
+			s.Cond = rw.expr(s.Cond)
+			s.True = rw.stmts(s.True)
+			s.False = rw.stmts(s.False)
+		case *syntax.ReturnStmt:
+			if s.Result != nil {
+				s.Result = rw.expr(s.Result)
+			}
+		}
+		rewritten = append(rewritten, stmt)
+	}
+	return rewritten
+}
+
+// augmented expands `target op= value` into `target = _raven_binary("op=", target, value)`.
+// The operands of an index or field target are first assigned to temporaries, so they are
+// evaluated once and in the same order as Starlark does.
+func (rw *rewriter) augmented(s *syntax.AssignStmt, op syntax.Token) []syntax.Stmt {
+	var prelude []syntax.Stmt
+	// Each use of the target gets its own nodes, since the resolver binds identifiers in place
+	var target, current syntax.Expr
+	switch t := unparen(s.LHS).(type) {
+	case *syntax.Ident:
+		target = newIdent(t.Name, t.NamePos)
+		current = newIdent(t.Name, t.NamePos)
+	case *syntax.IndexExpr:
+		x, y := rw.temp(), rw.temp()
+		prelude = append(prelude, rw.bind(x, t.X), rw.bind(y, t.Y))
+		target = &syntax.IndexExpr{X: newIdent(x, t.Lbrack), Lbrack: t.Lbrack, Y: newIdent(y, t.Lbrack), Rbrack: t.Rbrack}
+		current = &syntax.IndexExpr{X: newIdent(x, t.Lbrack), Lbrack: t.Lbrack, Y: newIdent(y, t.Lbrack), Rbrack: t.Rbrack}
+	case *syntax.DotExpr:
+		x := rw.temp()
+		prelude = append(prelude, rw.bind(x, t.X))
+		target = &syntax.DotExpr{X: newIdent(x, t.Dot), Dot: t.Dot, NamePos: t.NamePos, Name: t.Name}
+		current = &syntax.DotExpr{X: newIdent(x, t.Dot), Dot: t.Dot, NamePos: t.NamePos, Name: t.Name}
+	default:
+		// Starlark's resolver rejects any other target
+		return []syntax.Stmt{s}
+	}
+
+	value := helperCall(binaryHelper, s.OpPos, stringLiteral(op.String()+"=", s.OpPos), current, rw.expr(s.RHS))
+	return append(prelude, &syntax.AssignStmt{OpPos: s.OpPos, Op: syntax.EQ, LHS: target, RHS: value})
+}
+
+// temp names a new temporary
+func (rw *rewriter) temp() string {
+	rw.temps++
+	return tempPrefix + strconv.Itoa(rw.temps)
+}
+
+// bind assigns an operand, rewritten, to a temporary
+func (rw *rewriter) bind(name string, x syntax.Expr) syntax.Stmt {
+	pos := syntax.Start(x)
+	return &syntax.AssignStmt{OpPos: pos, Op: syntax.EQ, LHS: newIdent(name, pos), RHS: rw.expr(x)}
+}
+
+// target rewrites the operands of an assignment target, leaving the target itself in place
+func (rw *rewriter) target(x syntax.Expr) {
+	switch t := x.(type) {
+	case *syntax.IndexExpr:
+		t.X = rw.expr(t.X)
+		t.Y = rw.expr(t.Y)
+	case *syntax.DotExpr:
+		t.X = rw.expr(t.X)
+	case *syntax.ParenExpr:
+		rw.target(t.X)
+	case *syntax.ListExpr:
+		for _, elem := range t.List {
+			rw.target(elem)
+		}
+	case *syntax.TupleExpr:
+		for _, elem := range t.List {
+			rw.target(elem)
+		}
+	}
+}
+
+// params rewrites the default values of a function's parameters
+func (rw *rewriter) params(params []syntax.Expr) {
+	for _, param := range params {
+		if binary, ok := param.(*syntax.BinaryExpr); ok && binary.Op == syntax.EQ {
+			binary.Y = rw.expr(binary.Y)
+		}
+	}
+}
+
+// expr rewrites an expression
+func (rw *rewriter) expr(x syntax.Expr) syntax.Expr {
+	switch e := x.(type) {
+	case *syntax.BinaryExpr:
+		e.X = rw.expr(e.X)
+		e.Y = rw.expr(e.Y)
+		if binaryOps[e.Op] && !arithmetic(e.Op, e.X, e.Y) {
+			return helperCall(binaryHelper, e.OpPos, stringLiteral(e.Op.String(), e.OpPos), e.X, e.Y)
+		}
+	case *syntax.UnaryExpr:
+		if e.X != nil {
+			e.X = rw.expr(e.X)
+		}
+	case *syntax.CallExpr:
+		e.Fn = rw.expr(e.Fn)
+		for i, arg := range e.Args {
+			switch a := arg.(type) {
+			case *syntax.BinaryExpr:
+				if a.Op == syntax.EQ { // A keyword argument
+					a.Y = rw.expr(a.Y)
+					continue
+				}
+			case *syntax.UnaryExpr:
+				if a.Op == syntax.STAR || a.Op == syntax.STARSTAR {
+					a.X = helperCall(spreadHelper, a.OpPos, rw.expr(a.X))
+					continue
+				}
+			}
+			e.Args[i] = rw.expr(arg)
+		}
+	case *syntax.Comprehension:
+		for _, clause := range e.Clauses {
+			switch c := clause.(type) {
+			case *syntax.ForClause:
+				c.X = rw.expr(c.X)
+				rw.target(c.Vars)
+			case *syntax.IfClause:
+				c.Cond = rw.expr(c.Cond)
+			}
+		}
+		if entry, ok := e.Body.(*syntax.DictEntry); ok {
+			entry.Key = helperCall(itemHelper, syntax.Start(entry.Key), rw.expr(entry.Key))
+			entry.Value = rw.expr(entry.Value)
+		} else {
+			e.Body = helperCall(itemHelper, syntax.Start(e.Body), rw.expr(e.Body))
+		}
+	case *syntax.CondExpr:
+		e.Cond = rw.expr(e.Cond)
+		e.True = rw.expr(e.True)
+		e.False = rw.expr(e.False)
+	case *syntax.DictEntry:
+		e.Key = rw.expr(e.Key)
+		e.Value = rw.expr(e.Value)
+	case *syntax.DictExpr:
+		for i, entry := range e.List {
+			e.List[i] = rw.expr(entry)
+		}
+	case *syntax.DotExpr:
+		e.X = rw.expr(e.X)
+		if checkedAttrNames[e.Name.Name] {
+			return helperCall(attrHelper, e.NamePos, e.X, stringLiteral(e.Name.Name, e.NamePos))
+		}
+	case *syntax.IndexExpr:
+		e.X = rw.expr(e.X)
+		e.Y = rw.expr(e.Y)
+	case *syntax.LambdaExpr:
+		rw.params(e.Params)
+		e.Body = rw.expr(e.Body)
+	case *syntax.ListExpr:
+		for i, elem := range e.List {
+			e.List[i] = rw.expr(elem)
+		}
+	case *syntax.ParenExpr:
+		e.X = rw.expr(e.X)
+	case *syntax.SliceExpr:
+		call := helperCall(sliceHelper, e.Lbrack, rw.expr(e.X))
+		for _, bound := range []struct {
+			name string
+			x    syntax.Expr
+		}{{"lo", e.Lo}, {"hi", e.Hi}, {"step", e.Step}} {
+			if bound.x != nil {
+				call.Args = append(call.Args, &syntax.BinaryExpr{
+					X:     newIdent(bound.name, e.Lbrack),
+					OpPos: e.Lbrack,
+					Op:    syntax.EQ,
+					Y:     rw.expr(bound.x),
+				})
+			}
+		}
+		return call
+	case *syntax.TupleExpr:
+		for i, elem := range e.List {
+			e.List[i] = rw.expr(elem)
+		}
+	}
+	return x
+}
+
+// arithmetic reports whether an operator is applied to a number literal, so its result is
+// a number no larger than its operands
+func arithmetic(op syntax.Token, x, y syntax.Expr) bool {
+	switch op {
+	case syntax.STAR, syntax.LTLT:
+		return isNumber(x) && isNumber(y)
+	case syntax.PERCENT:
+		return isNumber(x)
+	}
+	return isNumber(x) || isNumber(y)
+}
+
+// isNumber reports whether x is a number literal
+func isNumber(x syntax.Expr) bool {
+	switch e := x.(type) {
+	case *syntax.Literal:
+		return e.Token == syntax.INT || e.Token == syntax.FLOAT
+	case *syntax.UnaryExpr:
+		return (e.Op == syntax.MINUS || e.Op == syntax.PLUS) && isNumber(e.X)
+	case *syntax.ParenExpr:
+		return isNumber(e.X)
+	}
+	return false
+}
+
+// unparen strips the parentheses around an expression
+func unparen(x syntax.Expr) syntax.Expr {
+	for {
+		paren, ok := x.(*syntax.ParenExpr)
+		if !ok {
+			return x
+		}
+		x = paren.X
+	}
+}
+
+// newIdent returns an identifier at pos
+func newIdent(name string, pos syntax.Position) *syntax.Ident {
+	return &syntax.Ident{NamePos: pos, Name: name}
+}
+
+// helperCall calls a helper at pos
+func helperCall(helper string, pos syntax.Position, args ...syntax.Expr) *syntax.CallExpr {
+	return &syntax.CallExpr{
+		Fn:     newIdent(helper, pos),
+		Lparen: pos,
+		Args:   args,
+		Rparen: pos,
+	}
+}
+
+// stringLiteral returns a string literal at pos
+func stringLiteral(value string, pos syntax.Position) *syntax.Literal {
+	return &syntax.Literal{Token: syntax.STRING, TokenPos: pos, Raw: strconv.Quote(value), Value: value}
+}
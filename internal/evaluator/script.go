@@ -0,0 +1,222 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.starlark.net/lib/json"
+	starlarkmath "go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+)
+
+// Limits of a single script run
+const (
+	scriptTimeout  = time.Second
+	scriptMaxSteps = 10_000_000
+)
+
+// EvaluateScriptRule runs a rule's script over the response envelope and compares the value
+// it returns. Scripts can't load modules or reach the network and filesystem, and they are
+// cancelled once they run too long, take too many steps or allocate too much memory.
+func (e *Evaluator) EvaluateScriptRule(ctx context.Context, rule model.Rule, response starlark.Value) model.RuleEvaluation {
+	result := newRuleEvaluation(rule)
+
+	value, err := runRuleScript(ctx, rule, response)
+	if err != nil {
+		result.Error = err.Error()
+		slog.Debug("Rule script failed",
+			"rule", rule.Name,
+			"error", err.Error(),
+		)
+		return result
+	}
+	result.ExtractedValue = value
+
+	matched, err := EvaluateOperator(rule.Operator, value, rule.ExpectedValue)
+	if err != nil {
+		result.Error = err.Error()
+		slog.Debug("Script rule evaluation failed",
+			"rule", rule.Name,
+			"operator", rule.Operator,
+			"error", err.Error(),
+		)
+		return result
+	}
+
+	result.Matched = matched
+	return result
+}
+
+// runRuleScript runs the rule's script and returns the value it evaluated to
+func runRuleScript(ctx context.Context, rule model.Rule, response starlark.Value) (interface{}, error) {
+	program, err := scriptCache.get(rule.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+	return runScript(ctx, rule.Name, program, starlark.StringDict{"response": response})
+}
+
+// RunHookScript runs a hook script with globals, given as decoded JSON values, and returns
+// the variables it set
+func RunHookScript(ctx context.Context, name, source string, globals map[string]interface{}) (map[string]interface{}, error) {
+	program, err := hookScriptCache.get(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+
+	predeclared := make(starlark.StringDict, len(globals))
+	for global, value := range globals {
+		predeclared[global] = toStarlark(value)
+	}
+	value, err := runScript(ctx, name, program, predeclared)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("script must evaluate to a dict of variables")
+	}
+	return variables, nil
+}
+
+// runScript runs a compiled script in a sandboxed thread and returns the value it evaluated to
+func runScript(ctx context.Context, name string, program *starlark.Program, globals starlark.StringDict) (interface{}, error) {
+	thread := &starlark.Thread{
+		Name: name,
+		Load: func(*starlark.Thread, string) (starlark.StringDict, error) {
+			return nil, errors.New("load is not allowed in scripts")
+		},
+		Print: func(_ *starlark.Thread, message string) {
+			slog.Debug("Script output", "script", name, "message", message)
+		},
+	}
+	thread.SetMaxExecutionSteps(scriptMaxSteps)
+	thread.SetLocal(allocBudgetKey, &allocBudget{remaining: scriptMaxAllocBytes})
+
+	ctx, cancel := context.WithTimeout(ctx, scriptTimeout)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() {
+		thread.Cancel("script timed out")
+	})
+	defer stop()
+
+	globals["json"] = json.Module
+	globals["math"] = starlarkmath.Module
+	for name, value := range allocGlobals {
+		globals[name] = value
+	}
+	values, err := program.Init(thread, globals)
+	if err != nil {
+		return nil, fmt.Errorf("script failed: %w", err)
+	}
+
+	value, ok := values[model.ScriptResult]
+	if !ok {
+		return nil, fmt.Errorf("script did not set %s", model.ScriptResult)
+	}
+	return fromStarlark(value)
+}
+
+// compileRuleScript compiles a rule script
+func compileRuleScript(source string) (*starlark.Program, error) {
+	return compileScript(source, model.RuleScriptGlobals)
+}
+
+// compileHookScript compiles a hook script
+func compileHookScript(source string) (*starlark.Program, error) {
+	return compileScript(source, model.HookScriptGlobals)
+}
+
+// toStarlark converts a decoded JSON value to a frozen Starlark value. Whole numbers become
+// ints so they can index lists.
+func toStarlark(value interface{}) starlark.Value {
+	var converted starlark.Value
+	switch v := value.(type) {
+	case nil:
+		converted = starlark.None
+	case bool:
+		converted = starlark.Bool(v)
+	case int:
+		converted = starlark.MakeInt(v)
+	case int64:
+		converted = starlark.MakeInt64(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			converted = starlark.MakeInt64(int64(v))
+		} else {
+			converted = starlark.Float(v)
+		}
+	case string:
+		converted = starlark.String(v)
+	case []interface{}:
+		elems := make([]starlark.Value, len(v))
+		for i, elem := range v {
+			elems[i] = toStarlark(elem)
+		}
+		converted = starlark.NewList(elems)
+	case map[string]interface{}:
+		dict := starlark.NewDict(len(v))
+		for key, elem := range v {
+			dict.SetKey(starlark.String(key), toStarlark(elem))
+		}
+		converted = dict
+	default:
+		converted = starlark.String(fmt.Sprint(v))
+	}
+	converted.Freeze()
+	return converted
+}
+
+// fromStarlark converts a script's value to the JSON value rules compare
+func fromStarlark(value starlark.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.Int:
+		return float64(v.Float()), nil
+	case starlark.Float:
+		return float64(v), nil
+	case starlark.String:
+		return string(v), nil
+	case *starlark.List:
+		return fromStarlarkSequence(v)
+	case starlark.Tuple:
+		return fromStarlarkSequence(v)
+	case *starlark.Dict:
+		converted := make(map[string]interface{}, v.Len())
+		for _, item := range v.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("script result has a %s dict key; keys must be strings", item[0].Type())
+			}
+			elem, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			converted[string(key)] = elem
+		}
+		return converted, nil
+	}
+	return nil, fmt.Errorf("script result of type %s can't be compared", value.Type())
+}
+
+// fromStarlarkSequence converts a list or tuple to a JSON array
+func fromStarlarkSequence(sequence starlark.Indexable) (interface{}, error) {
+	converted := make([]interface{}, sequence.Len())
+	for i := range converted {
+		elem, err := fromStarlark(sequence.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = elem
+	}
+	return converted, nil
+}
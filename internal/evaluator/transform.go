@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/itchyny/gojq"
+)
+
+// transformTimeout limits how long a jq transform may run
+const transformTimeout = time.Second
+
+// Transform applies a transform to the response body and returns the result as JSON
+func (e *Evaluator) Transform(ctx context.Context, transform *model.Transform, body string) (string, error) {
+	var document interface{}
+	if err := json.Unmarshal([]byte(body), &document); err != nil {
+		document = body
+	}
+
+	var result interface{}
+	if transform.JSONPath != "" {
+		var err error
+		if result, err = e.extractValue(document, transform.JSONPath); err != nil {
+			return "", err
+		}
+	} else {
+		code, err := jqCache.get(transform.JQ)
+		if err != nil {
+			return "", fmt.Errorf("invalid jq program: %w", err)
+		}
+		if result, err = runJQ(ctx, code, document); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transformed body: %w", err)
+	}
+	return string(data), nil
+}
+
+// runJQ runs a jq program and returns its only output, an array of several outputs, or nil
+func runJQ(ctx context.Context, code *gojq.Code, document interface{}) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, transformTimeout)
+	defer cancel()
+
+	var outputs []interface{}
+	iter := code.RunWithContext(ctx, document)
+	for {
+		value, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := value.(error); ok {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				break
+			}
+			return nil, fmt.Errorf("jq: %w", err)
+		}
+		outputs = append(outputs, value)
+	}
+
+	switch len(outputs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return outputs[0], nil
+	default:
+		return outputs, nil
+	}
+}
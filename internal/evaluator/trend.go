@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// EvaluateTrend re-evaluates a trend rule's operator against the aggregate of the current
+// extracted value and the values from previous executions
+func (e *Evaluator) EvaluateTrend(rule model.Rule, current model.RuleEvaluation, previous []float64) model.RuleEvaluation {
+	result := current
+	result.Matched = false
+
+	// Nothing to aggregate when the current value couldn't be extracted
+	if result.Error != "" {
+		return result
+	}
+
+	value, err := CoerceToNumber(current.ExtractedValue)
+	if err != nil {
+		result.Error = fmt.Sprintf("trend requires a numeric value: %v", err)
+		return result
+	}
+
+	trendValue, err := trendAggregate(rule.Trend.Function, value, previous)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.TrendValue = &trendValue
+
+	matched, err := EvaluateOperator(rule.Operator, trendValue, rule.ExpectedValue)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Matched = matched
+	return result
+}
+
+// trendAggregate computes the trend function over the current and previous values
+func trendAggregate(function string, current float64, previous []float64) (float64, error) {
+	if function == model.TrendChangePct {
+		if len(previous) == 0 {
+			return 0, fmt.Errorf("no previous values to compare against")
+		}
+		baseline := mean(previous)
+		if baseline == 0 {
+			return 0, fmt.Errorf("cannot compute change against a zero average")
+		}
+		return (current - baseline) / math.Abs(baseline) * 100, nil
+	}
+
+	values := append([]float64{current}, previous...)
+
+	switch function {
+	case model.TrendAvg:
+		return mean(values), nil
+	case model.TrendMin:
+		sort.Float64s(values)
+		return values[0], nil
+	case model.TrendMax:
+		sort.Float64s(values)
+		return values[len(values)-1], nil
+	case model.TrendP50:
+		return percentile(values, 50), nil
+	case model.TrendP90:
+		return percentile(values, 90), nil
+	case model.TrendP95:
+		return percentile(values, 95), nil
+	case model.TrendP99:
+		return percentile(values, 99), nil
+	default:
+		return 0, fmt.Errorf("unknown trend function: %s", function)
+	}
+}
+
+// mean returns the arithmetic mean of the values
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// percentile returns the nearest-rank percentile of the values
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/service"
+)
+
+// AgentHandler handles the endpoints used by remote probing agents
+type AgentHandler struct {
+	service *service.AgentService
+	token   string
+}
+
+// NewAgentHandler creates a new agent handler. When token is set, agents must send it as a bearer token.
+func NewAgentHandler(service *service.AgentService, token string) *AgentHandler {
+	return &AgentHandler{
+		service: service,
+		token:   token,
+	}
+}
+
+// authorized checks the agent bearer token
+func (h *AgentHandler) authorized(r *http.Request) bool {
+	if h.token == "" {
+		return true
+	}
+
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(h.token)) == 1
+}
+
+// Lease handles POST /api/v1/agents/lease
+func (h *AgentHandler) Lease(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "Invalid agent token")
+		return
+	}
+
+	var req model.AgentLeaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	response, err := h.service.Lease(r.Context(), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "required") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Report handles POST /api/v1/agents/results
+func (h *AgentHandler) Report(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "Invalid agent token")
+		return
+	}
+
+	var report model.AgentReport
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	response, err := h.service.Report(r.Context(), report)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
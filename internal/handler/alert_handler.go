@@ -1,12 +1,20 @@
 package handler
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/dandantas/raven/internal/database"
 	"github.com/dandantas/raven/internal/model"
 	"github.com/dandantas/raven/internal/service"
+	"github.com/dandantas/raven/internal/webhook"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // AlertHandler handles alert log queries
@@ -23,54 +31,284 @@ func NewAlertHandler(service *service.AlertService) *AlertHandler {
 
 // AlertListResponse represents alert list response
 type AlertListResponse struct {
-	Total   int64                   `json:"total"`
-	Page    int                     `json:"page"`
-	Limit   int                     `json:"limit"`
-	Results []model.AlertLogSummary `json:"results"`
+	Total      *int64                  `json:"total,omitempty"` // Omitted with count=false
+	Page       int                     `json:"page,omitempty"`  // Omitted when paging by cursor
+	Limit      int                     `json:"limit"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+	Results    []model.AlertLogSummary `json:"results"`
+}
+
+// alertExportWriteTimeout replaces the server's write timeout for alert exports
+const alertExportWriteTimeout = 10 * time.Minute
+
+// alertExportErrorTrailer reports an export that failed after its first row
+const alertExportErrorTrailer = "X-Export-Error"
+
+// alertFilterFromQuery reads the alert list and export filters from the query string
+func alertFilterFromQuery(query url.Values) service.AlertFilter {
+	var tags []string
+	if tagsStr := query.Get("tags"); tagsStr != "" {
+		tags = strings.Split(tagsStr, ",")
+	}
+	return service.AlertFilter{
+		ConfigID:             query.Get("config_id"),
+		Status:               query.Get("status"),
+		AcknowledgmentStatus: query.Get("acknowledgment_status"),
+		Severity:             query.Get("severity"),
+		RuleName:             query.Get("rule_name"),
+		WebhookHost:          query.Get("webhook_host"),
+		Source:               query.Get("source"),
+		Tags:                 tags,
+		From:                 query.Get("from"),
+		To:                   query.Get("to"),
+		Sort:                 query.Get("sort"),
+	}
 }
 
 // List handles GET /api/v1/alerts
 func (h *AlertHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
-	configID := r.URL.Query().Get("config_id")
-	status := r.URL.Query().Get("status")
-	acknowledgmentStatus := r.URL.Query().Get("acknowledgment_status")
-	from := r.URL.Query().Get("from")
-	to := r.URL.Query().Get("to")
-	page := parseQueryInt(r, "page", 1)
-	limit := parseQueryInt(r, "limit", 20)
+	query := r.URL.Query()
+	filter := alertFilterFromQuery(query)
+	page := database.Page{
+		Number: parseQueryInt(r, "page", 1),
+		Limit:  parseQueryInt(r, "limit", 20),
+		Cursor: query.Get("cursor"),
+		Count:  query.Get("count") != "false",
+	}
+	if page.Cursor != "" && query.Has("page") {
+		writeError(w, http.StatusBadRequest, "Set either page or cursor, not both")
+		return
+	}
 
-	// Enforce max limit
-	if limit > 100 {
-		limit = 100
+	// Enforce limit bounds
+	if page.Limit > 100 {
+		page.Limit = 100
+	}
+	if page.Limit < 1 {
+		page.Limit = 20
 	}
 
-	summaries, total, err := h.service.List(r.Context(), configID, status, acknowledgmentStatus, from, to, page, limit)
+	summaries, total, next, err := h.service.List(r.Context(), filter, page)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response := AlertListResponse{
-		Total:   total,
-		Page:    page,
-		Limit:   limit,
-		Results: summaries,
+		Limit:      page.Limit,
+		NextCursor: next,
+		Results:    summaries,
+	}
+	if page.Count {
+		response.Total = &total
+	}
+	if page.Cursor == "" {
+		response.Page = page.Number
+	}
+
+	writeJSONFields(w, r, http.StatusOK, response)
+}
+
+// Export handles GET /api/v1/alerts/export, streaming the matching alert history as CSV
+func (h *AlertHandler) Export(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if format := query.Get("format"); format != "" && format != "csv" {
+		writeError(w, http.StatusBadRequest, "invalid format: "+format+" (must be csv)")
+		return
+	}
+
+	// Large exports outlast the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(alertExportWriteTimeout))
+
+	// The header is written with the first row, so errors before it still get a JSON response
+	var writer *csv.Writer
+	start := func() {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="alert-export.csv"`)
+		w.Header().Set("Trailer", alertExportErrorTrailer)
+		w.WriteHeader(http.StatusOK)
+		writer = csv.NewWriter(w)
+		writer.Write(alertCSVHeader)
+	}
+
+	err := h.service.Export(r.Context(), alertFilterFromQuery(query), func(row *model.AlertExportRow) error {
+		if writer == nil {
+			start()
+		}
+		writer.Write(alertCSVRecord(row))
+		return writer.Error()
+	})
+	if writer == nil {
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "invalid") {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		start()
+	} else if err != nil {
+		// The status is already sent, so end the file with an error row the reader can't
+		// mistake for an alert, and report the failure in the trailer too
+		writer.Write([]string{"error", csvSafe(err.Error())})
+		w.Header().Set(alertExportErrorTrailer, err.Error())
+	}
+	writer.Flush()
+}
+
+// alertCSVHeader names the columns of alert exports
+var alertCSVHeader = []string{
+	"id", "created_at", "completed_at", "config_id", "execution_id", "correlation_id",
+	"source", "dedup_key", "rule_name", "severity", "tags", "message", "webhook_host",
+	"final_status", "delivery_latency_ms", "attempts", "last_attempt_at", "last_status_code", "last_error", "occurrences",
+	"acknowledgment_status", "acknowledged_by", "acknowledged_at", "classification", "comments",
+}
+
+// alertCSVRecord renders an exported alert as a CSV record matching alertCSVHeader
+func alertCSVRecord(row *model.AlertExportRow) []string {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	formatID := func(id primitive.ObjectID) string {
+		if id.IsZero() {
+			return ""
+		}
+		return id.Hex()
+	}
+
+	var lastAttemptAt, lastStatusCode, lastError string
+	if attempt := row.LastAttempt; attempt != nil {
+		lastAttemptAt = formatTime(attempt.Timestamp)
+		if attempt.StatusCode != 0 {
+			lastStatusCode = strconv.Itoa(attempt.StatusCode)
+		}
+		lastError = attempt.Error
+	}
+
+	var deliveryLatency string
+	if row.DeliveryLatencyMs > 0 {
+		deliveryLatency = strconv.FormatInt(row.DeliveryLatencyMs, 10)
+	}
+
+	// Alerts from before acknowledgment tracking are open
+	acknowledgmentStatus := row.AcknowledgmentStatus
+	if acknowledgmentStatus == "" {
+		acknowledgmentStatus = "open"
+	}
+
+	// Most columns carry caller-supplied text, so guard them all against formula injection
+	record := []string{
+		row.ID.Hex(),
+		formatTime(row.CreatedAt),
+		formatTime(row.CompletedAt),
+		formatID(row.ConfigID),
+		formatID(row.ExecutionID),
+		row.CorrelationID,
+		row.Source,
+		row.DedupKey,
+		row.RuleName,
+		row.Severity,
+		strings.Join(row.Tags, ";"),
+		row.Message,
+		row.WebhookHost,
+		row.FinalStatus,
+		deliveryLatency,
+		strconv.Itoa(row.AttemptsCount),
+		lastAttemptAt,
+		lastStatusCode,
+		lastError,
+		strconv.Itoa(row.Occurrences),
+		acknowledgmentStatus,
+		row.AcknowledgedBy,
+		formatTime(row.AcknowledgedAt),
+		row.Classification,
+		strconv.Itoa(row.CommentsCount),
+	}
+	for i, field := range record {
+		record[i] = csvSafe(field)
+	}
+	return record
+}
+
+// csvSafe keeps spreadsheets from evaluating a field as a formula by prefixing a quote to
+// values that start with a formula character
+func csvSafe(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
+
+// Get handles GET /api/v1/alerts/{id}
+func (h *AlertHandler) Get(w http.ResponseWriter, r *http.Request) {
+	alert, err := h.service.GetByID(r.Context(), pathParam(r, "id"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, alert)
+}
+
+// CommentRequest represents the add alert comment request
+type CommentRequest struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+// AddComment handles POST /api/v1/alerts/{id}/comments
+func (h *AlertHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	// The API key name identifies the author when none is given
+	author := req.Author
+	if author == "" {
+		author = triggeredBy(r, "")
 	}
 
-	writeJSON(w, http.StatusOK, response)
+	comment, err := h.service.AddComment(r.Context(), pathParam(r, "id"), author, req.Text)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, comment)
 }
 
 // AcknowledgeRequest represents the acknowledge alert request
 type AcknowledgeRequest struct {
 	AcknowledgedBy string `json:"acknowledged_by"`
+	Classification string `json:"classification,omitempty"` // real, noise or test
 }
 
 // Acknowledge handles PATCH /api/v1/alerts/{id}/acknowledge
 func (h *AlertHandler) Acknowledge(w http.ResponseWriter, r *http.Request) {
-	// Extract alert ID from URL path
-	path := strings.TrimPrefix(r.URL.Path, "/api/v1/alerts/")
-	alertID := strings.TrimSuffix(path, "/acknowledge")
+	alertID := pathParam(r, "id")
 
 	if alertID == "" {
 		writeError(w, http.StatusBadRequest, "alert ID is required")
@@ -91,13 +329,13 @@ func (h *AlertHandler) Acknowledge(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Acknowledge the alert
-	err := h.service.Acknowledge(r.Context(), alertID, req.AcknowledgedBy)
+	err := h.service.Acknowledge(r.Context(), alertID, req.AcknowledgedBy, req.Classification)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
 		}
-		if strings.Contains(err.Error(), "invalid alert ID") {
+		if strings.HasPrefix(err.Error(), "invalid") {
 			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -110,3 +348,81 @@ func (h *AlertHandler) Acknowledge(w http.ResponseWriter, r *http.Request) {
 		"message": "alert acknowledged successfully",
 	})
 }
+
+// linkPreviewAgents are user agents of chat link unfurlers, which fetch URLs posted in
+// messages and must not acknowledge the alert on the user's behalf
+var linkPreviewAgents = []string{"Slackbot", "Slack-ImgProxy", "SkypeUriPreview", "Discordbot", "TelegramBot", "facebookexternalhit", "Twitterbot"}
+
+// AcknowledgeByLink handles GET /api/v1/alerts/{id}/ack?token=...&by=...&classification=...
+func (h *AlertHandler) AcknowledgeByLink(w http.ResponseWriter, r *http.Request) {
+	alertID := pathParam(r, "id")
+	query := r.URL.Query()
+
+	userAgent := r.UserAgent()
+	for _, agent := range linkPreviewAgents {
+		if strings.Contains(userAgent, agent) {
+			writeJSON(w, http.StatusOK, map[string]string{
+				"message": "open this link in a browser to acknowledge the alert",
+			})
+			return
+		}
+	}
+
+	err := h.service.AcknowledgeByLink(r.Context(), alertID, query.Get("token"), strings.TrimSpace(query.Get("by")), query.Get("classification"))
+	if err != nil {
+		if errors.Is(err, webhook.ErrInvalidAckToken) {
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "alert acknowledged successfully",
+	})
+}
+
+// AlertIngestResponse represents the result of ingesting an external alert
+type AlertIngestResponse struct {
+	Alert        model.AlertLogSummary `json:"alert"`
+	Deduplicated bool                  `json:"deduplicated"`
+}
+
+// Ingest handles POST /api/v1/alerts/ingest
+func (h *AlertHandler) Ingest(w http.ResponseWriter, r *http.Request) {
+	var request model.AlertIngestRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	alertLog, deduplicated, err := h.service.Ingest(r.Context(), &request)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "validation failed") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Repeats of an open alert are folded into it rather than created
+	status := http.StatusCreated
+	if deduplicated {
+		status = http.StatusOK
+	}
+
+	writeJSON(w, status, AlertIngestResponse{
+		Alert:        alertLog.ToSummary(),
+		Deduplicated: deduplicated,
+	})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/service"
+)
+
+// AuthProfileHandler handles auth profile operations
+type AuthProfileHandler struct {
+	service *service.AuthProfileService
+}
+
+// NewAuthProfileHandler creates a new auth profile handler
+func NewAuthProfileHandler(service *service.AuthProfileService) *AuthProfileHandler {
+	return &AuthProfileHandler{
+		service: service,
+	}
+}
+
+// AuthProfileListResponse represents the auth profile list response
+type AuthProfileListResponse struct {
+	Total   int64               `json:"total"`
+	Page    int                 `json:"page"`
+	Limit   int                 `json:"limit"`
+	Results []model.AuthProfile `json:"results"`
+}
+
+// Create handles POST /api/v1/auth-profiles
+func (h *AuthProfileHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var profile model.AuthProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.Create(r.Context(), &profile); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, profile)
+}
+
+// Get handles GET /api/v1/auth-profiles/{id}
+func (h *AuthProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.service.GetByID(r.Context(), pathParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, profile)
+}
+
+// List handles GET /api/v1/auth-profiles
+func (h *AuthProfileHandler) List(w http.ResponseWriter, r *http.Request) {
+	page := parseQueryInt(r, "page", 1)
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	profiles, total, err := h.service.List(r.Context(), page, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, AuthProfileListResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Results: profiles,
+	})
+}
+
+// Update handles PUT /api/v1/auth-profiles/{id}
+func (h *AuthProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var profile model.AuthProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.Update(r.Context(), pathParam(r, "id"), &profile); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		case strings.Contains(err.Error(), "already exists"):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// Delete handles DELETE /api/v1/auth-profiles/{id}
+func (h *AuthProfileHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), pathParam(r, "id")); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "still used"):
+			writeError(w, http.StatusConflict, err.Error())
+		case strings.Contains(err.Error(), "not found"), strings.Contains(err.Error(), "invalid"):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DeleteResponse{
+		Message: "Auth profile deleted successfully",
+	})
+}
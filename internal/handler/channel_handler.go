@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/service"
+)
+
+// ChannelHandler handles notification channel operations
+type ChannelHandler struct {
+	service *service.ChannelService
+}
+
+// NewChannelHandler creates a new notification channel handler
+func NewChannelHandler(service *service.ChannelService) *ChannelHandler {
+	return &ChannelHandler{
+		service: service,
+	}
+}
+
+// ChannelListResponse represents the notification channel list response
+type ChannelListResponse struct {
+	Total   int64                       `json:"total"`
+	Page    int                         `json:"page"`
+	Limit   int                         `json:"limit"`
+	Results []model.NotificationChannel `json:"results"`
+}
+
+// Create handles POST /api/v1/notification-channels
+func (h *ChannelHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var channel model.NotificationChannel
+	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.Create(r.Context(), &channel); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, channel)
+}
+
+// Get handles GET /api/v1/notification-channels/{id}
+func (h *ChannelHandler) Get(w http.ResponseWriter, r *http.Request) {
+	channel, err := h.service.GetByID(r.Context(), pathParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, channel)
+}
+
+// List handles GET /api/v1/notification-channels
+func (h *ChannelHandler) List(w http.ResponseWriter, r *http.Request) {
+	page := parseQueryInt(r, "page", 1)
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	channels, total, err := h.service.List(r.Context(), page, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, ChannelListResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Results: channels,
+	})
+}
+
+// Update handles PUT /api/v1/notification-channels/{id}
+func (h *ChannelHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var channel model.NotificationChannel
+	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.Update(r.Context(), pathParam(r, "id"), &channel); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		case strings.Contains(err.Error(), "already exists"):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, channel)
+}
+
+// Delete handles DELETE /api/v1/notification-channels/{id}
+func (h *ChannelHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), pathParam(r, "id")); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "still used"):
+			writeError(w, http.StatusConflict, err.Error())
+		case strings.Contains(err.Error(), "not found"), strings.Contains(err.Error(), "invalid"):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DeleteResponse{
+		Message: "Notification channel deleted successfully",
+	})
+}
+
+// Stats handles GET /api/v1/notification-channels/{id}/stats
+func (h *ChannelHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	bucket := r.URL.Query().Get("bucket")
+	if bucket == "" {
+		bucket = "hour"
+	}
+
+	window := 24 * time.Hour
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		parsed, err := model.ParseRelativeDuration(windowStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid window: "+err.Error())
+			return
+		}
+		window = parsed
+	}
+
+	stats, err := h.service.Stats(r.Context(), pathParam(r, "id"), window, bucket)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		case strings.Contains(err.Error(), "invalid"):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/webhook"
+)
+
+// CircuitBreakerHandler inspects and resets the webhook circuit breakers of this instance
+type CircuitBreakerHandler struct {
+	breakers *webhook.CircuitBreakers
+}
+
+// NewCircuitBreakerHandler creates a new circuit breaker handler
+func NewCircuitBreakerHandler(breakers *webhook.CircuitBreakers) *CircuitBreakerHandler {
+	return &CircuitBreakerHandler{breakers: breakers}
+}
+
+// CircuitBreakerListResponse lists the circuit breakers of every host alerts were sent to
+type CircuitBreakerListResponse struct {
+	Total   int                          `json:"total"`
+	Results []model.CircuitBreakerStatus `json:"results"`
+}
+
+// List handles GET /api/v1/admin/circuit-breakers
+func (h *CircuitBreakerHandler) List(w http.ResponseWriter, r *http.Request) {
+	statuses := h.breakers.List()
+	writeJSONFields(w, r, http.StatusOK, CircuitBreakerListResponse{
+		Total:   len(statuses),
+		Results: statuses,
+	})
+}
+
+// Reset handles POST /api/v1/admin/circuit-breakers/{host}/reset, closing the host's breakers
+// so deliveries to it resume at once, e.g. after the receiver was fixed
+func (h *CircuitBreakerHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	statuses, ok := h.breakers.Reset(pathParam(r, "host"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "circuit breaker not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, CircuitBreakerListResponse{
+		Total:   len(statuses),
+		Results: statuses,
+	})
+}
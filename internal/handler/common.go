@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
+
+	"github.com/dandantas/raven/pkg/middleware"
 )
 
 // ErrorResponse represents an error response
@@ -27,6 +30,11 @@ func writeError(w http.ResponseWriter, statusCode int, message string) {
 	})
 }
 
+// pathParam returns the value of a route wildcard such as {id}
+func pathParam(r *http.Request, name string) string {
+	return strings.TrimSpace(r.PathValue(name))
+}
+
 // parseQueryInt parses an integer query parameter with a default value
 func parseQueryInt(r *http.Request, key string, defaultValue int) int {
 	value := r.URL.Query().Get(key)
@@ -52,3 +60,11 @@ func parseQueryBool(r *http.Request, key string) *bool {
 	boolValue := value == "true" || value == "1"
 	return &boolValue
 }
+
+// triggeredBy returns the API key name of the caller, or fallback for anonymous requests
+func triggeredBy(r *http.Request, fallback string) string {
+	if principal := middleware.GetPrincipal(r.Context()); principal != "" {
+		return principal
+	}
+	return fallback
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/dandantas/raven/internal/deploy"
+	"github.com/dandantas/raven/internal/service"
+	"github.com/dandantas/raven/pkg/middleware"
+	"github.com/google/uuid"
+)
+
+// maxDeployPayloadBytes limits inbound deployment webhook bodies
+const maxDeployPayloadBytes = 1 << 20
+
+// DeployHandler handles inbound deployment webhooks from source control providers
+type DeployHandler struct {
+	service      *service.DeployService
+	githubSecret string
+	gitlabSecret string
+}
+
+// NewDeployHandler creates a new deploy handler; an empty secret disables that provider
+func NewDeployHandler(service *service.DeployService, githubSecret, gitlabSecret string) *DeployHandler {
+	return &DeployHandler{
+		service:      service,
+		githubSecret: githubSecret,
+		gitlabSecret: gitlabSecret,
+	}
+}
+
+// DeployResponse represents the outcome of a deployment webhook
+type DeployResponse struct {
+	Status        string `json:"status"` // "verifying" or "ignored"
+	CorrelationID string `json:"correlation_id,omitempty"`
+	BatchID       string `json:"batch_id,omitempty"` // Batch the checks are recorded under, for GET /api/v1/batches/{id}
+	Checks        int    `json:"checks"`
+	Message       string `json:"message,omitempty"`
+}
+
+// GitHub handles POST /api/v1/integrations/github
+func (h *DeployHandler) GitHub(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, h.githubSecret, deploy.ParseGitHub)
+}
+
+// GitLab handles POST /api/v1/integrations/gitlab
+func (h *DeployHandler) GitLab(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, h.gitlabSecret, deploy.ParseGitLab)
+}
+
+// handle verifies and parses a delivery, then starts verification of the deployed service
+func (h *DeployHandler) handle(
+	w http.ResponseWriter,
+	r *http.Request,
+	secret string,
+	parse func(http.Header, []byte, string) (*deploy.Event, error),
+) {
+	if secret == "" {
+		writeError(w, http.StatusNotFound, "integration is not configured")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxDeployPayloadBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	event, err := parse(r.Header, body, secret)
+	switch {
+	case errors.Is(err, deploy.ErrUnauthorized):
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	case errors.Is(err, deploy.ErrIgnored):
+		writeJSON(w, http.StatusAccepted, DeployResponse{Status: "ignored", Message: "not a successful deployment"})
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	correlationID := middleware.GetCorrelationID(r.Context())
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+
+	checks, batchID, err := h.service.Verify(r.Context(), event, correlationID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if checks == 0 {
+		writeJSON(w, http.StatusAccepted, DeployResponse{Status: "ignored", Message: "no checks tagged for " + event.Service})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, DeployResponse{
+		Status:        "verifying",
+		CorrelationID: correlationID,
+		BatchID:       batchID,
+		Checks:        checks,
+	})
+}
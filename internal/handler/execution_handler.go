@@ -1,26 +1,41 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-	"strings"
 
+	"github.com/dandantas/raven/internal/model"
 	"github.com/dandantas/raven/internal/service"
+	"github.com/dandantas/raven/internal/worker"
 	"github.com/dandantas/raven/pkg/middleware"
 	"github.com/google/uuid"
 )
 
 // ExecutionHandler handles health check execution operations
 type ExecutionHandler struct {
-	executor      *service.Executor
-	asyncExecutor *service.AsyncExecutor
+	executor            *service.Executor
+	asyncExecutor       *service.AsyncExecutor
+	workerPool          *worker.WorkerPool
+	maxBatchConcurrency int
 }
 
 // NewExecutionHandler creates a new execution handler
-func NewExecutionHandler(executor *service.Executor, asyncExecutor *service.AsyncExecutor) *ExecutionHandler {
+func NewExecutionHandler(
+	executor *service.Executor,
+	asyncExecutor *service.AsyncExecutor,
+	workerPool *worker.WorkerPool,
+	maxBatchConcurrency int,
+) *ExecutionHandler {
+	if maxBatchConcurrency <= 0 {
+		maxBatchConcurrency = 1
+	}
+
 	return &ExecutionHandler{
-		executor:      executor,
-		asyncExecutor: asyncExecutor,
+		executor:            executor,
+		asyncExecutor:       asyncExecutor,
+		workerPool:          workerPool,
+		maxBatchConcurrency: maxBatchConcurrency,
 	}
 }
 
@@ -33,8 +48,9 @@ type AsyncResponse struct {
 
 // BatchRequest represents batch execution request
 type BatchRequest struct {
-	ConfigIDs []string `json:"config_ids"`
-	Async     bool     `json:"async"`
+	ConfigIDs      []string `json:"config_ids"`
+	Async          bool     `json:"async"`
+	MaxConcurrency int      `json:"max_concurrency,omitempty"` // Capped by BATCH_MAX_CONCURRENCY
 }
 
 // BatchExecutionResult represents a single execution result in batch
@@ -48,6 +64,7 @@ type BatchExecutionResult struct {
 
 // BatchResponse represents batch execution response
 type BatchResponse struct {
+	BatchID    string                 `json:"batch_id"`
 	Total      int                    `json:"total"`
 	Successful int                    `json:"successful"`
 	Failed     int                    `json:"failed"`
@@ -56,13 +73,7 @@ type BatchResponse struct {
 
 // Execute handles POST /api/v1/health-checks/{id}/execute
 func (h *ExecutionHandler) Execute(w http.ResponseWriter, r *http.Request) {
-	// Extract config ID from path
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 5 {
-		writeError(w, http.StatusBadRequest, "Invalid path")
-		return
-	}
-	configID := parts[4]
+	configID := pathParam(r, "id")
 
 	// Check if async
 	async := r.URL.Query().Get("async") == "true"
@@ -73,9 +84,14 @@ func (h *ExecutionHandler) Execute(w http.ResponseWriter, r *http.Request) {
 		correlationID = uuid.New().String()
 	}
 
+	opts := service.ExecuteOptions{
+		TriggerType: model.TriggerManual,
+		TriggeredBy: triggeredBy(r, "api"),
+	}
+
 	if async {
 		// Async execution
-		jobID, err := h.asyncExecutor.SubmitJob(r.Context(), configID)
+		jobID, err := h.asyncExecutor.SubmitJob(r.Context(), configID, correlationID, opts)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -92,7 +108,7 @@ func (h *ExecutionHandler) Execute(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Sync execution
-	execution, err := h.executor.Execute(r.Context(), configID, correlationID)
+	execution, err := h.executor.Execute(r.Context(), configID, correlationID, opts)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -101,7 +117,9 @@ func (h *ExecutionHandler) Execute(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, execution)
 }
 
-// ExecuteBatch handles POST /api/v1/health-checks/execute-batch
+// ExecuteBatch handles POST /api/v1/health-checks/execute-batch.
+// Sync batches run concurrently on the worker pool; with ?stream=true each result
+// is written as an NDJSON line as soon as it completes, followed by the summary.
 func (h *ExecutionHandler) ExecuteBatch(w http.ResponseWriter, r *http.Request) {
 	var req BatchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -114,56 +132,165 @@ func (h *ExecutionHandler) ExecuteBatch(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	results := make([]BatchExecutionResult, 0, len(req.ConfigIDs))
+	// The batch ID and the member correlation IDs derived from it are generated here, since
+	// the request's correlation ID comes from the client and may be reused
+	batchID := uuid.New().String()
+
+	opts := service.ExecuteOptions{
+		BatchID:              batchID,
+		RequestCorrelationID: middleware.GetCorrelationID(r.Context()),
+		TriggerType:          model.TriggerAPI,
+		TriggeredBy:          triggeredBy(r, "batch:"+batchID),
+	}
+
+	if req.Async {
+		h.executeBatchAsync(w, r, req, batchID, opts)
+		return
+	}
+
+	// Per-request concurrency limit, capped by the server-wide maximum
+	concurrency := req.MaxConcurrency
+	if concurrency <= 0 || concurrency > h.maxBatchConcurrency {
+		concurrency = h.maxBatchConcurrency
+	}
+
+	stream := r.URL.Query().Get("stream") == "true"
+	var encoder *json.Encoder
+	var streamErr error // Set once the client can no longer be written to
+	controller := http.NewResponseController(w)
+	if stream {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		encoder = json.NewEncoder(w)
+	}
+
+	results := make([]BatchExecutionResult, len(req.ConfigIDs))
 	successful := 0
 	failed := 0
 
-	// Execute each config
-	for _, configID := range req.ConfigIDs {
-		correlationID := uuid.New().String()
-
-		if req.Async {
-			// Async execution
-			jobID, err := h.asyncExecutor.SubmitJob(r.Context(), configID)
-			if err != nil {
-				failed++
-				results = append(results, BatchExecutionResult{
-					ConfigID: configID,
-					Status:   "failed",
-					Error:    err.Error(),
-				})
-			} else {
-				successful++
-				results = append(results, BatchExecutionResult{
-					CorrelationID: jobID,
-					ConfigID:      configID,
-					Status:        "queued",
-				})
-			}
+	h.runBatch(r.Context(), req.ConfigIDs, opts, concurrency, func(index int, result BatchExecutionResult) {
+		results[index] = result
+		if result.Error != "" {
+			failed++
 		} else {
-			// Sync execution
-			execution, err := h.executor.Execute(r.Context(), configID, correlationID)
-			if err != nil {
-				failed++
-				results = append(results, BatchExecutionResult{
-					CorrelationID: correlationID,
-					ConfigID:      configID,
-					Status:        "failed",
-					Error:         err.Error(),
-				})
-			} else {
-				successful++
-				results = append(results, BatchExecutionResult{
-					CorrelationID:   execution.CorrelationID,
-					ConfigID:        configID,
-					Status:          execution.Status,
-					AlertsTriggered: len(execution.AlertsTriggered),
-				})
+			successful++
+		}
+
+		if stream && streamErr == nil {
+			streamErr = encoder.Encode(result)
+			if streamErr == nil {
+				streamErr = controller.Flush()
 			}
 		}
+	})
+
+	response := BatchResponse{
+		BatchID:    batchID,
+		Total:      len(req.ConfigIDs),
+		Successful: successful,
+		Failed:     failed,
+		Executions: results,
+	}
+
+	if stream {
+		if streamErr == nil {
+			encoder.Encode(response)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// runBatch executes batch members on the worker pool with at most concurrency jobs in
+// flight, calling onResult from the calling goroutine as each member completes
+func (h *ExecutionHandler) runBatch(
+	ctx context.Context,
+	configIDs []string,
+	opts service.ExecuteOptions,
+	concurrency int,
+	onResult func(index int, result BatchExecutionResult),
+) {
+	jobResults := make(chan worker.Result, len(configIDs))
+	slots := make(chan struct{}, concurrency)
+
+	go func() {
+		for i, configID := range configIDs {
+			slots <- struct{}{}
+
+			job := worker.Job{
+				ConfigID:             configID,
+				CorrelationID:        service.ChildCorrelationID(opts.BatchID, i+1),
+				BatchID:              opts.BatchID,
+				TriggeredBy:          opts.TriggeredBy,
+				RequestCorrelationID: opts.RequestCorrelationID,
+				Index:                i,
+				Context:              ctx,
+				Results:              jobResults,
+			}
+
+			if err := h.workerPool.Submit(job); err != nil {
+				jobResults <- worker.Result{
+					Error:         err,
+					ConfigID:      job.ConfigID,
+					CorrelationID: job.CorrelationID,
+					Index:         i,
+				}
+			}
+		}
+	}()
+
+	for range configIDs {
+		res := <-jobResults
+		<-slots
+
+		result := BatchExecutionResult{
+			CorrelationID: res.CorrelationID,
+			ConfigID:      res.ConfigID,
+		}
+		switch {
+		case res.Error != nil:
+			result.Status = "failed"
+			result.Error = res.Error.Error()
+		case res.Execution != nil:
+			result.CorrelationID = res.Execution.CorrelationID
+			result.Status = res.Execution.Status
+			result.AlertsTriggered = len(res.Execution.AlertsTriggered)
+		}
+
+		onResult(res.Index, result)
+	}
+}
+
+// executeBatchAsync queues every batch member for background execution
+func (h *ExecutionHandler) executeBatchAsync(w http.ResponseWriter, r *http.Request, req BatchRequest, batchID string, opts service.ExecuteOptions) {
+	results := make([]BatchExecutionResult, 0, len(req.ConfigIDs))
+	successful := 0
+	failed := 0
+
+	for i, configID := range req.ConfigIDs {
+		correlationID := service.ChildCorrelationID(batchID, i+1)
+
+		jobID, err := h.asyncExecutor.SubmitJob(r.Context(), configID, correlationID, opts)
+		if err != nil {
+			failed++
+			results = append(results, BatchExecutionResult{
+				ConfigID: configID,
+				Status:   "failed",
+				Error:    err.Error(),
+			})
+		} else {
+			successful++
+			results = append(results, BatchExecutionResult{
+				CorrelationID: jobID,
+				ConfigID:      configID,
+				Status:        "queued",
+			})
+		}
 	}
 
 	response := BatchResponse{
+		BatchID:    batchID,
 		Total:      len(req.ConfigIDs),
 		Successful: successful,
 		Failed:     failed,
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// fieldSelection describes the attributes requested through the fields= query parameter.
+// Paths use dots for nested attributes (e.g. "response.status_code"); a leading "-"
+// excludes an attribute instead. Includes and excludes cannot be mixed.
+type fieldSelection struct {
+	paths   [][]string
+	exclude bool
+}
+
+// parseFields parses the fields= query parameter, returning nil when it is absent
+func parseFields(r *http.Request) (*fieldSelection, error) {
+	value := r.URL.Query().Get("fields")
+	if value == "" {
+		return nil, nil
+	}
+
+	selection := &fieldSelection{}
+	for i, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		exclude := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+		if field == "" {
+			return nil, fmt.Errorf("invalid fields: empty field name")
+		}
+		if i == 0 {
+			selection.exclude = exclude
+		} else if exclude != selection.exclude {
+			return nil, fmt.Errorf("invalid fields: cannot mix included and excluded fields")
+		}
+		selection.paths = append(selection.paths, strings.Split(field, "."))
+	}
+
+	return selection, nil
+}
+
+// apply filters a decoded JSON object according to the selection
+func (s *fieldSelection) apply(object map[string]interface{}) map[string]interface{} {
+	if s.exclude {
+		for _, path := range s.paths {
+			removePath(object, path)
+		}
+		return object
+	}
+
+	result := make(map[string]interface{})
+	for _, path := range s.paths {
+		copyPath(result, object, path)
+	}
+	return result
+}
+
+// removePath deletes a dotted path from a decoded JSON object
+func removePath(object map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(object, path[0])
+		return
+	}
+	if child, ok := object[path[0]].(map[string]interface{}); ok {
+		removePath(child, path[1:])
+	}
+}
+
+// copyPath copies a dotted path from src into dst, creating intermediate objects
+func copyPath(dst, src map[string]interface{}, path []string) {
+	value, ok := src[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+	child, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	next, ok := dst[path[0]].(map[string]interface{})
+	if !ok {
+		next = make(map[string]interface{})
+		dst[path[0]] = next
+	}
+	copyPath(next, child, path[1:])
+}
+
+// writeJSONFields writes a JSON response limited to the fields requested by the client.
+// For list responses the selection applies to each entry of "results".
+func writeJSONFields(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	selection, err := parseFields(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if selection == nil {
+		writeJSON(w, statusCode, data)
+		return
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(encoded, &object); err != nil {
+		writeJSON(w, statusCode, data)
+		return
+	}
+
+	if results, ok := object["results"].([]interface{}); ok {
+		for i, entry := range results {
+			if item, ok := entry.(map[string]interface{}); ok {
+				results[i] = selection.apply(item)
+			}
+		}
+		writeJSON(w, statusCode, object)
+		return
+	}
+
+	writeJSON(w, statusCode, selection.apply(object))
+}
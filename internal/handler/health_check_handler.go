@@ -2,10 +2,12 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/dandantas/raven/internal/database"
 	"github.com/dandantas/raven/internal/model"
 	"github.com/dandantas/raven/internal/service"
 )
@@ -42,6 +44,22 @@ type ListResponse struct {
 	Results []model.HealthCheckListItem `json:"results"`
 }
 
+// AuditListResponse represents the audit log list response
+type AuditListResponse struct {
+	Total   int64              `json:"total"`
+	Page    int                `json:"page"`
+	Limit   int                `json:"limit"`
+	Results []model.AuditEntry `json:"results"`
+}
+
+// StateTransitionListResponse represents the state transition list response
+type StateTransitionListResponse struct {
+	Total   int64                   `json:"total"`
+	Page    int                     `json:"page"`
+	Limit   int                     `json:"limit"`
+	Results []model.StateTransition `json:"results"`
+}
+
 // DeleteResponse represents the delete response
 type DeleteResponse struct {
 	Message string `json:"message"`
@@ -85,8 +103,7 @@ func (h *HealthCheckHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // Get handles GET /api/v1/health-checks/{id}
 func (h *HealthCheckHandler) Get(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/v1/health-checks/")
-	id = strings.Split(id, "/")[0]
+	id := pathParam(r, "id")
 
 	config, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
@@ -94,7 +111,114 @@ func (h *HealthCheckHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, config)
+	writeJSONFields(w, r, http.StatusOK, config)
+}
+
+// NameSearchResponse lists the health checks whose name contains the query
+type NameSearchResponse struct {
+	Query   string                      `json:"query"`
+	Limit   int                         `json:"limit"`
+	Results []model.HealthCheckListItem `json:"results"`
+}
+
+// GetByName handles GET /api/v1/health-checks/by-name/{name}. The name matches ignoring
+// case; with ?fuzzy=true every check whose name contains it is listed instead.
+func (h *HealthCheckHandler) GetByName(w http.ResponseWriter, r *http.Request) {
+	name := pathParam(r, "name")
+
+	if r.URL.Query().Get("fuzzy") == "true" {
+		limit := parseQueryInt(r, "limit", 20)
+
+		// Enforce limit bounds
+		if limit > 100 {
+			limit = 100
+		}
+		if limit < 1 {
+			limit = 20
+		}
+
+		items, err := h.service.SearchByName(r.Context(), name, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSONFields(w, r, http.StatusOK, NameSearchResponse{Query: name, Limit: limit, Results: items})
+		return
+	}
+
+	config, err := h.service.GetByName(r.Context(), name)
+	switch {
+	case errors.Is(err, database.ErrHealthCheckNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, service.ErrAmbiguousName):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, config)
+}
+
+// Bulk handles POST /api/v1/health-checks/bulk
+func (h *HealthCheckHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	var request model.BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	result, err := h.service.Bulk(r.Context(), &request, triggeredBy(r, "api"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "validation failed") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// SchedulePreview handles GET /api/v1/health-checks/{id}/schedule/preview
+func (h *HealthCheckHandler) SchedulePreview(w http.ResponseWriter, r *http.Request) {
+	id := pathParam(r, "id")
+
+	count := parseQueryInt(r, "count", 10)
+	if count < 1 {
+		count = 1
+	}
+	// Enforce max count
+	if count > 100 {
+		count = 100
+	}
+
+	loc := time.UTC
+	if tz := r.URL.Query().Get("timezone"); tz != "" {
+		parsed, err := time.LoadLocation(tz)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid timezone: "+err.Error())
+			return
+		}
+		loc = parsed
+	}
+
+	preview, err := h.service.SchedulePreview(r.Context(), id, count, loc)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preview)
 }
 
 // List handles GET /api/v1/health-checks
@@ -114,8 +238,12 @@ func (h *HealthCheckHandler) List(w http.ResponseWriter, r *http.Request) {
 		limit = 100
 	}
 
-	items, total, err := h.service.List(r.Context(), enabled, tags, page, limit)
+	items, total, err := h.service.List(r.Context(), enabled, tags, r.URL.Query().Get("sort"), page, limit)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -127,12 +255,128 @@ func (h *HealthCheckHandler) List(w http.ResponseWriter, r *http.Request) {
 		Results: items,
 	}
 
-	writeJSON(w, http.StatusOK, response)
+	writeJSONFields(w, r, http.StatusOK, response)
+}
+
+// Transitions handles GET /api/v1/health-checks/{id}/transitions
+func (h *HealthCheckHandler) Transitions(w http.ResponseWriter, r *http.Request) {
+	page := parseQueryInt(r, "page", 1)
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	transitions, total, err := h.service.ListTransitions(r.Context(), pathParam(r, "id"), page, limit)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, StateTransitionListResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Results: transitions,
+	})
+}
+
+// Lint handles POST /api/v1/health-checks/lint
+func (h *HealthCheckHandler) Lint(w http.ResponseWriter, r *http.Request) {
+	var config model.HealthCheckConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.service.Lint(r.Context(), &config))
+}
+
+// Import handles POST /api/v1/health-checks/import
+func (h *HealthCheckHandler) Import(w http.ResponseWriter, r *http.Request) {
+	var req model.ImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	result, err := h.service.Import(r.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+		} else {
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if result.Created > 0 {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, result)
+}
+
+// Enable handles POST /api/v1/health-checks/{id}/enable
+func (h *HealthCheckHandler) Enable(w http.ResponseWriter, r *http.Request) {
+	config, err := h.service.Enable(r.Context(), pathParam(r, "id"), triggeredBy(r, "api"))
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, config)
+}
+
+// Audit handles GET /api/v1/health-checks/{id}/audit
+func (h *HealthCheckHandler) Audit(w http.ResponseWriter, r *http.Request) {
+	page := parseQueryInt(r, "page", 1)
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	entries, total, err := h.service.ListAudit(r.Context(), pathParam(r, "id"), page, limit)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, AuditListResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Results: entries,
+	})
 }
 
 // Update handles PUT /api/v1/health-checks/{id}
 func (h *HealthCheckHandler) Update(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/v1/health-checks/")
+	id := pathParam(r, "id")
 
 	var config model.HealthCheckConfig
 	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
@@ -141,6 +385,10 @@ func (h *HealthCheckHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.service.Update(r.Context(), id, &config); err != nil {
+		if err.Error() == "health check not found" {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -150,7 +398,7 @@ func (h *HealthCheckHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Delete handles DELETE /api/v1/health-checks/{id}
 func (h *HealthCheckHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/v1/health-checks/")
+	id := pathParam(r, "id")
 
 	if err := h.service.Delete(r.Context(), id); err != nil {
 		writeError(w, http.StatusNotFound, err.Error())
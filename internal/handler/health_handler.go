@@ -5,31 +5,38 @@ import (
 	"time"
 
 	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/scheduler"
 )
 
 // HealthHandler handles service health and readiness checks
 type HealthHandler struct {
-	db        *database.MongoDB
-	startTime time.Time
-	version   string
+	db         *database.MongoDB
+	retryQueue *database.RetryQueue
+	scheduler  *scheduler.Scheduler
+	startTime  time.Time
+	version    string
 }
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler(db *database.MongoDB, version string) *HealthHandler {
+func NewHealthHandler(db *database.MongoDB, retryQueue *database.RetryQueue, sched *scheduler.Scheduler, version string) *HealthHandler {
 	return &HealthHandler{
-		db:        db,
-		startTime: time.Now(),
-		version:   version,
+		db:         db,
+		retryQueue: retryQueue,
+		scheduler:  sched,
+		startTime:  time.Now(),
+		version:    version,
 	}
 }
 
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status        string `json:"status"`
-	Version       string `json:"version"`
-	Timestamp     string `json:"timestamp"`
-	MongoDB       string `json:"mongodb"`
-	UptimeSeconds int64  `json:"uptime_seconds"`
+	Status        string                    `json:"status"` // "healthy", or "degraded" while writes are pending retry, after any was dropped or while the scheduler is behind
+	Version       string                    `json:"version"`
+	Timestamp     string                    `json:"timestamp"`
+	MongoDB       string                    `json:"mongodb"`
+	UptimeSeconds int64                     `json:"uptime_seconds"`
+	Persistence   *database.RetryQueueStats `json:"persistence,omitempty"`
+	Scheduler     *scheduler.Backpressure   `json:"scheduler,omitempty"`
 }
 
 // ReadyResponse represents the readiness check response
@@ -54,6 +61,25 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 		UptimeSeconds: int64(time.Since(h.startTime).Seconds()),
 	}
 
+	// Surface failed history and alert log writes, so data loss isn't silent
+	if h.retryQueue != nil {
+		stats := h.retryQueue.Stats()
+		response.Persistence = &stats
+		if !stats.Healthy() {
+			response.Status = "degraded"
+		}
+	}
+
+	// Surface a growing backlog of late scheduled checks before schedules silently drift
+	if h.scheduler != nil {
+		if pressure := h.scheduler.Backpressure(); !pressure.LastTickAt.IsZero() {
+			response.Scheduler = &pressure
+			if pressure.Behind {
+				response.Status = "degraded"
+			}
+		}
+	}
+
 	writeJSON(w, http.StatusOK, response)
 }
 
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/service"
+)
+
+// HeartbeatHandler handles heartbeat monitor CRUD and pings
+type HeartbeatHandler struct {
+	service *service.HeartbeatService
+}
+
+// NewHeartbeatHandler creates a new heartbeat handler
+func NewHeartbeatHandler(service *service.HeartbeatService) *HeartbeatHandler {
+	return &HeartbeatHandler{
+		service: service,
+	}
+}
+
+// HeartbeatListResponse represents the heartbeat list response
+type HeartbeatListResponse struct {
+	Total   int64             `json:"total"`
+	Page    int               `json:"page"`
+	Limit   int               `json:"limit"`
+	Results []model.Heartbeat `json:"results"`
+}
+
+// HeartbeatPingResponse represents the response to a heartbeat ping
+type HeartbeatPingResponse struct {
+	Status     string    `json:"status"`
+	ExpectedBy time.Time `json:"expected_by"`
+}
+
+// Create handles POST /api/v1/heartbeats
+func (h *HeartbeatHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var heartbeat model.Heartbeat
+	if err := json.NewDecoder(r.Body).Decode(&heartbeat); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.Create(r.Context(), &heartbeat); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, heartbeat)
+}
+
+// Get handles GET /api/v1/heartbeats/{id}
+func (h *HeartbeatHandler) Get(w http.ResponseWriter, r *http.Request) {
+	heartbeat, err := h.service.GetByID(r.Context(), pathParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, heartbeat)
+}
+
+// List handles GET /api/v1/heartbeats
+func (h *HeartbeatHandler) List(w http.ResponseWriter, r *http.Request) {
+	enabled := parseQueryBool(r, "enabled")
+	status := r.URL.Query().Get("status")
+	page := parseQueryInt(r, "page", 1)
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	heartbeats, total, err := h.service.List(r.Context(), enabled, status, page, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, HeartbeatListResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Results: heartbeats,
+	})
+}
+
+// Update handles PUT /api/v1/heartbeats/{id}
+func (h *HeartbeatHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var heartbeat model.Heartbeat
+	if err := json.NewDecoder(r.Body).Decode(&heartbeat); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.Update(r.Context(), pathParam(r, "id"), &heartbeat); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		case strings.Contains(err.Error(), "already exists"):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, heartbeat)
+}
+
+// Delete handles DELETE /api/v1/heartbeats/{id}
+func (h *HeartbeatHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), pathParam(r, "id")); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DeleteResponse{
+		Message: "Heartbeat deleted successfully",
+	})
+}
+
+// Ping handles POST /api/v1/heartbeats/{id}
+func (h *HeartbeatHandler) Ping(w http.ResponseWriter, r *http.Request) {
+	heartbeat, err := h.service.Ping(r.Context(), pathParam(r, "id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.HasPrefix(err.Error(), "invalid") {
+			writeError(w, http.StatusNotFound, "heartbeat not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, HeartbeatPingResponse{
+		Status:     heartbeat.Status,
+		ExpectedBy: heartbeat.ExpectedBy,
+	})
+}
@@ -31,10 +31,18 @@ type ExecutionListResponse struct {
 // List handles GET /api/v1/executions
 func (h *HistoryHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
-	configID := r.URL.Query().Get("config_id")
-	status := r.URL.Query().Get("status")
-	from := r.URL.Query().Get("from")
-	to := r.URL.Query().Get("to")
+	query := r.URL.Query()
+	filter := service.ExecutionFilter{
+		ConfigID:    query.Get("config_id"),
+		Status:      query.Get("status"),
+		From:        query.Get("from"),
+		To:          query.Get("to"),
+		TriggerType: query.Get("trigger_type"),
+		TriggeredBy: query.Get("triggered_by"),
+		PodID:       query.Get("pod_id"),
+		Region:      query.Get("region"),
+		Sort:        query.Get("sort"),
+	}
 	page := parseQueryInt(r, "page", 1)
 	limit := parseQueryInt(r, "limit", 20)
 
@@ -43,8 +51,12 @@ func (h *HistoryHandler) List(w http.ResponseWriter, r *http.Request) {
 		limit = 100
 	}
 
-	summaries, total, err := h.service.List(r.Context(), configID, status, from, to, page, limit)
+	summaries, total, err := h.service.List(r.Context(), filter, page, limit)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -56,12 +68,12 @@ func (h *HistoryHandler) List(w http.ResponseWriter, r *http.Request) {
 		Results: summaries,
 	}
 
-	writeJSON(w, http.StatusOK, response)
+	writeJSONFields(w, r, http.StatusOK, response)
 }
 
 // Get handles GET /api/v1/executions/{correlation_id}
 func (h *HistoryHandler) Get(w http.ResponseWriter, r *http.Request) {
-	correlationID := strings.TrimPrefix(r.URL.Path, "/api/v1/executions/")
+	correlationID := pathParam(r, "correlation_id")
 
 	execution, err := h.service.GetByCorrelationID(r.Context(), correlationID)
 	if err != nil {
@@ -69,5 +81,43 @@ func (h *HistoryHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, execution)
+	writeJSONFields(w, r, http.StatusOK, execution)
+}
+
+// GetBatch handles GET /api/v1/batches/{id}
+func (h *HistoryHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	batchID := pathParam(r, "id")
+	if batchID == "" {
+		writeError(w, http.StatusBadRequest, "batch ID is required")
+		return
+	}
+
+	batch, err := h.service.GetBatch(r.Context(), batchID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, batch)
+}
+
+// GetRegions handles GET /api/v1/health-checks/{id}/regions
+func (h *HistoryHandler) GetRegions(w http.ResponseWriter, r *http.Request) {
+	configID := pathParam(r, "id")
+
+	statuses, err := h.service.RegionStatus(r.Context(), configID)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, statuses)
 }
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dandantas/raven/internal/database"
+)
+
+// IndexHandler reports and reconciles MongoDB index drift
+type IndexHandler struct {
+	db *database.MongoDB
+}
+
+// NewIndexHandler creates a new index handler
+func NewIndexHandler(db *database.MongoDB) *IndexHandler {
+	return &IndexHandler{db: db}
+}
+
+// Drift lists the differences between the desired and existing indexes without changing them
+func (h *IndexHandler) Drift(w http.ResponseWriter, r *http.Request) {
+	report, err := database.ReconcileIndexes(r.Context(), h.db, false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// Reconcile creates missing indexes and rebuilds changed ones, returning what was done
+func (h *IndexHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	report, err := database.ReconcileIndexes(r.Context(), h.db, true)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/service"
+)
+
+// MetricHandler handles extracted metric queries
+type MetricHandler struct {
+	service *service.MetricService
+}
+
+// NewMetricHandler creates a new metric handler
+func NewMetricHandler(service *service.MetricService) *MetricHandler {
+	return &MetricHandler{
+		service: service,
+	}
+}
+
+// Series handles GET /api/v1/health-checks/{id}/metrics
+func (h *MetricHandler) Series(w http.ResponseWriter, r *http.Request) {
+	id := pathParam(r, "id")
+
+	window := 24 * time.Hour
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		parsed, err := model.ParseRelativeDuration(windowStr)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid window: "+windowStr)
+			return
+		}
+		window = parsed
+	}
+
+	series, err := h.service.Series(r.Context(), id, r.URL.Query().Get("name"), window)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, series)
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"net"
 	"net/http"
+	"net/http/pprof"
 	"strings"
 
 	"github.com/dandantas/raven/pkg/middleware"
@@ -14,7 +16,25 @@ type Router struct {
 	historyHandler     *HistoryHandler
 	alertHandler       *AlertHandler
 	healthHandler      *HealthHandler
+	statsHandler       *StatsHandler
+	suiteHandler       *SuiteHandler
+	agentHandler       *AgentHandler
+	channelHandler     *ChannelHandler
+	authProfileHandler *AuthProfileHandler
+	metricHandler      *MetricHandler
+	triggerHandler     *TriggerHandler
+	deployHandler      *DeployHandler
+	heartbeatHandler   *HeartbeatHandler
+	indexHandler       *IndexHandler
+	schedulerHandler   *SchedulerHandler
+	circuitBreakers    *CircuitBreakerHandler
+	prometheus         http.Handler
 	corsConfig         middleware.CORSConfig
+	loggingConfig      middleware.LoggingConfig
+	trustedProxies     []*net.IPNet
+	adminListener      bool
+	apiKeys            map[string]string
+	adminKeys          []string
 }
 
 // NewRouter creates a new router
@@ -24,7 +44,21 @@ func NewRouter(
 	historyHandler *HistoryHandler,
 	alertHandler *AlertHandler,
 	healthHandler *HealthHandler,
+	statsHandler *StatsHandler,
+	suiteHandler *SuiteHandler,
+	agentHandler *AgentHandler,
+	channelHandler *ChannelHandler,
+	authProfileHandler *AuthProfileHandler,
+	metricHandler *MetricHandler,
+	triggerHandler *TriggerHandler,
+	deployHandler *DeployHandler,
+	heartbeatHandler *HeartbeatHandler,
+	indexHandler *IndexHandler,
+	schedulerHandler *SchedulerHandler,
+	circuitBreakers *CircuitBreakerHandler,
+	prometheus http.Handler,
 	corsConfig middleware.CORSConfig,
+	apiKeys map[string]string,
 ) *Router {
 	return &Router{
 		healthCheckHandler: healthCheckHandler,
@@ -32,7 +66,85 @@ func NewRouter(
 		historyHandler:     historyHandler,
 		alertHandler:       alertHandler,
 		healthHandler:      healthHandler,
+		statsHandler:       statsHandler,
+		suiteHandler:       suiteHandler,
+		agentHandler:       agentHandler,
+		channelHandler:     channelHandler,
+		authProfileHandler: authProfileHandler,
+		metricHandler:      metricHandler,
+		triggerHandler:     triggerHandler,
+		deployHandler:      deployHandler,
+		heartbeatHandler:   heartbeatHandler,
+		indexHandler:       indexHandler,
+		schedulerHandler:   schedulerHandler,
+		circuitBreakers:    circuitBreakers,
+		prometheus:         prometheus,
 		corsConfig:         corsConfig,
+		apiKeys:            apiKeys,
+	}
+}
+
+// SetLogging configures request logging, including optional body capture
+func (rt *Router) SetLogging(config middleware.LoggingConfig) {
+	rt.loggingConfig = config
+}
+
+// SetTrustedProxies sets the load balancers whose forwarding headers identify the client
+func (rt *Router) SetTrustedProxies(proxies []*net.IPNet) {
+	rt.trustedProxies = proxies
+}
+
+// SetAdminListener moves health, metrics and profiling endpoints off the public handler
+// onto AdminHandler, which is served on a separate port
+func (rt *Router) SetAdminListener(enabled bool) {
+	rt.adminListener = enabled
+}
+
+// SetAdminKeys sets the names of the API keys allowed to call admin endpoints on the public handler
+func (rt *Router) SetAdminKeys(names []string) {
+	rt.adminKeys = names
+}
+
+// AdminHandler returns the handler for operational endpoints on the admin port
+func (rt *Router) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	rt.registerOperational(mux)
+
+	// The admin port is cluster-internal, so administration needs no admin key there
+	rt.registerAdministration(mux, func(h http.HandlerFunc) http.Handler { return h })
+
+	// Profiling
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+
+	mux.Handle("/", notFound(mux))
+
+	return middleware.Recovery(mux)
+}
+
+// registerOperational registers health and metrics endpoints
+func (rt *Router) registerOperational(mux *http.ServeMux) {
+	mux.HandleFunc("GET /health", rt.healthHandler.Health)
+	mux.HandleFunc("GET /ready", rt.healthHandler.Ready)
+	mux.Handle("GET /metrics", rt.prometheus)
+}
+
+// registerAdministration registers admin endpoints, each wrapped by guard
+func (rt *Router) registerAdministration(mux *http.ServeMux, guard func(http.HandlerFunc) http.Handler) {
+	mux.Handle("GET /api/v1/admin/indexes", guard(rt.indexHandler.Drift))
+	mux.Handle("POST /api/v1/admin/indexes/reconcile", guard(rt.indexHandler.Reconcile))
+	mux.Handle("POST /api/v1/admin/scheduler/tick", guard(rt.schedulerHandler.Tick))
+	mux.Handle("GET /api/v1/admin/circuit-breakers", guard(rt.circuitBreakers.List))
+	mux.Handle("POST /api/v1/admin/circuit-breakers/{host}/reset", guard(rt.circuitBreakers.Reset))
+
+	// Simulated clock, only when the scheduler runs on one for testing
+	if rt.schedulerHandler.clock != nil {
+		mux.Handle("GET /api/v1/admin/clock", guard(rt.schedulerHandler.Clock))
+		mux.Handle("POST /api/v1/admin/clock/advance", guard(rt.schedulerHandler.AdvanceClock))
 	}
 }
 
@@ -40,77 +152,143 @@ func NewRouter(
 func (rt *Router) Handler() http.Handler {
 	mux := http.NewServeMux()
 
-	// Health endpoints (no middleware)
-	mux.HandleFunc("/health", rt.healthHandler.Health)
-	mux.HandleFunc("/ready", rt.healthHandler.Ready)
+	// Health endpoints, unless they are served on the admin port
+	if !rt.adminListener {
+		rt.registerOperational(mux)
+	}
+
+	// Health checks
+	mux.HandleFunc("GET /api/v1/health-checks", rt.healthCheckHandler.List)
+	mux.HandleFunc("POST /api/v1/health-checks", rt.healthCheckHandler.Create)
+	mux.HandleFunc("POST /api/v1/health-checks/execute-batch", rt.executionHandler.ExecuteBatch)
+	mux.HandleFunc("POST /api/v1/health-checks/lint", rt.healthCheckHandler.Lint)
+	mux.HandleFunc("POST /api/v1/health-checks/import", rt.healthCheckHandler.Import)
+	mux.HandleFunc("POST /api/v1/health-checks/bulk", rt.healthCheckHandler.Bulk)
+	mux.HandleFunc("GET /api/v1/health-checks/{id}", rt.healthCheckHandler.Get)
+	mux.HandleFunc("PUT /api/v1/health-checks/{id}", rt.healthCheckHandler.Update)
+	mux.HandleFunc("DELETE /api/v1/health-checks/{id}", rt.healthCheckHandler.Delete)
+	mux.HandleFunc("POST /api/v1/health-checks/{id}/execute", rt.executionHandler.Execute)
+	mux.HandleFunc("GET /api/v1/health-checks/{id}/schedule/preview", rt.healthCheckHandler.SchedulePreview)
+	mux.HandleFunc("GET /api/v1/health-checks/{id}/metrics", rt.metricHandler.Series)
+	mux.HandleFunc("GET /api/v1/health-checks/{id}/regions", rt.historyHandler.GetRegions)
+	mux.HandleFunc("GET /api/v1/health-checks/{id}/transitions", rt.healthCheckHandler.Transitions)
+	mux.HandleFunc("GET /api/v1/health-checks/{id}/downtimes", rt.statsHandler.Downtimes)
+	mux.HandleFunc("POST /api/v1/health-checks/{id}/enable", rt.healthCheckHandler.Enable)
+	mux.HandleFunc("GET /api/v1/health-checks/{id}/audit", rt.healthCheckHandler.Audit)
+	mux.HandleFunc("POST /api/v1/health-checks/{id}/trigger-token", rt.triggerHandler.IssueToken)
+
+	// External triggers
+	mux.HandleFunc("POST /api/v1/triggers/{token}", rt.triggerHandler.Trigger)
+	mux.HandleFunc("POST /api/v1/integrations/github", rt.deployHandler.GitHub)
+	mux.HandleFunc("POST /api/v1/integrations/gitlab", rt.deployHandler.GitLab)
+
+	// Executions and alerts
+	mux.HandleFunc("GET /api/v1/executions", rt.historyHandler.List)
+	mux.HandleFunc("GET /api/v1/executions/{correlation_id}", rt.historyHandler.Get)
+	mux.HandleFunc("GET /api/v1/batches/{id}", rt.historyHandler.GetBatch)
+	mux.HandleFunc("GET /api/v1/alerts", rt.alertHandler.List)
+	mux.HandleFunc("GET /api/v1/alerts/export", rt.alertHandler.Export)
+	mux.HandleFunc("POST /api/v1/alerts/ingest", rt.alertHandler.Ingest)
+	mux.HandleFunc("GET /api/v1/alerts/{id}", rt.alertHandler.Get)
+	mux.HandleFunc("POST /api/v1/alerts/{id}/comments", rt.alertHandler.AddComment)
+	mux.HandleFunc("PATCH /api/v1/alerts/{id}/acknowledge", rt.alertHandler.Acknowledge)
+	mux.HandleFunc("GET /api/v1/alerts/{id}/ack", rt.alertHandler.AcknowledgeByLink)
+	mux.HandleFunc("GET /api/v1/stats/overview", rt.statsHandler.Overview)
+	mux.HandleFunc("GET /api/v1/stats/downtime", rt.statsHandler.Downtime)
+	mux.HandleFunc("GET /api/v1/reports/noisy-checks", rt.statsHandler.NoisyChecks)
+	mux.HandleFunc("GET /api/v1/reports/noisy-rules", rt.statsHandler.NoisyRules)
+
+	// Suites
+	mux.HandleFunc("GET /api/v1/suites", rt.suiteHandler.List)
+	mux.HandleFunc("POST /api/v1/suites", rt.suiteHandler.Create)
+	mux.HandleFunc("GET /api/v1/suites/{id}", rt.suiteHandler.Get)
+	mux.HandleFunc("PUT /api/v1/suites/{id}", rt.suiteHandler.Update)
+	mux.HandleFunc("DELETE /api/v1/suites/{id}", rt.suiteHandler.Delete)
+	mux.HandleFunc("POST /api/v1/suites/{id}/run", rt.suiteHandler.Run)
+	mux.HandleFunc("GET /api/v1/suites/{id}/results", rt.suiteHandler.Results)
+
+	// Heartbeats; external jobs ping POST /api/v1/heartbeats/{id}
+	mux.HandleFunc("GET /api/v1/heartbeats", rt.heartbeatHandler.List)
+	mux.HandleFunc("POST /api/v1/heartbeats", rt.heartbeatHandler.Create)
+	mux.HandleFunc("GET /api/v1/heartbeats/{id}", rt.heartbeatHandler.Get)
+	mux.HandleFunc("POST /api/v1/heartbeats/{id}", rt.heartbeatHandler.Ping)
+	mux.HandleFunc("PUT /api/v1/heartbeats/{id}", rt.heartbeatHandler.Update)
+	mux.HandleFunc("DELETE /api/v1/heartbeats/{id}", rt.heartbeatHandler.Delete)
+
+	// Agents
+	mux.HandleFunc("POST /api/v1/agents/lease", rt.agentHandler.Lease)
+	mux.HandleFunc("POST /api/v1/agents/results", rt.agentHandler.Report)
 
-	// API endpoints
-	mux.HandleFunc("/api/v1/health-checks", rt.handleHealthChecks)
-	mux.HandleFunc("/api/v1/health-checks/", rt.handleHealthChecksWithID)
-	mux.HandleFunc("/api/v1/health-checks/execute-batch", rt.executionHandler.ExecuteBatch)
-	mux.HandleFunc("/api/v1/executions", rt.historyHandler.List)
-	mux.HandleFunc("/api/v1/executions/", rt.historyHandler.Get)
-	mux.HandleFunc("/api/v1/alerts", rt.alertHandler.List)
-	mux.HandleFunc("/api/v1/alerts/", rt.handleAlertsWithID)
+	// Notification channels
+	mux.HandleFunc("GET /api/v1/notification-channels", rt.channelHandler.List)
+	mux.HandleFunc("POST /api/v1/notification-channels", rt.channelHandler.Create)
+	mux.HandleFunc("GET /api/v1/notification-channels/{id}", rt.channelHandler.Get)
+	mux.HandleFunc("PUT /api/v1/notification-channels/{id}", rt.channelHandler.Update)
+	mux.HandleFunc("DELETE /api/v1/notification-channels/{id}", rt.channelHandler.Delete)
+	mux.HandleFunc("GET /api/v1/notification-channels/{id}/stats", rt.channelHandler.Stats)
+
+	// Auth profiles
+	mux.HandleFunc("GET /api/v1/auth-profiles", rt.authProfileHandler.List)
+	mux.HandleFunc("POST /api/v1/auth-profiles", rt.authProfileHandler.Create)
+	mux.HandleFunc("GET /api/v1/auth-profiles/{id}", rt.authProfileHandler.Get)
+	mux.HandleFunc("PUT /api/v1/auth-profiles/{id}", rt.authProfileHandler.Update)
+	mux.HandleFunc("DELETE /api/v1/auth-profiles/{id}", rt.authProfileHandler.Delete)
+
+	// Administration, restricted to admin API keys
+	requireAdmin := middleware.RequireAdmin(rt.adminKeys)
+	rt.registerAdministration(mux, func(h http.HandlerFunc) http.Handler { return requireAdmin(h) })
+
+	// Unmatched requests get JSON errors instead of the mux's plain-text ones
+	mux.Handle("/", notFound(mux))
 
 	// Apply middleware (CORS first to handle preflight requests)
-	handler := middleware.CORS(rt.corsConfig)(mux)
+	handler := middleware.APIKeys(rt.apiKeys)(rt.nameLookups(mux))
+	handler = middleware.CORS(rt.corsConfig)(handler)
+	handler = middleware.Gzip(handler)
 	handler = middleware.Recovery(handler)
-	handler = middleware.Logging(handler)
+	handler = middleware.LoggingWithConfig(rt.loggingConfig)(handler)
+	handler = middleware.RealIP(rt.trustedProxies)(handler)
 	handler = middleware.CorrelationID(handler)
 
 	return handler
 }
 
-// handleHealthChecks routes health check collection endpoints
-func (rt *Router) handleHealthChecks(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		rt.healthCheckHandler.List(w, r)
-	case http.MethodPost:
-		rt.healthCheckHandler.Create(w, r)
-	default:
-		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	}
-}
-
-// handleHealthChecksWithID routes health check individual endpoints
-func (rt *Router) handleHealthChecksWithID(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/api/v1/health-checks/")
-
-	// Check if this is an execute endpoint
-	if strings.HasSuffix(path, "/execute") {
-		rt.executionHandler.Execute(w, r)
-		return
-	}
+// nameLookups serves lookups by name ahead of next. The mux can't register them itself:
+// by-name/{name} and the GET {id}/... routes both match paths like by-name/regions, and
+// neither pattern is more specific, which the mux rejects.
+func (rt *Router) nameLookups(next http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/health-checks/by-name/{name}", rt.healthCheckHandler.GetByName)
 
-	// Handle CRUD operations
-	switch r.Method {
-	case http.MethodGet:
-		rt.healthCheckHandler.Get(w, r)
-	case http.MethodPut:
-		rt.healthCheckHandler.Update(w, r)
-	case http.MethodDelete:
-		rt.healthCheckHandler.Delete(w, r)
-	default:
-		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
-// handleAlertsWithID routes alert individual endpoints
-func (rt *Router) handleAlertsWithID(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/api/v1/alerts/")
+// routedMethods are the methods checked when building a 405 Allow header
+var routedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// notFound answers requests no route matched: 405 when the path exists for other methods, 404 otherwise
+func notFound(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var allowed []string
+		for _, method := range routedMethods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := mux.Handler(probe); pattern != "" && pattern != "/" {
+				allowed = append(allowed, method)
+			}
+		}
 
-	// Check if this is an acknowledge endpoint
-	if strings.HasSuffix(path, "/acknowledge") {
-		if r.Method != http.MethodPatch && r.Method != http.MethodOptions {
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
 			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 			return
 		}
-		rt.alertHandler.Acknowledge(w, r)
-		return
-	}
-
-	// For other alert operations (if needed in the future)
-	writeError(w, http.StatusNotFound, "Endpoint not found")
+		writeError(w, http.StatusNotFound, "Endpoint not found")
+	})
 }
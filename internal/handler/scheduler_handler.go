@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dandantas/raven/internal/clock"
+	"github.com/dandantas/raven/internal/scheduler"
+	"github.com/dandantas/raven/pkg/middleware"
+)
+
+// SchedulerHandler drives the scheduler on demand
+type SchedulerHandler struct {
+	scheduler *scheduler.Scheduler
+	clock     *clock.Simulated // Set when the scheduler runs on a simulated clock
+}
+
+// NewSchedulerHandler creates a new scheduler handler; clock is nil unless the scheduler
+// runs on a simulated clock
+func NewSchedulerHandler(scheduler *scheduler.Scheduler, clock *clock.Simulated) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: scheduler, clock: clock}
+}
+
+// AdvanceClockRequest moves the simulated clock either by a duration or to a time
+type AdvanceClockRequest struct {
+	By string    `json:"by,omitempty"` // Go duration, e.g. "90s" or "24h"
+	To time.Time `json:"to,omitempty"`
+}
+
+// ClockResponse is the simulated time, with the tick run after advancing it
+type ClockResponse struct {
+	Now  time.Time             `json:"now"`
+	Tick *scheduler.TickReport `json:"tick,omitempty"`
+}
+
+// Tick forces an immediate scheduling pass instead of waiting for the next tick. With
+// ?wait=true the response is sent once the started executions have finished.
+func (h *SchedulerHandler) Tick(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.tick(w, r, r.URL.Query().Get("wait") == "true")
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// Clock returns the simulated time
+func (h *SchedulerHandler) Clock(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, ClockResponse{Now: h.clock.Now().UTC()})
+}
+
+// AdvanceClock moves the simulated clock forward, then runs a tick and waits for the
+// executions it started, so tests observe a deterministic outcome
+func (h *SchedulerHandler) AdvanceClock(w http.ResponseWriter, r *http.Request) {
+	var req AdvanceClockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var now time.Time
+	switch {
+	case req.By != "" && !req.To.IsZero():
+		writeError(w, http.StatusBadRequest, "Set either by or to, not both")
+		return
+	case req.By != "":
+		by, err := time.ParseDuration(req.By)
+		if err != nil || by < 0 {
+			writeError(w, http.StatusBadRequest, "by must be a non-negative duration such as 90s")
+			return
+		}
+		now = h.clock.Advance(by)
+	case !req.To.IsZero():
+		if req.To.Before(h.clock.Now()) {
+			writeError(w, http.StatusBadRequest, "to must not be before the simulated time")
+			return
+		}
+		now = h.clock.Set(req.To)
+	default:
+		writeError(w, http.StatusBadRequest, "by or to is required")
+		return
+	}
+
+	report, ok := h.tick(w, r, true)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ClockResponse{Now: now.UTC(), Tick: report})
+}
+
+// tick runs a scheduling pass, optionally waiting for its executions, and writes an error
+// response when it can't
+func (h *SchedulerHandler) tick(w http.ResponseWriter, r *http.Request, wait bool) (*scheduler.TickReport, bool) {
+	report, err := h.scheduler.Tick(r.Context(), middleware.GetPrincipal(r.Context()))
+	if err == nil && wait {
+		err = report.Wait(r.Context())
+	}
+	if errors.Is(err, scheduler.ErrNotRunning) {
+		writeError(w, http.StatusConflict, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return report, true
+}
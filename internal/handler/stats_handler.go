@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/service"
+)
+
+// StatsHandler handles aggregated statistics queries
+type StatsHandler struct {
+	service *service.StatsService
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(service *service.StatsService) *StatsHandler {
+	return &StatsHandler{
+		service: service,
+	}
+}
+
+// Overview handles GET /api/v1/stats/overview
+func (h *StatsHandler) Overview(w http.ResponseWriter, r *http.Request) {
+
+	// Parse query parameters
+	configID := r.URL.Query().Get("config_id")
+	bucket := r.URL.Query().Get("bucket")
+	if bucket == "" {
+		bucket = "hour"
+	}
+
+	window := 24 * time.Hour
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		parsed, err := model.ParseRelativeDuration(windowStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid window: "+err.Error())
+			return
+		}
+		window = parsed
+	}
+
+	overview, err := h.service.Overview(r.Context(), configID, window, bucket)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, overview)
+}
+
+// NoisyChecks handles GET /api/v1/reports/noisy-checks
+func (h *StatsHandler) NoisyChecks(w http.ResponseWriter, r *http.Request) {
+	window := 7 * 24 * time.Hour
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		parsed, err := model.ParseRelativeDuration(windowStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid window: "+err.Error())
+			return
+		}
+		window = parsed
+	}
+
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy == "" {
+		sortBy = "alerts"
+	}
+
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	report, err := h.service.NoisyChecks(r.Context(), window, sortBy, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// NoisyRules handles GET /api/v1/reports/noisy-rules
+func (h *StatsHandler) NoisyRules(w http.ResponseWriter, r *http.Request) {
+	window := 7 * 24 * time.Hour
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		parsed, err := model.ParseRelativeDuration(windowStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid window: "+err.Error())
+			return
+		}
+		window = parsed
+	}
+
+	suggest, _ := strconv.ParseBool(r.URL.Query().Get("suggest"))
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	report, err := h.service.NoisyRules(r.Context(), window, suggest, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// DowntimeListResponse represents the downtime list response
+type DowntimeListResponse struct {
+	Total   int64            `json:"total"`
+	Page    int              `json:"page"`
+	Limit   int              `json:"limit"`
+	Results []model.Downtime `json:"results"`
+}
+
+// Downtime handles GET /api/v1/stats/downtime
+func (h *StatsHandler) Downtime(w http.ResponseWriter, r *http.Request) {
+	from, to, err := model.ParseTimeRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.service.Downtime(r.Context(), r.URL.Query().Get("config_id"), from, to)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		writeDowntimeCSV(w, report)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// Downtimes handles GET /api/v1/health-checks/{id}/downtimes
+func (h *StatsHandler) Downtimes(w http.ResponseWriter, r *http.Request) {
+	page := parseQueryInt(r, "page", 1)
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	downtimes, total, err := h.service.ListDowntimes(r.Context(), pathParam(r, "id"), page, limit)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "not found"):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, DowntimeListResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Results: downtimes,
+	})
+}
+
+// writeDowntimeCSV writes a downtime report as CSV, one row per health check. Check names are
+// the only text users control, so they are the only field that needs csvSafe.
+func writeDowntimeCSV(w http.ResponseWriter, report *model.DowntimeReportResponse) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="downtime-report.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"config_id", "config_name", "from", "to", "incidents", "resolved", "downtime_ms", "uptime_percent", "mttr_ms", "mtbf_ms"})
+	for _, result := range report.Results {
+		writer.Write([]string{
+			result.ConfigID.Hex(),
+			csvSafe(result.ConfigName),
+			report.From,
+			report.To,
+			strconv.Itoa(result.Incidents),
+			strconv.Itoa(result.Resolved),
+			strconv.FormatInt(result.DowntimeMs, 10),
+			strconv.FormatFloat(result.UptimePercent, 'f', 2, 64),
+			strconv.FormatInt(result.MTTRMs, 10),
+			strconv.FormatInt(result.MTBFMs, 10),
+		})
+	}
+	writer.Flush()
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/service"
+	"github.com/dandantas/raven/pkg/middleware"
+)
+
+// SuiteHandler handles suite CRUD and execution operations
+type SuiteHandler struct {
+	service *service.SuiteService
+}
+
+// NewSuiteHandler creates a new suite handler
+func NewSuiteHandler(service *service.SuiteService) *SuiteHandler {
+	return &SuiteHandler{
+		service: service,
+	}
+}
+
+// SuiteListResponse represents the suite list response
+type SuiteListResponse struct {
+	Total   int64         `json:"total"`
+	Page    int           `json:"page"`
+	Limit   int           `json:"limit"`
+	Results []model.Suite `json:"results"`
+}
+
+// SuiteResultListResponse represents the suite result list response
+type SuiteResultListResponse struct {
+	Total   int64               `json:"total"`
+	Page    int                 `json:"page"`
+	Limit   int                 `json:"limit"`
+	Results []model.SuiteResult `json:"results"`
+}
+
+// Create handles POST /api/v1/suites
+func (h *SuiteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var suite model.Suite
+	if err := json.NewDecoder(r.Body).Decode(&suite); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.Create(r.Context(), &suite); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, suite)
+}
+
+// Get handles GET /api/v1/suites/{id}
+func (h *SuiteHandler) Get(w http.ResponseWriter, r *http.Request) {
+	suite, err := h.service.GetByID(r.Context(), pathParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, suite)
+}
+
+// List handles GET /api/v1/suites
+func (h *SuiteHandler) List(w http.ResponseWriter, r *http.Request) {
+	enabled := parseQueryBool(r, "enabled")
+	page := parseQueryInt(r, "page", 1)
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	suites, total, err := h.service.List(r.Context(), enabled, page, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, SuiteListResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Results: suites,
+	})
+}
+
+// Update handles PUT /api/v1/suites/{id}
+func (h *SuiteHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var suite model.Suite
+	if err := json.NewDecoder(r.Body).Decode(&suite); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.Update(r.Context(), pathParam(r, "id"), &suite); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, suite)
+}
+
+// Delete handles DELETE /api/v1/suites/{id}
+func (h *SuiteHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), pathParam(r, "id")); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DeleteResponse{
+		Message: "Suite deleted successfully",
+	})
+}
+
+// Run handles POST /api/v1/suites/{id}/run
+func (h *SuiteHandler) Run(w http.ResponseWriter, r *http.Request) {
+	correlationID := middleware.GetCorrelationID(r.Context())
+
+	result, err := h.service.Run(r.Context(), pathParam(r, "id"), correlationID, triggeredBy(r, "api"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// Results handles GET /api/v1/suites/{id}/results
+func (h *SuiteHandler) Results(w http.ResponseWriter, r *http.Request) {
+	page := parseQueryInt(r, "page", 1)
+	limit := parseQueryInt(r, "limit", 20)
+
+	// Enforce max limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	results, total, err := h.service.ListResults(r.Context(), pathParam(r, "id"), page, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSONFields(w, r, http.StatusOK, SuiteResultListResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Results: results,
+	})
+}
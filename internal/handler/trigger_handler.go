@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/service"
+	"github.com/dandantas/raven/pkg/middleware"
+	"github.com/google/uuid"
+)
+
+// TriggerHandler handles external trigger token endpoints
+type TriggerHandler struct {
+	service       *service.TriggerService
+	executor      *service.Executor
+	asyncExecutor *service.AsyncExecutor
+}
+
+// NewTriggerHandler creates a new trigger handler
+func NewTriggerHandler(service *service.TriggerService, executor *service.Executor, asyncExecutor *service.AsyncExecutor) *TriggerHandler {
+	return &TriggerHandler{
+		service:       service,
+		executor:      executor,
+		asyncExecutor: asyncExecutor,
+	}
+}
+
+// TriggerTokenResponse represents a newly issued trigger token
+type TriggerTokenResponse struct {
+	Token string `json:"token"`
+	Path  string `json:"path"`
+}
+
+// IssueToken handles POST /api/v1/health-checks/{id}/trigger-token
+func (h *TriggerHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	token, err := h.service.IssueToken(r.Context(), pathParam(r, "id"))
+	if err != nil {
+		switch {
+		case err.Error() == "health check not found":
+			writeError(w, http.StatusNotFound, err.Error())
+		case strings.HasPrefix(err.Error(), "invalid") || strings.Contains(err.Error(), "disabled"):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, TriggerTokenResponse{
+		Token: token,
+		Path:  "/api/v1/triggers/" + token,
+	})
+}
+
+// Trigger handles POST /api/v1/triggers/{token}
+func (h *TriggerHandler) Trigger(w http.ResponseWriter, r *http.Request) {
+	config, err := h.service.Resolve(r.Context(), pathParam(r, "token"))
+	if err != nil {
+		if err.Error() == "invalid trigger token" {
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	correlationID := middleware.GetCorrelationID(r.Context())
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+
+	opts := service.ExecuteOptions{
+		TriggerType: model.TriggerWebhook,
+		TriggeredBy: triggeredBy(r, "trigger-token"),
+	}
+	configID := config.ID.Hex()
+
+	if r.URL.Query().Get("async") == "true" {
+		jobID, err := h.asyncExecutor.SubmitJob(r.Context(), configID, correlationID, opts)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, AsyncResponse{
+			JobID:   jobID,
+			Status:  "queued",
+			Message: "Health check execution queued successfully",
+		})
+		return
+	}
+
+	execution, err := h.executor.Execute(r.Context(), configID, correlationID, opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, execution)
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// Formats
+const (
+	FormatOpenAPI = "openapi" // OpenAPI 3.x or Swagger 2.0, JSON only
+	FormatPostman = "postman" // Postman collection v2.0/v2.1
+)
+
+// Placeholder marks credentials and parameters that must be filled in before an imported check is enabled
+const Placeholder = "REPLACE_ME"
+
+// ImportedTag is added to the tags of every imported check
+const ImportedTag = "imported"
+
+// Operation is a request found in an API description
+type Operation struct {
+	Name           string // operationId, "METHOD /path", or the folder path of a Postman request
+	Method         string
+	Path           string // Path template for OpenAPI, raw URL for Postman
+	Description    string
+	URL            string
+	Headers        map[string]string
+	Body           string
+	Auth           model.Auth
+	ExpectedStatus int
+}
+
+// Parse extracts the operations of an API description. baseURL, when set, replaces the
+// servers of an OpenAPI spec and the scheme and host of Postman request URLs.
+func Parse(format string, spec []byte, baseURL string) ([]Operation, error) {
+	if format == "" {
+		format = DetectFormat(spec)
+	}
+
+	switch format {
+	case FormatOpenAPI:
+		return parseOpenAPI(spec, baseURL)
+	case FormatPostman:
+		return parsePostman(spec, baseURL)
+	case "":
+		return nil, errors.New("invalid spec: not an OpenAPI document or Postman collection")
+	default:
+		return nil, fmt.Errorf("invalid format: %s (must be 'openapi' or 'postman')", format)
+	}
+}
+
+// DetectFormat guesses the format of an API description from its top-level fields
+func DetectFormat(spec []byte) string {
+	var probe struct {
+		OpenAPI string          `json:"openapi"`
+		Swagger string          `json:"swagger"`
+		Info    json.RawMessage `json:"info"`
+		Item    json.RawMessage `json:"item"`
+	}
+	if err := json.Unmarshal(spec, &probe); err != nil {
+		return ""
+	}
+
+	switch {
+	case probe.OpenAPI != "" || probe.Swagger != "":
+		return FormatOpenAPI
+	case probe.Item != nil:
+		return FormatPostman
+	default:
+		return ""
+	}
+}
+
+// Select returns the operations matching keys by name or "METHOD path", and the keys that
+// matched nothing. Every operation is selected when keys is empty.
+func Select(operations []Operation, keys []string) ([]Operation, []string) {
+	if len(keys) == 0 {
+		return operations, nil
+	}
+
+	var selected []Operation
+	var unmatched []string
+	for _, key := range keys {
+		found := false
+		for _, op := range operations {
+			if strings.EqualFold(key, op.Name) || strings.EqualFold(key, op.Method+" "+op.Path) {
+				selected = append(selected, op)
+				found = true
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, key)
+		}
+	}
+	return selected, unmatched
+}
+
+// Draft builds a disabled health check for the operation with a rule alerting when the
+// response status differs from the expected one
+func (op *Operation) Draft(namePrefix string, tags []string) *model.HealthCheckConfig {
+	status := op.ExpectedStatus
+	if status == 0 {
+		status = 200
+	}
+
+	return &model.HealthCheckConfig{
+		Name:        namePrefix + op.Name,
+		Description: op.Description,
+		Enabled:     false,
+		Target: model.Target{
+			URL:     op.URL,
+			Method:  op.Method,
+			Headers: op.Headers,
+			Body:    op.Body,
+			Auth:    op.Auth,
+		},
+		Rules: []model.Rule{{
+			Name:          fmt.Sprintf("status-%d", status),
+			Description:   fmt.Sprintf("Alerts when the response status is not %d", status),
+			Expression:    "$.status",
+			Operator:      "ne",
+			ExpectedValue: status,
+			AlertOnMatch:  true,
+		}},
+		EvaluateEnvelope: true,
+		Metadata: model.Metadata{
+			Tags: append(append([]string{}, tags...), ImportedTag),
+		},
+	}
+}
+
+// replaceHost swaps the scheme and host of rawURL for baseURL. A leading {{variable}},
+// as Postman collections commonly use for the host, is replaced too.
+func replaceHost(rawURL, baseURL string) string {
+	rest := rawURL
+	switch {
+	case strings.HasPrefix(rest, "{{"):
+		if end := strings.Index(rest, "}}"); end >= 0 {
+			rest = rest[end+2:]
+		}
+	case strings.Contains(rest, "://"):
+		rest = rest[strings.Index(rest, "://")+3:]
+		if slash := strings.IndexAny(rest, "/?"); slash >= 0 {
+			rest = rest[slash:]
+		} else {
+			rest = ""
+		}
+	}
+	if rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasPrefix(rest, "?") {
+		rest = "/" + rest
+	}
+	return strings.TrimRight(baseURL, "/") + rest
+}
+
+// addQuery appends a query parameter to rawURL
+func addQuery(rawURL, name, value string) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + url.QueryEscape(name) + "=" + url.QueryEscape(value)
+}
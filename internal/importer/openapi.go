@@ -0,0 +1,337 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// openAPIMethods are the operation keys of a path item, in output order
+var openAPIMethods = []string{"get", "head", "options", "post", "put", "patch", "delete"}
+
+type openAPISpec struct {
+	OpenAPI             string                                `json:"openapi"`
+	Swagger             string                                `json:"swagger"`
+	Servers             []openAPIServer                       `json:"servers"`
+	Schemes             []string                              `json:"schemes"`  // Swagger 2.0
+	Host                string                                `json:"host"`     // Swagger 2.0
+	BasePath            string                                `json:"basePath"` // Swagger 2.0
+	Paths               map[string]map[string]json.RawMessage `json:"paths"`
+	Security            []map[string][]string                 `json:"security"`
+	SecurityDefinitions map[string]openAPISecurityScheme      `json:"securityDefinitions"` // Swagger 2.0
+	Components          struct {
+		SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+	} `json:"components"`
+}
+
+type openAPIServer struct {
+	URL       string `json:"url"`
+	Variables map[string]struct {
+		Default string `json:"default"`
+	} `json:"variables"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Parameters  []openAPIParameter         `json:"parameters"`
+	Responses   map[string]json.RawMessage `json:"responses"`
+	Security    *[]map[string][]string     `json:"security"` // Overrides the global requirement; an empty list disables auth
+	Servers     []openAPIServer            `json:"servers"`
+}
+
+type openAPIParameter struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"` // path, query, header or cookie
+	Required bool        `json:"required"`
+	Example  interface{} `json:"example"`
+	Default  interface{} `json:"default"` // Swagger 2.0
+	Schema   struct {
+		Example interface{} `json:"example"`
+		Default interface{} `json:"default"`
+	} `json:"schema"`
+}
+
+type openAPISecurityScheme struct {
+	Type     string `json:"type"`   // http, apiKey, oauth2, openIdConnect; basic in Swagger 2.0
+	Scheme   string `json:"scheme"` // basic or bearer for http
+	Name     string `json:"name"`   // apiKey parameter name
+	In       string `json:"in"`     // apiKey location
+	Flow     string `json:"flow"`   // Swagger 2.0 oauth2 flow
+	TokenURL string `json:"tokenUrl"`
+	Flows    struct {
+		ClientCredentials *struct {
+			TokenURL string `json:"tokenUrl"`
+		} `json:"clientCredentials"`
+	} `json:"flows"`
+}
+
+// parseOpenAPI extracts the operations of an OpenAPI 3.x or Swagger 2.0 document
+func parseOpenAPI(spec []byte, baseURL string) ([]Operation, error) {
+	var doc openAPISpec
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+	if doc.OpenAPI == "" && doc.Swagger == "" {
+		return nil, errors.New("invalid spec: missing openapi or swagger version")
+	}
+
+	schemes := doc.Components.SecuritySchemes
+	if doc.Swagger != "" {
+		schemes = doc.SecurityDefinitions
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var operations []Operation
+	for _, path := range paths {
+		item := doc.Paths[path]
+
+		var shared []openAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("invalid spec: path %s parameters: %w", path, err)
+			}
+		}
+		var pathServers []openAPIServer
+		if raw, ok := item["servers"]; ok {
+			if err := json.Unmarshal(raw, &pathServers); err != nil {
+				return nil, fmt.Errorf("invalid spec: path %s servers: %w", path, err)
+			}
+		}
+
+		for _, method := range openAPIMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op openAPIOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("invalid spec: %s %s: %w", strings.ToUpper(method), path, err)
+			}
+
+			base := baseURL
+			if base == "" {
+				base = doc.serverURL(op.Servers, pathServers)
+			}
+			if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+				return nil, errors.New("invalid spec: no absolute server URL; set base_url")
+			}
+
+			operation := Operation{
+				Name:           op.OperationID,
+				Method:         strings.ToUpper(method),
+				Path:           path,
+				Description:    op.Summary,
+				URL:            strings.TrimRight(base, "/") + path,
+				ExpectedStatus: expectedStatus(op.Responses),
+			}
+			if operation.Name == "" {
+				operation.Name = operation.Method + " " + path
+			}
+
+			applyParameters(&operation, mergeParameters(shared, op.Parameters))
+
+			requirements := doc.Security
+			if op.Security != nil {
+				requirements = *op.Security
+			}
+			applySecurity(&operation, requirements, schemes)
+
+			operations = append(operations, operation)
+		}
+	}
+
+	return operations, nil
+}
+
+// serverURL returns the first server URL, most specific first, with its variables
+// set to their defaults
+func (doc *openAPISpec) serverURL(operationServers, pathServers []openAPIServer) string {
+	if doc.Swagger != "" {
+		if doc.Host == "" {
+			return ""
+		}
+		scheme := "https"
+		if len(doc.Schemes) > 0 && !slices.Contains(doc.Schemes, "https") {
+			scheme = doc.Schemes[0]
+		}
+		return scheme + "://" + doc.Host + doc.BasePath
+	}
+
+	for _, servers := range [][]openAPIServer{operationServers, pathServers, doc.Servers} {
+		if len(servers) == 0 {
+			continue
+		}
+		server := servers[0].URL
+		for name, variable := range servers[0].Variables {
+			server = strings.ReplaceAll(server, "{"+name+"}", variable.Default)
+		}
+		return server
+	}
+	return ""
+}
+
+// mergeParameters overrides path-level parameters with operation parameters of the same name and location
+func mergeParameters(shared, own []openAPIParameter) []openAPIParameter {
+	merged := append([]openAPIParameter{}, own...)
+	for _, param := range shared {
+		overridden := false
+		for _, o := range own {
+			if o.Name == param.Name && o.In == param.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, param)
+		}
+	}
+	return merged
+}
+
+// applyParameters fills path parameters with their example or default values and adds the
+// required query and header parameters, using placeholders where the spec gives no value
+func applyParameters(operation *Operation, params []openAPIParameter) {
+	for _, param := range params {
+		value, known := param.value()
+		switch param.In {
+		case "path":
+			// Unknown path parameters are left as {name} for the user to fill in
+			if known {
+				operation.URL = strings.ReplaceAll(operation.URL, "{"+param.Name+"}", url.PathEscape(value))
+			}
+		case "query":
+			if param.Required {
+				operation.URL = addQuery(operation.URL, param.Name, value)
+			}
+		case "header":
+			if param.Required {
+				setHeader(operation, param.Name, value)
+			}
+		}
+	}
+}
+
+// value returns the parameter's example or default value, or the placeholder when it has neither
+func (p *openAPIParameter) value() (string, bool) {
+	for _, v := range []interface{}{p.Example, p.Schema.Example, p.Default, p.Schema.Default} {
+		if v != nil {
+			return fmt.Sprint(v), true
+		}
+	}
+	return Placeholder, false
+}
+
+// applySecurity turns the first security requirement into placeholder credentials
+func applySecurity(operation *Operation, requirements []map[string][]string, schemes map[string]openAPISecurityScheme) {
+	if len(requirements) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(requirements[0]))
+	for name := range requirements[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scheme, ok := schemes[name]
+		if !ok {
+			continue
+		}
+		scopes := requirements[0][name]
+
+		switch strings.ToLower(scheme.Type) {
+		case "http":
+			if strings.EqualFold(scheme.Scheme, "basic") {
+				operation.Auth = basicPlaceholder()
+			} else {
+				operation.Auth = bearerPlaceholder()
+			}
+		case "basic":
+			operation.Auth = basicPlaceholder()
+		case "apikey":
+			switch scheme.In {
+			case "header":
+				setHeader(operation, scheme.Name, Placeholder)
+			case "query":
+				operation.URL = addQuery(operation.URL, scheme.Name, Placeholder)
+			case "cookie":
+				setHeader(operation, "Cookie", scheme.Name+"="+Placeholder)
+			}
+		case "oauth2":
+			tokenURL := ""
+			if scheme.Flows.ClientCredentials != nil {
+				tokenURL = scheme.Flows.ClientCredentials.TokenURL
+			} else if scheme.Flow == "application" {
+				tokenURL = scheme.TokenURL
+			}
+			if tokenURL != "" {
+				operation.Auth = model.Auth{
+					Type:         "oauth2",
+					TokenURL:     tokenURL,
+					ClientID:     Placeholder,
+					ClientSecret: Placeholder,
+					Scopes:       scopes,
+				}
+			} else {
+				// Interactive flows can't run unattended; a token has to be supplied
+				operation.Auth = bearerPlaceholder()
+			}
+		case "openidconnect":
+			operation.Auth = bearerPlaceholder()
+		}
+	}
+}
+
+// expectedStatus returns 200 when the operation documents it, and otherwise its lowest 2xx status
+func expectedStatus(responses map[string]json.RawMessage) int {
+	if _, ok := responses["200"]; ok {
+		return 200
+	}
+
+	lowest := 0
+	for code := range responses {
+		status, err := strconv.Atoi(code)
+		if err != nil || status < 200 || status > 299 {
+			continue
+		}
+		if lowest == 0 || status < lowest {
+			lowest = status
+		}
+	}
+	if lowest == 0 {
+		return 200
+	}
+	return lowest
+}
+
+// setHeader sets a target header, creating the header map on first use
+func setHeader(operation *Operation, name, value string) {
+	if operation.Headers == nil {
+		operation.Headers = make(map[string]string)
+	}
+	operation.Headers[name] = value
+}
+
+// basicPlaceholder returns basic auth with placeholder credentials
+func basicPlaceholder() model.Auth {
+	return model.Auth{Type: "basic", Username: Placeholder, Password: Placeholder}
+}
+
+// bearerPlaceholder returns bearer auth with a placeholder token
+func bearerPlaceholder() model.Auth {
+	return model.Auth{Type: "bearer", Token: Placeholder}
+}
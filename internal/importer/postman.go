@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// postmanVariablePattern matches {{name}} variable references
+var postmanVariablePattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+type postmanCollection struct {
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanKeyValue `json:"variable"`
+	Auth     *postmanAuth      `json:"auth"`
+}
+
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item"` // Set on folders
+	Request *postmanRequest `json:"request"`
+	Auth    *postmanAuth    `json:"auth"` // Folder auth, inherited by its requests
+}
+
+type postmanRequest struct {
+	Method      string            `json:"method"`
+	Description json.RawMessage   `json:"description"`
+	Header      []postmanKeyValue `json:"header"`
+	URL         postmanURL        `json:"url"`
+	Body        *struct {
+		Mode string `json:"mode"`
+		Raw  string `json:"raw"`
+	} `json:"body"`
+	Auth *postmanAuth `json:"auth"`
+}
+
+// postmanURL is a request URL, given either as a string or as an object with its raw form
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Variable []postmanKeyValue `json:"variable"` // Values of :name path variables
+}
+
+// UnmarshalJSON accepts both URL forms
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &u.Raw)
+	}
+	type plain postmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"` // noauth, basic, bearer, apikey, oauth2, ...
+	APIKey []postmanKeyValue `json:"apikey"`
+	OAuth2 []postmanKeyValue `json:"oauth2"`
+}
+
+type postmanKeyValue struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Disabled bool        `json:"disabled"`
+}
+
+// postmanValue returns the value of key in a key/value list
+func postmanValue(values []postmanKeyValue, key string) string {
+	for _, kv := range values {
+		if kv.Key == key && kv.Value != nil {
+			return fmt.Sprint(kv.Value)
+		}
+	}
+	return ""
+}
+
+// parsePostman extracts the requests of a Postman collection, walking folders depth-first
+func parsePostman(spec []byte, baseURL string) ([]Operation, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(spec, &collection); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+	if collection.Item == nil {
+		return nil, errors.New("invalid spec: collection has no items")
+	}
+
+	variables := make(map[string]string)
+	for _, v := range collection.Variable {
+		if v.Value != nil {
+			variables[v.Key] = fmt.Sprint(v.Value)
+		}
+	}
+
+	var operations []Operation
+	var walk func(items []postmanItem, prefix string, auth *postmanAuth)
+	walk = func(items []postmanItem, prefix string, auth *postmanAuth) {
+		for _, item := range items {
+			name := prefix + item.Name
+			inherited := auth
+			if item.Auth != nil {
+				inherited = item.Auth
+			}
+
+			if item.Request == nil {
+				walk(item.Item, name+"/", inherited)
+				continue
+			}
+			if item.Request.Auth != nil {
+				inherited = item.Request.Auth
+			}
+			operations = append(operations, postmanOperation(name, item.Request, inherited, variables, baseURL))
+		}
+	}
+	walk(collection.Item, "", collection.Auth)
+
+	return operations, nil
+}
+
+// postmanOperation converts a collection request, resolving the collection variables it uses
+func postmanOperation(name string, request *postmanRequest, auth *postmanAuth, variables map[string]string, baseURL string) Operation {
+	resolve := func(s string) string {
+		return postmanVariablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+			if value, ok := variables[strings.TrimSpace(ref[2:len(ref)-2])]; ok {
+				return value
+			}
+			return ref
+		})
+	}
+
+	rawURL := request.URL.Raw
+	for _, v := range request.URL.Variable {
+		if value := fmt.Sprint(v.Value); v.Value != nil && value != "" {
+			rawURL = strings.ReplaceAll(rawURL, ":"+v.Key, value)
+		}
+	}
+	if baseURL != "" {
+		rawURL = replaceHost(rawURL, baseURL)
+	}
+
+	method := strings.ToUpper(request.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	operation := Operation{
+		Name:           name,
+		Method:         method,
+		Path:           request.URL.Raw,
+		Description:    postmanDescription(request.Description),
+		URL:            resolve(rawURL),
+		ExpectedStatus: 200,
+	}
+
+	for _, header := range request.Header {
+		if header.Disabled || header.Key == "" {
+			continue
+		}
+		value := ""
+		if header.Value != nil {
+			value = resolve(fmt.Sprint(header.Value))
+		}
+		// Credentials are never imported
+		if strings.EqualFold(header.Key, "Authorization") {
+			value = Placeholder
+		}
+		setHeader(&operation, header.Key, value)
+	}
+
+	if request.Body != nil && request.Body.Mode == "raw" {
+		operation.Body = resolve(request.Body.Raw)
+	}
+
+	if auth != nil {
+		applyPostmanAuth(&operation, auth, resolve)
+	}
+
+	return operation
+}
+
+// applyPostmanAuth turns collection auth into placeholder credentials
+func applyPostmanAuth(operation *Operation, auth *postmanAuth, resolve func(string) string) {
+	switch auth.Type {
+	case "basic":
+		operation.Auth = basicPlaceholder()
+	case "bearer", "jwt":
+		operation.Auth = bearerPlaceholder()
+	case "apikey":
+		name := postmanValue(auth.APIKey, "key")
+		if name == "" {
+			return
+		}
+		if postmanValue(auth.APIKey, "in") == "query" {
+			operation.URL = addQuery(operation.URL, name, Placeholder)
+		} else {
+			setHeader(operation, name, Placeholder)
+		}
+	case "oauth2":
+		tokenURL := resolve(postmanValue(auth.OAuth2, "accessTokenUrl"))
+		if postmanValue(auth.OAuth2, "grant_type") == "client_credentials" && tokenURL != "" {
+			operation.Auth = model.Auth{
+				Type:         "oauth2",
+				TokenURL:     tokenURL,
+				ClientID:     Placeholder,
+				ClientSecret: Placeholder,
+			}
+			if scope := resolve(postmanValue(auth.OAuth2, "scope")); scope != "" {
+				operation.Auth.Scopes = strings.Fields(scope)
+			}
+		} else {
+			// Interactive grants can't run unattended; a token has to be supplied
+			operation.Auth = bearerPlaceholder()
+		}
+	}
+}
+
+// postmanDescription returns a request description given as a string or as {content}
+func postmanDescription(raw json.RawMessage) string {
+	var description string
+	if err := json.Unmarshal(raw, &description); err == nil {
+		return description
+	}
+	var object struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return object.Content
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DeliveryLatencyMetric is the Prometheus histogram of the time from a rule match to the
+// successful delivery of its alert
+const DeliveryLatencyMetric = "raven_alert_delivery_latency_seconds"
+
+// DeliveryLatencyBuckets are the histogram's upper bounds in seconds, fine-grained around the
+// common "alert within 60 seconds" objective
+var DeliveryLatencyBuckets = []float64{0.5, 1, 2, 5, 10, 15, 30, 45, 60, 90, 120, 300, 600}
+
+// DeliveryMetrics keeps a histogram of alert delivery latency per severity, so teams can check
+// how many alerts reached their destination within their objective
+type DeliveryMetrics struct {
+	mu         sync.Mutex
+	histograms map[string]*histogram
+}
+
+// histogram counts observations per bucket, with their sum and total count
+type histogram struct {
+	buckets []uint64 // Non-cumulative counts, one per DeliveryLatencyBuckets bound
+	sum     float64
+	count   uint64
+}
+
+// NewDeliveryMetrics creates an empty delivery latency histogram
+func NewDeliveryMetrics() *DeliveryMetrics {
+	return &DeliveryMetrics{histograms: make(map[string]*histogram)}
+}
+
+// Delivered records the latency of an alert delivered successfully; metrics are optional, so a
+// nil receiver records nothing
+func (m *DeliveryMetrics) Delivered(severity string, latency time.Duration) {
+	if m == nil {
+		return
+	}
+
+	seconds := latency.Seconds()
+	labels := formatLabels(map[string]string{"severity": severity})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.histograms[labels]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(DeliveryLatencyBuckets))}
+		m.histograms[labels] = h
+	}
+	if i := sort.SearchFloat64s(DeliveryLatencyBuckets, seconds); i < len(h.buckets) {
+		h.buckets[i]++
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// Export writes the histogram in the Prometheus text exposition format
+func (m *DeliveryMetrics) Export(w io.Writer) {
+	type series struct {
+		labels string
+		histogram
+	}
+
+	m.mu.Lock()
+	all := make([]series, 0, len(m.histograms))
+	for labels, h := range m.histograms {
+		all = append(all, series{labels: labels, histogram: histogram{
+			buckets: append([]uint64(nil), h.buckets...),
+			sum:     h.sum,
+			count:   h.count,
+		}})
+	}
+	m.mu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool { return all[i].labels < all[j].labels })
+
+	fmt.Fprintf(w, "# HELP %s Time from a rule match to the successful delivery of its alert\n", DeliveryLatencyMetric)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", DeliveryLatencyMetric)
+	for _, s := range all {
+		var cumulative uint64
+		for i, bound := range DeliveryLatencyBuckets {
+			cumulative += s.buckets[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", DeliveryLatencyMetric, s.labels, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", DeliveryLatencyMetric, s.labels, s.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", DeliveryLatencyMetric, s.labels, strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", DeliveryLatencyMetric, s.labels, s.count)
+	}
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// Gauges holds the latest value of each metric rule series for Prometheus scraping
+type Gauges struct {
+	mu     sync.RWMutex
+	series map[string]gauge
+}
+
+// gauge is the latest value of one labeled series
+type gauge struct {
+	labels string
+	value  float64
+}
+
+// NewGauges creates an empty gauge registry
+func NewGauges() *Gauges {
+	return &Gauges{
+		series: make(map[string]gauge),
+	}
+}
+
+// Set records the latest value of each sample's series
+func (g *Gauges) Set(samples []model.MetricSample) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, sample := range samples {
+		labels := formatLabels(SampleLabels(sample.Meta))
+		g.series[labels] = gauge{labels: labels, value: sample.Value}
+	}
+}
+
+// ServeHTTP writes the gauges in the Prometheus text exposition format
+func (g *Gauges) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	g.Export(w)
+}
+
+// Export writes the gauges in the Prometheus text exposition format
+func (g *Gauges) Export(w io.Writer) {
+	g.mu.RLock()
+	series := make([]gauge, 0, len(g.series))
+	for _, s := range g.series {
+		series = append(series, s)
+	}
+	g.mu.RUnlock()
+
+	sort.Slice(series, func(i, j int) bool { return series[i].labels < series[j].labels })
+
+	fmt.Fprintf(w, "# HELP %s Latest value extracted by a Raven metric rule\n", RuleValueMetric)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", RuleValueMetric)
+	for _, s := range series {
+		fmt.Fprintf(w, "%s{%s} %s\n", RuleValueMetric, s.labels, strconv.FormatFloat(s.value, 'g', -1, 64))
+	}
+}
+
+// formatLabels renders labels sorted by name as name="value" pairs
+func formatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+`="`+labelEscaper.Replace(labels[name])+`"`)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// labelEscaper escapes label values for the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
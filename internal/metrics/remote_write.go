@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/golang/snappy"
+)
+
+// RuleValueMetric is the Prometheus metric name of values extracted by metric rules
+const RuleValueMetric = "raven_rule_value"
+
+// RemoteWriter pushes metric samples to a Prometheus remote-write endpoint
+type RemoteWriter struct {
+	url    string
+	client *http.Client
+}
+
+// NewRemoteWriter creates a remote writer for the given endpoint
+func NewRemoteWriter(url string, timeout time.Duration) *RemoteWriter {
+	return &RemoteWriter{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Write sends the samples as a snappy-compressed remote-write request
+func (w *RemoteWriter) Write(ctx context.Context, samples []model.MetricSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	body := snappy.Encode(nil, encodeWriteRequest(samples))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote-write request: %w", err)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote-write request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("remote-write endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SampleLabels returns the Prometheus labels identifying a metric sample's series
+func SampleLabels(meta model.MetricMeta) map[string]string {
+	labels := map[string]string{
+		"config_id": meta.ConfigID.Hex(),
+		"config":    meta.ConfigName,
+		"rule":      meta.Name,
+	}
+	if meta.Region != "" {
+		labels["region"] = meta.Region
+	}
+	return labels
+}
+
+// encodeWriteRequest encodes samples as a prometheus.WriteRequest protobuf message
+func encodeWriteRequest(samples []model.MetricSample) []byte {
+	var request []byte
+	for _, sample := range samples {
+		labels := SampleLabels(sample.Meta)
+		labels["__name__"] = RuleValueMetric
+
+		// Remote-write requires labels sorted by name
+		names := make([]string, 0, len(labels))
+		for name := range labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var series []byte
+		for _, name := range names {
+			var label []byte
+			label = appendString(label, 1, name)
+			label = appendString(label, 2, labels[name])
+			series = appendBytes(series, 1, label)
+		}
+
+		var point []byte
+		point = appendTag(point, 1, 1)
+		point = binary.LittleEndian.AppendUint64(point, math.Float64bits(sample.Value))
+		point = appendTag(point, 2, 0)
+		point = binary.AppendUvarint(point, uint64(sample.Timestamp.UnixMilli()))
+		series = appendBytes(series, 2, point)
+
+		request = appendBytes(request, 1, series)
+	}
+	return request
+}
+
+// appendTag appends a protobuf field tag
+func appendTag(b []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+// appendBytes appends a length-delimited protobuf field
+func appendBytes(b []byte, field int, value []byte) []byte {
+	b = appendTag(b, field, 2)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+// appendString appends a protobuf string field
+func appendString(b []byte, field int, value string) []byte {
+	return appendBytes(b, field, []byte(value))
+}
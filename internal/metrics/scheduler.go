@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Prometheus metric names of scheduled runs, counted per health check by each instance
+const (
+	SchedulerStartedMetric = "raven_scheduler_runs_started_total"
+	SchedulerQueuedMetric  = "raven_scheduler_runs_queued_total"
+	SchedulerSkippedMetric = "raven_scheduler_runs_skipped_total"
+)
+
+// Prometheus metric names of the scheduler's latest tick, for spotting when it falls behind
+const (
+	SchedulerTickDurationMetric = "raven_scheduler_tick_duration_seconds"
+	SchedulerBacklogMetric      = "raven_scheduler_backlog"
+	SchedulerLagMetric          = "raven_scheduler_lag_seconds"
+	SchedulerWaitingMetric      = "raven_scheduler_runs_waiting"
+)
+
+// Reasons an instance doesn't start a due scheduled run
+const (
+	SkipLockHeld  = "lock_held"  // Another instance holds the check's lock
+	SkipLockError = "lock_error" // The lock couldn't be acquired
+	SkipShutdown  = "shutdown"   // The scheduler stopped while the run waited for a slot
+)
+
+// Exporter writes metrics in the Prometheus text exposition format
+type Exporter interface {
+	Export(w io.Writer)
+}
+
+// Handler serves the metrics of every exporter
+func Handler(exporters ...Exporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		for _, exporter := range exporters {
+			exporter.Export(w)
+		}
+	})
+}
+
+// SchedulerMetrics counts scheduled runs started, queued for a concurrency slot and skipped
+// per health check, so checks that are systematically starved stand out, and gauges how far
+// behind the latest tick was
+type SchedulerMetrics struct {
+	mu     sync.Mutex
+	counts map[counterKey]float64
+	gauges map[string]float64
+}
+
+// counterKey identifies one labeled counter series
+type counterKey struct {
+	metric string
+	labels string
+}
+
+// NewSchedulerMetrics creates zeroed scheduler metrics
+func NewSchedulerMetrics() *SchedulerMetrics {
+	return &SchedulerMetrics{counts: make(map[counterKey]float64), gauges: make(map[string]float64)}
+}
+
+// SetTick records the latest tick's duration, backlog of late checks, lag of the most
+// overdue check and the runs waiting for a concurrency slot
+func (c *SchedulerMetrics) SetTick(duration time.Duration, backlog int, lag time.Duration, waiting int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gauges[SchedulerTickDurationMetric] = duration.Seconds()
+	c.gauges[SchedulerBacklogMetric] = float64(backlog)
+	c.gauges[SchedulerLagMetric] = lag.Seconds()
+	c.gauges[SchedulerWaitingMetric] = float64(waiting)
+}
+
+// RunStarted counts a due run this instance locked and started
+func (c *SchedulerMetrics) RunStarted(configID, configName string) {
+	c.add(SchedulerStartedMetric, map[string]string{"config_id": configID, "config": configName})
+}
+
+// RunQueued counts a run that had to wait because every concurrency slot was busy
+func (c *SchedulerMetrics) RunQueued(configID, configName string) {
+	c.add(SchedulerQueuedMetric, map[string]string{"config_id": configID, "config": configName})
+}
+
+// RunSkipped counts a due run this instance didn't start, with the reason
+func (c *SchedulerMetrics) RunSkipped(configID, configName, reason string) {
+	c.add(SchedulerSkippedMetric, map[string]string{"config_id": configID, "config": configName, "reason": reason})
+}
+
+// add increments a series; counters are optional, so a nil receiver counts nothing
+func (c *SchedulerMetrics) add(metric string, labels map[string]string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[counterKey{metric: metric, labels: formatLabels(labels)}]++
+}
+
+// Export writes the counters and gauges in the Prometheus text exposition format
+func (c *SchedulerMetrics) Export(w io.Writer) {
+	type series struct {
+		labels string
+		count  float64
+	}
+	byMetric := make(map[string][]series)
+
+	c.mu.Lock()
+	for key, count := range c.counts {
+		byMetric[key.metric] = append(byMetric[key.metric], series{labels: key.labels, count: count})
+	}
+	gauges := make(map[string]float64, len(c.gauges))
+	for name, value := range c.gauges {
+		gauges[name] = value
+	}
+	c.mu.Unlock()
+
+	for _, metric := range []struct{ name, help string }{
+		{SchedulerBacklogMetric, "Due scheduled checks more than a tick interval late at the latest tick"},
+		{SchedulerLagMetric, "How late the most overdue scheduled check was at the latest tick"},
+		{SchedulerTickDurationMetric, "Duration of the latest scheduler tick"},
+		{SchedulerWaitingMetric, "Scheduled runs on this instance waiting for a free concurrency slot"},
+	} {
+		value, ok := gauges[metric.name]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", metric.name)
+		fmt.Fprintf(w, "%s %s\n", metric.name, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+
+	for _, metric := range []struct{ name, help string }{
+		{SchedulerQueuedMetric, "Scheduled runs that waited for a free concurrency slot"},
+		{SchedulerSkippedMetric, "Due scheduled runs this instance didn't start, by reason"},
+		{SchedulerStartedMetric, "Scheduled runs this instance started"},
+	} {
+		all := byMetric[metric.name]
+		sort.Slice(all, func(i, j int) bool { return all[i].labels < all[j].labels })
+
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s counter\n", metric.name)
+		for _, s := range all {
+			fmt.Fprintf(w, "%s{%s} %s\n", metric.name, s.labels, strconv.FormatFloat(s.count, 'g', -1, 64))
+		}
+	}
+}
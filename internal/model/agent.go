@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AgentSchedule tracks the schedule state of a health check in one agent region
+type AgentSchedule struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ConfigID    primitive.ObjectID `json:"config_id" bson:"config_id"`
+	Region      string             `json:"region" bson:"region"`
+	AgentID     string             `json:"agent_id,omitempty" bson:"agent_id,omitempty"`         // Agent holding the current lease
+	LeasedUntil time.Time          `json:"leased_until,omitempty" bson:"leased_until,omitempty"` // Lease expiration
+	LastRun     time.Time          `json:"last_run,omitempty" bson:"last_run,omitempty"`
+	NextRun     time.Time          `json:"next_run,omitempty" bson:"next_run,omitempty"`
+}
+
+// AgentLeaseRequest is sent by an agent to pull the checks due in its region
+type AgentLeaseRequest struct {
+	AgentID string `json:"agent_id"`
+	Region  string `json:"region"`
+	Max     int    `json:"max,omitempty"`
+}
+
+// AgentLeaseResponse contains the checks leased to an agent
+type AgentLeaseResponse struct {
+	LeaseSeconds int                 `json:"lease_seconds"`
+	Checks       []HealthCheckConfig `json:"checks"`
+}
+
+// AgentResult is the outcome of a target call made by an agent
+type AgentResult struct {
+	ConfigID      string            `json:"config_id"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	DurationMs    int64             `json:"duration_ms"`
+	Request       ExecutionRequest  `json:"request"`
+	Response      ExecutionResponse `json:"response"`
+}
+
+// AgentReport is sent by an agent to report the results of leased checks
+type AgentReport struct {
+	AgentID string        `json:"agent_id"`
+	Region  string        `json:"region"`
+	Results []AgentResult `json:"results"`
+}
+
+// AgentReportResponse summarizes the recorded agent results
+type AgentReportResponse struct {
+	Accepted   int                `json:"accepted"`
+	Rejected   int                `json:"rejected"`
+	Executions []ExecutionSummary `json:"executions"`
+	Errors     []string           `json:"errors,omitempty"`
+}
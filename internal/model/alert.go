@@ -1,6 +1,8 @@
 package model
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,6 +18,33 @@ type AlertAttempt struct {
 	DurationMs    int64     `json:"duration_ms" bson:"duration_ms"`
 }
 
+// Alert classifications given by responders
+const (
+	AlertClassificationReal  = "real"  // A genuine problem
+	AlertClassificationNoise = "noise" // A false positive
+	AlertClassificationTest  = "test"  // Raised on purpose while testing
+)
+
+// IsValidAlertClassification reports whether classification is a known alert classification
+func IsValidAlertClassification(classification string) bool {
+	switch classification {
+	case AlertClassificationReal, AlertClassificationNoise, AlertClassificationTest:
+		return true
+	}
+	return false
+}
+
+// AlertComment represents a responder note attached to an alert
+type AlertComment struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Author    string             `json:"author" bson:"author"`
+	Text      string             `json:"text" bson:"text"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// MaxAlertCommentLength is the longest comment text accepted
+const MaxAlertCommentLength = 4000
+
 // AlertPayload represents the payload sent to webhook
 type AlertPayload struct {
 	Text string `json:"text" bson:"text"`
@@ -27,29 +56,65 @@ type AlertLog struct {
 	ExecutionID          primitive.ObjectID `json:"execution_id" bson:"execution_id"`
 	CorrelationID        string             `json:"correlation_id" bson:"correlation_id"`
 	ConfigID             primitive.ObjectID `json:"config_id" bson:"config_id"`
+	SuiteID              primitive.ObjectID `json:"suite_id,omitempty" bson:"suite_id,omitempty"`         // Set for suite digest alerts
+	HeartbeatID          primitive.ObjectID `json:"heartbeat_id,omitempty" bson:"heartbeat_id,omitempty"` // Set for heartbeat alerts
+	ChannelID            primitive.ObjectID `json:"channel_id,omitempty" bson:"channel_id,omitempty"`     // Notification channel the alert was sent through
 	WebhookURL           string             `json:"webhook_url" bson:"webhook_url"`
+	WebhookHost          string             `json:"webhook_host,omitempty" bson:"webhook_host,omitempty"` // Destination host, for filtering
+	RuleName             string             `json:"rule_name,omitempty" bson:"rule_name,omitempty"`       // Rule that triggered the alert
+	Tags                 []string           `json:"tags,omitempty" bson:"tags,omitempty"`                 // Config tags at the time of the alert
+	Severity             string             `json:"severity,omitempty" bson:"severity,omitempty"`         // info, warning, error or critical
+	Source               string             `json:"source,omitempty" bson:"source,omitempty"`             // External system for ingested alerts
+	DedupKey             string             `json:"dedup_key,omitempty" bson:"dedup_key,omitempty"`       // Groups repeats of an ingested alert while it is open
+	Occurrences          int                `json:"occurrences,omitempty" bson:"occurrences,omitempty"`   // Times an ingested alert was received
+	LastSeenAt           time.Time          `json:"last_seen_at,omitempty" bson:"last_seen_at,omitempty"` // Latest occurrence of an ingested alert
 	Payload              AlertPayload       `json:"payload" bson:"payload"`
 	Attempts             []AlertAttempt     `json:"attempts" bson:"attempts"`
-	FinalStatus          string             `json:"final_status" bson:"final_status"`                           // "delivered", "failed", "retrying"
+	FinalStatus          string             `json:"final_status" bson:"final_status"`                           // "pending", "delivered", "failed", "retrying"
 	AcknowledgmentStatus string             `json:"acknowledgment_status" bson:"acknowledgment_status"`         // "open", "acknowledged"
 	AcknowledgedBy       string             `json:"acknowledged_by,omitempty" bson:"acknowledged_by,omitempty"` // email/username
 	AcknowledgedAt       time.Time          `json:"acknowledged_at,omitempty" bson:"acknowledged_at,omitempty"`
+	Classification       string             `json:"classification,omitempty" bson:"classification,omitempty"`           // Responder verdict: "real", "noise" or "test"
+	Comments             []AlertComment     `json:"comments,omitempty" bson:"comments,omitempty"`                       // Investigation notes, oldest first
+	MatchedAt            time.Time          `json:"matched_at,omitempty" bson:"matched_at,omitempty"`                   // When the rule matched or the event was received
+	DeliveryLatencyMs    int64              `json:"delivery_latency_ms,omitempty" bson:"delivery_latency_ms,omitempty"` // Time from MatchedAt to successful delivery
 	CreatedAt            time.Time          `json:"created_at" bson:"created_at"`
 	CompletedAt          time.Time          `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
 }
 
+// MarkDelivered records a successful delivery and its latency since the rule matched
+func (al *AlertLog) MarkDelivered(deliveredAt time.Time) {
+	matchedAt := al.MatchedAt
+	if matchedAt.IsZero() {
+		matchedAt = al.CreatedAt
+	}
+
+	al.FinalStatus = "delivered"
+	al.CompletedAt = deliveredAt
+	al.DeliveryLatencyMs = deliveredAt.Sub(matchedAt).Milliseconds()
+}
+
 // AlertLogSummary represents a summary for list responses
 type AlertLogSummary struct {
-	ID                   string `json:"id"`
-	CorrelationID        string `json:"correlation_id"`
-	WebhookURL           string `json:"webhook_url"`
-	FinalStatus          string `json:"final_status"`
-	AcknowledgmentStatus string `json:"acknowledgment_status"`
-	AcknowledgedBy       string `json:"acknowledged_by,omitempty"`
-	AcknowledgedAt       string `json:"acknowledged_at,omitempty"`
-	AttemptsCount        int    `json:"attempts_count"`
-	CreatedAt            string `json:"created_at"`
-	CompletedAt          string `json:"completed_at,omitempty"`
+	ID                   string   `json:"id"`
+	CorrelationID        string   `json:"correlation_id"`
+	WebhookURL           string   `json:"webhook_url"`
+	RuleName             string   `json:"rule_name,omitempty"`
+	Tags                 []string `json:"tags,omitempty"`
+	Severity             string   `json:"severity,omitempty"`
+	Source               string   `json:"source,omitempty"`
+	DedupKey             string   `json:"dedup_key,omitempty"`
+	Occurrences          int      `json:"occurrences,omitempty"`
+	LastSeenAt           string   `json:"last_seen_at,omitempty"`
+	FinalStatus          string   `json:"final_status"`
+	AcknowledgmentStatus string   `json:"acknowledgment_status"`
+	AcknowledgedBy       string   `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt       string   `json:"acknowledged_at,omitempty"`
+	AttemptsCount        int      `json:"attempts_count"`
+	DeliveryLatencyMs    int64    `json:"delivery_latency_ms,omitempty"`
+	CommentsCount        int      `json:"comments_count,omitempty"`
+	CreatedAt            string   `json:"created_at"`
+	CompletedAt          string   `json:"completed_at,omitempty"`
 }
 
 // ToSummary converts AlertLog to AlertLogSummary
@@ -61,7 +126,7 @@ func (al *AlertLog) ToSummary() AlertLogSummary {
 	}
 
 	// Convert time.Time fields to ISO 8601 strings
-	var acknowledgedAt, createdAt, completedAt string
+	var acknowledgedAt, createdAt, completedAt, lastSeenAt string
 	if !al.AcknowledgedAt.IsZero() {
 		acknowledgedAt = al.AcknowledgedAt.Format(time.RFC3339)
 	}
@@ -71,17 +136,99 @@ func (al *AlertLog) ToSummary() AlertLogSummary {
 	if !al.CompletedAt.IsZero() {
 		completedAt = al.CompletedAt.Format(time.RFC3339)
 	}
+	if !al.LastSeenAt.IsZero() {
+		lastSeenAt = al.LastSeenAt.Format(time.RFC3339)
+	}
 
 	return AlertLogSummary{
 		ID:                   al.ID.Hex(),
 		CorrelationID:        al.CorrelationID,
 		WebhookURL:           al.WebhookURL,
+		RuleName:             al.RuleName,
+		Tags:                 al.Tags,
+		Severity:             al.Severity,
+		Source:               al.Source,
+		DedupKey:             al.DedupKey,
+		Occurrences:          al.Occurrences,
+		LastSeenAt:           lastSeenAt,
 		FinalStatus:          al.FinalStatus,
 		AcknowledgmentStatus: ackStatus,
 		AcknowledgedBy:       al.AcknowledgedBy,
 		AcknowledgedAt:       acknowledgedAt,
 		AttemptsCount:        len(al.Attempts),
+		DeliveryLatencyMs:    al.DeliveryLatencyMs,
+		CommentsCount:        len(al.Comments),
 		CreatedAt:            createdAt,
 		CompletedAt:          completedAt,
 	}
 }
+
+// AlertExportRow is an alert log as exported for incident reviews and audits. Webhook URLs
+// are left out since they often embed credentials.
+type AlertExportRow struct {
+	ID                   primitive.ObjectID `bson:"_id"`
+	CreatedAt            time.Time          `bson:"created_at"`
+	CompletedAt          time.Time          `bson:"completed_at,omitempty"`
+	ConfigID             primitive.ObjectID `bson:"config_id"`
+	ExecutionID          primitive.ObjectID `bson:"execution_id"`
+	CorrelationID        string             `bson:"correlation_id"`
+	Source               string             `bson:"source,omitempty"`
+	DedupKey             string             `bson:"dedup_key,omitempty"`
+	RuleName             string             `bson:"rule_name,omitempty"`
+	Severity             string             `bson:"severity,omitempty"`
+	Tags                 []string           `bson:"tags,omitempty"`
+	Message              string             `bson:"message"`
+	WebhookHost          string             `bson:"webhook_host,omitempty"`
+	FinalStatus          string             `bson:"final_status"`
+	DeliveryLatencyMs    int64              `bson:"delivery_latency_ms,omitempty"`
+	AttemptsCount        int                `bson:"attempts_count"`
+	LastAttempt          *AlertAttempt      `bson:"last_attempt,omitempty"`
+	Occurrences          int                `bson:"occurrences,omitempty"`
+	AcknowledgmentStatus string             `bson:"acknowledgment_status"`
+	AcknowledgedBy       string             `bson:"acknowledged_by,omitempty"`
+	AcknowledgedAt       time.Time          `bson:"acknowledged_at,omitempty"`
+	Classification       string             `bson:"classification,omitempty"`
+	CommentsCount        int                `bson:"comments_count"`
+}
+
+// AlertIngestRequest represents an alert pushed into Raven by an external system
+type AlertIngestRequest struct {
+	Source   string                 `json:"source"`              // Sending system, e.g. "prometheus"
+	DedupKey string                 `json:"dedup_key,omitempty"` // Repeats with the same key update the open alert instead of notifying again
+	Summary  string                 `json:"summary"`
+	Severity string                 `json:"severity,omitempty"` // Defaults to "warning"
+	Tags     []string               `json:"tags,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	Webhook  *Webhook               `json:"webhook,omitempty"` // Alert destination; defaults to the global default webhook
+}
+
+// Validate validates an ingested alert and applies defaults
+func (r *AlertIngestRequest) Validate() error {
+	if r.Source == "" {
+		return errors.New("source is required")
+	}
+	if len(r.Source) > 100 {
+		return errors.New("source must be 100 characters or less")
+	}
+	if r.Summary == "" {
+		return errors.New("summary is required")
+	}
+	if len(r.DedupKey) > 255 {
+		return errors.New("dedup_key must be 255 characters or less")
+	}
+
+	if r.Severity == "" {
+		r.Severity = SeverityWarning
+	}
+	if !IsValidSeverity(r.Severity) {
+		return fmt.Errorf("invalid severity: %s (must be info, warning, error or critical)", r.Severity)
+	}
+
+	if r.Webhook != nil {
+		if err := r.Webhook.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit actions
+const (
+	AuditActionAutoDisabled = "auto_disabled" // Disabled after prolonged unreachability
+	AuditActionEnabled      = "enabled"       // Re-enabled through the enable or bulk endpoint
+	AuditActionDisabled     = "disabled"      // Disabled through the bulk endpoint
+)
+
+// AuditEntry records an action taken on a health check outside of config updates
+type AuditEntry struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ConfigID   primitive.ObjectID `json:"config_id" bson:"config_id"`
+	ConfigName string             `json:"config_name" bson:"config_name"`
+	Action     string             `json:"action" bson:"action"`
+	Actor      string             `json:"actor" bson:"actor"` // API key name, or "system" for automatic actions
+	Reason     string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	At         time.Time          `json:"at" bson:"at"`
+}
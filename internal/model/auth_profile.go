@@ -0,0 +1,51 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuthProfile represents named target credentials referenced by health checks
+type AuthProfile struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	Auth        Auth               `json:"auth" bson:"auth"`
+	Metadata    Metadata           `json:"metadata" bson:"metadata"`
+}
+
+// Validate validates the auth profile
+func (p *AuthProfile) Validate() error {
+	if p.Name == "" {
+		return errors.New("auth profile name is required")
+	}
+
+	if len(p.Name) > 255 {
+		return errors.New("auth profile name must be 255 characters or less")
+	}
+
+	// Profiles hold the credentials themselves and can't point at another profile
+	if !p.Auth.ProfileID.IsZero() {
+		return errors.New("auth profile cannot reference another profile")
+	}
+	switch strings.ToLower(p.Auth.Type) {
+	case "none", "":
+		return errors.New("auth profile type is required")
+	}
+	if err := p.Auth.Validate(); err != nil {
+		return fmt.Errorf("auth validation failed: %w", err)
+	}
+
+	// Set metadata timestamps
+	now := time.Now().UTC()
+	if p.Metadata.CreatedAt.IsZero() {
+		p.Metadata.CreatedAt = now
+	}
+	p.Metadata.UpdatedAt = now
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// AutoDisable disables a check that has been unreachable for a long time, e.g. a
+// decommissioned endpoint, so it stops taking scheduler slots
+type AutoDisable struct {
+	AfterFailures int `json:"after_failures" bson:"after_failures"`               // Consecutive target-unreachable runs required
+	AfterHours    int `json:"after_hours,omitempty" bson:"after_hours,omitempty"` // Minimum time since the first of those runs
+}
+
+// UnreachableStreak tracks consecutive runs in which the target could not be reached
+type UnreachableStreak struct {
+	Runs  int       `json:"runs" bson:"runs"`
+	Since time.Time `json:"since" bson:"since"` // First run of the streak
+}
+
+// Validate validates the auto-disable settings
+func (a *AutoDisable) Validate() error {
+	if a.AfterFailures < 1 {
+		return errors.New("auto_disable after_failures must be at least 1")
+	}
+	if a.AfterHours < 0 {
+		return errors.New("auto_disable after_hours cannot be negative")
+	}
+	return nil
+}
+
+// Due reports whether an unreachable streak has lasted long enough to disable the check
+func (a *AutoDisable) Due(streak *UnreachableStreak, now time.Time) bool {
+	if streak == nil || streak.Runs < a.AfterFailures {
+		return false
+	}
+	return now.Sub(streak.Since) >= time.Duration(a.AfterHours)*time.Hour
+}
+
+// Unreachable reports whether an execution failed without reaching the target
+func (e *ExecutionHistory) Unreachable() bool {
+	return e.Status == "failed" && e.Response.StatusCode == 0
+}
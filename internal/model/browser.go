@@ -0,0 +1,29 @@
+package model
+
+import "errors"
+
+// BrowserCheck configures how a browser check loads and inspects its page. Rules evaluate
+// a JSON document with the page's url, title, status, timing and values.
+type BrowserCheck struct {
+	WaitSelector string            `json:"wait_selector,omitempty" bson:"wait_selector,omitempty"` // CSS selector that must become visible before the page counts as loaded
+	Values       map[string]string `json:"values,omitempty" bson:"values,omitempty"`               // Name to CSS selector; the element's text is exposed as $.values.<name>
+}
+
+// validateBrowser validates the settings of a browser check
+func (hc *HealthCheckConfig) validateBrowser() error {
+	if hc.Target.Method != "GET" {
+		return errors.New("browser checks only support the GET method")
+	}
+	if hc.Target.HasBody() {
+		return errors.New("browser checks cannot send a request body")
+	}
+	if hc.Browser == nil {
+		return nil
+	}
+	for name, selector := range hc.Browser.Values {
+		if name == "" || selector == "" {
+			return errors.New("browser values need a name and a selector")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Actions applied to health checks in bulk
+const (
+	BulkActionDelete      = "delete"
+	BulkActionEnable      = "enable"
+	BulkActionDisable     = "disable"
+	BulkActionSetSchedule = "set-schedule"
+)
+
+// Outcomes of a bulk action on one health check
+const (
+	BulkItemDone      = "done"
+	BulkItemUnchanged = "unchanged" // The check was already in the requested state
+	BulkItemNotFound  = "not_found"
+	BulkItemFailed    = "failed"
+)
+
+// MaxBulkItems is the most health checks one bulk request may act on
+const MaxBulkItems = 500
+
+// BulkRequest applies an action to health checks selected by ID or by tags
+type BulkRequest struct {
+	Action          string   `json:"action"` // delete, enable, disable or set-schedule
+	IDs             []string `json:"ids,omitempty"`
+	Tags            []string `json:"tags,omitempty"`             // Selects the checks carrying all of these tags
+	Schedule        string   `json:"schedule,omitempty"`         // set-schedule: new cron schedule
+	ScheduleEnabled *bool    `json:"schedule_enabled,omitempty"` // set-schedule: turns scheduling on or off
+}
+
+// Validate validates a bulk request
+func (r *BulkRequest) Validate() error {
+	switch r.Action {
+	case BulkActionDelete, BulkActionEnable, BulkActionDisable:
+		if r.Schedule != "" || r.ScheduleEnabled != nil {
+			return fmt.Errorf("schedule and schedule_enabled only apply to %s", BulkActionSetSchedule)
+		}
+	case BulkActionSetSchedule:
+		if r.Schedule == "" && r.ScheduleEnabled == nil {
+			return errors.New("schedule or schedule_enabled is required for set-schedule")
+		}
+		if r.Schedule != "" {
+			if _, err := ParseSchedule(r.Schedule); err != nil {
+				return fmt.Errorf("invalid cron expression: %w", err)
+			}
+		}
+	case "":
+		return errors.New("action is required")
+	default:
+		return fmt.Errorf("invalid action: %s (must be delete, enable, disable or set-schedule)", r.Action)
+	}
+
+	switch {
+	case len(r.IDs) == 0 && len(r.Tags) == 0:
+		return errors.New("ids or tags is required")
+	case len(r.IDs) > 0 && len(r.Tags) > 0:
+		return errors.New("set either ids or tags, not both")
+	case len(r.IDs) > MaxBulkItems:
+		return fmt.Errorf("at most %d ids are allowed", MaxBulkItems)
+	}
+
+	return nil
+}
+
+// BulkItemResult is the outcome of a bulk action on one health check
+type BulkItemResult struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"` // done, unchanged, not_found or failed
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResult reports a bulk action per health check
+type BulkResult struct {
+	Action    string           `json:"action"`
+	Total     int              `json:"total"`
+	Done      int              `json:"done"`
+	Unchanged int              `json:"unchanged"`
+	Failed    int              `json:"failed"` // Not found or failed
+	Results   []BulkItemResult `json:"results"`
+}
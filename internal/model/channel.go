@@ -0,0 +1,64 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationChannel represents a reusable alert destination referenced by health checks
+type NotificationChannel struct {
+	ID             primitive.ObjectID    `json:"id" bson:"_id,omitempty"`
+	Name           string                `json:"name" bson:"name"`
+	Description    string                `json:"description,omitempty" bson:"description,omitempty"`
+	Webhook        Webhook               `json:"webhook" bson:"webhook"`
+	CircuitBreaker *CircuitBreakerConfig `json:"circuit_breaker,omitempty" bson:"circuit_breaker,omitempty"` // Overrides the default breaker settings of the webhook's host
+	Metadata       Metadata              `json:"metadata" bson:"metadata"`
+}
+
+// Validate validates the notification channel
+func (c *NotificationChannel) Validate() error {
+	if c.Name == "" {
+		return errors.New("channel name is required")
+	}
+
+	if len(c.Name) > 255 {
+		return errors.New("channel name must be 255 characters or less")
+	}
+
+	// Channels hold the destination themselves and can't point elsewhere
+	if c.Webhook.URL == "" {
+		return errors.New("channel webhook URL is required")
+	}
+	if c.Webhook.Profile != "" || !c.Webhook.ChannelID.IsZero() {
+		return errors.New("channel webhook cannot reference a profile or another channel")
+	}
+	if err := c.Webhook.Validate(); err != nil {
+		return err
+	}
+	if c.CircuitBreaker != nil {
+		if err := c.CircuitBreaker.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Set metadata timestamps
+	now := time.Now().UTC()
+	if c.Metadata.CreatedAt.IsZero() {
+		c.Metadata.CreatedAt = now
+	}
+	c.Metadata.UpdatedAt = now
+
+	return nil
+}
+
+// ChannelStats represents delivery statistics of a notification channel
+type ChannelStats struct {
+	ChannelID       string        `json:"channel_id"`
+	Total           int64         `json:"total"`
+	ByFinalStatus   []CountBucket `json:"by_final_status"`
+	ByTime          []CountBucket `json:"by_time"`
+	AvgAttempts     float64       `json:"avg_attempts"`
+	LastDeliveredAt string        `json:"last_delivered_at,omitempty"`
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	CircuitStateClosed   = "closed"
+	CircuitStateOpen     = "open"
+	CircuitStateHalfOpen = "half-open"
+)
+
+// CircuitBreakerConfig tunes the circuit breaker that stops deliveries to a failing host.
+// Zero fields use the CIRCUIT_BREAKER_* defaults.
+type CircuitBreakerConfig struct {
+	FailureThreshold int `json:"failure_threshold,omitempty" bson:"failure_threshold,omitempty"` // Failed deliveries in a row that open the breaker
+	SuccessThreshold int `json:"success_threshold,omitempty" bson:"success_threshold,omitempty"` // Successful half-open deliveries that close it again
+	TimeoutSeconds   int `json:"timeout_seconds,omitempty" bson:"timeout_seconds,omitempty"`     // How long the breaker stays open before trying again
+
+	HalfOpenMaxProbes   int  `json:"half_open_max_probes,omitempty" bson:"half_open_max_probes,omitempty"`     // Deliveries let through at once while half-open
+	HalfOpenWaitSeconds *int `json:"half_open_wait_seconds,omitempty" bson:"half_open_wait_seconds,omitempty"` // How long other deliveries wait for a probe to finish; 0 fails them at once
+}
+
+// Validate validates circuit breaker settings
+func (c *CircuitBreakerConfig) Validate() error {
+	if c.FailureThreshold < 0 || c.FailureThreshold > 1000 {
+		return errors.New("circuit_breaker.failure_threshold must be between 1 and 1000")
+	}
+	if c.SuccessThreshold < 0 || c.SuccessThreshold > 100 {
+		return errors.New("circuit_breaker.success_threshold must be between 1 and 100")
+	}
+	if c.TimeoutSeconds < 0 || c.TimeoutSeconds > 86400 {
+		return errors.New("circuit_breaker.timeout_seconds must be between 1 and 86400")
+	}
+	if c.HalfOpenMaxProbes < 0 || c.HalfOpenMaxProbes > 100 {
+		return errors.New("circuit_breaker.half_open_max_probes must be between 1 and 100")
+	}
+	if c.HalfOpenWaitSeconds != nil && (*c.HalfOpenWaitSeconds < 0 || *c.HalfOpenWaitSeconds > 300) {
+		return errors.New("circuit_breaker.half_open_wait_seconds must be between 0 and 300")
+	}
+	return nil
+}
+
+// WithDefaults returns the settings with unset fields taken from defaults
+func (c CircuitBreakerConfig) WithDefaults(defaults CircuitBreakerConfig) CircuitBreakerConfig {
+	if c.FailureThreshold == 0 {
+		c.FailureThreshold = defaults.FailureThreshold
+	}
+	if c.SuccessThreshold == 0 {
+		c.SuccessThreshold = defaults.SuccessThreshold
+	}
+	if c.TimeoutSeconds == 0 {
+		c.TimeoutSeconds = defaults.TimeoutSeconds
+	}
+	if c.HalfOpenMaxProbes == 0 {
+		c.HalfOpenMaxProbes = defaults.HalfOpenMaxProbes
+	}
+	if c.HalfOpenWaitSeconds == nil {
+		c.HalfOpenWaitSeconds = defaults.HalfOpenWaitSeconds
+	}
+	return c
+}
+
+// Timeout returns how long the breaker stays open
+func (c CircuitBreakerConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// HalfOpenWait returns how long deliveries wait for a free half-open probe slot
+func (c CircuitBreakerConfig) HalfOpenWait() time.Duration {
+	if c.HalfOpenWaitSeconds == nil {
+		return 0
+	}
+	return time.Duration(*c.HalfOpenWaitSeconds) * time.Second
+}
+
+// CircuitBreakerStatus describes the circuit breaker of one destination host on this instance
+type CircuitBreakerStatus struct {
+	Host            string               `json:"host"`
+	ChannelID       string               `json:"channel_id,omitempty"` // Set for the breaker of a channel with its own settings
+	State           string               `json:"state"`                // closed, open or half-open
+	StateSince      time.Time            `json:"state_since"`
+	StateAgeSeconds int64                `json:"state_age_seconds"`
+	FailureCount    int                  `json:"failure_count"`
+	SuccessCount    int                  `json:"success_count"`
+	ProbesInFlight  int                  `json:"probes_in_flight"` // Half-open deliveries under way
+	LastFailureAt   *time.Time           `json:"last_failure_at,omitempty"`
+	RetryAt         *time.Time           `json:"retry_at,omitempty"` // When an open breaker lets the next delivery through
+	Config          CircuitBreakerConfig `json:"config"`
+}
@@ -1,23 +1,43 @@
 package model
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
+
+	"github.com/oliveagle/jsonpath"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Auth represents authentication configuration
 type Auth struct {
-	Type     string `json:"type" bson:"type"`                             // "basic" | "bearer" | "none"
-	Username string `json:"username,omitempty" bson:"username,omitempty"` // For basic auth
-	Password string `json:"password,omitempty" bson:"password,omitempty"` // For basic auth
-	Token    string `json:"token,omitempty" bson:"token,omitempty"`       // For bearer token
+	Type         string             `json:"type,omitempty" bson:"type,omitempty"`                   // "basic" | "bearer" | "oauth2" | "none"
+	ProfileID    primitive.ObjectID `json:"profile_id,omitempty" bson:"profile_id,omitempty"`       // Stored auth profile to use instead of inline credentials
+	Username     string             `json:"username,omitempty" bson:"username,omitempty"`           // For basic auth
+	Password     string             `json:"password,omitempty" bson:"password,omitempty"`           // For basic auth
+	Token        string             `json:"token,omitempty" bson:"token,omitempty"`                 // For bearer token
+	TokenURL     string             `json:"token_url,omitempty" bson:"token_url,omitempty"`         // For oauth2 client credentials
+	ClientID     string             `json:"client_id,omitempty" bson:"client_id,omitempty"`         // For oauth2 client credentials
+	ClientSecret string             `json:"client_secret,omitempty" bson:"client_secret,omitempty"` // For oauth2 client credentials
+	Scopes       []string           `json:"scopes,omitempty" bson:"scopes,omitempty"`               // For oauth2 client credentials
 }
 
 // Validate validates auth configuration
 func (a *Auth) Validate() error {
+	// A profile reference carries no credentials of its own
+	if !a.ProfileID.IsZero() {
+		if a.Type != "" || a.Username != "" || a.Password != "" || a.Token != "" ||
+			a.TokenURL != "" || a.ClientID != "" || a.ClientSecret != "" || len(a.Scopes) > 0 {
+			return errors.New("profile_id cannot be combined with inline credentials")
+		}
+		return nil
+	}
+
 	switch strings.ToLower(a.Type) {
 	case "basic":
 		if a.Username == "" || a.Password == "" {
@@ -27,10 +47,18 @@ func (a *Auth) Validate() error {
 		if a.Token == "" {
 			return errors.New("token required for bearer auth")
 		}
+	case "oauth2":
+		if a.TokenURL == "" || a.ClientID == "" || a.ClientSecret == "" {
+			return errors.New("token_url, client_id and client_secret required for oauth2 auth")
+		}
+		parsedURL, err := url.Parse(a.TokenURL)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return errors.New("oauth2 token_url must be a valid http or https URL")
+		}
 	case "none", "":
 		// No validation needed
 	default:
-		return fmt.Errorf("invalid auth type: %s (must be 'basic', 'bearer', or 'none')", a.Type)
+		return fmt.Errorf("invalid auth type: %s (must be 'basic', 'bearer', 'oauth2', or 'none')", a.Type)
 	}
 	return nil
 }
@@ -43,8 +71,52 @@ type Target struct {
 	Body    string            `json:"body,omitempty" bson:"body,omitempty"`
 	Auth    Auth              `json:"auth,omitempty" bson:"auth,omitempty"`
 	Timeout int               `json:"timeout,omitempty" bson:"timeout,omitempty"` // In seconds
+
+	BodyType       string      `json:"body_type,omitempty" bson:"body_type,omitempty"`             // raw (default), json, form or multipart
+	FormFields     []FormField `json:"form_fields,omitempty" bson:"form_fields,omitempty"`         // Body of form and multipart requests
+	ExpectContinue bool        `json:"expect_continue,omitempty" bson:"expect_continue,omitempty"` // Send Expect: 100-continue and wait for the server before the body
+
+	HeaderTimeout       int   `json:"header_timeout,omitempty" bson:"header_timeout,omitempty"`                 // In seconds; limit on waiting for response headers
+	BodyTimeout         int   `json:"body_timeout,omitempty" bson:"body_timeout,omitempty"`                     // In seconds; limit on reading the response body
+	RetryOnNetworkError *bool `json:"retry_on_network_error,omitempty" bson:"retry_on_network_error,omitempty"` // Overrides TARGET_RETRY_ON_NETWORK_ERROR
+	RetryBudget         int   `json:"retry_budget,omitempty" bson:"retry_budget,omitempty"`                     // Overrides TARGET_RETRY_BUDGET
+	RetryBackoffMs      int   `json:"retry_backoff_ms,omitempty" bson:"retry_backoff_ms,omitempty"`             // Overrides TARGET_RETRY_BACKOFF_MS
+	RetryOnStatus       []int `json:"retry_on_status,omitempty" bson:"retry_on_status,omitempty"`               // Overrides TARGET_RETRY_ON_STATUS
+
+	IPVersion int               `json:"ip_version,omitempty" bson:"ip_version,omitempty"` // 4 or 6 forces the address family
+	Resolve   map[string]string `json:"resolve,omitempty" bson:"resolve,omitempty"`       // Host to IP overrides, like curl --resolve
 }
 
+// Target body types
+const (
+	BodyTypeRaw       = "raw"       // Body sent as-is
+	BodyTypeJSON      = "json"      // Body sent as application/json
+	BodyTypeForm      = "form"      // Form fields sent as application/x-www-form-urlencoded
+	BodyTypeMultipart = "multipart" // Form fields sent as multipart/form-data
+)
+
+// FormField is a field of a form or multipart request body
+type FormField struct {
+	Name        string `json:"name" bson:"name"`
+	Value       string `json:"value" bson:"value"`
+	Filename    string `json:"filename,omitempty" bson:"filename,omitempty"`         // Sends the value as a file part (multipart only)
+	ContentType string `json:"content_type,omitempty" bson:"content_type,omitempty"` // Content type of a file part
+}
+
+// HasBody reports whether requests to the target carry a body
+func (t *Target) HasBody() bool {
+	return t.Body != "" || len(t.FormFields) > 0
+}
+
+// methodPattern matches HTTP method tokens such as GET, PROPFIND or PURGE
+var methodPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_-]{0,31}$`)
+
+// MaxTargetRetryBudget caps the retries a single execution can make
+const MaxTargetRetryBudget = 5
+
+// MaxTargetRetryBackoffMs caps the initial delay before a target retry
+const MaxTargetRetryBackoffMs = 30000
+
 // Validate validates target configuration
 func (t *Target) Validate() error {
 	if t.URL == "" {
@@ -60,14 +132,22 @@ func (t *Target) Validate() error {
 		return errors.New("URL must start with http:// or https://")
 	}
 
-	// Validate HTTP method
-	validMethods := map[string]bool{
-		"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true,
-	}
-	if !validMethods[strings.ToUpper(t.Method)] {
+	// Validate HTTP method; custom methods are allowed except those that tunnel or echo the request
+	method := strings.ToUpper(t.Method)
+	if !methodPattern.MatchString(method) || method == "CONNECT" || method == "TRACE" {
 		return fmt.Errorf("invalid HTTP method: %s", t.Method)
 	}
-	t.Method = strings.ToUpper(t.Method)
+	t.Method = method
+
+	if err := t.validateBody(); err != nil {
+		return err
+	}
+	if t.Method == "HEAD" && t.HasBody() {
+		return errors.New("HEAD requests cannot have a body")
+	}
+	if t.ExpectContinue && !t.HasBody() {
+		return errors.New("expect_continue requires a request body")
+	}
 
 	// Validate auth if present
 	if err := t.Auth.Validate(); err != nil {
@@ -79,17 +159,222 @@ func (t *Target) Validate() error {
 		t.Timeout = 30
 	}
 
+	if t.HeaderTimeout < 0 || t.BodyTimeout < 0 {
+		return errors.New("header_timeout and body_timeout cannot be negative")
+	}
+	if t.RetryBudget < 0 || t.RetryBudget > MaxTargetRetryBudget {
+		return fmt.Errorf("retry_budget must be between 0 and %d", MaxTargetRetryBudget)
+	}
+	if t.RetryBackoffMs < 0 || t.RetryBackoffMs > MaxTargetRetryBackoffMs {
+		return fmt.Errorf("retry_backoff_ms must be between 0 and %d", MaxTargetRetryBackoffMs)
+	}
+	for _, code := range t.RetryOnStatus {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("invalid retry_on_status code: %d", code)
+		}
+	}
+
+	if t.IPVersion != 0 && t.IPVersion != 4 && t.IPVersion != 6 {
+		return fmt.Errorf("invalid ip_version: %d (must be 4 or 6)", t.IPVersion)
+	}
+	for host, address := range t.Resolve {
+		if host == "" {
+			return errors.New("resolve host cannot be empty")
+		}
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return fmt.Errorf("invalid resolve address for %s: %s", host, address)
+		}
+		if (t.IPVersion == 4 && ip.To4() == nil) || (t.IPVersion == 6 && ip.To4() != nil) {
+			return fmt.Errorf("resolve address for %s is not an IPv%d address: %s", host, t.IPVersion, address)
+		}
+	}
+
 	return nil
 }
 
-// Rule represents a JSONPath evaluation rule
+// validateBody checks the body matches the body type
+func (t *Target) validateBody() error {
+	t.BodyType = strings.ToLower(t.BodyType)
+	switch t.BodyType {
+	case "", BodyTypeRaw:
+		if len(t.FormFields) > 0 {
+			return errors.New("form_fields require body_type form or multipart")
+		}
+	case BodyTypeJSON:
+		if len(t.FormFields) > 0 {
+			return errors.New("form_fields require body_type form or multipart")
+		}
+		if t.Body != "" && !json.Valid([]byte(t.Body)) {
+			return errors.New("body is not valid JSON")
+		}
+	case BodyTypeForm, BodyTypeMultipart:
+		if t.Body != "" {
+			return fmt.Errorf("body_type %s takes form_fields instead of body", t.BodyType)
+		}
+		for _, field := range t.FormFields {
+			if field.Name == "" {
+				return errors.New("form field name is required")
+			}
+			if t.BodyType == BodyTypeForm && (field.Filename != "" || field.ContentType != "") {
+				return fmt.Errorf("form field %s: filename and content_type require body_type multipart", field.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid body_type: %s (must be 'raw', 'json', 'form' or 'multipart')", t.BodyType)
+	}
+	return nil
+}
+
+// Rule sources
+const (
+	RuleSourceBody         = "body"
+	RuleSourceRaw          = "raw"    // Body as plain text, for HTML and other non-JSON targets
+	RuleSourceScript       = "script" // Starlark script over the response envelope
+	ruleSourceHeaderPrefix = "header:"
+)
+
+// Rule represents a JSONPath or script evaluation rule
 type Rule struct {
 	Name          string      `json:"name" bson:"name"`
 	Description   string      `json:"description,omitempty" bson:"description,omitempty"`
-	Expression    string      `json:"expression" bson:"expression"`         // JSONPath expression
-	Operator      string      `json:"operator" bson:"operator"`             // eq, ne, gt, lt, gte, lte, contains, exists, regex
-	ExpectedValue interface{} `json:"expected_value" bson:"expected_value"` // Expected value
-	AlertOnMatch  bool        `json:"alert_on_match" bson:"alert_on_match"` // Trigger alert if rule matches
+	Source        string      `json:"source,omitempty" bson:"source,omitempty"`     // "body" (default), "raw", "script" or "header:<Name>"
+	Expression    string      `json:"expression" bson:"expression"`                 // JSONPath expression, or the Starlark source of script rules; unused for header sources
+	Operator      string      `json:"operator" bson:"operator"`                     // eq, ne, gt, lt, gte, lte, contains, exists, regex
+	ExpectedValue interface{} `json:"expected_value" bson:"expected_value"`         // Expected value
+	AlertOnMatch  bool        `json:"alert_on_match" bson:"alert_on_match"`         // Trigger alert if rule matches
+	Trend         *Trend      `json:"trend,omitempty" bson:"trend,omitempty"`       // Compare an aggregate of recent values instead of the current value
+	Baseline      *Baseline   `json:"baseline,omitempty" bson:"baseline,omitempty"` // Statistical baseline for the "anomaly" operator
+	Metric        bool        `json:"metric,omitempty" bson:"metric,omitempty"`     // Store the extracted numeric value as a time-series sample
+	Severity      string      `json:"severity,omitempty" bson:"severity,omitempty"` // info, warning, error or critical; overrides the config's severity mapping
+}
+
+// OperatorChanged matches when a rule's value differs from the previous execution
+const OperatorChanged = "changed"
+
+// Trend functions
+const (
+	TrendAvg       = "avg"
+	TrendMin       = "min"
+	TrendMax       = "max"
+	TrendP50       = "p50"
+	TrendP90       = "p90"
+	TrendP95       = "p95"
+	TrendP99       = "p99"
+	TrendChangePct = "change_pct"
+)
+
+// defaultTrendRuns is the number of previous executions a trend covers when no bound is set
+const defaultTrendRuns = 10
+
+// Trend aggregates the values a rule extracted in recent executions
+type Trend struct {
+	Function string `json:"function" bson:"function"`                       // avg, min, max, p50, p90, p95, p99 or change_pct (current vs. average)
+	LastRuns int    `json:"last_runs,omitempty" bson:"last_runs,omitempty"` // Previous executions to include
+	Window   string `json:"window,omitempty" bson:"window,omitempty"`       // Only include executions within this duration, e.g. "1h"
+}
+
+// Validate validates the trend configuration and applies defaults
+func (t *Trend) Validate() error {
+	switch t.Function {
+	case TrendAvg, TrendMin, TrendMax, TrendP50, TrendP90, TrendP95, TrendP99, TrendChangePct:
+	default:
+		return fmt.Errorf("invalid trend function: %s", t.Function)
+	}
+
+	if t.LastRuns < 0 || t.LastRuns > 1000 {
+		return errors.New("trend last_runs must be between 0 and 1000")
+	}
+
+	if t.Window != "" {
+		window, err := time.ParseDuration(t.Window)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("invalid trend window: %s", t.Window)
+		}
+	}
+
+	if t.LastRuns == 0 && t.Window == "" {
+		t.LastRuns = defaultTrendRuns
+	}
+
+	return nil
+}
+
+// Baseline methods
+const (
+	BaselineStdDev = "stddev"
+	BaselineEWMA   = "ewma"
+)
+
+// Baseline defaults
+const (
+	defaultBaselineRuns       = 30
+	defaultBaselineMinSamples = 10
+	defaultBaselineAlpha      = 0.3
+	DefaultAnomalySigma       = 3.0
+)
+
+// Baseline describes how the expected range of a rule's value is learned from recent executions
+type Baseline struct {
+	Method     string  `json:"method,omitempty" bson:"method,omitempty"`           // "stddev" (rolling mean/stddev, default) or "ewma"
+	LastRuns   int     `json:"last_runs,omitempty" bson:"last_runs,omitempty"`     // Previous executions to learn from (default 30)
+	Window     string  `json:"window,omitempty" bson:"window,omitempty"`           // Only learn from executions within this duration
+	Alpha      float64 `json:"alpha,omitempty" bson:"alpha,omitempty"`             // EWMA smoothing factor (default 0.3)
+	MinSamples int     `json:"min_samples,omitempty" bson:"min_samples,omitempty"` // Don't flag anomalies before this many samples (default 10)
+}
+
+// Validate validates the baseline configuration and applies defaults
+func (b *Baseline) Validate() error {
+	switch b.Method {
+	case "":
+		b.Method = BaselineStdDev
+	case BaselineStdDev, BaselineEWMA:
+	default:
+		return fmt.Errorf("invalid baseline method: %s", b.Method)
+	}
+
+	if b.LastRuns < 0 || b.LastRuns > 1000 {
+		return errors.New("baseline last_runs must be between 0 and 1000")
+	}
+
+	if b.Window != "" {
+		window, err := time.ParseDuration(b.Window)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("invalid baseline window: %s", b.Window)
+		}
+	}
+
+	if b.Alpha < 0 || b.Alpha > 1 {
+		return errors.New("baseline alpha must be between 0 and 1")
+	}
+	if b.Alpha == 0 {
+		b.Alpha = defaultBaselineAlpha
+	}
+
+	if b.MinSamples < 0 {
+		return errors.New("baseline min_samples cannot be negative")
+	}
+	if b.MinSamples == 0 {
+		b.MinSamples = defaultBaselineMinSamples
+	}
+
+	if b.LastRuns == 0 && b.Window == "" {
+		b.LastRuns = defaultBaselineRuns
+	}
+
+	return nil
+}
+
+// WindowDuration returns the baseline window, or zero when none is set
+func (b *Baseline) WindowDuration() time.Duration {
+	window, _ := time.ParseDuration(b.Window)
+	return window
+}
+
+// WindowDuration returns the trend window, or zero when none is set
+func (t *Trend) WindowDuration() time.Duration {
+	window, _ := time.ParseDuration(t.Window)
+	return window
 }
 
 // Validate validates rule configuration
@@ -97,7 +382,24 @@ func (r *Rule) Validate() error {
 	if r.Name == "" {
 		return errors.New("rule name is required")
 	}
-	if r.Expression == "" {
+	// Validate source
+	headerName, isHeader := r.HeaderName()
+	switch {
+	case isHeader:
+		if headerName == "" {
+			return errors.New("rule source header name is required")
+		}
+	case r.IsRaw():
+		r.Source = RuleSourceRaw
+	case r.IsScript():
+		if r.Expression == "" {
+			return errors.New("rule script is required in expression")
+		}
+		r.Source = RuleSourceScript
+	case r.Source != "" && r.Source != RuleSourceBody:
+		return fmt.Errorf("invalid rule source: %s (must be 'body', 'raw', 'script' or 'header:<name>')", r.Source)
+	case r.Expression == "" && !strings.EqualFold(r.Operator, OperatorChanged):
+		// Change rules without an expression watch the whole body
 		return errors.New("rule expression is required")
 	}
 
@@ -105,15 +407,101 @@ func (r *Rule) Validate() error {
 	validOperators := map[string]bool{
 		"eq": true, "ne": true, "gt": true, "lt": true,
 		"gte": true, "lte": true, "contains": true, "exists": true, "regex": true,
+		"anomaly": true, OperatorChanged: true, "not_contains": true,
 	}
 	if !validOperators[strings.ToLower(r.Operator)] {
 		return fmt.Errorf("invalid operator: %s", r.Operator)
 	}
 	r.Operator = strings.ToLower(r.Operator)
 
+	if r.Severity != "" && !IsValidSeverity(r.Severity) {
+		return fmt.Errorf("invalid severity: %s", r.Severity)
+	}
+
+	if err := r.validateExpressions(); err != nil {
+		return err
+	}
+
+	if r.Trend != nil {
+		if err := r.Trend.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if r.IsRaw() {
+		switch r.Operator {
+		case "contains", "not_contains", "regex", "eq", "ne", OperatorChanged:
+		default:
+			return fmt.Errorf("raw rules support contains, not_contains, regex, eq, ne and changed, not %s", r.Operator)
+		}
+		if r.Trend != nil {
+			return errors.New("raw rules cannot use a trend")
+		}
+	}
+
+	if r.Operator == OperatorChanged && r.Trend != nil {
+		return errors.New("changed rules cannot use a trend")
+	}
+
+	if r.Operator == "anomaly" {
+		if r.Trend != nil {
+			return errors.New("anomaly rules cannot use a trend")
+		}
+		if r.ExpectedValue != nil {
+			if sigma, ok := r.ExpectedValue.(float64); !ok || sigma <= 0 {
+				return errors.New("anomaly expected_value must be a positive number of standard deviations")
+			}
+		}
+		if r.Baseline == nil {
+			r.Baseline = &Baseline{}
+		}
+		if err := r.Baseline.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateExpressions compiles the rule's JSONPath expression or script and regex pattern so
+// mistakes are reported when the config is saved rather than on every run
+func (r *Rule) validateExpressions() error {
+	if r.IsScript() {
+		if _, err := CompileScript(r.Expression, RuleScriptGlobals); err != nil {
+			return fmt.Errorf("invalid script: %v", err)
+		}
+	} else if _, isHeader := r.HeaderName(); !isHeader && !r.IsRaw() && r.Expression != "" {
+		if _, err := jsonpath.Compile(r.Expression); err != nil {
+			return fmt.Errorf("invalid JSONPath expression '%s': %v", r.Expression, err)
+		}
+	}
+
+	if r.Operator == "regex" {
+		pattern, ok := r.ExpectedValue.(string)
+		if !ok {
+			return errors.New("regex expected_value must be a string pattern")
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regex pattern '%s': %v", pattern, err)
+		}
+	}
+
 	return nil
 }
 
+// IsRaw reports whether the rule reads the body as plain text instead of JSON
+func (r *Rule) IsRaw() bool {
+	return strings.EqualFold(r.Source, RuleSourceRaw)
+}
+
+// HeaderName returns the response header a rule reads when its source is "header:<Name>"
+func (r *Rule) HeaderName() (string, bool) {
+	if !strings.HasPrefix(strings.ToLower(r.Source), ruleSourceHeaderPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(r.Source[len(ruleSourceHeaderPrefix):]), true
+}
+
 // RetryConfig represents webhook retry configuration
 type RetryConfig struct {
 	MaxAttempts    int     `json:"max_attempts" bson:"max_attempts"`
@@ -140,16 +528,57 @@ func (rc *RetryConfig) SetDefaults() {
 
 // Webhook represents webhook alert configuration
 type Webhook struct {
-	URL         string            `json:"url" bson:"url"`
-	Method      string            `json:"method" bson:"method"`
-	Headers     map[string]string `json:"headers,omitempty" bson:"headers,omitempty"`
-	RetryConfig RetryConfig       `json:"retry_config,omitempty" bson:"retry_config,omitempty"`
+	ChannelID   primitive.ObjectID `json:"channel_id,omitempty" bson:"channel_id,omitempty"` // Notification channel used instead of URL
+	Profile     string             `json:"profile,omitempty" bson:"profile,omitempty"`       // Named webhook profile used instead of URL
+	Type        string             `json:"type,omitempty" bson:"type,omitempty"`             // webhook (default), slack, email or a plugin type
+	URL         string             `json:"url,omitempty" bson:"url,omitempty"`               // mailto:a@example.com,b@example.com for email
+	Method      string             `json:"method" bson:"method"`
+	Headers     map[string]string  `json:"headers,omitempty" bson:"headers,omitempty"`
+	Settings    map[string]string  `json:"settings,omitempty" bson:"settings,omitempty"` // Type-specific settings of plugin notification types
+	RetryConfig RetryConfig        `json:"retry_config,omitempty" bson:"retry_config,omitempty"`
+
+	CircuitBreaker *CircuitBreakerConfig `json:"-" bson:"-"` // Set from the notification channel when resolved
 }
 
-// Validate validates webhook configuration
+// Validate validates webhook configuration. An empty webhook is valid and
+// falls back to the global default webhook.
 func (w *Webhook) Validate() error {
+	if !w.ChannelID.IsZero() {
+		if w.URL != "" || w.Profile != "" {
+			return errors.New("webhook channel_id, profile and URL are mutually exclusive")
+		}
+		if w.Type != "" || len(w.Settings) > 0 {
+			return errors.New("webhook type and settings are taken from the channel")
+		}
+		return nil
+	}
+
+	if w.Profile != "" {
+		if w.URL != "" {
+			return errors.New("webhook profile and URL are mutually exclusive")
+		}
+		if w.Type != "" || len(w.Settings) > 0 {
+			return errors.New("webhook type and settings are taken from the profile")
+		}
+		return nil
+	}
+
 	if w.URL == "" {
-		return errors.New("webhook URL is required")
+		if w.Type != "" || len(w.Settings) > 0 {
+			return errors.New("webhook URL is required")
+		}
+		return nil
+	}
+
+	switch w.Type {
+	case "", WebhookTypeWebhook, WebhookTypeSlack:
+	case WebhookTypeEmail:
+		return w.validateEmail()
+	default:
+		return w.validatePlugin()
+	}
+	if len(w.Settings) > 0 {
+		return errors.New("webhook settings require a plugin notification type")
 	}
 
 	// Validate URL format
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Downtime records an interval a check spent in the down state
+type Downtime struct {
+	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ConfigID           primitive.ObjectID `json:"config_id" bson:"config_id"`
+	ConfigName         string             `json:"config_name" bson:"config_name"`
+	StartedAt          time.Time          `json:"started_at" bson:"started_at"`
+	EndedAt            *time.Time         `json:"ended_at,omitempty" bson:"ended_at,omitempty"`       // Nil while the check is still down
+	DurationMs         int64              `json:"duration_ms,omitempty" bson:"duration_ms,omitempty"` // Set when the downtime ends
+	StartCorrelationID string             `json:"start_correlation_id" bson:"start_correlation_id"`
+	EndCorrelationID   string             `json:"end_correlation_id,omitempty" bson:"end_correlation_id,omitempty"`
+}
+
+// DowntimeReport summarizes a check's downtime over a period
+type DowntimeReport struct {
+	ConfigID      primitive.ObjectID `json:"config_id"`
+	ConfigName    string             `json:"config_name"`
+	Incidents     int                `json:"incidents"`      // Downtimes that started in the period
+	Resolved      int                `json:"resolved"`       // Downtimes that ended in the period
+	DowntimeMs    int64              `json:"downtime_ms"`    // Time spent down within the period
+	UptimePercent float64            `json:"uptime_percent"` // Share of the period the check was not down
+	MTTRMs        int64              `json:"mttr_ms"`        // Mean duration of the downtimes resolved in the period
+	MTBFMs        int64              `json:"mtbf_ms"`        // Time up in the period divided by incidents
+}
+
+// DowntimeReportResponse represents the downtime report response
+type DowntimeReportResponse struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Results []DowntimeReport `json:"results"`
+}
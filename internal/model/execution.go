@@ -16,21 +16,39 @@ type ExecutionRequest struct {
 
 // ExecutionResponse represents the HTTP response from target API
 type ExecutionResponse struct {
-	StatusCode int               `json:"status_code" bson:"status_code"`
-	Headers    map[string]string `json:"headers" bson:"headers"`
-	Body       string            `json:"body" bson:"body"`
-	Error      string            `json:"error,omitempty" bson:"error,omitempty"`
+	StatusCode  int               `json:"status_code" bson:"status_code"`
+	Headers     map[string]string `json:"headers" bson:"headers"`
+	Body        string            `json:"body" bson:"body"`
+	BodyNotJSON bool              `json:"body_not_json,omitempty" bson:"body_not_json,omitempty"` // Body is set but isn't valid JSON; JSONPath rules on it fail
+	Error       string            `json:"error,omitempty" bson:"error,omitempty"`
+
+	TransformedBody string `json:"transformed_body,omitempty" bson:"transformed_body,omitempty"` // Body rules were evaluated against, when the config has a transform
+	BodyOmitted     bool   `json:"body_omitted,omitempty" bson:"body_omitted,omitempty"`         // Body wasn't stored because this successful run wasn't sampled
 }
 
 // RuleEvaluation represents the result of a single rule evaluation
 type RuleEvaluation struct {
-	RuleName       string      `json:"rule_name" bson:"rule_name"`
-	Expression     string      `json:"expression" bson:"expression"`
-	ExtractedValue interface{} `json:"extracted_value" bson:"extracted_value"`
-	ExpectedValue  interface{} `json:"expected_value" bson:"expected_value"`
-	Operator       string      `json:"operator" bson:"operator"`
-	Matched        bool        `json:"matched" bson:"matched"`
-	Error          string      `json:"error,omitempty" bson:"error,omitempty"`
+	RuleName       string         `json:"rule_name" bson:"rule_name"`
+	Source         string         `json:"source,omitempty" bson:"source,omitempty"`
+	Expression     string         `json:"expression" bson:"expression"`
+	ExtractedValue interface{}    `json:"extracted_value" bson:"extracted_value"`
+	TrendValue     *float64       `json:"trend_value,omitempty" bson:"trend_value,omitempty"`       // Aggregate compared by trend rules
+	Baseline       *BaselineStats `json:"baseline,omitempty" bson:"baseline,omitempty"`             // Learned range for anomaly rules
+	PreviousValue  interface{}    `json:"previous_value,omitempty" bson:"previous_value,omitempty"` // Fingerprint compared by change rules
+	ExpectedValue  interface{}    `json:"expected_value" bson:"expected_value"`
+	Operator       string         `json:"operator" bson:"operator"`
+	Matched        bool           `json:"matched" bson:"matched"`
+	AlertOnMatch   bool           `json:"alert_on_match" bson:"alert_on_match"`
+	Error          string         `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// BaselineStats represents the learned baseline an anomaly rule compared against
+type BaselineStats struct {
+	Method    string  `json:"method" bson:"method"`
+	Mean      float64 `json:"mean" bson:"mean"`
+	StdDev    float64 `json:"stddev" bson:"stddev"`
+	Samples   int     `json:"samples" bson:"samples"`
+	Deviation float64 `json:"deviation" bson:"deviation"` // Distance from the mean in standard deviations
 }
 
 // AlertTriggered represents an alert that was triggered
@@ -38,28 +56,88 @@ type AlertTriggered struct {
 	AlertID         primitive.ObjectID `json:"alert_id" bson:"alert_id"`
 	TriggeredByRule string             `json:"triggered_by_rule" bson:"triggered_by_rule"`
 	WebhookURL      string             `json:"webhook_url" bson:"webhook_url"`
+	DeliveryStatus  string             `json:"delivery_status" bson:"delivery_status"` // "pending", "delivered", "failed"
 }
 
+// Trigger types recorded in ExecutionMetadata
+const (
+	TriggerManual     = "manual"     // Single execution requested through the API
+	TriggerAPI        = "api"        // Batch or suite execution requested through the API
+	TriggerScheduled  = "scheduled"  // Cron schedule, including agent-probed checks
+	TriggerWebhook    = "webhook"    // External system calling a trigger token URL
+	TriggerDeployment = "deployment" // Post-deploy verification from a GitHub/GitLab deployment event
+)
+
 // ExecutionMetadata represents execution metadata
 type ExecutionMetadata struct {
 	TriggeredBy string `json:"triggered_by,omitempty" bson:"triggered_by,omitempty"`
+	TriggerType string `json:"trigger_type,omitempty" bson:"trigger_type,omitempty"` // "manual", "api", "scheduled"
 	Environment string `json:"environment,omitempty" bson:"environment,omitempty"`
+	PodID       string `json:"pod_id,omitempty" bson:"pod_id,omitempty"` // Instance that ran or recorded the execution
+	Region      string `json:"region,omitempty" bson:"region,omitempty"`
+	AgentID     string `json:"agent_id,omitempty" bson:"agent_id,omitempty"`
+
+	RequestCorrelationID string `json:"request_correlation_id,omitempty" bson:"request_correlation_id,omitempty"` // Correlation ID of the API request that started a batch
 }
 
 // ExecutionHistory represents a complete execution history document
 type ExecutionHistory struct {
-	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	CorrelationID   string             `json:"correlation_id" bson:"correlation_id"`
-	ConfigID        primitive.ObjectID `json:"config_id" bson:"config_id"`
-	ConfigName      string             `json:"config_name" bson:"config_name"`
-	ExecutedAt      time.Time          `json:"executed_at" bson:"executed_at"`
-	DurationMs      int64              `json:"duration_ms" bson:"duration_ms"`
-	Request         ExecutionRequest   `json:"request" bson:"request"`
-	Response        ExecutionResponse  `json:"response" bson:"response"`
-	RulesEvaluation []RuleEvaluation   `json:"rules_evaluation" bson:"rules_evaluation"`
-	AlertsTriggered []AlertTriggered   `json:"alerts_triggered" bson:"alerts_triggered"`
-	Status          string             `json:"status" bson:"status"` // "success", "failed", "partial"
-	Metadata        ExecutionMetadata  `json:"metadata" bson:"metadata"`
+	ID              primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	CorrelationID   string               `json:"correlation_id" bson:"correlation_id"`
+	ConfigID        primitive.ObjectID   `json:"config_id" bson:"config_id"`
+	ConfigName      string               `json:"config_name" bson:"config_name"`
+	BatchID         string               `json:"batch_id,omitempty" bson:"batch_id,omitempty"`
+	ExecutedAt      time.Time            `json:"executed_at" bson:"executed_at"`
+	DurationMs      int64                `json:"duration_ms" bson:"duration_ms"`
+	Request         ExecutionRequest     `json:"request" bson:"request"`
+	Response        ExecutionResponse    `json:"response" bson:"response"`
+	RulesEvaluation []RuleEvaluation     `json:"rules_evaluation" bson:"rules_evaluation"`
+	AlertsTriggered []AlertTriggered     `json:"alerts_triggered" bson:"alerts_triggered"`
+	Status          string               `json:"status" bson:"status"`                             // "success", "failed", "partial", "blocked", "budget_exceeded", "timeout", "interrupted", "skipped", "error"
+	BlockedBy       []primitive.ObjectID `json:"blocked_by,omitempty" bson:"blocked_by,omitempty"` // Failing parent checks when status is "blocked"
+	Confirmation    *ConfirmationCheck   `json:"confirmation,omitempty" bson:"confirmation,omitempty"`
+	TargetRetries   *TargetRetries       `json:"target_retries,omitempty" bson:"target_retries,omitempty"`     // Set when target retries were enabled
+	RequestAttempts []RequestAttempt     `json:"request_attempts,omitempty" bson:"request_attempts,omitempty"` // Every target call made, when target retries were enabled
+	Hooks           []HookResult         `json:"hooks,omitempty" bson:"hooks,omitempty"`                       // Pre and post hooks that ran
+	Truncated       bool                 `json:"truncated,omitempty" bson:"truncated,omitempty"`               // Bodies or rule values were cut to fit the document size limit
+	Metadata        ExecutionMetadata    `json:"metadata" bson:"metadata"`
+}
+
+// RequestAttempt records one call made to the target during an execution
+type RequestAttempt struct {
+	Attempt    int       `json:"attempt" bson:"attempt"` // Starting at 1
+	StartedAt  time.Time `json:"started_at" bson:"started_at"`
+	DurationMs int64     `json:"duration_ms" bson:"duration_ms"`
+	StatusCode int       `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// TargetRetries records the retry budget of a target call and how much of it was used
+type TargetRetries struct {
+	Budget int `json:"budget" bson:"budget"`
+	Used   int `json:"used" bson:"used"`
+}
+
+// ConfirmationCheck represents the follow-up re-check made before alerting
+type ConfirmationCheck struct {
+	StatusCode     int      `json:"status_code" bson:"status_code"`
+	DurationMs     int64    `json:"duration_ms" bson:"duration_ms"`
+	Error          string   `json:"error,omitempty" bson:"error,omitempty"`
+	ConfirmedRules []string `json:"confirmed_rules" bson:"confirmed_rules"` // Alerting rules that matched again
+	DroppedRules   []string `json:"dropped_rules" bson:"dropped_rules"`     // Alerting rules that no longer matched
+}
+
+// Healthy reports whether the execution succeeded without any alerting rule matching
+func (eh *ExecutionHistory) Healthy() bool {
+	if eh.Status != "success" {
+		return false
+	}
+	for _, eval := range eh.RulesEvaluation {
+		if eval.Matched && eval.AlertOnMatch {
+			return false
+		}
+	}
+	return true
 }
 
 // ExecutionSummary represents a summary for list responses
@@ -67,6 +145,11 @@ type ExecutionSummary struct {
 	CorrelationID   string `json:"correlation_id"`
 	ConfigID        string `json:"config_id"`
 	ConfigName      string `json:"config_name"`
+	BatchID         string `json:"batch_id,omitempty"`
+	TriggerType     string `json:"trigger_type,omitempty"`
+	TriggeredBy     string `json:"triggered_by,omitempty"`
+	PodID           string `json:"pod_id,omitempty"`
+	Region          string `json:"region,omitempty"`
 	ExecutedAt      string `json:"executed_at"`
 	DurationMs      int64  `json:"duration_ms"`
 	Status          string `json:"status"`
@@ -85,9 +168,36 @@ func (eh *ExecutionHistory) ToSummary() ExecutionSummary {
 		CorrelationID:   eh.CorrelationID,
 		ConfigID:        eh.ConfigID.Hex(),
 		ConfigName:      eh.ConfigName,
+		BatchID:         eh.BatchID,
+		TriggerType:     eh.Metadata.TriggerType,
+		TriggeredBy:     eh.Metadata.TriggeredBy,
+		PodID:           eh.Metadata.PodID,
+		Region:          eh.Metadata.Region,
 		ExecutedAt:      executedAt,
 		DurationMs:      eh.DurationMs,
 		Status:          eh.Status,
 		AlertsTriggered: len(eh.AlertsTriggered),
 	}
 }
+
+// BatchSummary represents the aggregate result of a batch execution
+type BatchSummary struct {
+	BatchID         string             `json:"batch_id"`
+	Total           int                `json:"total"`
+	StatusCounts    map[string]int     `json:"status_counts"`
+	AlertsTriggered int                `json:"alerts_triggered"`
+	FirstExecutedAt string             `json:"first_executed_at,omitempty"`
+	LastExecutedAt  string             `json:"last_executed_at,omitempty"`
+	TotalDurationMs int64              `json:"total_duration_ms"`
+	Executions      []ExecutionSummary `json:"executions"`
+}
+
+// RegionStatus represents the latest result of a check in one region
+type RegionStatus struct {
+	Region        string `json:"region"`
+	AgentID       string `json:"agent_id,omitempty"`
+	CorrelationID string `json:"correlation_id"`
+	ExecutedAt    string `json:"executed_at"`
+	Status        string `json:"status"`
+	Healthy       bool   `json:"healthy"`
+}
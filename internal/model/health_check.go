@@ -5,24 +5,78 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/robfig/cron/v3"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Check types
+const (
+	CheckTypeHTTP     = "http"     // Default; calls the target over HTTP
+	CheckTypeBrowser  = "browser"  // Loads the target page in headless Chrome
+	CheckTypeKafka    = "kafka"    // Measures the lag of a Kafka consumer group
+	CheckTypeRabbitMQ = "rabbitmq" // Reads a queue's depth from the RabbitMQ management API
+	CheckTypeObject   = "object"   // Checks that a storage object exists and was modified recently
+)
+
 // HealthCheckConfig represents a health check configuration document
 type HealthCheckConfig struct {
-	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name             string             `json:"name" bson:"name"`
-	Description      string             `json:"description,omitempty" bson:"description,omitempty"`
-	Enabled          bool               `json:"enabled" bson:"enabled"`
-	Target           Target             `json:"target" bson:"target"`
-	Rules            []Rule             `json:"rules" bson:"rules"`
-	Webhook          Webhook            `json:"webhook" bson:"webhook"`
-	Metadata         Metadata           `json:"metadata" bson:"metadata"`
-	Schedule         string             `json:"schedule,omitempty" bson:"schedule,omitempty"`
-	ScheduleEnabled  bool               `json:"schedule_enabled" bson:"schedule_enabled"`
-	LastScheduledRun time.Time          `json:"last_scheduled_run,omitempty" bson:"last_scheduled_run,omitempty"`
-	NextScheduledRun time.Time          `json:"next_scheduled_run,omitempty" bson:"next_scheduled_run,omitempty"`
+	ID                  primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Name                string               `json:"name" bson:"name"`
+	Description         string               `json:"description,omitempty" bson:"description,omitempty"`
+	Enabled             bool                 `json:"enabled" bson:"enabled"`
+	Type                string               `json:"type,omitempty" bson:"type,omitempty"` // http (default), browser, kafka, rabbitmq, object or a plugin type
+	Target              Target               `json:"target" bson:"target"`
+	Browser             *BrowserCheck        `json:"browser,omitempty" bson:"browser,omitempty"`     // Page loading settings of browser checks
+	Kafka               *KafkaCheck          `json:"kafka,omitempty" bson:"kafka,omitempty"`         // Consumer group of kafka checks
+	RabbitMQ            *RabbitMQCheck       `json:"rabbitmq,omitempty" bson:"rabbitmq,omitempty"`   // Queue of rabbitmq checks
+	Object              *ObjectCheck         `json:"object,omitempty" bson:"object,omitempty"`       // Bucket and key of object checks
+	Settings            map[string]string    `json:"settings,omitempty" bson:"settings,omitempty"`   // Type-specific settings of plugin check types
+	Hooks               *Hooks               `json:"hooks,omitempty" bson:"hooks,omitempty"`         // Calls or scripts run before and after the target call
+	Transform           *Transform           `json:"transform,omitempty" bson:"transform,omitempty"` // Reshapes the response body before rules are evaluated
+	Rules               []Rule               `json:"rules" bson:"rules"`
+	Webhook             Webhook              `json:"webhook" bson:"webhook"`
+	Metadata            Metadata             `json:"metadata" bson:"metadata"`
+	DependsOn           []primitive.ObjectID `json:"depends_on,omitempty" bson:"depends_on,omitempty"`                         // Parent checks; this check is blocked while any of them fails
+	Regions             []string             `json:"regions,omitempty" bson:"regions,omitempty"`                               // Probed by agents in these regions instead of the core scheduler
+	MinFailingRegions   int                  `json:"min_failing_regions,omitempty" bson:"min_failing_regions,omitempty"`       // Alert only when at least this many regions fail
+	MaxExecutionSeconds int                  `json:"max_execution_seconds,omitempty" bson:"max_execution_seconds,omitempty"`   // Hard limit on target call, rule evaluation and alert preparation
+	MaxExecutionsPerDay int                  `json:"max_executions_per_day,omitempty" bson:"max_executions_per_day,omitempty"` // Executions allowed per UTC day; zero is unlimited
+	ConfirmBeforeAlert  bool                 `json:"confirm_before_alert,omitempty" bson:"confirm_before_alert,omitempty"`     // Re-check the target once and alert only if the rule matches again
+	ConfirmDelaySeconds int                  `json:"confirm_delay_seconds,omitempty" bson:"confirm_delay_seconds,omitempty"`   // Delay before the re-check (defaults to 5)
+	EvaluateEnvelope    bool                 `json:"evaluate_envelope,omitempty" bson:"evaluate_envelope,omitempty"`           // Evaluate JSONPath over {status, headers, body, duration_ms, size_bytes}
+	BodySampleRate      int                  `json:"body_sample_rate,omitempty" bson:"body_sample_rate,omitempty"`             // Store the response body of 1 in N successful runs; overrides EXECUTION_BODY_SAMPLE_RATE
+	Severity            *SeverityMapping     `json:"severity,omitempty" bson:"severity,omitempty"`                             // Maps rules and response status codes to alert severities
+	Schedule            string               `json:"schedule,omitempty" bson:"schedule,omitempty"`
+	ScheduleEnabled     bool                 `json:"schedule_enabled" bson:"schedule_enabled"`
+	LastScheduledRun    time.Time            `json:"last_scheduled_run,omitempty" bson:"last_scheduled_run,omitempty"`
+	NextScheduledRun    time.Time            `json:"next_scheduled_run,omitempty" bson:"next_scheduled_run,omitempty"`
+	TriggerNonce        string               `json:"-" bson:"trigger_nonce,omitempty"`                                       // Current trigger token generation; rotating it revokes old tokens
+	RunStats            *RunStats            `json:"run_stats,omitempty" bson:"run_stats,omitempty"`                         // Rolling execution counters, maintained by the executor
+	State               string               `json:"state,omitempty" bson:"state,omitempty"`                                 // up, degraded, down or unknown; maintained by the executor
+	StateSince          time.Time            `json:"state_since,omitempty" bson:"state_since,omitempty"`                     // When the check entered its current state
+	NotifyStateChanges  bool                 `json:"notify_state_changes,omitempty" bson:"notify_state_changes,omitempty"`   // Send state transitions to the config webhook
+	StateWebhook        *Webhook             `json:"state_webhook,omitempty" bson:"state_webhook,omitempty"`                 // Receives state transitions only, separately from rule alerts
+	StateNotifyOn       []string             `json:"state_notify_on,omitempty" bson:"state_notify_on,omitempty"`             // States whose entry is notified; empty notifies every transition
+	DisableProbeHeaders bool                 `json:"disable_probe_headers,omitempty" bson:"disable_probe_headers,omitempty"` // Don't send X-Raven-Check-Id and X-Raven-Correlation-Id to the target
+	AutoDisable         *AutoDisable         `json:"auto_disable,omitempty" bson:"auto_disable,omitempty"`                   // Disable the check after prolonged unreachability
+	Unreachable         *UnreachableStreak   `json:"unreachable,omitempty" bson:"unreachable,omitempty"`                     // Current target-unreachable streak; maintained by the executor
+	DisabledReason      string               `json:"disabled_reason,omitempty" bson:"disabled_reason,omitempty"`             // Why the check was disabled automatically
+	DisabledAt          time.Time            `json:"disabled_at,omitempty" bson:"disabled_at,omitempty"`
+}
+
+// MaxBodySampleRate bounds body_sample_rate, so a sampled body is stored at least this often
+const MaxBodySampleRate = 10000
+
+// RecentRunsSize is the number of recent run outcomes kept in RunStats
+const RecentRunsSize = 30
+
+// RunStats holds rolling execution counters updated after each run, so list views can
+// show a success sparkline without querying the execution history
+type RunStats struct {
+	TotalRuns  int64     `json:"total_runs" bson:"total_runs"`
+	FailedRuns int64     `json:"failed_runs" bson:"failed_runs"`
+	Recent     []bool    `json:"recent" bson:"recent"` // Outcomes of the last runs, oldest first; true means healthy
+	LastRunAt  time.Time `json:"last_run_at,omitempty" bson:"last_run_at,omitempty"`
+	LastStatus string    `json:"last_status,omitempty" bson:"last_status,omitempty"`
 }
 
 // Validate validates the entire health check configuration
@@ -35,18 +89,34 @@ func (hc *HealthCheckConfig) Validate() error {
 		return errors.New("health check name must be 255 characters or less")
 	}
 
-	// Validate target
-	if err := hc.Target.Validate(); err != nil {
+	// Validate target and type-specific settings
+	if err := hc.validateType(); err != nil {
 		return err
 	}
 
+	if hc.Transform != nil {
+		if err := hc.Transform.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Validate rules
 	if len(hc.Rules) == 0 {
 		return errors.New("at least one rule is required")
 	}
 	for i, rule := range hc.Rules {
-		if err := rule.Validate(); err != nil {
-			return errors.New("rule " + rule.Name + " validation failed: " + err.Error())
+		err := rule.Validate()
+		// HEAD responses have no body, so only header rules, scripts or the envelope apply
+		if _, isHeader := rule.HeaderName(); err == nil && hc.Target.Method == "HEAD" && !isHeader && !rule.IsScript() && !hc.EvaluateEnvelope {
+			err = errors.New("HEAD checks have no response body; use a header source, a script or evaluate_envelope")
+		}
+		if err != nil {
+			// Unnamed rules are identified by position
+			name := rule.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return errors.New("rule " + name + " validation failed: " + err.Error())
 		}
 		hc.Rules[i] = rule // Update in case validation modified the rule
 	}
@@ -56,6 +126,79 @@ func (hc *HealthCheckConfig) Validate() error {
 		return err
 	}
 
+	if hc.StateWebhook != nil {
+		if err := hc.StateWebhook.Validate(); err != nil {
+			return fmt.Errorf("state_webhook: %w", err)
+		}
+	}
+	for _, state := range hc.StateNotifyOn {
+		switch state {
+		case CheckStateUp, CheckStateDegraded, CheckStateDown:
+		default:
+			return fmt.Errorf("invalid state_notify_on state: %s (must be up, degraded or down)", state)
+		}
+	}
+
+	if hc.Severity != nil {
+		if err := hc.Severity.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if hc.Hooks != nil {
+		if len(hc.Regions) > 0 {
+			return errors.New("hooks cannot be used with regions")
+		}
+		if err := hc.Hooks.Validate(&hc.Target); err != nil {
+			return fmt.Errorf("hooks: %w", err)
+		}
+	}
+
+	// Validate dependencies
+	seen := make(map[primitive.ObjectID]bool, len(hc.DependsOn))
+	for _, parentID := range hc.DependsOn {
+		if parentID.IsZero() {
+			return errors.New("depends_on contains an invalid ID")
+		}
+		if !hc.ID.IsZero() && parentID == hc.ID {
+			return errors.New("health check cannot depend on itself")
+		}
+		if seen[parentID] {
+			return fmt.Errorf("duplicate dependency: %s", parentID.Hex())
+		}
+		seen[parentID] = true
+	}
+
+	// Validate regions
+	if hc.MinFailingRegions < 0 {
+		return errors.New("min_failing_regions must not be negative")
+	}
+	if hc.MinFailingRegions > len(hc.Regions) && hc.MinFailingRegions > 1 {
+		return errors.New("min_failing_regions cannot exceed the number of regions")
+	}
+
+	if hc.MaxExecutionSeconds < 0 {
+		return errors.New("max_execution_seconds cannot be negative")
+	}
+
+	if hc.MaxExecutionsPerDay < 0 {
+		return errors.New("max_executions_per_day cannot be negative")
+	}
+
+	if hc.BodySampleRate < 0 || hc.BodySampleRate > MaxBodySampleRate {
+		return fmt.Errorf("body_sample_rate must be between 0 and %d", MaxBodySampleRate)
+	}
+
+	if hc.AutoDisable != nil {
+		if err := hc.AutoDisable.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if hc.ConfirmDelaySeconds < 0 || hc.ConfirmDelaySeconds > 60 {
+		return errors.New("confirm_delay_seconds must be between 0 and 60")
+	}
+
 	// Validate schedule if enabled
 	if hc.ScheduleEnabled {
 		if hc.Schedule == "" {
@@ -63,8 +206,7 @@ func (hc *HealthCheckConfig) Validate() error {
 		}
 
 		// Validate cron expression
-		parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-		schedule, err := parser.Parse(hc.Schedule)
+		schedule, err := ParseSchedule(hc.Schedule)
 		if err != nil {
 			return fmt.Errorf("invalid cron expression: %w", err)
 		}
@@ -89,6 +231,68 @@ func (hc *HealthCheckConfig) Validate() error {
 	return nil
 }
 
+// validateType validates the target and the settings of the config's check type
+func (hc *HealthCheckConfig) validateType() error {
+	// Browser, RabbitMQ and object checks only read from their target, so the method is implied
+	if (hc.Type == CheckTypeBrowser || hc.Type == CheckTypeRabbitMQ || hc.Type == CheckTypeObject) && hc.Target.Method == "" {
+		hc.Target.Method = "GET"
+	}
+
+	var plugin CheckTypeValidator
+	switch hc.Type {
+	case "", CheckTypeHTTP, CheckTypeBrowser, CheckTypeRabbitMQ, CheckTypeObject:
+		if err := hc.Target.Validate(); err != nil {
+			return err
+		}
+	case CheckTypeKafka:
+		// Kafka targets list brokers rather than an HTTP endpoint
+	default:
+		// Plugin targets are validated with the rest of their settings
+		var ok bool
+		if plugin, ok = pluginCheckType(hc.Type); !ok {
+			return fmt.Errorf("invalid check type: %s (must be 'http', 'browser', 'kafka', 'rabbitmq', 'object' or a plugin type)", hc.Type)
+		}
+	}
+
+	if hc.Browser != nil && hc.Type != CheckTypeBrowser {
+		return errors.New("browser settings require type browser")
+	}
+	if hc.Kafka != nil && hc.Type != CheckTypeKafka {
+		return errors.New("kafka settings require type kafka")
+	}
+	if hc.RabbitMQ != nil && hc.Type != CheckTypeRabbitMQ {
+		return errors.New("rabbitmq settings require type rabbitmq")
+	}
+	if hc.Object != nil && hc.Type != CheckTypeObject {
+		return errors.New("object settings require type object")
+	}
+	if len(hc.Settings) > 0 && plugin == nil {
+		return errors.New("settings require a plugin check type")
+	}
+
+	if hc.Type == "" || hc.Type == CheckTypeHTTP {
+		return nil
+	}
+
+	// Agents only make HTTP calls
+	if len(hc.Regions) > 0 {
+		return fmt.Errorf("%s checks cannot run in regions", hc.Type)
+	}
+
+	switch hc.Type {
+	case CheckTypeBrowser:
+		return hc.validateBrowser()
+	case CheckTypeKafka:
+		return hc.validateKafka()
+	case CheckTypeObject:
+		return hc.validateObject()
+	case CheckTypeRabbitMQ:
+		return hc.validateRabbitMQ()
+	default:
+		return hc.validatePlugin(plugin)
+	}
+}
+
 // HealthCheckListItem represents a summary of a health check for list responses
 type HealthCheckListItem struct {
 	ID               string    `json:"id"`
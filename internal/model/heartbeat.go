@@ -0,0 +1,93 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Heartbeat states
+const (
+	HeartbeatStatusNew  = "new"  // No ping received yet
+	HeartbeatStatusUp   = "up"   // Last ping arrived on time
+	HeartbeatStatusDown = "down" // Ping overdue, alert sent
+)
+
+// MinHeartbeatInterval is the shortest expected ping interval
+const MinHeartbeatInterval = 60
+
+// Heartbeat represents a dead man's switch: an external job pings it periodically and
+// an alert fires when no ping arrives within the interval plus grace period
+type Heartbeat struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name            string             `json:"name" bson:"name"`
+	Description     string             `json:"description,omitempty" bson:"description,omitempty"`
+	Enabled         bool               `json:"enabled" bson:"enabled"`
+	IntervalSeconds int                `json:"interval_seconds" bson:"interval_seconds"`     // Expected time between pings
+	GraceSeconds    int                `json:"grace_seconds" bson:"grace_seconds"`           // Extra time allowed before alerting
+	Webhook         *Webhook           `json:"webhook,omitempty" bson:"webhook,omitempty"`   // Alert destination; defaults to the global default webhook
+	Severity        string             `json:"severity,omitempty" bson:"severity,omitempty"` // Severity of missed-ping alerts, defaults to "error"
+	Metadata        Metadata           `json:"metadata" bson:"metadata"`
+	Status          string             `json:"status" bson:"status"`
+	LastPingAt      time.Time          `json:"last_ping_at,omitempty" bson:"last_ping_at,omitempty"`
+	ExpectedBy      time.Time          `json:"expected_by,omitempty" bson:"expected_by,omitempty"`     // Deadline for the next ping
+	LastAlertAt     time.Time          `json:"last_alert_at,omitempty" bson:"last_alert_at,omitempty"` // When the last missed-ping alert was sent
+}
+
+// Validate validates the heartbeat configuration
+func (h *Heartbeat) Validate() error {
+	if h.Name == "" {
+		return errors.New("heartbeat name is required")
+	}
+
+	if len(h.Name) > 255 {
+		return errors.New("heartbeat name must be 255 characters or less")
+	}
+
+	if h.IntervalSeconds < MinHeartbeatInterval {
+		return fmt.Errorf("interval_seconds must be at least %d", MinHeartbeatInterval)
+	}
+
+	if h.GraceSeconds < 0 {
+		return errors.New("grace_seconds must not be negative")
+	}
+
+	if h.Severity != "" && !IsValidSeverity(h.Severity) {
+		return fmt.Errorf("invalid severity: %s (must be info, warning, error or critical)", h.Severity)
+	}
+
+	// Validate alert webhook; without one the default webhook is used
+	if h.Webhook != nil {
+		if err := h.Webhook.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if h.Status == "" {
+		h.Status = HeartbeatStatusNew
+	}
+
+	// Set metadata timestamps
+	now := time.Now().UTC()
+	if h.Metadata.CreatedAt.IsZero() {
+		h.Metadata.CreatedAt = now
+	}
+	h.Metadata.UpdatedAt = now
+
+	return nil
+}
+
+// Period returns the interval plus grace period allowed between pings
+func (h *Heartbeat) Period() time.Duration {
+	return time.Duration(h.IntervalSeconds+h.GraceSeconds) * time.Second
+}
+
+// AlertSeverity returns the severity used for missed-ping alerts
+func (h *Heartbeat) AlertSeverity() string {
+	if h.Severity != "" {
+		return h.Severity
+	}
+	return SeverityError
+}
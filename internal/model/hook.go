@@ -0,0 +1,171 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/oliveagle/jsonpath"
+)
+
+// Hook phases
+const (
+	HookPhasePre  = "pre"
+	HookPhasePost = "post"
+)
+
+// maxHooks limits the hooks of each phase
+const maxHooks = 5
+
+// Hook variable namespaces
+const (
+	HookNamespaceHooks     = "hooks"     // {{hooks.<hook>.<variable>}}: variables set by earlier hooks
+	HookNamespaceExecution = "execution" // {{execution.<field>}}: outcome of the execution, in post hooks
+	HookNamespaceRules     = "rules"     // {{rules.<rule>}}: values extracted by rules, in post hooks
+)
+
+// hookNamePattern matches hook and variable names, which scripts use as dict keys
+var hookNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// HookVariablePattern matches {{namespace.path}} placeholders in hook and target fields
+var HookVariablePattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Hooks are calls or scripts run around a check's target call
+type Hooks struct {
+	Pre  []Hook `json:"pre,omitempty" bson:"pre,omitempty"`   // Run before the target call; their variables can be used in the target
+	Post []Hook `json:"post,omitempty" bson:"post,omitempty"` // Run after rules are evaluated, with the execution's outcome
+}
+
+// Hook is an HTTP call or a Starlark script run before or after a check. Its variables are
+// extracted from the call's response or returned by the script.
+type Hook struct {
+	Name            string            `json:"name" bson:"name"`
+	Target          *Target           `json:"target,omitempty" bson:"target,omitempty"`                       // HTTP call to make; URL, headers and body may use variables
+	Script          string            `json:"script,omitempty" bson:"script,omitempty"`                       // Starlark script evaluating to a dict of variables
+	Extract         map[string]string `json:"extract,omitempty" bson:"extract,omitempty"`                     // Variable name -> JSONPath into the call's response body
+	ContinueOnError bool              `json:"continue_on_error,omitempty" bson:"continue_on_error,omitempty"` // Pre hooks only: run the check even when the hook fails
+}
+
+// HookResult records a hook run in the execution history. Variable values aren't stored
+// since they often hold credentials.
+type HookResult struct {
+	Name       string   `json:"name" bson:"name"`
+	Phase      string   `json:"phase" bson:"phase"` // "pre" or "post"
+	StatusCode int      `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	DurationMs int64    `json:"duration_ms" bson:"duration_ms"`
+	Variables  []string `json:"variables,omitempty" bson:"variables,omitempty"` // Names of the variables the hook set
+	Error      string   `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// Validate validates the hooks and the variables they and the check target reference
+func (h *Hooks) Validate(target *Target) error {
+	if len(h.Pre) > maxHooks || len(h.Post) > maxHooks {
+		return fmt.Errorf("at most %d pre and %d post hooks are allowed", maxHooks, maxHooks)
+	}
+
+	defined := make(map[string]bool)
+	for i := range h.Pre {
+		if err := h.Pre[i].validate(HookPhasePre, defined); err != nil {
+			return err
+		}
+		defined[h.Pre[i].Name] = true
+	}
+
+	// The target only sees the variables of pre hooks
+	if err := validateHookVariables(targetTemplates(target), HookPhasePre, defined); err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	for i := range h.Post {
+		if err := h.Post[i].validate(HookPhasePost, defined); err != nil {
+			return err
+		}
+		defined[h.Post[i].Name] = true
+	}
+	return nil
+}
+
+// validate validates a hook run in phase after the hooks in defined
+func (h *Hook) validate(phase string, defined map[string]bool) error {
+	if !hookNamePattern.MatchString(h.Name) {
+		return fmt.Errorf("invalid hook name %q: use letters, digits and underscores", h.Name)
+	}
+	if defined[h.Name] {
+		return fmt.Errorf("duplicate hook name: %s", h.Name)
+	}
+	if (h.Target == nil) == (h.Script == "") {
+		return fmt.Errorf("hook %s: set either target or script", h.Name)
+	}
+	if h.ContinueOnError && phase == HookPhasePost {
+		return fmt.Errorf("hook %s: continue_on_error only applies to pre hooks", h.Name)
+	}
+
+	if h.Script != "" {
+		if len(h.Extract) > 0 {
+			return fmt.Errorf("hook %s: extract requires a target; scripts return their variables", h.Name)
+		}
+		if _, err := CompileScript(h.Script, HookScriptGlobals); err != nil {
+			return fmt.Errorf("hook %s: invalid script: %v", h.Name, err)
+		}
+		return nil
+	}
+
+	if err := h.Target.Validate(); err != nil {
+		return fmt.Errorf("hook %s: %w", h.Name, err)
+	}
+	if err := validateHookVariables(targetTemplates(h.Target), phase, defined); err != nil {
+		return fmt.Errorf("hook %s: %w", h.Name, err)
+	}
+	for variable, expression := range h.Extract {
+		if !hookNamePattern.MatchString(variable) {
+			return fmt.Errorf("hook %s: invalid variable name %q", h.Name, variable)
+		}
+		if _, err := jsonpath.Compile(expression); err != nil {
+			return fmt.Errorf("hook %s: invalid JSONPath expression '%s': %v", h.Name, expression, err)
+		}
+	}
+	return nil
+}
+
+// targetTemplates returns the target fields that may hold variables
+func targetTemplates(target *Target) []string {
+	templates := []string{target.URL, target.Body}
+	for _, value := range target.Headers {
+		templates = append(templates, value)
+	}
+	for _, field := range target.FormFields {
+		templates = append(templates, field.Value)
+	}
+	return templates
+}
+
+// validateHookVariables checks that the placeholders in templates reference hooks that run
+// earlier, and execution fields only after the target call
+func validateHookVariables(templates []string, phase string, defined map[string]bool) error {
+	for _, template := range templates {
+		for _, match := range HookVariablePattern.FindAllStringSubmatch(template, -1) {
+			namespace, path, _ := strings.Cut(match[1], ".")
+			switch namespace {
+			case HookNamespaceHooks:
+				hook, variable, _ := strings.Cut(path, ".")
+				if variable == "" {
+					return fmt.Errorf("variable %s must be hooks.<hook>.<variable>", match[0])
+				}
+				if !defined[hook] {
+					return fmt.Errorf("variable %s references hook %s, which doesn't run before it", match[0], hook)
+				}
+			case HookNamespaceExecution, HookNamespaceRules:
+				if phase != HookPhasePost {
+					return fmt.Errorf("variable %s is only available in post hooks", match[0])
+				}
+				if path == "" {
+					return fmt.Errorf("variable %s needs a field", match[0])
+				}
+			default:
+				return errors.New("unknown variable " + match[0] + " (must start with hooks., execution. or rules.)")
+			}
+		}
+	}
+	return nil
+}
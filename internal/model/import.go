@@ -0,0 +1,30 @@
+package model
+
+import "encoding/json"
+
+// ImportRequest generates draft health checks from an OpenAPI spec or Postman collection
+type ImportRequest struct {
+	Format     string          `json:"format,omitempty"`     // openapi or postman; detected from the spec when empty
+	Spec       json.RawMessage `json:"spec"`                 // The JSON document itself
+	BaseURL    string          `json:"base_url,omitempty"`   // Replaces the spec's servers or the collection's hosts
+	Operations []string        `json:"operations,omitempty"` // operationIds, request names or "METHOD path"; empty imports everything
+	NamePrefix string          `json:"name_prefix,omitempty"`
+	Tags       []string        `json:"tags,omitempty"`
+	Webhook    Webhook         `json:"webhook,omitempty"` // Webhook of every draft; empty uses the default webhook
+	Create     bool            `json:"create,omitempty"`  // Save the drafts, disabled, instead of only returning them
+}
+
+// ImportResult represents the outcome of an import
+type ImportResult struct {
+	Format    string               `json:"format"`
+	Drafts    []*HealthCheckConfig `json:"drafts"`
+	Created   int                  `json:"created"`
+	Errors    []ImportError        `json:"errors,omitempty"`    // Drafts that failed to save
+	Unmatched []string             `json:"unmatched,omitempty"` // Requested operations not found in the spec
+}
+
+// ImportError is a draft that could not be saved
+type ImportError struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,21 @@
+package model
+
+// Lint finding levels
+const (
+	LintLevelError   = "error"   // The config would be rejected
+	LintLevelWarning = "warning" // The config is accepted but goes against best practice
+)
+
+// LintFinding is a problem found in a health check configuration
+type LintFinding struct {
+	Level   string `json:"level"`
+	Code    string `json:"code"`            // Stable identifier, e.g. "missing_timeout"
+	Field   string `json:"field,omitempty"` // JSON path of the offending field
+	Message string `json:"message"`
+}
+
+// LintResult represents the result of linting a health check configuration
+type LintResult struct {
+	Valid    bool          `json:"valid"` // Whether the config passes validation; warnings don't affect it
+	Findings []LintFinding `json:"findings"`
+}
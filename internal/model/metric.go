@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MetricSample represents one numeric value extracted by a metric rule
+type MetricSample struct {
+	Timestamp time.Time  `json:"timestamp" bson:"timestamp"`
+	Meta      MetricMeta `json:"meta" bson:"meta"`
+	Value     float64    `json:"value" bson:"value"`
+}
+
+// MetricMeta identifies the series a metric sample belongs to
+type MetricMeta struct {
+	ConfigID   primitive.ObjectID `json:"config_id" bson:"config_id"`
+	ConfigName string             `json:"config_name" bson:"config_name"`
+	Name       string             `json:"name" bson:"name"` // Rule name
+	Region     string             `json:"region,omitempty" bson:"region,omitempty"`
+}
+
+// MetricPoint represents a single point of a metric series
+type MetricPoint struct {
+	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
+	Value     float64   `json:"value" bson:"value"`
+	Region    string    `json:"region,omitempty" bson:"region,omitempty"`
+}
+
+// MetricSeries represents the recent values of one metric of a health check
+type MetricSeries struct {
+	ConfigID string        `json:"config_id"`
+	Name     string        `json:"name"`
+	Window   string        `json:"window"`
+	Points   []MetricPoint `json:"points"`
+}
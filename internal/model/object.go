@@ -0,0 +1,36 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ObjectCheck configures an object freshness check. The target URL is the S3-compatible
+// storage endpoint, e.g. https://s3.eu-west-1.amazonaws.com or https://storage.googleapis.com.
+// Rules evaluate whether the object exists, its last modification and its age.
+type ObjectCheck struct {
+	Bucket string `json:"bucket" bson:"bucket"`
+	Key    string `json:"key" bson:"key"`
+	Region string `json:"region,omitempty" bson:"region,omitempty"`   // Signing region; taken from AWS endpoints, "auto" for GCS and us-east-1 otherwise
+	MaxAge string `json:"max_age,omitempty" bson:"max_age,omitempty"` // Freshness window, e.g. "26h" or "2d"; the object is fresh when modified within it
+}
+
+// validateObject validates the settings of an object check
+func (hc *HealthCheckConfig) validateObject() error {
+	if hc.Target.Method != "GET" {
+		return errors.New("object checks only support the GET method")
+	}
+	if hc.Target.HasBody() {
+		return errors.New("object checks cannot send a request body")
+	}
+	if hc.Object == nil || hc.Object.Bucket == "" || hc.Object.Key == "" {
+		return errors.New("object.bucket and object.key are required")
+	}
+	if hc.Object.MaxAge != "" {
+		maxAge, err := ParseRelativeDuration(hc.Object.MaxAge)
+		if err != nil || maxAge <= 0 {
+			return fmt.Errorf("invalid object.max_age: %s", hc.Object.MaxAge)
+		}
+	}
+	return nil
+}
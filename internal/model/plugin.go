@@ -0,0 +1,91 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// CheckTypeValidator validates a health check of a plugin check type
+type CheckTypeValidator func(hc *HealthCheckConfig) error
+
+// WebhookTypeValidator validates a webhook of a plugin notification type
+type WebhookTypeValidator func(w *Webhook) error
+
+// The check and notification types added by plugins, mapped to their validators
+var (
+	pluginTypesMu      sync.RWMutex
+	pluginCheckTypes   = make(map[string]CheckTypeValidator)
+	pluginWebhookTypes = make(map[string]WebhookTypeValidator)
+)
+
+// RegisterCheckType makes a check type provided by a plugin valid; validate runs after the
+// common target checks
+func RegisterCheckType(checkType string, validate CheckTypeValidator) error {
+	switch checkType {
+	case "", CheckTypeHTTP, CheckTypeBrowser, CheckTypeKafka, CheckTypeRabbitMQ, CheckTypeObject:
+		return fmt.Errorf("check type %q is built in", checkType)
+	}
+	if validate == nil {
+		return errors.New("check type validator is required")
+	}
+
+	pluginTypesMu.Lock()
+	defer pluginTypesMu.Unlock()
+	if _, exists := pluginCheckTypes[checkType]; exists {
+		return fmt.Errorf("check type %s is already registered", checkType)
+	}
+	pluginCheckTypes[checkType] = validate
+	return nil
+}
+
+// pluginCheckType returns the validator of a plugin check type
+func pluginCheckType(checkType string) (CheckTypeValidator, bool) {
+	pluginTypesMu.RLock()
+	defer pluginTypesMu.RUnlock()
+	validate, ok := pluginCheckTypes[checkType]
+	return validate, ok
+}
+
+// RegisterWebhookType makes a notification type provided by a plugin valid; validate runs
+// after the common webhook checks
+func RegisterWebhookType(webhookType string, validate WebhookTypeValidator) error {
+	switch webhookType {
+	case "", WebhookTypeWebhook, WebhookTypeSlack, WebhookTypeEmail:
+		return fmt.Errorf("webhook type %q is built in", webhookType)
+	}
+	if validate == nil {
+		return errors.New("webhook type validator is required")
+	}
+
+	pluginTypesMu.Lock()
+	defer pluginTypesMu.Unlock()
+	if _, exists := pluginWebhookTypes[webhookType]; exists {
+		return fmt.Errorf("webhook type %s is already registered", webhookType)
+	}
+	pluginWebhookTypes[webhookType] = validate
+	return nil
+}
+
+// pluginWebhookType returns the validator of a plugin notification type
+func pluginWebhookType(webhookType string) (WebhookTypeValidator, bool) {
+	pluginTypesMu.RLock()
+	defer pluginTypesMu.RUnlock()
+	validate, ok := pluginWebhookTypes[webhookType]
+	return validate, ok
+}
+
+// validatePlugin validates the target of a plugin check, which needn't be an HTTP URL, and
+// hands the rest to the plugin
+func (hc *HealthCheckConfig) validatePlugin(validate CheckTypeValidator) error {
+	if hc.Target.URL == "" {
+		return errors.New("target URL is required")
+	}
+	if err := hc.Target.Auth.Validate(); err != nil {
+		return fmt.Errorf("auth validation failed: %w", err)
+	}
+	if hc.Target.Timeout == 0 {
+		hc.Target.Timeout = 30
+	}
+	return validate(hc)
+}
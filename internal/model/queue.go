@@ -0,0 +1,91 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Kafka SASL mechanisms; target basic auth credentials are sent with the selected one
+const (
+	KafkaSASLPlain       = "plain"
+	KafkaSASLScramSHA256 = "scram-sha-256"
+	KafkaSASLScramSHA512 = "scram-sha-512"
+)
+
+// KafkaCheck configures a consumer lag check. The target URL lists the brokers, e.g.
+// kafka://broker1:9092,broker2:9092. Rules evaluate the group's total and per-topic lag.
+type KafkaCheck struct {
+	Group         string   `json:"group" bson:"group"`
+	Topics        []string `json:"topics,omitempty" bson:"topics,omitempty"`                 // Topics to measure; every topic the group committed offsets for when empty
+	TLS           bool     `json:"tls,omitempty" bson:"tls,omitempty"`                       // Connect to the brokers over TLS
+	SASLMechanism string   `json:"sasl_mechanism,omitempty" bson:"sasl_mechanism,omitempty"` // plain (default), scram-sha-256 or scram-sha-512
+}
+
+// RabbitMQCheck configures a queue depth check. The target URL is the management API, e.g.
+// http://rabbitmq:15672. Rules evaluate the queue's message counts and rates.
+type RabbitMQCheck struct {
+	VHost string `json:"vhost,omitempty" bson:"vhost,omitempty"` // Defaults to "/"
+	Queue string `json:"queue" bson:"queue"`
+}
+
+// KafkaBrokers returns the broker addresses listed in a kafka:// target URL
+func KafkaBrokers(targetURL string) ([]string, error) {
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil || parsedURL.Scheme != "kafka" || parsedURL.Host == "" {
+		return nil, errors.New("kafka target URL must list the brokers, e.g. kafka://broker1:9092,broker2:9092")
+	}
+
+	var brokers []string
+	for _, broker := range strings.Split(parsedURL.Host, ",") {
+		if broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	return brokers, nil
+}
+
+// validateKafka validates the target and settings of a kafka check
+func (hc *HealthCheckConfig) validateKafka() error {
+	if hc.Target.URL == "" {
+		return errors.New("target URL is required")
+	}
+	if _, err := KafkaBrokers(hc.Target.URL); err != nil {
+		return err
+	}
+
+	if err := hc.Target.Auth.Validate(); err != nil {
+		return fmt.Errorf("auth validation failed: %w", err)
+	}
+	switch strings.ToLower(hc.Target.Auth.Type) {
+	case "", "none", "basic":
+	default:
+		return errors.New("kafka checks only support basic auth, sent as SASL credentials")
+	}
+
+	if hc.Target.Timeout == 0 {
+		hc.Target.Timeout = 30
+	}
+
+	if hc.Kafka == nil || hc.Kafka.Group == "" {
+		return errors.New("kafka.group is required")
+	}
+	switch hc.Kafka.SASLMechanism {
+	case "", KafkaSASLPlain, KafkaSASLScramSHA256, KafkaSASLScramSHA512:
+	default:
+		return fmt.Errorf("invalid kafka.sasl_mechanism: %s (must be 'plain', 'scram-sha-256' or 'scram-sha-512')", hc.Kafka.SASLMechanism)
+	}
+	return nil
+}
+
+// validateRabbitMQ validates the settings of a rabbitmq check
+func (hc *HealthCheckConfig) validateRabbitMQ() error {
+	if hc.Target.Method != "GET" {
+		return errors.New("rabbitmq checks only support the GET method")
+	}
+	if hc.RabbitMQ == nil || hc.RabbitMQ.Queue == "" {
+		return errors.New("rabbitmq.queue is required")
+	}
+	return nil
+}
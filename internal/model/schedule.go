@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser parses standard 5-field cron expressions
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// ParseSchedule parses a standard 5-field cron expression
+func ParseSchedule(expression string) (cron.Schedule, error) {
+	return cronParser.Parse(expression)
+}
+
+// NextRuns returns the next count run times of a cron expression after from
+func NextRuns(expression string, from time.Time, count int) ([]time.Time, error) {
+	schedule, err := ParseSchedule(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]time.Time, 0, count)
+	next := from
+	for i := 0; i < count; i++ {
+		next = schedule.Next(next)
+		if next.IsZero() {
+			break
+		}
+		runs = append(runs, next)
+	}
+
+	return runs, nil
+}
+
+// SchedulePreview represents the upcoming run times of a health check schedule
+type SchedulePreview struct {
+	ConfigID        string      `json:"config_id"`
+	Schedule        string      `json:"schedule"`
+	ScheduleEnabled bool        `json:"schedule_enabled"`
+	Timezone        string      `json:"timezone"`
+	NextRuns        []time.Time `json:"next_runs"`
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// ScriptResult is the global a multi-statement script assigns its value to
+const ScriptResult = "result"
+
+// Names predeclared for scripts: the response envelope, the json and math modules and, in
+// hook scripts, the variables of earlier hooks and the execution's outcome
+var (
+	RuleScriptGlobals = []string{"response", "json", "math"}
+	HookScriptGlobals = []string{"hooks", "execution", "rules", "response", "json", "math"}
+)
+
+// scriptOptions is the Starlark dialect of rule and hook scripts
+var scriptOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+	GlobalReassign:  true,
+}
+
+// CompileScript compiles a script that may use globals. A script that is a single expression
+// evaluates to its value; longer scripts assign the value to result.
+func CompileScript(source string, globals []string) (*starlark.Program, error) {
+	file, err := ParseScript(source)
+	if err != nil {
+		return nil, err
+	}
+	return CompileScriptFile(file, func(name string) bool {
+		return slices.Contains(globals, name)
+	})
+}
+
+// ParseScript parses a script, turning a single expression into an assignment to result
+func ParseScript(source string) (*syntax.File, error) {
+	if source == "" {
+		return nil, errors.New("script is empty")
+	}
+
+	if expr, err := scriptOptions.ParseExpr("rule", source, 0); err == nil {
+		// Expressions are compiled as an assignment to result, keeping their source positions
+		return &syntax.File{
+			Path: "rule",
+			Stmts: []syntax.Stmt{&syntax.AssignStmt{
+				OpPos: syntax.Start(expr),
+				Op:    syntax.EQ,
+				LHS:   &syntax.Ident{NamePos: syntax.Start(expr), Name: ScriptResult},
+				RHS:   expr,
+			}},
+			Options: scriptOptions,
+		}, nil
+	}
+	return scriptOptions.Parse("rule", source, 0)
+}
+
+// CompileScriptFile compiles a parsed script whose predeclared names are those isPredeclared
+// accepts, rejecting load statements
+func CompileScriptFile(file *syntax.File, isPredeclared func(name string) bool) (*starlark.Program, error) {
+	program, err := starlark.FileProgram(file, isPredeclared)
+	if err != nil {
+		return nil, err
+	}
+	if program.NumLoads() > 0 {
+		_, pos := program.Load(0)
+		return nil, fmt.Errorf("%s: load is not allowed in scripts", pos)
+	}
+	return program, nil
+}
+
+// IsScript reports whether the rule is evaluated by a script
+func (r *Rule) IsScript() bool {
+	return strings.EqualFold(r.Source, RuleSourceScript)
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Alert severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// IsValidSeverity reports whether s is a known alert severity
+func IsValidSeverity(s string) bool {
+	switch s {
+	case SeverityInfo, SeverityWarning, SeverityError, SeverityCritical:
+		return true
+	}
+	return false
+}
+
+// SeverityMapping assigns alert severities for a health check
+type SeverityMapping struct {
+	Default     string            `json:"default,omitempty" bson:"default,omitempty"`           // Severity when nothing else applies (defaults to "warning")
+	Rules       map[string]string `json:"rules,omitempty" bson:"rules,omitempty"`               // Rule name -> severity
+	StatusCodes map[string]string `json:"status_codes,omitempty" bson:"status_codes,omitempty"` // Exact code ("503") or class ("5xx") -> severity
+}
+
+// Validate validates the severity mapping
+func (m *SeverityMapping) Validate() error {
+	if m.Default != "" && !IsValidSeverity(m.Default) {
+		return fmt.Errorf("invalid default severity: %s", m.Default)
+	}
+	for rule, severity := range m.Rules {
+		if !IsValidSeverity(severity) {
+			return fmt.Errorf("invalid severity for rule %s: %s", rule, severity)
+		}
+	}
+	for code, severity := range m.StatusCodes {
+		if !isStatusPattern(code) {
+			return fmt.Errorf("invalid status code pattern: %s (use e.g. '503' or '5xx')", code)
+		}
+		if !IsValidSeverity(severity) {
+			return fmt.Errorf("invalid severity for status %s: %s", code, severity)
+		}
+	}
+	return nil
+}
+
+// isStatusPattern reports whether p is a three-digit status code or a class such as "5xx"
+func isStatusPattern(p string) bool {
+	p = strings.ToLower(p)
+	if len(p) != 3 || p[0] < '1' || p[0] > '5' {
+		return false
+	}
+	if p[1:] == "xx" {
+		return true
+	}
+	_, err := strconv.Atoi(p)
+	return err == nil
+}
+
+// AlertSeverity resolves the severity of an alert raised by a rule evaluation.
+// Precedence: the rule's own severity, the mapping for the rule name, the
+// mapping for the exact status code, then the status class, evaluation errors
+// ("error"), the mapping default and finally "warning".
+func (hc *HealthCheckConfig) AlertSeverity(evaluation RuleEvaluation, statusCode int) string {
+	for _, rule := range hc.Rules {
+		if rule.Name == evaluation.RuleName && rule.Severity != "" {
+			return rule.Severity
+		}
+	}
+
+	mapping := hc.Severity
+	if mapping != nil {
+		if severity, ok := mapping.Rules[evaluation.RuleName]; ok {
+			return severity
+		}
+		if statusCode > 0 {
+			code := strconv.Itoa(statusCode)
+			if severity, ok := mapping.StatusCodes[code]; ok {
+				return severity
+			}
+			if severity, ok := mapping.StatusCodes[code[:1]+"xx"]; ok {
+				return severity
+			}
+			if severity, ok := mapping.StatusCodes[code[:1]+"XX"]; ok {
+				return severity
+			}
+		}
+	}
+
+	if evaluation.Error != "" {
+		return SeverityError
+	}
+	if mapping != nil && mapping.Default != "" {
+		return mapping.Default
+	}
+	return SeverityWarning
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Check states
+const (
+	CheckStateUnknown  = "unknown"  // No execution yet
+	CheckStateUp       = "up"       // Healthy
+	CheckStateDegraded = "degraded" // Rule errors, or only info/warning alerts matched
+	CheckStateDown     = "down"     // Target unreachable, timed out, or an error/critical alert matched
+)
+
+// StateTransition records a check moving from one state to another
+type StateTransition struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ConfigID      primitive.ObjectID `json:"config_id" bson:"config_id"`
+	ConfigName    string             `json:"config_name" bson:"config_name"`
+	From          string             `json:"from" bson:"from"`
+	To            string             `json:"to" bson:"to"`
+	CorrelationID string             `json:"correlation_id" bson:"correlation_id"` // Execution that caused the transition
+	At            time.Time          `json:"at" bson:"at"`
+	DurationMs    int64              `json:"duration_ms" bson:"duration_ms"` // Time spent in the previous state; zero when it was unknown
+}
+
+// DeriveState returns the state an execution puts the check in
+func (hc *HealthCheckConfig) DeriveState(execution *ExecutionHistory) string {
+	switch execution.Status {
+	case "failed", "timeout":
+		return CheckStateDown
+	}
+
+	state := CheckStateUp
+	if execution.Status == "partial" {
+		state = CheckStateDegraded
+	}
+
+	for _, eval := range execution.RulesEvaluation {
+		if !eval.Matched || !eval.AlertOnMatch {
+			continue
+		}
+		switch hc.AlertSeverity(eval, execution.Response.StatusCode) {
+		case SeverityError, SeverityCritical:
+			return CheckStateDown
+		default:
+			state = CheckStateDegraded
+		}
+	}
+
+	return state
+}
+
+// StateSeverity returns the alert severity of entering a state
+func StateSeverity(state string) string {
+	switch state {
+	case CheckStateDown:
+		return SeverityError
+	case CheckStateDegraded:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// NotifiesState reports whether a transition into state should be notified
+func (hc *HealthCheckConfig) NotifiesState(state string) bool {
+	if hc.StateWebhook == nil && !hc.NotifyStateChanges {
+		return false
+	}
+	if len(hc.StateNotifyOn) == 0 {
+		return true
+	}
+	for _, s := range hc.StateNotifyOn {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package model
+
+// CountBucket represents a grouped document count
+type CountBucket struct {
+	Key   string `json:"key" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// ExecutionStats represents aggregated execution counts
+type ExecutionStats struct {
+	Total    int64         `json:"total"`
+	ByStatus []CountBucket `json:"by_status"`
+	ByConfig []CountBucket `json:"by_config"`
+	ByTime   []CountBucket `json:"by_time"`
+}
+
+// AlertStats represents aggregated alert counts
+type AlertStats struct {
+	Total                  int64         `json:"total"`
+	ByFinalStatus          []CountBucket `json:"by_final_status"`
+	ByAcknowledgmentStatus []CountBucket `json:"by_acknowledgment_status"`
+	ByConfig               []CountBucket `json:"by_config"`
+	ByTime                 []CountBucket `json:"by_time"`
+}
+
+// StatsOverview represents the combined stats overview response
+type StatsOverview struct {
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Bucket     string         `json:"bucket"`
+	Executions ExecutionStats `json:"executions"`
+	Alerts     AlertStats     `json:"alerts"`
+}
+
+// NoisyCheck represents a health check's alert volume and quality over a report window
+type NoisyCheck struct {
+	ConfigID            string  `json:"config_id" bson:"_id"`
+	ConfigName          string  `json:"config_name,omitempty" bson:"-"`
+	Alerts              int64   `json:"alerts" bson:"alerts"`
+	Acknowledged        int64   `json:"acknowledged" bson:"acknowledged"`
+	Noise               int64   `json:"noise" bson:"noise"`                     // Alerts classified as noise
+	FalsePositiveRate   float64 `json:"false_positive_rate" bson:"-"`           // Noise over alerts
+	DeliveryFailed      int64   `json:"delivery_failed" bson:"delivery_failed"` // Alerts whose webhook delivery failed
+	DeliveryFailureRate float64 `json:"delivery_failure_rate" bson:"-"`         // Failed deliveries over alerts
+}
+
+// NoisyChecksReport represents the noisy-check report response
+type NoisyChecksReport struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	Sort    string       `json:"sort"`
+	Results []NoisyCheck `json:"results"`
+}
+
+// NoisyRule represents a rule's alerts and their responder classifications over a report window
+type NoisyRule struct {
+	ConfigID          string               `json:"config_id" bson:"config_id"`
+	ConfigName        string               `json:"config_name,omitempty" bson:"-"`
+	RuleName          string               `json:"rule_name" bson:"rule_name"`
+	Alerts            int64                `json:"alerts" bson:"alerts"`
+	Real              int64                `json:"real" bson:"real"`
+	Noise             int64                `json:"noise" bson:"noise"`
+	Test              int64                `json:"test" bson:"test"`
+	FalsePositiveRate float64              `json:"false_positive_rate" bson:"-"` // Noise over alerts classified real or noise
+	Suggestion        *ThresholdSuggestion `json:"suggestion,omitempty" bson:"-"`
+}
+
+// ThresholdSuggestion proposes a threshold for a numeric comparison rule that would not have
+// raised the alerts classified as noise
+type ThresholdSuggestion struct {
+	Operator       string      `json:"operator"`
+	ExpectedValue  interface{} `json:"expected_value"` // Current threshold
+	SuggestedValue float64     `json:"suggested_value"`
+	NoiseSamples   int         `json:"noise_samples"` // Noise alerts whose value was found
+	RealSamples    int         `json:"real_samples"`  // Real alerts whose value was found
+	RealMissed     int         `json:"real_missed"`   // Real alerts the suggested threshold would not have raised
+}
+
+// NoisyRulesReport represents the noisy-rule report response
+type NoisyRulesReport struct {
+	From    string      `json:"from"`
+	To      string      `json:"to"`
+	Results []NoisyRule `json:"results"`
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Suite represents a group of health checks executed as a unit on its own schedule
+type Suite struct {
+	ID               primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Name             string               `json:"name" bson:"name"`
+	Description      string               `json:"description,omitempty" bson:"description,omitempty"`
+	Enabled          bool                 `json:"enabled" bson:"enabled"`
+	ConfigIDs        []primitive.ObjectID `json:"config_ids" bson:"config_ids"`
+	Webhook          *Webhook             `json:"webhook,omitempty" bson:"webhook,omitempty"` // Digest alert destination; defaults to the global default webhook
+	AlertOnFailure   bool                 `json:"alert_on_failure" bson:"alert_on_failure"`   // Send a digest alert when the suite fails
+	Metadata         Metadata             `json:"metadata" bson:"metadata"`
+	Schedule         string               `json:"schedule,omitempty" bson:"schedule,omitempty"`
+	ScheduleEnabled  bool                 `json:"schedule_enabled" bson:"schedule_enabled"`
+	LastScheduledRun time.Time            `json:"last_scheduled_run,omitempty" bson:"last_scheduled_run,omitempty"`
+	NextScheduledRun time.Time            `json:"next_scheduled_run,omitempty" bson:"next_scheduled_run,omitempty"`
+}
+
+// Validate validates the suite configuration
+func (s *Suite) Validate() error {
+	if s.Name == "" {
+		return errors.New("suite name is required")
+	}
+
+	if len(s.Name) > 255 {
+		return errors.New("suite name must be 255 characters or less")
+	}
+
+	if len(s.ConfigIDs) == 0 {
+		return errors.New("at least one config ID is required")
+	}
+
+	// Validate digest webhook; without one the default webhook is used
+	if s.Webhook != nil {
+		if err := s.Webhook.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validate schedule if enabled
+	if s.ScheduleEnabled {
+		if s.Schedule == "" {
+			return errors.New("schedule is required when schedule_enabled is true")
+		}
+
+		schedule, err := ParseSchedule(s.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid cron expression: %w", err)
+		}
+
+		if s.NextScheduledRun.IsZero() {
+			s.NextScheduledRun = schedule.Next(time.Now().UTC())
+		}
+	}
+
+	// Set metadata timestamps
+	now := time.Now().UTC()
+	if s.Metadata.CreatedAt.IsZero() {
+		s.Metadata.CreatedAt = now
+	}
+	s.Metadata.UpdatedAt = now
+
+	return nil
+}
+
+// SuiteMemberResult represents the outcome of one check within a suite run
+type SuiteMemberResult struct {
+	ConfigID        primitive.ObjectID `json:"config_id" bson:"config_id"`
+	ConfigName      string             `json:"config_name,omitempty" bson:"config_name,omitempty"`
+	CorrelationID   string             `json:"correlation_id" bson:"correlation_id"`
+	Status          string             `json:"status" bson:"status"` // Execution status, or "failed" when it couldn't run
+	AlertsTriggered int                `json:"alerts_triggered" bson:"alerts_triggered"`
+	Passed          bool               `json:"passed" bson:"passed"`
+	Error           string             `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// SuiteResult represents the combined result of a suite run
+type SuiteResult struct {
+	ID            primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	SuiteID       primitive.ObjectID  `json:"suite_id" bson:"suite_id"`
+	SuiteName     string              `json:"suite_name" bson:"suite_name"`
+	CorrelationID string              `json:"correlation_id" bson:"correlation_id"` // Also the batch ID of member executions
+	ExecutedAt    time.Time           `json:"executed_at" bson:"executed_at"`
+	DurationMs    int64               `json:"duration_ms" bson:"duration_ms"`
+	Status        string              `json:"status" bson:"status"` // "passed", "failed"
+	Total         int                 `json:"total" bson:"total"`
+	Passed        int                 `json:"passed" bson:"passed"`
+	Failed        int                 `json:"failed" bson:"failed"`
+	Members       []SuiteMemberResult `json:"members" bson:"members"`
+	DigestAlertID primitive.ObjectID  `json:"digest_alert_id,omitempty" bson:"digest_alert_id,omitempty"`
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRelativeDuration parses a Go duration ("90m", "24h") or a number of days ("7d")
+func ParseRelativeDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	return d, nil
+}
+
+// ParseTimeBound parses a time range bound given as RFC3339, a date (2006-01-02)
+// or a relative duration measured back from now ("24h", "7d")
+func ParseTimeBound(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	if d, err := ParseRelativeDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("must be RFC3339, YYYY-MM-DD or a relative duration such as 24h or 7d")
+}
+
+// ParseTimeRange parses optional from/to bounds, rejecting ranges where from is after to
+func ParseTimeRange(from, to string) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+
+	var fromTime, toTime time.Time
+	var err error
+	if from != "" {
+		if fromTime, err = ParseTimeBound(from, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if to != "" {
+		if toTime, err = ParseTimeBound(to, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	if !fromTime.IsZero() && !toTime.IsZero() && fromTime.After(toTime) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid time range: from is after to")
+	}
+
+	return fromTime, toTime, nil
+}
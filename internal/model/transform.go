@@ -0,0 +1,43 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+	"github.com/oliveagle/jsonpath"
+)
+
+// Transform reshapes the response body before rules are evaluated, e.g. to drop noisy fields
+// or compute values several rules share. Bodies that aren't JSON are transformed as a string.
+type Transform struct {
+	JQ       string `json:"jq,omitempty" bson:"jq,omitempty"`             // jq program; several outputs are collected into an array
+	JSONPath string `json:"jsonpath,omitempty" bson:"jsonpath,omitempty"` // JSONPath expression selecting part of the body
+}
+
+// Validate checks that exactly one transform is set and compiles it
+func (t *Transform) Validate() error {
+	if (t.JQ == "") == (t.JSONPath == "") {
+		return errors.New("transform must set either jq or jsonpath")
+	}
+	if t.JSONPath != "" {
+		if _, err := jsonpath.Compile(t.JSONPath); err != nil {
+			return fmt.Errorf("invalid transform JSONPath expression '%s': %v", t.JSONPath, err)
+		}
+		return nil
+	}
+	if _, err := CompileJQ(t.JQ); err != nil {
+		return fmt.Errorf("invalid transform jq program: %v", err)
+	}
+	return nil
+}
+
+// CompileJQ parses and compiles a jq program. env and $ENV are empty, so transforms can't
+// read Raven's environment.
+func CompileJQ(program string) (*gojq.Code, error) {
+	query, err := gojq.Parse(program)
+	if err != nil {
+		return nil, err
+	}
+	return gojq.Compile(query, gojq.WithEnvironLoader(func() []string { return nil }))
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// Webhook types; any other type is delivered by a plugin
+const (
+	WebhookTypeWebhook = "webhook" // Default; POSTs {"text": ...} to the URL
+	WebhookTypeSlack   = "slack"   // Slack incoming webhook, with the alert details as attachment fields
+	WebhookTypeEmail   = "email"   // Email to the addresses of a mailto: URL, sent over the configured SMTP server
+)
+
+// IsPlugin reports whether the webhook is delivered by a plugin
+func (w *Webhook) IsPlugin() bool {
+	switch w.Type {
+	case "", WebhookTypeWebhook, WebhookTypeSlack, WebhookTypeEmail:
+		return false
+	}
+	return true
+}
+
+// EmailRecipients returns the addresses listed in a mailto: URL
+func EmailRecipients(rawURL string) ([]string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Scheme != "mailto" || parsedURL.Opaque == "" {
+		return nil, errors.New("email webhook URL must list the recipients, e.g. mailto:oncall@example.com,ops@example.com")
+	}
+	list, err := url.PathUnescape(parsedURL.Opaque)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email recipients: %w", err)
+	}
+	addresses, err := mail.ParseAddressList(list)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email recipients: %w", err)
+	}
+
+	recipients := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		recipients = append(recipients, address.Address)
+	}
+	return recipients, nil
+}
+
+// EmailDomain returns the lower-cased domain of an email address
+func EmailDomain(address string) string {
+	_, domain, _ := strings.Cut(address, "@")
+	return strings.ToLower(domain)
+}
+
+// validateEmail validates an email webhook
+func (w *Webhook) validateEmail() error {
+	if len(w.Settings) > 0 {
+		return errors.New("webhook settings require a plugin notification type")
+	}
+	if _, err := EmailRecipients(w.URL); err != nil {
+		return err
+	}
+	w.RetryConfig.SetDefaults()
+	return nil
+}
+
+// validatePlugin validates a webhook delivered by a plugin; its URL needn't be an HTTP URL
+func (w *Webhook) validatePlugin() error {
+	validate, ok := pluginWebhookType(w.Type)
+	if !ok {
+		return fmt.Errorf("invalid webhook type: %s (must be 'webhook', 'slack', 'email' or a plugin type)", w.Type)
+	}
+	if _, err := url.Parse(w.URL); err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	w.RetryConfig.SetDefaults()
+	return validate(w)
+}
@@ -0,0 +1,72 @@
+package probe
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// quoteEscaper escapes quoted Content-Disposition parameters
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// encodeBody returns the request body of a target and the content type its body type implies,
+// which is empty for raw bodies
+func encodeBody(target model.Target) (string, string, error) {
+	switch target.BodyType {
+	case model.BodyTypeJSON:
+		return target.Body, "application/json", nil
+	case model.BodyTypeForm:
+		values := url.Values{}
+		for _, field := range target.FormFields {
+			values.Add(field.Name, field.Value)
+		}
+		return values.Encode(), "application/x-www-form-urlencoded", nil
+	case model.BodyTypeMultipart:
+		return encodeMultipart(target.FormFields)
+	default:
+		return target.Body, "", nil
+	}
+}
+
+// encodeMultipart encodes form fields as a multipart/form-data body
+func encodeMultipart(fields []model.FormField) (string, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for _, field := range fields {
+		if field.Filename == "" {
+			if err := writer.WriteField(field.Name, field.Value); err != nil {
+				return "", "", fmt.Errorf("failed to write form field %s: %w", field.Name, err)
+			}
+			continue
+		}
+
+		contentType := field.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(field.Name), quoteEscaper.Replace(field.Filename)))
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to write form file %s: %w", field.Name, err)
+		}
+		if _, err := part.Write([]byte(field.Value)); err != nil {
+			return "", "", fmt.Errorf("failed to write form file %s: %w", field.Name, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", "", fmt.Errorf("failed to encode multipart body: %w", err)
+	}
+
+	return buf.String(), writer.FormDataContentType(), nil
+}
@@ -0,0 +1,272 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/chromedp"
+	"github.com/dandantas/raven/internal/model"
+)
+
+// ErrBrowserDisabled is returned for browser checks when no browser is configured
+var ErrBrowserDisabled = errors.New("browser checks are disabled; set BROWSER_ENABLED")
+
+// navigationTimingScript reads the page's navigation timing, in milliseconds since navigation start
+const navigationTimingScript = `(() => {
+	const nav = performance.getEntriesByType("navigation")[0];
+	if (!nav) return {};
+	return {ttfb_ms: nav.responseStart, dom_content_loaded_ms: nav.domContentLoadedEventEnd, load_ms: nav.loadEventEnd};
+})()`
+
+// Browser runs browser checks in tabs of one shared headless Chrome, started on first use
+// and restarted if it exits
+type Browser struct {
+	allocate func() (context.Context, context.CancelFunc)
+
+	mu         sync.Mutex
+	browserCtx context.Context
+	cancel     context.CancelFunc
+}
+
+// NewBrowser creates a browser that connects to the DevTools endpoint at remoteURL when set,
+// and otherwise launches Chrome from execPath, or from the PATH when execPath is empty
+func NewBrowser(execPath, remoteURL string) *Browser {
+	b := &Browser{}
+	if remoteURL != "" {
+		b.allocate = func() (context.Context, context.CancelFunc) {
+			return chromedp.NewRemoteAllocator(context.Background(), remoteURL)
+		}
+		return b
+	}
+
+	opts := chromedp.DefaultExecAllocatorOptions[:]
+	if execPath != "" {
+		opts = append(opts, chromedp.ExecPath(execPath))
+	}
+	b.allocate = func() (context.Context, context.CancelFunc) {
+		return chromedp.NewExecAllocator(context.Background(), opts...)
+	}
+	return b
+}
+
+// Close shuts the browser down
+func (b *Browser) Close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cancel != nil {
+		b.cancel()
+		b.browserCtx, b.cancel = nil, nil
+	}
+}
+
+// tab opens a new tab, starting the browser if it isn't running
+func (b *Browser) tab() (context.Context, context.CancelFunc, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.browserCtx == nil || b.browserCtx.Err() != nil {
+		allocCtx, cancelAlloc := b.allocate()
+		browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+		if err := chromedp.Run(browserCtx); err != nil {
+			cancelBrowser()
+			cancelAlloc()
+			return nil, nil, fmt.Errorf("failed to start browser: %w", err)
+		}
+		b.browserCtx = browserCtx
+		b.cancel = func() {
+			cancelBrowser()
+			cancelAlloc()
+		}
+	}
+
+	tabCtx, cancel := chromedp.NewContext(b.browserCtx)
+	return tabCtx, cancel, nil
+}
+
+// browserPage is the document browser check rules are evaluated against
+type browserPage struct {
+	URL    string             `json:"url"` // After redirects
+	Title  string             `json:"title"`
+	Status int                `json:"status"`
+	Timing browserTiming      `json:"timing"`
+	Values map[string]*string `json:"values,omitempty"` // Null when the selector matches nothing
+}
+
+// browserTiming holds page load milestones in milliseconds
+type browserTiming struct {
+	TTFBMs             float64 `json:"ttfb_ms"`
+	DOMContentLoadedMs float64 `json:"dom_content_loaded_ms"`
+	LoadMs             float64 `json:"load_ms"`
+	SelectorVisibleMs  int64   `json:"selector_visible_ms,omitempty"` // Until the wait selector became visible
+}
+
+// Call loads the target page in a new tab, waits for the wait selector and captures the page
+// status, load timing and extracted values as a JSON response body
+func (b *Browser) Call(ctx context.Context, client *http.Client, target model.Target, check *model.BrowserCheck, opts Options) (model.ExecutionRequest, model.ExecutionResponse, error) {
+	execRequest := model.ExecutionRequest{
+		URL:     target.URL,
+		Method:  http.MethodGet,
+		Headers: make(map[string]string),
+	}
+	execResponse := model.ExecutionResponse{
+		Headers: make(map[string]string),
+	}
+	if check == nil {
+		check = &model.BrowserCheck{}
+	}
+
+	if b == nil {
+		execResponse.Error = ErrBrowserDisabled.Error()
+		return execRequest, execResponse, ErrBrowserDisabled
+	}
+
+	timeout := time.Duration(target.Timeout) * time.Second
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Headers and credentials are prepared as for an HTTP call, then added to the page's requests
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, target.URL, nil)
+	if err != nil {
+		execResponse.Error = fmt.Sprintf("Failed to create request: %v", err)
+		return execRequest, execResponse, err
+	}
+	setHeaders(req, execRequest.Headers, target, opts)
+	if err := setAuthentication(reqCtx, client, req, target.Auth); err != nil {
+		execResponse.Error = fmt.Sprintf("Failed to set authentication: %v", err)
+		return execRequest, execResponse, err
+	}
+
+	tabCtx, closeTab, err := b.tab()
+	if err != nil {
+		execResponse.Error = err.Error()
+		return execRequest, execResponse, err
+	}
+	defer closeTab()
+	tabCtx, cancelTab := context.WithTimeout(tabCtx, timeout)
+	defer cancelTab()
+	// Execution limits shorter than the target timeout close the tab too
+	stop := context.AfterFunc(reqCtx, cancelTab)
+	defer stop()
+
+	if len(req.Header) > 0 {
+		if err := addHeaders(tabCtx, req); err != nil {
+			execResponse.Error = fmt.Sprintf("Failed to set request headers: %v", err)
+			return execRequest, execResponse, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := chromedp.RunResponse(tabCtx, chromedp.Navigate(target.URL))
+	if err != nil {
+		err = &NetworkError{Err: browserError(reqCtx, tabCtx, err, "page not loaded", timeout)}
+		execResponse.Error = fmt.Sprintf("Page load failed: %v", err)
+		return execRequest, execResponse, err
+	}
+	execResponse.StatusCode = int(resp.Status)
+	for key, value := range resp.Headers {
+		execResponse.Headers[key] = fmt.Sprint(value)
+	}
+
+	page := browserPage{Status: execResponse.StatusCode}
+	var actions []chromedp.Action
+	if check.WaitSelector != "" {
+		actions = append(actions,
+			chromedp.WaitVisible(check.WaitSelector, chromedp.ByQuery),
+			chromedp.ActionFunc(func(context.Context) error {
+				page.Timing.SelectorVisibleMs = time.Since(start).Milliseconds()
+				return nil
+			}),
+		)
+	}
+	actions = append(actions,
+		chromedp.Location(&page.URL),
+		chromedp.Title(&page.Title),
+		chromedp.Evaluate(navigationTimingScript, &page.Timing),
+	)
+	if len(check.Values) > 0 {
+		actions = append(actions, chromedp.Evaluate(valuesScript(check.Values), &page.Values))
+	}
+	if err := chromedp.Run(tabCtx, actions...); err != nil {
+		what := "page not inspected"
+		if check.WaitSelector != "" && page.Timing.SelectorVisibleMs == 0 {
+			what = fmt.Sprintf("selector %q not visible", check.WaitSelector)
+		}
+		err = browserError(reqCtx, tabCtx, err, what, timeout)
+		execResponse.Error = fmt.Sprintf("Page inspection failed: %v", err)
+		return execRequest, execResponse, err
+	}
+
+	body, err := json.Marshal(page)
+	if err != nil {
+		execResponse.Error = fmt.Sprintf("Failed to encode page: %v", err)
+		return execRequest, execResponse, err
+	}
+	execResponse.Body = string(body)
+
+	return execRequest, execResponse, nil
+}
+
+// addHeaders intercepts the tab's requests to add the prepared headers. Only requests to the
+// target's own origin get them, so credentials aren't sent to third-party hosts the page uses.
+func addHeaders(tabCtx context.Context, req *http.Request) error {
+	origin := req.URL.Scheme + "://" + req.URL.Host
+
+	chromedp.ListenTarget(tabCtx, func(ev any) {
+		paused, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		// Listeners must not block, so requests are continued from their own goroutine
+		go func() {
+			continued := fetch.ContinueRequest(paused.RequestID)
+			if u, err := url.Parse(paused.Request.URL); err == nil && u.Scheme+"://"+u.Host == origin {
+				var entries []*fetch.HeaderEntry
+				for name, value := range paused.Request.Headers {
+					if req.Header.Get(name) == "" {
+						entries = append(entries, &fetch.HeaderEntry{Name: name, Value: fmt.Sprint(value)})
+					}
+				}
+				for name := range req.Header {
+					entries = append(entries, &fetch.HeaderEntry{Name: name, Value: req.Header.Get(name)})
+				}
+				continued = continued.WithHeaders(entries)
+			}
+			_ = chromedp.Run(tabCtx, continued)
+		}()
+	})
+
+	return chromedp.Run(tabCtx, fetch.Enable())
+}
+
+// valuesScript returns a script mapping each value name to the trimmed text of the first
+// element its selector matches
+func valuesScript(values map[string]string) string {
+	selectors, _ := json.Marshal(values)
+	return `(() => {
+	const values = {};
+	for (const [name, selector] of Object.entries(` + string(selectors) + `)) {
+		const element = document.querySelector(selector);
+		values[name] = element ? element.textContent.trim() : null;
+	}
+	return values;
+})()`
+}
+
+// browserError reports a step cut short by the timeout as what did not happen in time, and
+// returns other errors as is
+func browserError(reqCtx, tabCtx context.Context, err error, what string, timeout time.Duration) error {
+	if errors.Is(reqCtx.Err(), context.DeadlineExceeded) || errors.Is(tabCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s within %s", what, timeout)
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProfileStore looks up stored auth profiles
+type ProfileStore interface {
+	GetByID(ctx context.Context, id primitive.ObjectID) (*model.AuthProfile, error)
+}
+
+// Credentials resolves auth profile references into the credentials sent to targets
+type Credentials struct {
+	profiles ProfileStore
+}
+
+// NewCredentials creates a credentials resolver backed by the given profile store
+func NewCredentials(profiles ProfileStore) *Credentials {
+	return &Credentials{
+		profiles: profiles,
+	}
+}
+
+// ResolveTarget returns a copy of the target whose auth profile reference is replaced by the profile's credentials
+func (c *Credentials) ResolveTarget(ctx context.Context, target model.Target) (model.Target, error) {
+	if target.Auth.ProfileID.IsZero() {
+		return target, nil
+	}
+
+	if c == nil || c.profiles == nil {
+		return target, errors.New("auth profiles are not configured")
+	}
+
+	profile, err := c.profiles.GetByID(ctx, target.Auth.ProfileID)
+	if err != nil {
+		return target, fmt.Errorf("failed to resolve auth profile %s: %w", target.Auth.ProfileID.Hex(), err)
+	}
+
+	target.Auth = profile.Auth
+	return target, nil
+}
@@ -0,0 +1,106 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// maxDialClients bounds the cached clients for targets with dial settings; a full cache is reset
+const maxDialClients = 256
+
+var (
+	dialClientsMu sync.Mutex
+	dialClients   = make(map[string]*http.Client)
+)
+
+// clientFor returns the client to call a target with. Targets that force an address family
+// or override DNS get their own transport, so their connections are never pooled with
+// connections to the same hostname made through normal resolution.
+func clientFor(base *http.Client, target model.Target) *http.Client {
+	if target.IPVersion == 0 && len(target.Resolve) == 0 {
+		return base
+	}
+
+	key := dialKey(base, target)
+
+	dialClientsMu.Lock()
+	defer dialClientsMu.Unlock()
+
+	if client, ok := dialClients[key]; ok {
+		return client
+	}
+
+	if len(dialClients) >= maxDialClients {
+		for _, client := range dialClients {
+			client.CloseIdleConnections()
+		}
+		dialClients = make(map[string]*http.Client)
+	}
+
+	transport, ok := base.Transport.(*http.Transport)
+	if ok {
+		transport = transport.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport.DialContext = dialContext(target.IPVersion, target.Resolve)
+
+	client := &http.Client{
+		Timeout:       base.Timeout,
+		Transport:     transport,
+		CheckRedirect: base.CheckRedirect,
+		Jar:           base.Jar,
+	}
+	dialClients[key] = client
+	return client
+}
+
+// dialKey identifies a base client and dial settings combination
+func dialKey(base *http.Client, target model.Target) string {
+	hosts := make([]string, 0, len(target.Resolve))
+	for host, ip := range target.Resolve {
+		hosts = append(hosts, strings.ToLower(host)+"="+ip)
+	}
+	sort.Strings(hosts)
+	return fmt.Sprintf("%p|%d|%s", base, target.IPVersion, strings.Join(hosts, ","))
+}
+
+// dialContext dials with an optional address family and host to IP overrides, like curl --resolve
+func dialContext(ipVersion int, resolve map[string]string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	overrides := make(map[string]string, len(resolve))
+	for host, ip := range resolve {
+		overrides[strings.ToLower(host)] = ip
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		switch ipVersion {
+		case 4:
+			network = "tcp4"
+		case 6:
+			network = "tcp6"
+		}
+
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if ip, ok := overrides[strings.ToLower(host)]; ok {
+			addr = net.JoinHostPort(ip, port)
+		}
+
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
@@ -1,4 +1,4 @@
-package service
+package probe
 
 import (
 	"net/http"
@@ -14,7 +14,9 @@ func NewHTTPClient(timeout time.Duration) *http.Client {
 			MaxIdleConnsPerHost: 10,
 			IdleConnTimeout:     90 * time.Second,
 			TLSHandshakeTimeout: 10 * time.Second,
-			DisableCompression:  false,
+			// How long Expect: 100-continue requests wait before sending the body anyway
+			ExpectContinueTimeout: 1 * time.Second,
+			DisableCompression:    false,
 		},
 	}
 }
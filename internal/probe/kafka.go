@@ -0,0 +1,189 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// kafkaLag is the document kafka check rules are evaluated against
+type kafkaLag struct {
+	Group  string                    `json:"group"`
+	Lag    int64                     `json:"lag"` // Across all measured topics
+	Topics map[string]*kafkaTopicLag `json:"topics"`
+}
+
+// kafkaTopicLag is the lag of a consumer group on one topic
+type kafkaTopicLag struct {
+	Lag        int64               `json:"lag"`
+	MaxLag     int64               `json:"max_lag"` // Of the most lagging partition
+	Partitions []kafkaPartitionLag `json:"partitions"`
+}
+
+// kafkaPartitionLag is the lag of a consumer group on one partition
+type kafkaPartitionLag struct {
+	Partition int   `json:"partition"`
+	Committed int64 `json:"committed"` // -1 when the group never committed an offset
+	End       int64 `json:"end"`
+	Lag       int64 `json:"lag"`
+}
+
+// CallKafka measures the lag of a consumer group: per partition, how far its committed offset
+// trails the end of the log. Partitions without a committed offset lag by every retained message.
+func CallKafka(ctx context.Context, target model.Target, check *model.KafkaCheck) (model.ExecutionRequest, model.ExecutionResponse, error) {
+	execRequest := model.ExecutionRequest{
+		URL:     target.URL,
+		Headers: make(map[string]string),
+	}
+	execResponse := model.ExecutionResponse{
+		Headers: make(map[string]string),
+	}
+	if check == nil {
+		check = &model.KafkaCheck{}
+	}
+
+	brokers, err := model.KafkaBrokers(target.URL)
+	if err != nil {
+		execResponse.Error = err.Error()
+		return execRequest, execResponse, err
+	}
+
+	timeout := time.Duration(target.Timeout) * time.Second
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	transport := &kafka.Transport{DialTimeout: timeout}
+	if check.TLS {
+		transport.TLS = &tls.Config{}
+	}
+	if target.Auth.Username != "" {
+		mechanism, err := kafkaSASL(check.SASLMechanism, target.Auth.Username, target.Auth.Password)
+		if err != nil {
+			execResponse.Error = fmt.Sprintf("Failed to set authentication: %v", err)
+			return execRequest, execResponse, err
+		}
+		transport.SASL = mechanism
+	}
+	defer transport.CloseIdleConnections()
+
+	client := &kafka.Client{Addr: kafka.TCP(brokers...), Timeout: timeout, Transport: transport}
+
+	lag, err := kafkaGroupLag(reqCtx, client, check)
+	if err != nil {
+		execResponse.Error = fmt.Sprintf("Lag lookup failed: %v", err)
+		return execRequest, execResponse, err
+	}
+
+	body, err := json.Marshal(lag)
+	if err != nil {
+		execResponse.Error = fmt.Sprintf("Failed to encode lag: %v", err)
+		return execRequest, execResponse, err
+	}
+	execResponse.StatusCode = 200
+	execResponse.Body = string(body)
+
+	return execRequest, execResponse, nil
+}
+
+// kafkaGroupLag fetches the group's committed offsets and the end offsets of the same partitions
+func kafkaGroupLag(ctx context.Context, client *kafka.Client, check *model.KafkaCheck) (*kafkaLag, error) {
+	// Listed topics are measured on every partition; otherwise the broker returns the committed ones
+	var partitions map[string][]int
+	if len(check.Topics) > 0 {
+		metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: check.Topics})
+		if err != nil {
+			return nil, &NetworkError{Err: err}
+		}
+		partitions = make(map[string][]int, len(metadata.Topics))
+		for _, topic := range metadata.Topics {
+			if topic.Error != nil {
+				return nil, fmt.Errorf("topic %s: %w", topic.Name, topic.Error)
+			}
+			for _, partition := range topic.Partitions {
+				partitions[topic.Name] = append(partitions[topic.Name], partition.ID)
+			}
+		}
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: check.Group, Topics: partitions})
+	if err != nil {
+		return nil, &NetworkError{Err: err}
+	}
+	if committed.Error != nil {
+		return nil, fmt.Errorf("group %s: %w", check.Group, committed.Error)
+	}
+
+	requests := make(map[string][]kafka.OffsetRequest, len(committed.Topics))
+	for topic, offsets := range committed.Topics {
+		for _, offset := range offsets {
+			requests[topic] = append(requests[topic], kafka.FirstOffsetOf(offset.Partition), kafka.LastOffsetOf(offset.Partition))
+		}
+	}
+	ends, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: requests})
+	if err != nil {
+		return nil, &NetworkError{Err: err}
+	}
+
+	lag := &kafkaLag{Group: check.Group, Topics: make(map[string]*kafkaTopicLag, len(committed.Topics))}
+	for topic, offsets := range committed.Topics {
+		logs := make(map[int]kafka.PartitionOffsets, len(ends.Topics[topic]))
+		for _, log := range ends.Topics[topic] {
+			if log.Error != nil {
+				return nil, fmt.Errorf("topic %s partition %d: %w", topic, log.Partition, log.Error)
+			}
+			logs[log.Partition] = log
+		}
+
+		topicLag := &kafkaTopicLag{Partitions: make([]kafkaPartitionLag, 0, len(offsets))}
+		for _, offset := range offsets {
+			if offset.Error != nil {
+				return nil, fmt.Errorf("topic %s partition %d: %w", topic, offset.Partition, offset.Error)
+			}
+			log := logs[offset.Partition]
+
+			partitionLag := kafkaPartitionLag{
+				Partition: offset.Partition,
+				Committed: offset.CommittedOffset,
+				End:       log.LastOffset,
+			}
+			if offset.CommittedOffset < 0 {
+				partitionLag.Lag = log.LastOffset - log.FirstOffset
+			} else {
+				partitionLag.Lag = max(log.LastOffset-offset.CommittedOffset, 0)
+			}
+
+			topicLag.Lag += partitionLag.Lag
+			topicLag.MaxLag = max(topicLag.MaxLag, partitionLag.Lag)
+			topicLag.Partitions = append(topicLag.Partitions, partitionLag)
+		}
+		sort.Slice(topicLag.Partitions, func(i, j int) bool {
+			return topicLag.Partitions[i].Partition < topicLag.Partitions[j].Partition
+		})
+
+		lag.Lag += topicLag.Lag
+		lag.Topics[topic] = topicLag
+	}
+
+	return lag, nil
+}
+
+// kafkaSASL returns the SASL mechanism carrying the target credentials
+func kafkaSASL(mechanism, username, password string) (sasl.Mechanism, error) {
+	switch mechanism {
+	case model.KafkaSASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, username, password)
+	case model.KafkaSASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, username, password)
+	default:
+		return plain.Mechanism{Username: username, Password: password}, nil
+	}
+}
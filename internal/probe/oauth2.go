@@ -0,0 +1,109 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// tokenRefreshMargin renews cached tokens this long before they expire
+const tokenRefreshMargin = 30 * time.Second
+
+// defaultTokenLifetime is used when the token endpoint omits expires_in
+const defaultTokenLifetime = 5 * time.Minute
+
+// cachedToken is an OAuth2 access token and its expiry
+type cachedToken struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+// tokenCache shares client credentials tokens between probes of the same client
+var tokenCache = struct {
+	sync.Mutex
+	tokens map[string]cachedToken
+}{tokens: make(map[string]cachedToken)}
+
+// oauth2Token returns a cached access token for the client, fetching a new one when it is missing or about to expire
+func oauth2Token(ctx context.Context, client *http.Client, auth model.Auth) (string, error) {
+	key := auth.TokenURL + "|" + auth.ClientID + "|" + strings.Join(auth.Scopes, " ")
+
+	tokenCache.Lock()
+	cached, ok := tokenCache.tokens[key]
+	tokenCache.Unlock()
+	if ok && time.Now().Add(tokenRefreshMargin).Before(cached.expiresAt) {
+		return cached.accessToken, nil
+	}
+
+	token, err := fetchOAuth2Token(ctx, client, auth)
+	if err != nil {
+		return "", err
+	}
+
+	tokenCache.Lock()
+	tokenCache.tokens[key] = token
+	tokenCache.Unlock()
+
+	return token.accessToken, nil
+}
+
+// fetchOAuth2Token requests an access token with the client credentials grant
+func fetchOAuth2Token(ctx context.Context, client *http.Client, auth model.Auth) (cachedToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(auth.Scopes) > 0 {
+		form.Set("scope", strings.Join(auth.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, auth.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return cachedToken{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(auth.ClientID), url.QueryEscape(auth.ClientSecret))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return cachedToken{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	if err != nil {
+		return cachedToken{}, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return cachedToken{}, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return cachedToken{}, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return cachedToken{}, fmt.Errorf("token response has no access_token")
+	}
+
+	lifetime := defaultTokenLifetime
+	if payload.ExpiresIn > 0 {
+		lifetime = time.Duration(payload.ExpiresIn) * time.Second
+	}
+
+	return cachedToken{
+		accessToken: payload.AccessToken,
+		expiresAt:   time.Now().Add(lifetime),
+	}, nil
+}
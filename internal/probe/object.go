@@ -0,0 +1,218 @@
+package probe
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// storageObject is the document object check rules are evaluated against
+type storageObject struct {
+	Bucket        string     `json:"bucket"`
+	Key           string     `json:"key"`
+	Exists        bool       `json:"exists"`
+	LastModified  *time.Time `json:"last_modified"` // Null when the object doesn't exist
+	AgeSeconds    *int64     `json:"age_seconds"`   // Since the last modification; null when the object doesn't exist
+	MaxAgeSeconds int64      `json:"max_age_seconds,omitempty"`
+	Fresh         bool       `json:"fresh"` // Exists and, when a max age is set, was modified within it
+	SizeBytes     int64      `json:"size_bytes"`
+	ETag          string     `json:"etag,omitempty"`
+}
+
+// CallObject reads an object's metadata with a HEAD request to an S3-compatible endpoint. Basic auth
+// credentials are used as the access key ID and secret to sign the request with AWS Signature
+// Version 4; other auth types are sent as for HTTP calls. A missing object is reported in the
+// document rather than as an error, so rules can alert on it.
+func CallObject(ctx context.Context, client *http.Client, target model.Target, check *model.ObjectCheck, opts Options) (model.ExecutionRequest, model.ExecutionResponse, error) {
+	if check == nil {
+		check = &model.ObjectCheck{}
+	}
+
+	objectTarget := target
+	objectTarget.URL = strings.TrimRight(target.URL, "/") + "/" + s3Escape(check.Bucket) + "/" + s3Escape(check.Key)
+	objectTarget.Method = http.MethodHead
+
+	if strings.EqualFold(target.Auth.Type, "basic") {
+		headers, err := signV4(objectTarget, objectRegion(target.URL, check.Region), time.Now().UTC())
+		if err != nil {
+			execResponse := model.ExecutionResponse{Error: fmt.Sprintf("Failed to sign request: %v", err)}
+			return model.ExecutionRequest{URL: objectTarget.URL, Method: objectTarget.Method}, execResponse, err
+		}
+		objectTarget.Headers = headers
+		objectTarget.Auth = model.Auth{}
+	}
+
+	request, response, err := Call(ctx, client, objectTarget, opts)
+	// The signature is only valid briefly, but isn't kept with the execution either
+	delete(request.Headers, "Authorization")
+	if err != nil {
+		return request, response, err
+	}
+
+	object := storageObject{Bucket: check.Bucket, Key: check.Key}
+	var maxAge time.Duration
+	if check.MaxAge != "" {
+		maxAge, _ = model.ParseRelativeDuration(check.MaxAge)
+		object.MaxAgeSeconds = int64(maxAge.Seconds())
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		lastModified, err := http.ParseTime(response.Headers["Last-Modified"])
+		if err != nil {
+			err = fmt.Errorf("invalid Last-Modified header: %q", response.Headers["Last-Modified"])
+			response.Error = err.Error()
+			return request, response, err
+		}
+		lastModified = lastModified.UTC()
+		age := int64(max(time.Since(lastModified), 0).Seconds())
+
+		object.Exists = true
+		object.LastModified = &lastModified
+		object.AgeSeconds = &age
+		object.Fresh = maxAge == 0 || age <= object.MaxAgeSeconds
+		object.SizeBytes, _ = strconv.ParseInt(response.Headers["Content-Length"], 10, 64)
+		object.ETag = strings.Trim(response.Headers["Etag"], `"`)
+	case http.StatusNotFound:
+	case http.StatusForbidden:
+		// S3 answers 403 for missing objects too when the credentials can't list the bucket
+		err = fmt.Errorf("access to %s/%s denied", check.Bucket, check.Key)
+		response.Error = err.Error()
+		return request, response, err
+	default:
+		err = fmt.Errorf("storage returned status %d", response.StatusCode)
+		response.Error = err.Error()
+		return request, response, err
+	}
+
+	body, err := json.Marshal(object)
+	if err != nil {
+		response.Error = fmt.Sprintf("Failed to encode object: %v", err)
+		return request, response, err
+	}
+	// Missing objects are a result to evaluate, not a failed call
+	response.StatusCode = http.StatusOK
+	response.Body = string(body)
+
+	return request, response, nil
+}
+
+// objectRegion returns the region to sign requests to the endpoint with
+func objectRegion(endpoint, region string) string {
+	if region != "" {
+		return region
+	}
+	parsedURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "us-east-1"
+	}
+	host := parsedURL.Hostname()
+	if host == "storage.googleapis.com" {
+		return "auto"
+	}
+	// s3.<region>.amazonaws.com, also in the s3-<region> and dualstack forms
+	if rest, ok := strings.CutSuffix(host, ".amazonaws.com"); ok {
+		labels := strings.Split(rest, ".")
+		if name := labels[len(labels)-1]; strings.HasPrefix(name, "s3-") {
+			return strings.TrimPrefix(name, "s3-")
+		}
+		if len(labels) >= 2 && labels[len(labels)-1] != "s3" {
+			return labels[len(labels)-1]
+		}
+	}
+	return "us-east-1"
+}
+
+// signV4 returns the target headers with those of an AWS Signature Version 4 for an S3 request
+// without a body added. Target headers starting with x-amz-, such as a session token, are signed too.
+func signV4(target model.Target, region string, now time.Time) (map[string]string, error) {
+	parsedURL, err := url.Parse(target.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + region + "/s3/aws4_request"
+
+	signed := map[string]string{
+		"host":                 parsedURL.Host,
+		"x-amz-content-sha256": emptyPayloadHash,
+		"x-amz-date":           amzDate,
+	}
+	for key, value := range target.Headers {
+		if name := strings.ToLower(key); strings.HasPrefix(name, "x-amz-") && value != "" {
+			signed[name] = strings.TrimSpace(value)
+		}
+	}
+	names := slices.Sorted(maps.Keys(signed))
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		target.Method,
+		parsedURL.EscapedPath(),
+		parsedURL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+target.Auth.Password), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	headers := make(map[string]string, len(target.Headers)+3)
+	maps.Copy(headers, target.Headers)
+	headers["X-Amz-Content-Sha256"] = emptyPayloadHash
+	headers["X-Amz-Date"] = amzDate
+	headers["Authorization"] = fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		target.Auth.Username, scope, signedHeaders, signature)
+	return headers, nil
+}
+
+// s3Escape escapes a bucket or key for an S3 request path: everything but unreserved
+// characters is percent-encoded, and slashes in keys are kept
+func s3Escape(value string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~', b == '/':
+			escaped.WriteByte(b)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
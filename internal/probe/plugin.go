@@ -0,0 +1,100 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/pkg/plugins"
+)
+
+// pluginValidateTimeout limits how long a plugin may take to validate a health check
+const pluginValidateTimeout = 10 * time.Second
+
+// PluginValidator returns the validator of a check type run by executor
+func PluginValidator(executor plugins.CheckExecutor) model.CheckTypeValidator {
+	return func(hc *model.HealthCheckConfig) error {
+		ctx, cancel := context.WithTimeout(context.Background(), pluginValidateTimeout)
+		defer cancel()
+
+		if err := executor.Validate(ctx, pluginRequest(hc, hc.Target, Options{})); err != nil {
+			return fmt.Errorf("invalid %s check: %w", hc.Type, err)
+		}
+		return nil
+	}
+}
+
+// CallPlugin makes a single call to the target through the executor of a plugin check type
+func CallPlugin(ctx context.Context, executor plugins.CheckExecutor, config *model.HealthCheckConfig, target model.Target, opts Options) (model.ExecutionRequest, model.ExecutionResponse, error) {
+	execRequest := model.ExecutionRequest{
+		URL:     target.URL,
+		Method:  target.Method,
+		Headers: make(map[string]string),
+		Body:    target.Body,
+	}
+	maps.Copy(execRequest.Headers, target.Headers)
+	execResponse := model.ExecutionResponse{
+		Headers: make(map[string]string),
+	}
+
+	if executor == nil {
+		err := fmt.Errorf("no plugin is loaded for check type %s", config.Type)
+		execResponse.Error = err.Error()
+		return execRequest, execResponse, err
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, time.Duration(target.Timeout)*time.Second)
+	defer cancel()
+
+	result, err := executor.Execute(reqCtx, pluginRequest(config, target, opts))
+	if err != nil {
+		execResponse.Error = fmt.Sprintf("Plugin call failed: %v", err)
+		return execRequest, execResponse, err
+	}
+
+	execResponse.StatusCode = result.StatusCode
+	if execResponse.StatusCode == 0 {
+		execResponse.StatusCode = http.StatusOK
+	}
+	maps.Copy(execResponse.Headers, result.Headers)
+	execResponse.Body = string(result.Body)
+
+	return execRequest, execResponse, nil
+}
+
+// pluginRequest describes the config and target to a plugin
+func pluginRequest(config *model.HealthCheckConfig, target model.Target, opts Options) plugins.CheckRequest {
+	req := plugins.CheckRequest{
+		Type:           config.Type,
+		CheckID:        opts.CheckID,
+		CheckName:      config.Name,
+		CorrelationID:  opts.CorrelationID,
+		URL:            target.URL,
+		Method:         target.Method,
+		Headers:        target.Headers,
+		Body:           target.Body,
+		TimeoutSeconds: target.Timeout,
+		Settings:       config.Settings,
+	}
+	if req.CheckID == "" && !config.ID.IsZero() {
+		req.CheckID = config.ID.Hex()
+	}
+
+	auth := target.Auth
+	if auth.Type != "" && auth.Type != "none" {
+		req.Auth = &plugins.Auth{
+			Type:         auth.Type,
+			Username:     auth.Username,
+			Password:     auth.Password,
+			Token:        auth.Token,
+			TokenURL:     auth.TokenURL,
+			ClientID:     auth.ClientID,
+			ClientSecret: auth.ClientSecret,
+			Scopes:       auth.Scopes,
+		}
+	}
+	return req
+}
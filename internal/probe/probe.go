@@ -0,0 +1,264 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// maxResponseBodySize limits how much of a target response body is read
+const maxResponseBodySize = 1024 * 1024
+
+// Options tunes target calls. Per-target settings take precedence over these defaults.
+type Options struct {
+	HeaderTimeout       time.Duration     // Limit on waiting for response headers; zero leaves only the target timeout
+	BodyTimeout         time.Duration     // Limit on reading the response body; zero leaves only the target timeout
+	RetryOnNetworkError bool              // Retry calls that fail before a response is read
+	RetryBudget         int               // Retries allowed per execution
+	RetryBackoff        time.Duration     // Delay before the first retry, doubled for each one after it
+	RetryOnStatus       []int             // Response status codes that are retried
+	UserAgent           string            // User-Agent sent unless the target sets one
+	DefaultHeaders      map[string]string // Headers sent unless the target overrides them
+	CheckID             string            // Sent as X-Raven-Check-Id when set
+	CorrelationID       string            // Sent as X-Raven-Correlation-Id when set
+}
+
+// Probe identification headers, so target-side logs can be matched to executions
+const (
+	HeaderCheckID       = "X-Raven-Check-Id"
+	HeaderCorrelationID = "X-Raven-Correlation-Id"
+)
+
+// ForTarget returns the options with the target's overrides applied
+func (o Options) ForTarget(target model.Target) Options {
+	if target.HeaderTimeout > 0 {
+		o.HeaderTimeout = time.Duration(target.HeaderTimeout) * time.Second
+	}
+	if target.BodyTimeout > 0 {
+		o.BodyTimeout = time.Duration(target.BodyTimeout) * time.Second
+	}
+	if target.RetryOnNetworkError != nil {
+		o.RetryOnNetworkError = *target.RetryOnNetworkError
+	}
+	if target.RetryBudget > 0 {
+		o.RetryBudget = target.RetryBudget
+	}
+	if target.RetryBackoffMs > 0 {
+		o.RetryBackoff = time.Duration(target.RetryBackoffMs) * time.Millisecond
+	}
+	if len(target.RetryOnStatus) > 0 {
+		o.RetryOnStatus = target.RetryOnStatus
+	}
+	return o
+}
+
+// RetriesEnabled reports whether failed calls may be retried
+func (o Options) RetriesEnabled() bool {
+	return o.RetryBudget > 0 && (o.RetryOnNetworkError || len(o.RetryOnStatus) > 0)
+}
+
+// NetworkError reports a target call that failed in transport, before a full response was read
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Call makes an HTTP request to the target API and captures the request and response
+func Call(ctx context.Context, client *http.Client, target model.Target, opts Options) (model.ExecutionRequest, model.ExecutionResponse, error) {
+	opts = opts.ForTarget(target)
+
+	execRequest := model.ExecutionRequest{
+		URL:     target.URL,
+		Method:  target.Method,
+		Headers: make(map[string]string),
+	}
+
+	execResponse := model.ExecutionResponse{
+		Headers: make(map[string]string),
+	}
+
+	// Set timeout
+	timeout := time.Duration(target.Timeout) * time.Second
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	slog.Debug("Making API request",
+		"url", target.URL,
+		"method", target.Method,
+		"timeout_seconds", target.Timeout,
+	)
+
+	// Prepare request body
+	body, contentType, err := encodeBody(target)
+	if err != nil {
+		execResponse.Error = fmt.Sprintf("Failed to encode request body: %v", err)
+		return execRequest, execResponse, err
+	}
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = bytes.NewBufferString(body)
+		execRequest.Body = body
+	}
+
+	// Header and body stage limits cancel the request with their own cause
+	stageCtx, cancelStage := context.WithCancelCause(reqCtx)
+	defer cancelStage(nil)
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(stageCtx, target.Method, target.URL, bodyReader)
+	if err != nil {
+		execResponse.Error = fmt.Sprintf("Failed to create request: %v", err)
+		return execRequest, execResponse, err
+	}
+
+	setHeaders(req, execRequest.Headers, target, opts)
+	// Multipart bodies need the boundary they were encoded with; other types keep a configured Content-Type
+	if contentType != "" && (target.BodyType == model.BodyTypeMultipart || req.Header.Get("Content-Type") == "") {
+		req.Header.Set("Content-Type", contentType)
+		execRequest.Headers["Content-Type"] = contentType
+	}
+
+	if target.ExpectContinue && body != "" {
+		req.Header.Set("Expect", "100-continue")
+		execRequest.Headers["Expect"] = "100-continue"
+	}
+
+	// Set authentication
+	if err := setAuthentication(reqCtx, client, req, target.Auth); err != nil {
+		execResponse.Error = fmt.Sprintf("Failed to set authentication: %v", err)
+		return execRequest, execResponse, err
+	}
+
+	// Make request
+	stop := stageTimeout(cancelStage, opts.HeaderTimeout, "no response headers within %s")
+	resp, err := clientFor(client, target).Do(req)
+	stop()
+	if err != nil {
+		err = &NetworkError{Err: stageError(stageCtx, err)}
+		execResponse.Error = fmt.Sprintf("Request failed: %v", err)
+		return execRequest, execResponse, err
+	}
+	defer resp.Body.Close()
+
+	// Read response (limit to 1MB); HEAD responses have no body to read
+	var bodyBytes []byte
+	if req.Method != http.MethodHead {
+		stop = stageTimeout(cancelStage, opts.BodyTimeout, "response body not read within %s")
+		bodyBytes, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+		stop()
+		if err != nil {
+			err = &NetworkError{Err: stageError(stageCtx, err)}
+			execResponse.Error = fmt.Sprintf("Failed to read response: %v", err)
+			return execRequest, execResponse, err
+		}
+	}
+
+	// Capture response headers
+	for key := range resp.Header {
+		execResponse.Headers[key] = resp.Header.Get(key)
+	}
+
+	execResponse.StatusCode = resp.StatusCode
+	execResponse.Body = string(bodyBytes)
+
+	slog.Debug("API request completed",
+		"url", target.URL,
+		"status_code", resp.StatusCode,
+		"body_length", len(bodyBytes),
+	)
+
+	return execRequest, execResponse, nil
+}
+
+// setHeaders sets the request headers and records them; target headers override the defaults,
+// and an empty value removes a default
+func setHeaders(req *http.Request, recorded map[string]string, target model.Target, opts Options) {
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+		recorded["User-Agent"] = opts.UserAgent
+	}
+	for key, value := range opts.DefaultHeaders {
+		req.Header.Set(key, value)
+		recorded[key] = value
+	}
+	if opts.CheckID != "" {
+		req.Header.Set(HeaderCheckID, opts.CheckID)
+		recorded[HeaderCheckID] = opts.CheckID
+	}
+	if opts.CorrelationID != "" {
+		req.Header.Set(HeaderCorrelationID, opts.CorrelationID)
+		recorded[HeaderCorrelationID] = opts.CorrelationID
+	}
+	for key, value := range target.Headers {
+		for name := range recorded {
+			if strings.EqualFold(name, key) {
+				delete(recorded, name)
+			}
+		}
+		if value == "" {
+			req.Header.Del(key)
+			continue
+		}
+		req.Header.Set(key, value)
+		recorded[key] = value
+	}
+}
+
+// stageTimeout cancels a request stage after limit, if set. The returned func stops the timer.
+func stageTimeout(cancel context.CancelCauseFunc, limit time.Duration, format string) func() {
+	if limit <= 0 {
+		return func() {}
+	}
+	timer := time.AfterFunc(limit, func() {
+		cancel(fmt.Errorf(format, limit))
+	})
+	return func() { timer.Stop() }
+}
+
+// stageError replaces the cancellation error of a request stopped by a stage limit with the limit's cause
+func stageError(stageCtx context.Context, err error) error {
+	cause := context.Cause(stageCtx)
+	if cause == nil || errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded) {
+		return err
+	}
+	return cause
+}
+
+// setAuthentication sets authentication headers on the request
+func setAuthentication(ctx context.Context, client *http.Client, req *http.Request, auth model.Auth) error {
+	switch strings.ToLower(auth.Type) {
+	case "basic":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	case "oauth2":
+		token, err := oauth2Token(ctx, client, auth)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case "none", "":
+		// No authentication
+		if !auth.ProfileID.IsZero() {
+			return fmt.Errorf("auth profile %s was not resolved", auth.ProfileID.Hex())
+		}
+	default:
+		return fmt.Errorf("unsupported auth type: %s", auth.Type)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// rabbitMQQueue is the part of a management API queue that rabbitmq check rules are evaluated against
+type rabbitMQQueue struct {
+	Queue                  string  `json:"queue"`
+	VHost                  string  `json:"vhost"`
+	State                  string  `json:"state"`
+	Messages               int64   `json:"messages"`
+	MessagesReady          int64   `json:"messages_ready"`
+	MessagesUnacknowledged int64   `json:"messages_unacknowledged"`
+	Consumers              int64   `json:"consumers"`
+	PublishRate            float64 `json:"publish_rate"` // Messages per second
+	DeliverRate            float64 `json:"deliver_rate"` // Messages per second, delivered or fetched
+}
+
+// CallRabbitMQ reads a queue's depth and rates from the RabbitMQ management API
+func CallRabbitMQ(ctx context.Context, client *http.Client, target model.Target, check *model.RabbitMQCheck, opts Options) (model.ExecutionRequest, model.ExecutionResponse, error) {
+	if check == nil {
+		check = &model.RabbitMQCheck{}
+	}
+	vhost := check.VHost
+	if vhost == "" {
+		vhost = "/"
+	}
+
+	queueTarget := target
+	queueTarget.URL = strings.TrimRight(target.URL, "/") + "/api/queues/" + url.PathEscape(vhost) + "/" + url.PathEscape(check.Queue)
+	queueTarget.Method = http.MethodGet
+
+	request, response, err := Call(ctx, client, queueTarget, opts)
+	if err != nil {
+		return request, response, err
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		err = fmt.Errorf("queue %s not found in vhost %s", check.Queue, vhost)
+		response.Error = err.Error()
+		return request, response, err
+	default:
+		err = fmt.Errorf("management API returned status %d", response.StatusCode)
+		response.Error = err.Error()
+		return request, response, err
+	}
+
+	var queue struct {
+		State                  string `json:"state"`
+		Messages               int64  `json:"messages"`
+		MessagesReady          int64  `json:"messages_ready"`
+		MessagesUnacknowledged int64  `json:"messages_unacknowledged"`
+		Consumers              int64  `json:"consumers"`
+		MessageStats           struct {
+			PublishDetails struct {
+				Rate float64 `json:"rate"`
+			} `json:"publish_details"`
+			DeliverGetDetails struct {
+				Rate float64 `json:"rate"`
+			} `json:"deliver_get_details"`
+		} `json:"message_stats"`
+	}
+	if err := json.Unmarshal([]byte(response.Body), &queue); err != nil {
+		err = fmt.Errorf("invalid management API response: %w", err)
+		response.Error = err.Error()
+		return request, response, err
+	}
+
+	body, err := json.Marshal(rabbitMQQueue{
+		Queue:                  check.Queue,
+		VHost:                  vhost,
+		State:                  queue.State,
+		Messages:               queue.Messages,
+		MessagesReady:          queue.MessagesReady,
+		MessagesUnacknowledged: queue.MessagesUnacknowledged,
+		Consumers:              queue.Consumers,
+		PublishRate:            queue.MessageStats.PublishDetails.Rate,
+		DeliverRate:            queue.MessageStats.DeliverGetDetails.Rate,
+	})
+	if err != nil {
+		response.Error = fmt.Sprintf("Failed to encode queue: %v", err)
+		return request, response, err
+	}
+	response.Body = string(body)
+
+	return request, response, nil
+}
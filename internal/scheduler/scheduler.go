@@ -2,31 +2,99 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
-	"os"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dandantas/raven/internal/clock"
 	"github.com/dandantas/raven/internal/config"
 	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/metrics"
 	"github.com/dandantas/raven/internal/model"
 	"github.com/dandantas/raven/internal/service"
+	"github.com/dandantas/raven/internal/webhook"
 	"github.com/google/uuid"
-	"github.com/robfig/cron/v3"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// schedulerPrincipal is recorded as the trigger of scheduled executions
+const schedulerPrincipal = "scheduler"
+
+// changeStreamRetryDelay is the delay before reopening a failed change stream
+const changeStreamRetryDelay = 5 * time.Second
+
+// handoverGrace is the part of the shutdown timeout kept for handing over interrupted checks
+const handoverGrace = 5 * time.Second
+
+// backlogAlertCooldown is the minimum time between backlog alerts across all pods
+const backlogAlertCooldown = 30 * time.Minute
+
+// backlogAlertLockID is the schedule lock that deduplicates backlog alerts across pods
+var backlogAlertLockID = primitive.ObjectID([]byte("backlogalert"))
+
+// ErrNotRunning is returned when a tick is requested while the scheduler isn't running
+var ErrNotRunning = errors.New("scheduler is not running")
+
+// TickReport summarizes one scheduling pass
+type TickReport struct {
+	At            time.Time `json:"at"`
+	DueChecks     int       `json:"due_checks"`
+	StartedChecks int       `json:"started_checks"` // Due checks whose lock this pod acquired
+	StartedSuites int       `json:"started_suites"`
+	Backlog       int       `json:"backlog"`     // Due checks more than a tick interval late
+	LagSeconds    float64   `json:"lag_seconds"` // How late the most overdue due check is
+	DurationMs    int64     `json:"duration_ms"`
+
+	runs sync.WaitGroup // Executions started by the tick
+}
+
+// Backpressure tells whether the scheduler keeps up with due checks, as of its latest tick
+type Backpressure struct {
+	LastTickAt     time.Time  `json:"last_tick_at"`
+	TickDurationMs int64      `json:"tick_duration_ms"`
+	DueChecks      int        `json:"due_checks"`
+	Backlog        int        `json:"backlog"`
+	LagSeconds     float64    `json:"lag_seconds"`
+	WaitingRuns    int64      `json:"waiting_runs"` // Runs on this pod waiting for a concurrency slot
+	Threshold      int        `json:"threshold,omitempty"`
+	Behind         bool       `json:"behind"` // Backlog is above the threshold
+	BehindSince    *time.Time `json:"behind_since,omitempty"`
+}
+
+// tickRequest asks the run loop for a tick and receives its report
+type tickRequest struct {
+	done chan *TickReport
+}
+
 // Scheduler handles scheduled health check executions with distributed locking
 type Scheduler struct {
 	cfg             *config.Config
 	executor        *service.Executor
 	lockRepo        *database.LockRepository
 	healthCheckRepo *database.HealthCheckRepository
+	suiteRepo       *database.SuiteRepository
+	suiteService    *service.SuiteService
+	heartbeats      *service.HeartbeatService
 	podID           string
-	ticker          *time.Ticker
+	clock           clock.Clock
+	metrics         *metrics.SchedulerMetrics
+	ticker          clock.Ticker
+	running         chan struct{} // Closed once the run loop has started
 	stopChan        chan struct{}
+	wakeChan        chan struct{}    // Triggers an immediate tick
+	tickChan        chan tickRequest // Triggers a tick and reports it
+	last            *TickReport      // Latest tick; only touched by the run loop
 	wg              sync.WaitGroup
 	semaphore       chan struct{} // Limits concurrent executions
+	inflightMu      sync.Mutex
+	inflight        map[primitive.ObjectID]context.CancelCauseFunc // Running health checks
+	waiting         atomic.Int64                                   // Runs waiting for a semaphore slot
+	pressureMu      sync.Mutex
+	pressure        Backpressure
 }
 
 // NewScheduler creates a new scheduler instance
@@ -35,25 +103,40 @@ func NewScheduler(
 	executor *service.Executor,
 	lockRepo *database.LockRepository,
 	healthCheckRepo *database.HealthCheckRepository,
+	suiteRepo *database.SuiteRepository,
+	suiteService *service.SuiteService,
+	heartbeats *service.HeartbeatService,
 ) *Scheduler {
-	// Get pod identifier (hostname in Kubernetes)
-	podID, err := os.Hostname()
-	if err != nil {
-		podID = uuid.New().String() // Fallback to UUID
-		slog.Warn("Failed to get hostname, using UUID as pod ID", "pod_id", podID)
-	}
-
 	return &Scheduler{
 		cfg:             cfg,
 		executor:        executor,
 		lockRepo:        lockRepo,
 		healthCheckRepo: healthCheckRepo,
-		podID:           podID,
+		suiteRepo:       suiteRepo,
+		suiteService:    suiteService,
+		heartbeats:      heartbeats,
+		podID:           cfg.PodID,
+		clock:           clock.Real,
+		running:         make(chan struct{}),
 		stopChan:        make(chan struct{}),
+		wakeChan:        make(chan struct{}, 1),
+		tickChan:        make(chan tickRequest),
 		semaphore:       make(chan struct{}, cfg.SchedulerConcurrency),
+		inflight:        make(map[primitive.ObjectID]context.CancelCauseFunc),
 	}
 }
 
+// SetClock replaces the wall clock, e.g. with a simulated clock that tests advance; it must
+// be called before Start
+func (s *Scheduler) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetMetrics sets the metrics of scheduled runs and ticks
+func (s *Scheduler) SetMetrics(m *metrics.SchedulerMetrics) {
+	s.metrics = m
+}
+
 // Start begins the scheduler tick loop
 func (s *Scheduler) Start(ctx context.Context) {
 	if !s.cfg.SchedulerEnabled {
@@ -66,13 +149,19 @@ func (s *Scheduler) Start(ctx context.Context) {
 		"tick_interval", s.cfg.SchedulerTickInterval,
 		"lock_ttl", s.cfg.SchedulerLockTTL,
 		"concurrency", s.cfg.SchedulerConcurrency,
+		"startup_delay", s.cfg.SchedulerStartupDelay,
+		"startup_jitter", s.cfg.SchedulerStartupJitter,
 	)
 
-	// s.ticker = time.NewTicker(s.cfg.SchedulerTickInterval)
-	s.ticker = time.NewTicker(1 * time.Minute)
 	s.wg.Add(1)
+	close(s.running)
 
 	go s.run(ctx)
+
+	if s.cfg.SchedulerWatchChanges {
+		s.wg.Add(1)
+		go s.watchConfigChanges(ctx)
+	}
 }
 
 // Stop gracefully stops the scheduler
@@ -86,23 +175,32 @@ func (s *Scheduler) Stop(ctx context.Context) {
 	// Signal stop
 	close(s.stopChan)
 
-	// Stop ticker
-	if s.ticker != nil {
-		s.ticker.Stop()
-	}
-
-	// Wait for in-flight executions with timeout
+	// Wait for in-flight executions, keeping time to hand over those that don't finish
 	done := make(chan struct{})
 	go func() {
 		s.wg.Wait()
 		close(done)
 	}()
 
+	waitCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithDeadline(ctx, deadline.Add(-handoverGrace))
+		defer cancel()
+	}
+
 	select {
 	case <-done:
 		slog.Info("All scheduled executions completed")
-	case <-ctx.Done():
-		slog.Warn("Timeout waiting for scheduled executions to complete")
+	case <-waitCtx.Done():
+		s.interruptInflight()
+
+		select {
+		case <-done:
+			slog.Info("Interrupted scheduled executions handed over")
+		case <-ctx.Done():
+			slog.Warn("Timeout waiting for scheduled executions to complete")
+		}
 	}
 
 	// Release all locks owned by this pod
@@ -117,13 +215,29 @@ func (s *Scheduler) Stop(ctx context.Context) {
 func (s *Scheduler) run(ctx context.Context) {
 	defer s.wg.Done()
 
-	// Run immediately on start
-	s.tick(ctx)
+	if !s.warmUp(ctx) {
+		return
+	}
+
+	// Ticking starts after the warm-up, so pods restarted together stay out of phase
+	s.ticker = s.clock.NewTicker(s.cfg.SchedulerTickInterval)
+	defer s.ticker.Stop()
+
+	// Run immediately once warmed up; a wake-up during the warm-up is covered by it
+	select {
+	case <-s.wakeChan:
+	default:
+	}
+	s.last = s.tick(ctx)
 
 	for {
 		select {
-		case <-s.ticker.C:
-			s.tick(ctx)
+		case <-s.ticker.C():
+			s.last = s.tick(ctx)
+		case <-s.wakeChan:
+			s.last = s.tick(ctx)
+		case req := <-s.tickChan:
+			req.done <- s.requestedTick(ctx)
 		case <-s.stopChan:
 			slog.Info("Scheduler stopped", "pod_id", s.podID)
 			return
@@ -134,9 +248,179 @@ func (s *Scheduler) run(ctx context.Context) {
 	}
 }
 
+// Tick runs a scheduling pass between the regular ticks and returns its report once due
+// health checks and suites have been started; Wait on the report for them to finish
+func (s *Scheduler) Tick(ctx context.Context, requestedBy string) (*TickReport, error) {
+	select {
+	case <-s.running:
+	default:
+		return nil, ErrNotRunning
+	}
+
+	slog.Info("Scheduler tick requested", "pod_id", s.podID, "requested_by", requestedBy)
+
+	req := tickRequest{done: make(chan *TickReport, 1)}
+	select {
+	case s.tickChan <- req:
+	case <-s.stopChan:
+		return nil, ErrNotRunning
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case report := <-req.done:
+		return report, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Wait blocks until the health checks and suites started by the tick have finished
+func (r *TickReport) Wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		r.runs.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// warmUp waits for the startup delay plus a random jitter before the first tick, so pods
+// restarted by a rolling deploy don't execute everything due at once. Requested ticks are
+// still served meanwhile. It returns false if the scheduler stops first.
+func (s *Scheduler) warmUp(ctx context.Context) bool {
+	delay := s.cfg.SchedulerStartupDelay
+	if s.cfg.SchedulerStartupJitter > 0 {
+		delay += rand.N(s.cfg.SchedulerStartupJitter)
+	}
+	if delay <= 0 {
+		return true
+	}
+
+	slog.Info("Delaying first scheduler tick", "pod_id", s.podID, "delay", delay)
+
+	ready := make(chan struct{})
+	timer := s.clock.AfterFunc(delay, func() { close(ready) })
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ready:
+			return true
+		case req := <-s.tickChan:
+			req.done <- s.requestedTick(ctx)
+		case <-s.stopChan:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// requestedTick runs a tick for Tick. A pending tick from the ticker or a wake-up is folded
+// into it, and on a simulated clock a tick that already ran at the current instant is reused.
+func (s *Scheduler) requestedTick(ctx context.Context) *TickReport {
+	if s.ticker != nil {
+		select {
+		case <-s.ticker.C():
+		default:
+		}
+	}
+	select {
+	case <-s.wakeChan:
+	default:
+	}
+
+	if s.last != nil && s.last.At.Equal(s.clock.Now().UTC()) {
+		return s.last
+	}
+	s.last = s.tick(ctx)
+	return s.last
+}
+
+// wake requests an immediate tick without blocking
+func (s *Scheduler) wake() {
+	select {
+	case s.wakeChan <- struct{}{}:
+	default:
+		// A tick is already pending
+	}
+}
+
+// watchConfigChanges reacts to configuration changes so newly created or edited
+// scheduled checks are picked up without waiting for the next tick
+func (s *Scheduler) watchConfigChanges(ctx context.Context) {
+	defer s.wg.Done()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-s.stopChan:
+			cancel()
+		case <-watchCtx.Done():
+		}
+	}()
+
+	slog.Info("Watching health check configuration changes", "pod_id", s.podID)
+
+	for {
+		err := s.healthCheckRepo.Watch(watchCtx, s.handleConfigChange)
+		if watchCtx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, database.ErrChangeStreamsUnsupported) {
+			slog.Warn("Change streams unsupported, relying on scheduler ticks only", "pod_id", s.podID)
+			return
+		}
+
+		slog.Error("Configuration change stream stopped, retrying", "error", err)
+
+		select {
+		case <-time.After(changeStreamRetryDelay):
+		case <-watchCtx.Done():
+			return
+		}
+	}
+}
+
+// handleConfigChange wakes the scheduler when a changed configuration is due soon
+func (s *Scheduler) handleConfigChange(change database.ConfigChange) {
+	config := change.Config
+	if config == nil || !config.Enabled || !config.ScheduleEnabled || config.NextScheduledRun.IsZero() {
+		return
+	}
+
+	until := config.NextScheduledRun.Sub(s.clock.Now())
+	if until <= 0 {
+		slog.Debug("Scheduled check changed and is due, waking scheduler",
+			"config_id", change.ConfigID.Hex(),
+			"operation", change.OperationType,
+		)
+		s.wake()
+		return
+	}
+
+	// Wake up exactly when the check becomes due if that's before the next tick
+	if until < s.cfg.SchedulerTickInterval {
+		s.clock.AfterFunc(until, s.wake)
+	}
+}
+
 // tick processes one scheduler tick
-func (s *Scheduler) tick(ctx context.Context) {
-	now := time.Now().UTC()
+func (s *Scheduler) tick(ctx context.Context) *TickReport {
+	now := s.clock.Now().UTC()
+	report := &TickReport{At: now}
+	start := time.Now()
 
 	slog.Info("Scheduler tick", "pod_id", s.podID, "time", now.Format(time.RFC3339))
 
@@ -147,16 +431,25 @@ func (s *Scheduler) tick(ctx context.Context) {
 		slog.Info("Cleaned expired locks", "count", cleaned)
 	}
 
+	// Scheduled suites are processed independently of individual checks
+	s.tickSuites(ctx, report)
+
+	// Missed heartbeats are claimed atomically, so no schedule lock is needed
+	s.heartbeats.CheckOverdue(ctx, now)
+
 	// Find health checks that are due
 	configs, err := s.healthCheckRepo.FindScheduledChecks(ctx, now)
 	if err != nil {
 		slog.Error("Failed to find scheduled checks", "error", err)
-		return
+		return report
 	}
 
+	report.DueChecks = len(configs)
+	report.Backlog, report.LagSeconds = s.backlog(configs, now)
 	if len(configs) == 0 {
 		slog.Info("No scheduled checks due", "pod_id", s.podID)
-		return
+		s.recordBackpressure(ctx, report, start)
+		return report
 	}
 
 	slog.Info("Found scheduled checks due for execution",
@@ -164,11 +457,15 @@ func (s *Scheduler) tick(ctx context.Context) {
 		"count", len(configs),
 	)
 
+	// Parent health is evaluated once per tick and shared by all dependent checks
+	deps := s.executor.NewDependencyState()
+
 	// Process each due health check
 	for _, config := range configs {
 		// Try to acquire lock
 		acquired, err := s.lockRepo.AcquireLock(ctx, config.ID, s.podID, s.cfg.SchedulerLockTTL)
 		if err != nil {
+			s.metrics.RunSkipped(config.ID.Hex(), config.Name, metrics.SkipLockError)
 			slog.Error("Failed to acquire lock",
 				"config_id", config.ID.Hex(),
 				"config_name", config.Name,
@@ -178,6 +475,7 @@ func (s *Scheduler) tick(ctx context.Context) {
 		}
 
 		if !acquired {
+			s.metrics.RunSkipped(config.ID.Hex(), config.Name, metrics.SkipLockHeld)
 			slog.Debug("Lock already held by another pod",
 				"config_id", config.ID.Hex(),
 				"config_name", config.Name,
@@ -193,13 +491,161 @@ func (s *Scheduler) tick(ctx context.Context) {
 		)
 
 		// Execute asynchronously with concurrency control
+		report.StartedChecks++
 		s.wg.Add(1)
-		go s.executeHealthCheck(ctx, config)
+		report.runs.Add(1)
+		go func() {
+			defer report.runs.Done()
+			s.executeHealthCheck(ctx, config, deps)
+		}()
 	}
+
+	s.recordBackpressure(ctx, report, start)
+	return report
 }
 
-// executeHealthCheck executes a single health check with lock management
-func (s *Scheduler) executeHealthCheck(ctx context.Context, config model.HealthCheckConfig) {
+// backlog counts the due checks more than a tick interval late, which some pod should have
+// started by now, and returns how late the most overdue one is in seconds
+func (s *Scheduler) backlog(configs []model.HealthCheckConfig, now time.Time) (int, float64) {
+	var backlog int
+	var lag time.Duration
+	for _, config := range configs {
+		late := now.Sub(config.NextScheduledRun)
+		if late > s.cfg.SchedulerTickInterval {
+			backlog++
+		}
+		lag = max(lag, late)
+	}
+	return backlog, lag.Seconds()
+}
+
+// recordBackpressure publishes a tick's backlog and duration, and warns when the backlog
+// crosses the threshold
+func (s *Scheduler) recordBackpressure(ctx context.Context, report *TickReport, start time.Time) {
+	duration := time.Since(start)
+	report.DurationMs = duration.Milliseconds()
+	waiting := s.waiting.Load()
+	s.metrics.SetTick(duration, report.Backlog, time.Duration(report.LagSeconds*float64(time.Second)), waiting)
+
+	threshold := s.cfg.SchedulerBacklogThreshold
+	behind := threshold > 0 && report.Backlog > threshold
+
+	s.pressureMu.Lock()
+	wasBehind := s.pressure.Behind
+	since := s.pressure.BehindSince
+	if !behind {
+		since = nil
+	} else if !wasBehind {
+		since = &report.At
+	}
+	s.pressure = Backpressure{
+		LastTickAt:     report.At,
+		TickDurationMs: report.DurationMs,
+		DueChecks:      report.DueChecks,
+		Backlog:        report.Backlog,
+		LagSeconds:     report.LagSeconds,
+		Threshold:      threshold,
+		Behind:         behind,
+		BehindSince:    since,
+	}
+	s.pressureMu.Unlock()
+
+	switch {
+	case behind && !wasBehind:
+		slog.Warn("Scheduler is falling behind",
+			"pod_id", s.podID,
+			"backlog", report.Backlog,
+			"threshold", threshold,
+			"lag_seconds", report.LagSeconds,
+			"waiting_runs", waiting,
+			"tick_duration_ms", report.DurationMs,
+		)
+		if s.cfg.SchedulerBacklogAlert {
+			s.wg.Add(1)
+			go s.alertBacklog(ctx, report)
+		}
+	case !behind && wasBehind:
+		slog.Info("Scheduler caught up", "pod_id", s.podID, "backlog", report.Backlog)
+	}
+}
+
+// alertBacklog notifies the admin channel that the scheduler is behind, unless another pod
+// already did within the cooldown
+func (s *Scheduler) alertBacklog(ctx context.Context, report *TickReport) {
+	defer s.wg.Done()
+
+	// The lock is left to expire, so it doubles as the cooldown
+	acquired, err := s.lockRepo.AcquireLock(ctx, backlogAlertLockID, s.podID, backlogAlertCooldown)
+	if err != nil {
+		slog.Error("Failed to acquire backlog alert lock", "error", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	correlationID := uuid.New().String()
+	lag := time.Duration(report.LagSeconds * float64(time.Second))
+	payload := webhook.FormatSchedulerBacklogPayload(s.podID, report.Backlog, s.cfg.SchedulerBacklogThreshold, lag, correlationID)
+	s.executor.NotifyAdmin(ctx, payload, correlationID)
+}
+
+// Backpressure returns whether the scheduler keeps up, as of its latest tick
+func (s *Scheduler) Backpressure() Backpressure {
+	s.pressureMu.Lock()
+	defer s.pressureMu.Unlock()
+
+	pressure := s.pressure
+	pressure.WaitingRuns = s.waiting.Load()
+	return pressure
+}
+
+// tickSuites acquires locks for due suites and runs them
+func (s *Scheduler) tickSuites(ctx context.Context, report *TickReport) {
+	suites, err := s.suiteRepo.FindScheduledSuites(ctx, report.At)
+	if err != nil {
+		slog.Error("Failed to find scheduled suites", "error", err)
+		return
+	}
+
+	for _, suite := range suites {
+		// Suite IDs share the lock collection with health check IDs
+		acquired, err := s.lockRepo.AcquireLock(ctx, suite.ID, s.podID, s.cfg.SchedulerLockTTL)
+		if err != nil {
+			slog.Error("Failed to acquire suite lock",
+				"suite_id", suite.ID.Hex(),
+				"suite_name", suite.Name,
+				"error", err,
+			)
+			continue
+		}
+
+		if !acquired {
+			slog.Debug("Suite lock already held by another pod",
+				"suite_id", suite.ID.Hex(),
+				"suite_name", suite.Name,
+			)
+			continue
+		}
+
+		slog.Info("Acquired lock for scheduled suite",
+			"suite_id", suite.ID.Hex(),
+			"suite_name", suite.Name,
+			"pod_id", s.podID,
+		)
+
+		report.StartedSuites++
+		s.wg.Add(1)
+		report.runs.Add(1)
+		go func() {
+			defer report.runs.Done()
+			s.executeSuite(ctx, suite)
+		}()
+	}
+}
+
+// executeSuite runs a scheduled suite with lock management
+func (s *Scheduler) executeSuite(ctx context.Context, suite model.Suite) {
 	defer s.wg.Done()
 
 	// Acquire semaphore slot (limit concurrent executions)
@@ -207,14 +653,87 @@ func (s *Scheduler) executeHealthCheck(ctx context.Context, config model.HealthC
 	case s.semaphore <- struct{}{}:
 		defer func() { <-s.semaphore }()
 	case <-s.stopChan:
-		// Scheduler is stopping, release lock and return
-		s.releaseLock(ctx, config.ID)
+		s.releaseLock(ctx, suite.ID)
 		return
 	case <-ctx.Done():
-		s.releaseLock(ctx, config.ID)
+		s.releaseLock(ctx, suite.ID)
 		return
 	}
 
+	correlationID := uuid.New().String()
+
+	slog.Info("Executing scheduled suite",
+		"suite_id", suite.ID.Hex(),
+		"suite_name", suite.Name,
+		"correlation_id", correlationID,
+		"pod_id", s.podID,
+	)
+
+	result := s.suiteService.RunSuite(ctx, &suite, service.ExecuteOptions{
+		BatchID:     correlationID,
+		TriggerType: model.TriggerScheduled,
+		TriggeredBy: schedulerPrincipal,
+	})
+
+	slog.Info("Scheduled suite execution completed",
+		"suite_id", suite.ID.Hex(),
+		"suite_name", suite.Name,
+		"correlation_id", correlationID,
+		"status", result.Status,
+		"duration_ms", result.DurationMs,
+	)
+
+	// Update next scheduled run time
+	now := s.clock.Now().UTC()
+	schedule, err := model.ParseSchedule(suite.Schedule)
+	if err == nil {
+		err = s.suiteRepo.UpdateScheduledRun(ctx, suite.ID, now, schedule.Next(now))
+	}
+	if err != nil {
+		slog.Error("Failed to update next scheduled suite run",
+			"suite_id", suite.ID.Hex(),
+			"error", err,
+		)
+	}
+
+	s.releaseLock(ctx, suite.ID)
+}
+
+// executeHealthCheck executes a single health check with lock management
+func (s *Scheduler) executeHealthCheck(ctx context.Context, config model.HealthCheckConfig, deps *service.DependencyState) {
+	defer s.wg.Done()
+
+	// Acquire semaphore slot (limit concurrent executions), noting runs that have to wait
+	select {
+	case s.semaphore <- struct{}{}:
+	default:
+		s.metrics.RunQueued(config.ID.Hex(), config.Name)
+		slog.Debug("All scheduler slots busy, waiting",
+			"config_id", config.ID.Hex(),
+			"config_name", config.Name,
+			"concurrency", s.cfg.SchedulerConcurrency,
+		)
+
+		s.waiting.Add(1)
+		select {
+		case s.semaphore <- struct{}{}:
+			s.waiting.Add(-1)
+		case <-s.stopChan:
+			// Scheduler is stopping, release lock and return
+			s.waiting.Add(-1)
+			s.metrics.RunSkipped(config.ID.Hex(), config.Name, metrics.SkipShutdown)
+			s.releaseLock(ctx, config.ID)
+			return
+		case <-ctx.Done():
+			s.waiting.Add(-1)
+			s.metrics.RunSkipped(config.ID.Hex(), config.Name, metrics.SkipShutdown)
+			s.releaseLock(ctx, config.ID)
+			return
+		}
+	}
+	defer func() { <-s.semaphore }()
+	s.metrics.RunStarted(config.ID.Hex(), config.Name)
+
 	// Generate correlation ID for this execution
 	correlationID := uuid.New().String()
 
@@ -227,47 +746,140 @@ func (s *Scheduler) executeHealthCheck(ctx context.Context, config model.HealthC
 
 	start := time.Now()
 
+	// Shutdown cancels runs that outlast the grace period with ErrInterrupted
+	runCtx, cancel := context.WithCancelCause(ctx)
+	s.inflightMu.Lock()
+	s.inflight[config.ID] = cancel
+	s.inflightMu.Unlock()
+	defer func() {
+		s.inflightMu.Lock()
+		delete(s.inflight, config.ID)
+		s.inflightMu.Unlock()
+		cancel(nil)
+	}()
+
 	// Execute the health check
-	_, err := s.executor.Execute(ctx, config.ID.Hex(), correlationID)
+	opts := service.ExecuteOptions{
+		Dependencies:     deps,
+		TriggerType:      model.TriggerScheduled,
+		TriggeredBy:      schedulerPrincipal,
+		ScheduledVersion: config.Metadata.UpdatedAt,
+	}
+	execution, err := s.executor.Execute(runCtx, config.ID.Hex(), correlationID, opts)
 
 	duration := time.Since(start)
 
+	// A run that finished before noticing the interruption is kept as it is
+	if errors.Is(context.Cause(runCtx), service.ErrInterrupted) && (execution == nil || execution.Status == "interrupted") {
+		if execution == nil {
+			reason := fmt.Sprintf("Interrupted by shutdown of pod %s", s.podID)
+			s.executor.RecordNotRun(ctx, &config, correlationID, opts, "interrupted", reason, start)
+		}
+		s.handOver(ctx, config)
+		return
+	}
+
+	// Execute only fails before the target is called; target failures are recorded results
 	if err != nil {
-		slog.Error("Scheduled health check execution failed",
+		s.recordNotRun(ctx, config, correlationID, opts, err, start)
+		s.releaseLock(ctx, config.ID)
+		return
+	}
+
+	slog.Info("Scheduled health check execution completed",
+		"config_id", config.ID.Hex(),
+		"config_name", config.Name,
+		"correlation_id", correlationID,
+		"duration_ms", duration.Milliseconds(),
+	)
+
+	// Update next scheduled run time
+	if err := s.updateNextScheduledRun(ctx, config); err != nil {
+		slog.Error("Failed to update next scheduled run",
+			"config_id", config.ID.Hex(),
+			"error", err,
+		)
+	}
+
+	// Release the lock
+	s.releaseLock(ctx, config.ID)
+}
+
+// recordNotRun records a scheduled run that failed before calling the target, leaving the
+// next run alone. Runs whose config changed since the tick are "skipped" and keep the new
+// schedule. Other failures, like a failed config fetch, are an "error" and stay due, so the
+// next tick retries them. A config deleted in the meantime is only logged.
+func (s *Scheduler) recordNotRun(ctx context.Context, config model.HealthCheckConfig, correlationID string, opts service.ExecuteOptions, err error, start time.Time) {
+	if errors.Is(err, database.ErrHealthCheckNotFound) {
+		slog.Info("Scheduled health check was deleted before it ran",
 			"config_id", config.ID.Hex(),
 			"config_name", config.Name,
 			"correlation_id", correlationID,
-			"duration_ms", duration.Milliseconds(),
-			"error", err,
 		)
+		return
+	}
+
+	status := "error"
+	if errors.Is(err, service.ErrDisabled) || errors.Is(err, service.ErrScheduleDisabled) || errors.Is(err, service.ErrNoLongerDue) {
+		status = "skipped"
 	} else {
-		slog.Info("Scheduled health check execution completed",
+		slog.Error("Scheduled health check failed before calling the target",
 			"config_id", config.ID.Hex(),
 			"config_name", config.Name,
 			"correlation_id", correlationID,
-			"duration_ms", duration.Milliseconds(),
+			"error", err,
 		)
 	}
 
-	// Update next scheduled run time
-	if err := s.updateNextScheduledRun(ctx, config); err != nil {
-		slog.Error("Failed to update next scheduled run",
+	s.executor.RecordNotRun(ctx, &config, correlationID, opts, status, err.Error(), start)
+}
+
+// interruptInflight cancels running health checks so they are recorded as interrupted and
+// handed over to another pod
+func (s *Scheduler) interruptInflight() {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+
+	if len(s.inflight) == 0 {
+		return
+	}
+
+	slog.Warn("Interrupting scheduled executions still running at shutdown",
+		"pod_id", s.podID,
+		"count", len(s.inflight),
+	)
+	for _, cancel := range s.inflight {
+		cancel(service.ErrInterrupted)
+	}
+}
+
+// handOver releases an interrupted health check and makes it due now, so another pod
+// re-runs it instead of waiting for the next cron slot
+func (s *Scheduler) handOver(ctx context.Context, config model.HealthCheckConfig) {
+	// Release first so pods woken by the change can take the lock
+	s.releaseLock(ctx, config.ID)
+
+	if err := s.healthCheckRepo.MarkDue(ctx, config.ID, s.clock.Now().UTC()); err != nil {
+		slog.Error("Failed to hand over interrupted health check",
 			"config_id", config.ID.Hex(),
 			"error", err,
 		)
+		return
 	}
 
-	// Release the lock
-	s.releaseLock(ctx, config.ID)
+	slog.Info("Handed over interrupted health check",
+		"config_id", config.ID.Hex(),
+		"config_name", config.Name,
+		"pod_id", s.podID,
+	)
 }
 
 // updateNextScheduledRun calculates and updates the next scheduled run time
 func (s *Scheduler) updateNextScheduledRun(ctx context.Context, config model.HealthCheckConfig) error {
-	now := time.Now().UTC()
+	now := s.clock.Now().UTC()
 
 	// Parse the cron expression
-	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-	schedule, err := parser.Parse(config.Schedule)
+	schedule, err := model.ParseSchedule(config.Schedule)
 	if err != nil {
 		return err
 	}
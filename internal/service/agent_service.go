@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultAgentLeaseMax limits the checks handed to an agent per lease request
+const defaultAgentLeaseMax = 50
+
+// AgentService hands due region checks to remote agents and records their results
+type AgentService struct {
+	healthCheckRepo *database.HealthCheckRepository
+	scheduleRepo    *database.AgentScheduleRepository
+	executor        *Executor
+	leaseTTL        time.Duration
+}
+
+// NewAgentService creates a new agent service
+func NewAgentService(
+	healthCheckRepo *database.HealthCheckRepository,
+	scheduleRepo *database.AgentScheduleRepository,
+	executor *Executor,
+	leaseTTL time.Duration,
+) *AgentService {
+	return &AgentService{
+		healthCheckRepo: healthCheckRepo,
+		scheduleRepo:    scheduleRepo,
+		executor:        executor,
+		leaseTTL:        leaseTTL,
+	}
+}
+
+// Lease returns the checks due in the agent's region and leases them to the agent
+func (s *AgentService) Lease(ctx context.Context, req model.AgentLeaseRequest) (*model.AgentLeaseResponse, error) {
+	if req.AgentID == "" || req.Region == "" {
+		return nil, errors.New("agent_id and region are required")
+	}
+
+	max := req.Max
+	if max <= 0 || max > defaultAgentLeaseMax {
+		max = defaultAgentLeaseMax
+	}
+
+	configs, err := s.healthCheckRepo.FindByRegion(ctx, req.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]model.HealthCheckConfig, len(configs))
+	ids := make([]primitive.ObjectID, 0, len(configs))
+	for _, config := range configs {
+		byID[config.ID] = config
+		ids = append(ids, config.ID)
+	}
+
+	checks := make([]model.HealthCheckConfig, 0)
+	if len(ids) == 0 {
+		return &model.AgentLeaseResponse{LeaseSeconds: int(s.leaseTTL.Seconds()), Checks: checks}, nil
+	}
+	if err := s.scheduleRepo.EnsureSchedules(ctx, ids, req.Region); err != nil {
+		return nil, err
+	}
+
+	// Checks leased before an error are still handed out; their leases are held either way
+	leased, err := s.scheduleRepo.LeaseDue(ctx, ids, req.Region, req.AgentID, time.Now().UTC(), s.leaseTTL, max)
+	if err != nil {
+		if len(leased) == 0 {
+			return nil, err
+		}
+		slog.Error("Failed to lease checks to agent",
+			"agent_id", req.AgentID,
+			"region", req.Region,
+			"leased", len(leased),
+			"error", err,
+		)
+	}
+
+	for _, id := range leased {
+		config := byID[id]
+
+		// Agents only need the target with its resolved credentials; webhooks stay on the core
+		target, err := s.executor.ResolveTarget(ctx, &config)
+		if err != nil {
+			slog.Error("Failed to resolve auth profile for agent",
+				"config_id", config.ID.Hex(),
+				"agent_id", req.AgentID,
+				"error", err,
+			)
+		}
+		config.Target = target
+		config.Webhook = model.Webhook{}
+		config.StateWebhook = nil
+		checks = append(checks, config)
+	}
+
+	if len(checks) > 0 {
+		slog.Info("Leased checks to agent",
+			"agent_id", req.AgentID,
+			"region", req.Region,
+			"count", len(checks),
+		)
+	}
+
+	return &model.AgentLeaseResponse{
+		LeaseSeconds: int(s.leaseTTL.Seconds()),
+		Checks:       checks,
+	}, nil
+}
+
+// Report records the results of checks leased to the agent and schedules their next run in
+// the region. Results without a live lease held by the agent are rejected.
+func (s *AgentService) Report(ctx context.Context, report model.AgentReport) (*model.AgentReportResponse, error) {
+	if report.AgentID == "" || report.Region == "" {
+		return nil, errors.New("agent_id and region are required")
+	}
+
+	response := &model.AgentReportResponse{
+		Executions: make([]model.ExecutionSummary, 0, len(report.Results)),
+	}
+
+	opts := ExecuteOptions{
+		TriggerType: model.TriggerScheduled,
+		TriggeredBy: "agent:" + report.AgentID,
+		Region:      report.Region,
+		AgentID:     report.AgentID,
+	}
+
+	for _, result := range report.Results {
+		execution, err := s.record(ctx, result, opts)
+		if err != nil {
+			response.Rejected++
+			response.Errors = append(response.Errors, fmt.Sprintf("%s: %v", result.ConfigID, err))
+			continue
+		}
+
+		response.Accepted++
+		response.Executions = append(response.Executions, execution.ToSummary())
+	}
+
+	return response, nil
+}
+
+// record stores a single agent result and advances the region schedule
+func (s *AgentService) record(ctx context.Context, result model.AgentResult, opts ExecuteOptions) (*model.ExecutionHistory, error) {
+	objID, err := primitive.ObjectIDFromHex(result.ConfigID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config ID: %w", err)
+	}
+
+	config, err := s.healthCheckRepo.GetByID(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := model.ParseSchedule(config.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	// Releasing the lease also proves the agent held it, so only then is the result recorded
+	now := time.Now().UTC()
+	if err := s.scheduleRepo.Complete(ctx, config.ID, opts.Region, opts.AgentID, now, schedule.Next(now)); err != nil {
+		return nil, err
+	}
+
+	execution := s.executor.RecordResult(
+		ctx,
+		config,
+		result.CorrelationID,
+		opts,
+		result.Request,
+		result.Response,
+		time.Duration(result.DurationMs)*time.Millisecond,
+	)
+
+	return execution, nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/metrics"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/webhook"
+)
+
+// ErrAlertQueueFull is returned when an alert intent cannot be enqueued
+var ErrAlertQueueFull = errors.New("alert queue is full")
+
+// AlertIntent represents an alert waiting to be delivered
+type AlertIntent struct {
+	AlertLog *model.AlertLog
+	Webhook  model.Webhook
+	Payload  webhook.AlertPayloadData
+}
+
+// AlertQueue delivers alerts asynchronously using a pool of dispatcher goroutines,
+// keeping webhook delivery out of the execution critical path
+type AlertQueue struct {
+	dispatcher    *webhook.Dispatcher
+	alertRepo     *database.AlertRepository
+	executionRepo *database.ExecutionRepository
+	metrics       *metrics.DeliveryMetrics
+	workers       int
+	intents       chan AlertIntent
+	wg            sync.WaitGroup
+	mu            sync.RWMutex
+	closed        bool
+}
+
+// NewAlertQueue creates a new alert queue
+func NewAlertQueue(
+	dispatcher *webhook.Dispatcher,
+	alertRepo *database.AlertRepository,
+	executionRepo *database.ExecutionRepository,
+	workers int,
+	queueSize int,
+) *AlertQueue {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	return &AlertQueue{
+		dispatcher:    dispatcher,
+		alertRepo:     alertRepo,
+		executionRepo: executionRepo,
+		workers:       workers,
+		intents:       make(chan AlertIntent, queueSize),
+	}
+}
+
+// SetMetrics records the latency of successful deliveries in m
+func (q *AlertQueue) SetMetrics(m *metrics.DeliveryMetrics) {
+	q.metrics = m
+}
+
+// Start starts the dispatcher goroutines
+func (q *AlertQueue) Start() {
+	slog.Info("Starting alert queue", "workers", q.workers, "queue_size", cap(q.intents))
+
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.worker(i)
+	}
+}
+
+// Stop stops accepting new intents and waits for queued alerts to be delivered
+func (q *AlertQueue) Stop(ctx context.Context) {
+	slog.Info("Stopping alert queue", "pending", len(q.intents))
+
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.intents)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("Alert queue stopped")
+	case <-ctx.Done():
+		slog.Warn("Timeout waiting for alert queue to drain", "pending", len(q.intents))
+	}
+}
+
+// Enqueue submits an alert intent for delivery without blocking
+func (q *AlertQueue) Enqueue(intent AlertIntent) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return ErrAlertQueueFull
+	}
+
+	select {
+	case q.intents <- intent:
+		return nil
+	default:
+		return ErrAlertQueueFull
+	}
+}
+
+// Len returns the number of alerts waiting to be delivered
+func (q *AlertQueue) Len() int {
+	return len(q.intents)
+}
+
+// worker delivers queued alerts until the queue is closed
+func (q *AlertQueue) worker(id int) {
+	defer q.wg.Done()
+
+	slog.Debug("Alert queue worker started", "worker_id", id)
+
+	for intent := range q.intents {
+		q.deliver(intent)
+	}
+
+	slog.Debug("Alert queue worker stopped", "worker_id", id)
+}
+
+// deliver sends a single alert and records the outcome
+func (q *AlertQueue) deliver(intent AlertIntent) {
+	alertLog := intent.AlertLog
+	ctx := context.Background()
+
+	if err := q.dispatcher.Deliver(ctx, alertLog, intent.Webhook, intent.Payload); err != nil {
+		slog.Error("Failed to deliver alert",
+			"correlation_id", alertLog.CorrelationID,
+			"alert_id", alertLog.ID.Hex(),
+			"error", err.Error(),
+		)
+	}
+	if alertLog.FinalStatus == "delivered" {
+		q.metrics.Delivered(alertLog.Severity, time.Duration(alertLog.DeliveryLatencyMs)*time.Millisecond)
+	}
+
+	q.recordOutcome(ctx, alertLog)
+}
+
+// MarkFailed records an alert that could not be enqueued as failed
+func (q *AlertQueue) MarkFailed(ctx context.Context, alertLog *model.AlertLog, reason string) {
+	alertLog.FinalStatus = "failed"
+	alertLog.CompletedAt = time.Now().UTC()
+	alertLog.Attempts = append(alertLog.Attempts, model.AlertAttempt{
+		Timestamp: alertLog.CompletedAt,
+		Error:     reason,
+	})
+
+	q.recordOutcome(ctx, alertLog)
+}
+
+// recordOutcome persists the alert log and the delivery status on the execution history
+func (q *AlertQueue) recordOutcome(ctx context.Context, alertLog *model.AlertLog) {
+	if err := q.alertRepo.Update(ctx, alertLog.ID, alertLog); err != nil {
+		slog.Error("Failed to update alert log",
+			"correlation_id", alertLog.CorrelationID,
+			"alert_id", alertLog.ID.Hex(),
+			"error", err.Error(),
+		)
+	}
+
+	// Alerts not tied to an execution (e.g. suite digests) have nothing else to update
+	if alertLog.ExecutionID.IsZero() {
+		return
+	}
+
+	if err := q.executionRepo.UpdateAlertDeliveryStatus(ctx, alertLog.ExecutionID, alertLog.ID, alertLog.FinalStatus); err != nil {
+		slog.Error("Failed to update alert delivery status",
+			"correlation_id", alertLog.CorrelationID,
+			"alert_id", alertLog.ID.Hex(),
+			"error", err.Error(),
+		)
+	}
+}
@@ -3,17 +3,24 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/dandantas/raven/internal/database"
 	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/webhook"
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// AlertService handles alert log queries
+// AlertService handles alert log queries and ingestion
 type AlertService struct {
-	repo *database.AlertRepository
+	repo       *database.AlertRepository
+	alertQueue *AlertQueue
+	webhooks   *webhook.Resolver
+	ackLinks   *webhook.AckLinks
 }
 
 // NewAlertService creates a new alert service
@@ -23,23 +30,121 @@ func NewAlertService(repo *database.AlertRepository) *AlertService {
 	}
 }
 
-// List retrieves alert logs with filtering
-func (s *AlertService) List(ctx context.Context, configID, status, acknowledgmentStatus, from, to string, page, limit int) ([]model.AlertLogSummary, int64, error) {
-	// Build filter
+// SetDelivery wires the queue and webhook resolver used to notify on ingested alerts
+func (s *AlertService) SetDelivery(alertQueue *AlertQueue, webhooks *webhook.Resolver) {
+	s.alertQueue = alertQueue
+	s.webhooks = webhooks
+}
+
+// SetAckLinks sets the signer that verifies one-click acknowledge links
+func (s *AlertService) SetAckLinks(links *webhook.AckLinks) {
+	s.ackLinks = links
+}
+
+// AlertFilter holds alert list query parameters
+type AlertFilter struct {
+	ConfigID             string
+	Status               string
+	AcknowledgmentStatus string
+	Severity             string
+	RuleName             string
+	WebhookHost          string
+	Source               string
+	Tags                 []string
+	From                 string
+	To                   string
+	Sort                 string
+}
+
+// List retrieves a page of alert logs with filtering, with the total if counted and the
+// cursor of the next page
+func (s *AlertService) List(ctx context.Context, params AlertFilter, page database.Page) ([]model.AlertLogSummary, int64, string, error) {
+	filter, err := alertFilter(params)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	sort, err := database.ParseSort(params.Sort, database.AlertSortFields)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	// Fetch from database
+	alerts, total, next, err := s.repo.List(ctx, filter, sort, page)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	// Convert to summaries
+	summaries := make([]model.AlertLogSummary, len(alerts))
+	for i, alert := range alerts {
+		summaries[i] = alert.ToSummary()
+	}
+
+	return summaries, total, next, nil
+}
+
+// Export streams the alert logs matching the filter to fn, oldest first. Errors are also
+// logged, since once rows are sent the response can no longer report them.
+func (s *AlertService) Export(ctx context.Context, params AlertFilter, fn func(*model.AlertExportRow) error) error {
+	filter, err := alertFilter(params)
+	if err != nil {
+		return err
+	}
+
+	var rows int
+	err = s.repo.Export(ctx, filter, func(row *model.AlertExportRow) error {
+		rows++
+		return fn(row)
+	})
+	if err != nil {
+		slog.Error("Failed to export alert logs",
+			"rows", rows,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.Info("Exported alert logs", "rows", rows, "from", params.From, "to", params.To)
+	return nil
+}
+
+// alertFilter builds the query matching alert list and export parameters
+func alertFilter(params AlertFilter) (bson.M, error) {
 	filter := bson.M{}
 
-	if configID != "" {
-		objID, err := primitive.ObjectIDFromHex(configID)
+	if params.ConfigID != "" {
+		objID, err := primitive.ObjectIDFromHex(params.ConfigID)
 		if err == nil {
 			filter["config_id"] = objID
 		}
 	}
 
-	if status != "" {
-		filter["final_status"] = status
+	if params.Status != "" {
+		filter["final_status"] = params.Status
 	}
 
-	if acknowledgmentStatus != "" {
+	if params.Severity != "" {
+		filter["severity"] = params.Severity
+	}
+
+	if params.RuleName != "" {
+		filter["rule_name"] = params.RuleName
+	}
+
+	if params.WebhookHost != "" {
+		filter["webhook_host"] = strings.ToLower(params.WebhookHost)
+	}
+
+	if params.Source != "" {
+		filter["source"] = params.Source
+	}
+
+	if len(params.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": params.Tags}
+	}
+
+	if acknowledgmentStatus := params.AcknowledgmentStatus; acknowledgmentStatus != "" {
 		// Handle filtering for "open" status, which includes both explicit "open" and missing field
 		if acknowledgmentStatus == "open" {
 			filter["$or"] = []bson.M{
@@ -52,37 +157,67 @@ func (s *AlertService) List(ctx context.Context, configID, status, acknowledgmen
 		}
 	}
 
-	if from != "" {
-		if filter["created_at"] == nil {
-			filter["created_at"] = bson.M{}
+	from, to, err := model.ParseTimeRange(params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+	if !from.IsZero() || !to.IsZero() {
+		timeRange := bson.M{}
+		if !from.IsZero() {
+			timeRange["$gte"] = from
 		}
-		filter["created_at"].(bson.M)["$gte"] = from
+		if !to.IsZero() {
+			timeRange["$lte"] = to
+		}
+		filter["created_at"] = timeRange
 	}
 
-	if to != "" {
-		if filter["created_at"] == nil {
-			filter["created_at"] = bson.M{}
-		}
-		filter["created_at"].(bson.M)["$lte"] = to
+	return filter, nil
+}
+
+// GetByID retrieves an alert log with its attempts and comments
+func (s *AlertService) GetByID(ctx context.Context, alertID string) (*model.AlertLog, error) {
+	objID, err := primitive.ObjectIDFromHex(alertID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert ID: %w", err)
 	}
 
-	// Fetch from database
-	alerts, total, err := s.repo.List(ctx, filter, page, limit)
+	return s.repo.GetByID(ctx, objID)
+}
+
+// AddComment records a timestamped investigation note on an alert
+func (s *AlertService) AddComment(ctx context.Context, alertID, author, text string) (*model.AlertComment, error) {
+	objID, err := primitive.ObjectIDFromHex(alertID)
 	if err != nil {
-		return nil, 0, err
+		return nil, fmt.Errorf("invalid alert ID: %w", err)
 	}
 
-	// Convert to summaries
-	summaries := make([]model.AlertLogSummary, len(alerts))
-	for i, alert := range alerts {
-		summaries[i] = alert.ToSummary()
+	text = strings.TrimSpace(text)
+	if author == "" {
+		return nil, fmt.Errorf("invalid comment: author is required")
+	}
+	if text == "" {
+		return nil, fmt.Errorf("invalid comment: text is required")
+	}
+	if len(text) > model.MaxAlertCommentLength {
+		return nil, fmt.Errorf("invalid comment: text must be %d characters or less", model.MaxAlertCommentLength)
 	}
 
-	return summaries, total, nil
+	comment := model.AlertComment{
+		ID:        primitive.NewObjectID(),
+		Author:    author,
+		Text:      text,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.AddComment(ctx, objID, comment); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
 }
 
-// Acknowledge marks an alert as acknowledged
-func (s *AlertService) Acknowledge(ctx context.Context, alertID, acknowledgedBy string) error {
+// Acknowledge marks an alert as acknowledged with an optional classification
+func (s *AlertService) Acknowledge(ctx context.Context, alertID, acknowledgedBy, classification string) error {
 	// Validate alert ID
 	objID, err := primitive.ObjectIDFromHex(alertID)
 	if err != nil {
@@ -94,14 +229,83 @@ func (s *AlertService) Acknowledge(ctx context.Context, alertID, acknowledgedBy
 		return fmt.Errorf("acknowledged_by is required")
 	}
 
+	if classification != "" && !model.IsValidAlertClassification(classification) {
+		return fmt.Errorf("invalid classification: %s (must be 'real', 'noise', or 'test')", classification)
+	}
+
 	// Generate timestamp
 	acknowledgedAt := time.Now().UTC()
 
 	// Update the alert
-	err = s.repo.AcknowledgeAlert(ctx, objID, acknowledgedBy, acknowledgedAt)
+	err = s.repo.AcknowledgeAlert(ctx, objID, acknowledgedBy, acknowledgedAt, classification)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// AcknowledgeByLink marks an alert as acknowledged through a signed acknowledge link
+func (s *AlertService) AcknowledgeByLink(ctx context.Context, alertID, token, acknowledgedBy, classification string) error {
+	if err := s.ackLinks.Verify(alertID, token, time.Now()); err != nil {
+		return err
+	}
+	if acknowledgedBy == "" {
+		acknowledgedBy = "ack-link"
+	}
+	return s.Acknowledge(ctx, alertID, acknowledgedBy, classification)
+}
+
+// Ingest records an alert pushed by an external system and notifies its webhook. When
+// an unacknowledged alert with the same source and dedup key exists, its occurrence
+// count is bumped instead and no new notification is sent.
+func (s *AlertService) Ingest(ctx context.Context, request *model.AlertIngestRequest) (*model.AlertLog, bool, error) {
+	if err := request.Validate(); err != nil {
+		return nil, false, fmt.Errorf("validation failed: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if request.DedupKey != "" {
+		existing, err := s.repo.RecordOccurrence(ctx, request.Source, request.DedupKey, now)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing != nil {
+			return existing, true, nil
+		}
+	}
+
+	destination := model.Webhook{}
+	if request.Webhook != nil {
+		destination = *request.Webhook
+	}
+	destination, err := s.webhooks.Resolve(ctx, destination)
+	if err != nil {
+		return nil, false, fmt.Errorf("validation failed: %w", err)
+	}
+
+	correlationID := uuid.New().String()
+	payload := webhook.FormatIngestedAlertPayload(request, correlationID)
+
+	alertLog := webhook.NewAlertLog(destination, payload, correlationID)
+	alertLog.Source = request.Source
+	alertLog.DedupKey = request.DedupKey
+	alertLog.Tags = request.Tags
+	alertLog.Occurrences = 1
+	alertLog.LastSeenAt = now
+
+	if err := s.repo.Create(ctx, alertLog); err != nil {
+		return nil, false, err
+	}
+
+	intent := AlertIntent{
+		AlertLog: alertLog,
+		Webhook:  destination,
+		Payload:  payload,
+	}
+	if err := s.alertQueue.Enqueue(intent); err != nil {
+		s.alertQueue.MarkFailed(ctx, alertLog, err.Error())
+	}
+
+	return alertLog, false, nil
+}
@@ -22,11 +22,14 @@ func NewAsyncExecutor(executor *Executor) *AsyncExecutor {
 	}
 }
 
-// SubmitJob submits a health check for async execution
-func (ae *AsyncExecutor) SubmitJob(ctx context.Context, configID string) (string, error) {
+// SubmitJob submits a health check for async execution.
+// A new correlation ID is generated when none is provided.
+func (ae *AsyncExecutor) SubmitJob(ctx context.Context, configID, correlationID string, opts ExecuteOptions) (string, error) {
 	// Generate job ID
 	jobID := uuid.New().String()
-	correlationID := uuid.New().String()
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
 
 	// Create job status
 	status := &model.JobStatus{
@@ -37,7 +40,7 @@ func (ae *AsyncExecutor) SubmitJob(ctx context.Context, configID string) (string
 	ae.jobStore.Set(jobID, status)
 
 	// Execute in background
-	go ae.executeAsync(context.Background(), jobID, configID, correlationID)
+	go ae.executeAsync(context.Background(), jobID, configID, correlationID, opts)
 
 	return jobID, nil
 }
@@ -48,7 +51,7 @@ func (ae *AsyncExecutor) GetJobStatus(jobID string) (*model.JobStatus, bool) {
 }
 
 // executeAsync executes a health check asynchronously
-func (ae *AsyncExecutor) executeAsync(ctx context.Context, jobID, configID, correlationID string) {
+func (ae *AsyncExecutor) executeAsync(ctx context.Context, jobID, configID, correlationID string, opts ExecuteOptions) {
 	// Update status to processing
 	if status, exists := ae.jobStore.Get(jobID); exists {
 		status.Status = "processing"
@@ -62,7 +65,7 @@ func (ae *AsyncExecutor) executeAsync(ctx context.Context, jobID, configID, corr
 	)
 
 	// Execute health check
-	result, err := ae.executor.Execute(ctx, configID, correlationID)
+	result, err := ae.executor.Execute(ctx, configID, correlationID, opts)
 
 	// Update job status
 	if status, exists := ae.jobStore.Get(jobID); exists {
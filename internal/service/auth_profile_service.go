@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuthProfileService handles auth profile management
+type AuthProfileService struct {
+	repo *database.AuthProfileRepository
+}
+
+// NewAuthProfileService creates a new auth profile service
+func NewAuthProfileService(repo *database.AuthProfileRepository) *AuthProfileService {
+	return &AuthProfileService{
+		repo: repo,
+	}
+}
+
+// Create creates a new auth profile
+func (s *AuthProfileService) Create(ctx context.Context, profile *model.AuthProfile) error {
+	if err := profile.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	return s.repo.Create(ctx, profile)
+}
+
+// GetByID retrieves an auth profile by ID
+func (s *AuthProfileService) GetByID(ctx context.Context, id string) (*model.AuthProfile, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	return s.repo.GetByID(ctx, objID)
+}
+
+// List retrieves auth profiles
+func (s *AuthProfileService) List(ctx context.Context, page, limit int) ([]model.AuthProfile, int64, error) {
+	return s.repo.List(ctx, page, limit)
+}
+
+// Update updates an existing auth profile
+func (s *AuthProfileService) Update(ctx context.Context, id string, profile *model.AuthProfile) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	if err := profile.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	return s.repo.Update(ctx, objID, profile)
+}
+
+// Delete deletes an auth profile that is no longer referenced
+func (s *AuthProfileService) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	references, err := s.repo.CountReferences(ctx, objID)
+	if err != nil {
+		return err
+	}
+	if references > 0 {
+		return fmt.Errorf("auth profile is still used by %d health checks", references)
+	}
+
+	return s.repo.Delete(ctx, objID)
+}
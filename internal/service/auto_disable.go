@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/webhook"
+)
+
+// SetAudit sets the repository automatic disables are recorded in
+func (e *Executor) SetAudit(audit *database.AuditRepository) {
+	e.audit = audit
+}
+
+// trackReachability maintains the unreachable streak of a check with auto-disable set and
+// disables the check once the streak is long enough
+func (e *Executor) trackReachability(ctx context.Context, config *model.HealthCheckConfig, execution *model.ExecutionHistory) {
+	if config.AutoDisable == nil {
+		return
+	}
+
+	streak, err := e.healthCheckRepo.RecordReachability(ctx, config.ID, execution.Unreachable(), execution.ExecutedAt)
+	if err != nil {
+		slog.Error("Failed to record unreachable streak",
+			"correlation_id", execution.CorrelationID,
+			"error", err.Error(),
+		)
+		return
+	}
+	if !config.AutoDisable.Due(streak, execution.ExecutedAt) {
+		return
+	}
+
+	reason := fmt.Sprintf("Target unreachable for %d consecutive runs since %s", streak.Runs, streak.Since.Format(time.RFC3339))
+	disabled, err := e.healthCheckRepo.AutoDisable(ctx, config.ID, reason, execution.ExecutedAt)
+	if err != nil {
+		slog.Error("Failed to disable health check",
+			"correlation_id", execution.CorrelationID,
+			"error", err.Error(),
+		)
+		return
+	}
+	if !disabled {
+		return
+	}
+
+	slog.Warn("Health check disabled after prolonged unreachability",
+		"correlation_id", execution.CorrelationID,
+		"config_name", config.Name,
+		"unreachable_runs", streak.Runs,
+		"unreachable_since", streak.Since,
+	)
+
+	if e.audit != nil {
+		entry := &model.AuditEntry{
+			ConfigID:   config.ID,
+			ConfigName: config.Name,
+			Action:     model.AuditActionAutoDisabled,
+			Actor:      "system",
+			Reason:     reason,
+			At:         execution.ExecutedAt,
+		}
+		if err := e.audit.Create(ctx, entry); err != nil {
+			slog.Error("Failed to record audit entry",
+				"correlation_id", execution.CorrelationID,
+				"error", err.Error(),
+			)
+		}
+	}
+
+	e.notifyAutoDisabled(ctx, config, execution, streak)
+}
+
+// notifyAutoDisabled tells the config webhook that the check was disabled
+func (e *Executor) notifyAutoDisabled(ctx context.Context, config *model.HealthCheckConfig, execution *model.ExecutionHistory, streak *model.UnreachableStreak) {
+	destination, err := e.webhooks.Resolve(ctx, config.Webhook)
+	if err != nil {
+		slog.Error("Failed to resolve auto-disable webhook",
+			"correlation_id", execution.CorrelationID,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	payload := webhook.FormatAutoDisabledPayload(config.ID.Hex(), config.Name, config.Target.URL, streak, execution.CorrelationID)
+
+	alertLog := webhook.NewAlertLog(destination, payload, execution.CorrelationID)
+	alertLog.ExecutionID = execution.ID
+	alertLog.ConfigID = config.ID
+	alertLog.Tags = config.Metadata.Tags
+
+	if err := e.alertRepo.Create(ctx, alertLog); err != nil {
+		slog.Error("Failed to create auto-disable alert",
+			"correlation_id", execution.CorrelationID,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	e.enqueueAlerts(ctx, []AlertIntent{{
+		AlertLog: alertLog,
+		Webhook:  destination,
+		Payload:  payload,
+	}})
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/webhook"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// globalBudgetKey is the counter key of the budget shared by all checks
+const globalBudgetKey = "global"
+
+// budgetExceeded describes the daily budget an execution was refused by
+type budgetExceeded struct {
+	Scope string // "config" or "global"
+	Limit int
+	First bool // First refusal of the day, which notifies the admin channel
+}
+
+// SetBudgets enables daily execution budgets. globalLimit caps the executions of all checks
+// per UTC day (zero is unlimited); adminChannel, when set, is notified the first time each
+// budget is exceeded in a day.
+func (e *Executor) SetBudgets(budgets *database.BudgetRepository, globalLimit int, adminChannel primitive.ObjectID) {
+	e.budgets = budgets
+	e.globalBudget = globalLimit
+	e.adminChannel = adminChannel
+}
+
+// consumeBudget takes an execution from the config's and the global daily budget, returning
+// the exhausted budget or nil when the execution may run. Budget store errors let it run.
+func (e *Executor) consumeBudget(ctx context.Context, config *model.HealthCheckConfig, correlationID string, at time.Time) *budgetExceeded {
+	if e.budgets == nil || (config.MaxExecutionsPerDay == 0 && e.globalBudget == 0) {
+		return nil
+	}
+
+	configKey := "config:" + config.ID.Hex()
+	if config.MaxExecutionsPerDay > 0 {
+		count, err := e.budgets.Consume(ctx, configKey, at)
+		if err != nil {
+			e.budgetError(correlationID, err)
+			return nil
+		}
+		if count > int64(config.MaxExecutionsPerDay) {
+			return &budgetExceeded{Scope: "config", Limit: config.MaxExecutionsPerDay, First: count == int64(config.MaxExecutionsPerDay)+1}
+		}
+	}
+
+	if e.globalBudget > 0 {
+		count, err := e.budgets.Consume(ctx, globalBudgetKey, at)
+		if err != nil {
+			e.budgetError(correlationID, err)
+			return nil
+		}
+		if count > int64(e.globalBudget) {
+			// The check didn't run, so it shouldn't count against its own budget
+			if config.MaxExecutionsPerDay > 0 {
+				if err := e.budgets.Release(ctx, configKey, at); err != nil {
+					e.budgetError(correlationID, err)
+				}
+			}
+			return &budgetExceeded{Scope: "global", Limit: e.globalBudget, First: count == int64(e.globalBudget)+1}
+		}
+	}
+
+	return nil
+}
+
+// budgetError logs a failure to update a budget counter
+func (e *Executor) budgetError(correlationID string, err error) {
+	slog.Warn("Failed to check execution budget, executing anyway",
+		"correlation_id", correlationID,
+		"error", err.Error(),
+	)
+}
+
+// recordBudgetExceeded persists a "budget_exceeded" execution without calling the target,
+// notifying the admin channel on the first refusal of the day
+func (e *Executor) recordBudgetExceeded(
+	ctx context.Context,
+	config *model.HealthCheckConfig,
+	correlationID string,
+	opts ExecuteOptions,
+	exceeded *budgetExceeded,
+	start time.Time,
+) *model.ExecutionHistory {
+	execution := &model.ExecutionHistory{
+		ID:            primitive.NewObjectID(),
+		CorrelationID: correlationID,
+		ConfigID:      config.ID,
+		ConfigName:    config.Name,
+		BatchID:       opts.BatchID,
+		ExecutedAt:    time.Now().UTC(),
+		DurationMs:    time.Since(start).Milliseconds(),
+		Request:       model.ExecutionRequest{URL: config.Target.URL, Method: config.Target.Method},
+		Response: model.ExecutionResponse{
+			Error: fmt.Sprintf("Daily %s execution budget of %d exceeded", exceeded.Scope, exceeded.Limit),
+		},
+		RulesEvaluation: make([]model.RuleEvaluation, 0),
+		AlertsTriggered: make([]model.AlertTriggered, 0),
+		Status:          "budget_exceeded",
+		Metadata:        e.metadata(opts),
+	}
+
+	if err := e.executionWriter.Write(ctx, execution); err != nil {
+		slog.Error("Failed to save execution history",
+			"correlation_id", correlationID,
+			"error", err.Error(),
+		)
+	}
+
+	slog.Warn("Health check skipped, execution budget exceeded",
+		"correlation_id", correlationID,
+		"config_name", config.Name,
+		"scope", exceeded.Scope,
+		"limit", exceeded.Limit,
+	)
+
+	if exceeded.First && !e.adminChannel.IsZero() {
+		e.notifyBudgetExceeded(ctx, config, execution, exceeded)
+	}
+
+	return execution
+}
+
+// notifyBudgetExceeded sends a budget alert to the admin channel through the alert queue
+func (e *Executor) notifyBudgetExceeded(ctx context.Context, config *model.HealthCheckConfig, execution *model.ExecutionHistory, exceeded *budgetExceeded) {
+	payload := webhook.FormatBudgetExceededPayload(config.Name, exceeded.Scope, exceeded.Limit, execution.CorrelationID)
+	e.notifyAdmin(ctx, payload, execution.CorrelationID, config.ID, execution.ID)
+}
+
+// NotifyAdmin sends an operator notification to the admin channel through the alert queue;
+// without an admin channel it does nothing
+func (e *Executor) NotifyAdmin(ctx context.Context, payload webhook.AlertPayloadData, correlationID string) {
+	if e.adminChannel.IsZero() {
+		return
+	}
+	e.notifyAdmin(ctx, payload, correlationID, primitive.NilObjectID, primitive.NilObjectID)
+}
+
+// notifyAdmin logs and queues a payload for the admin channel
+func (e *Executor) notifyAdmin(ctx context.Context, payload webhook.AlertPayloadData, correlationID string, configID, executionID primitive.ObjectID) {
+	destination, err := e.webhooks.Resolve(ctx, model.Webhook{ChannelID: e.adminChannel})
+	if err != nil {
+		slog.Error("Failed to resolve admin channel",
+			"correlation_id", correlationID,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	alertLog := webhook.NewAlertLog(destination, payload, correlationID)
+	alertLog.ExecutionID = executionID
+	alertLog.ConfigID = configID
+
+	if err := e.alertRepo.Create(ctx, alertLog); err != nil {
+		slog.Error("Failed to create admin alert",
+			"correlation_id", correlationID,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	e.enqueueAlerts(ctx, []AlertIntent{{
+		AlertLog: alertLog,
+		Webhook:  destination,
+		Payload:  payload,
+	}})
+}
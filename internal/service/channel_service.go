@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/webhook"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChannelService handles notification channel management
+type ChannelService struct {
+	repo   *database.ChannelRepository
+	policy *webhook.DestinationPolicy
+}
+
+// NewChannelService creates a new notification channel service
+func NewChannelService(repo *database.ChannelRepository, policy *webhook.DestinationPolicy) *ChannelService {
+	return &ChannelService{
+		repo:   repo,
+		policy: policy,
+	}
+}
+
+// Create creates a new notification channel
+func (s *ChannelService) Create(ctx context.Context, channel *model.NotificationChannel) error {
+	if err := s.validate(ctx, channel); err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, channel)
+}
+
+// GetByID retrieves a notification channel by ID
+func (s *ChannelService) GetByID(ctx context.Context, id string) (*model.NotificationChannel, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	return s.repo.GetByID(ctx, objID)
+}
+
+// List retrieves notification channels
+func (s *ChannelService) List(ctx context.Context, page, limit int) ([]model.NotificationChannel, int64, error) {
+	return s.repo.List(ctx, page, limit)
+}
+
+// Update updates an existing notification channel
+func (s *ChannelService) Update(ctx context.Context, id string, channel *model.NotificationChannel) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	if err := s.validate(ctx, channel); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, objID, channel)
+}
+
+// Delete deletes a notification channel that is no longer referenced
+func (s *ChannelService) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	references, err := s.repo.CountReferences(ctx, objID)
+	if err != nil {
+		return err
+	}
+	if references > 0 {
+		return fmt.Errorf("channel is still used by %d health checks or suites", references)
+	}
+
+	return s.repo.Delete(ctx, objID)
+}
+
+// Stats retrieves delivery statistics for a notification channel over a time window
+func (s *ChannelService) Stats(ctx context.Context, id string, window time.Duration, bucket string) (*model.ChannelStats, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	if !database.IsValidTimeBucket(bucket) {
+		return nil, fmt.Errorf("invalid bucket: %s", bucket)
+	}
+
+	// Make sure the channel exists
+	if _, err := s.repo.GetByID(ctx, objID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Stats(ctx, objID, time.Now().UTC().Add(-window), bucket)
+}
+
+// validate validates the channel and checks its destination against the allow-list
+func (s *ChannelService) validate(ctx context.Context, channel *model.NotificationChannel) error {
+	if err := channel.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.policy.AllowedWebhook(ctx, channel.Webhook); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ChildCorrelationID derives a correlation ID for a member of a parent operation
+// (e.g. the n-th item of a batch), keeping the parent ID as a searchable prefix
+func ChildCorrelationID(parentID string, index int) string {
+	if parentID == "" {
+		parentID = uuid.New().String()
+	}
+	return fmt.Sprintf("%s-%d", parentID, index)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dandantas/raven/internal/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DependencyState caches the health of parent checks so that every dependent check
+// evaluated during the same scheduler tick sees a consistent view
+type DependencyState struct {
+	repo    *database.ExecutionRepository
+	mu      sync.Mutex
+	healthy map[primitive.ObjectID]bool
+}
+
+// NewDependencyState creates an empty dependency state
+func NewDependencyState(repo *database.ExecutionRepository) *DependencyState {
+	return &DependencyState{
+		repo:    repo,
+		healthy: make(map[primitive.ObjectID]bool),
+	}
+}
+
+// FailingParents returns the parents whose latest execution was not healthy.
+// Parents that have never been executed are treated as healthy.
+func (d *DependencyState) FailingParents(ctx context.Context, parentIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Load the parents not seen yet during this tick in a single query
+	var missing []primitive.ObjectID
+	for _, parentID := range parentIDs {
+		if _, ok := d.healthy[parentID]; !ok {
+			missing = append(missing, parentID)
+		}
+	}
+
+	if len(missing) > 0 {
+		latest, err := d.repo.LatestByConfigIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, parentID := range missing {
+			execution, ok := latest[parentID]
+			d.healthy[parentID] = !ok || execution.Healthy()
+		}
+	}
+
+	var failing []primitive.ObjectID
+	for _, parentID := range parentIDs {
+		if !d.healthy[parentID] {
+			failing = append(failing, parentID)
+		}
+	}
+
+	return failing, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/deploy"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// deployVerifyTimeout bounds a post-deploy verification run
+const deployVerifyTimeout = 10 * time.Minute
+
+// DeployService runs the checks tagged for a service after it is deployed and
+// reports the aggregate result back to the provider
+type DeployService struct {
+	repo      *database.HealthCheckRepository
+	suites    *SuiteService
+	reporters map[string]deploy.Reporter
+	tagPrefix string
+}
+
+// NewDeployService creates a new deploy service; checks tagged tagPrefix+<service> are verified
+func NewDeployService(repo *database.HealthCheckRepository, suites *SuiteService, tagPrefix string) *DeployService {
+	return &DeployService{
+		repo:      repo,
+		suites:    suites,
+		reporters: make(map[string]deploy.Reporter),
+		tagPrefix: tagPrefix,
+	}
+}
+
+// SetReporter registers the commit status reporter for a provider
+func (s *DeployService) SetReporter(provider string, reporter deploy.Reporter) {
+	s.reporters[provider] = reporter
+}
+
+// Verify starts post-deploy verification in the background and returns the number of checks it
+// runs and the ID of the batch they are recorded under. correlationID is the webhook request's and
+// is kept as metadata only, since providers reuse it when they retry a delivery.
+func (s *DeployService) Verify(ctx context.Context, event *deploy.Event, correlationID string) (int, string, error) {
+	tag := s.tagPrefix + event.Service
+	configs, err := s.repo.FindByTag(ctx, tag)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(configs) == 0 {
+		return 0, "", nil
+	}
+
+	configIDs := make([]primitive.ObjectID, len(configs))
+	for i, config := range configs {
+		configIDs[i] = config.ID
+	}
+
+	// Verification outlives the webhook request
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deployVerifyTimeout)
+	batchID := uuid.New().String()
+	go func() {
+		defer cancel()
+		s.run(runCtx, event, tag, configIDs, batchID, correlationID)
+	}()
+
+	return len(configs), batchID, nil
+}
+
+// run executes the tagged checks as an ad-hoc suite and reports the outcome
+func (s *DeployService) run(ctx context.Context, event *deploy.Event, tag string, configIDs []primitive.ObjectID, batchID, correlationID string) {
+	s.report(ctx, event, deploy.StatePending, fmt.Sprintf("Running %d post-deploy checks", len(configIDs)))
+
+	suite := &model.Suite{
+		Name:           fmt.Sprintf("deploy %s@%s", event.Repository, shortSHA(event.SHA)),
+		Enabled:        true,
+		ConfigIDs:      configIDs,
+		AlertOnFailure: true,
+	}
+	result := s.suites.RunSuite(ctx, suite, ExecuteOptions{
+		BatchID:              batchID,
+		RequestCorrelationID: correlationID,
+		TriggerType:          model.TriggerDeployment,
+		TriggeredBy:          event.Provider + ":" + event.Repository,
+	})
+
+	slog.Info("Post-deploy verification completed",
+		"correlation_id", correlationID,
+		"batch_id", batchID,
+		"repository", event.Repository,
+		"sha", event.SHA,
+		"environment", event.Environment,
+		"tag", tag,
+		"status", result.Status,
+	)
+
+	state := deploy.StateSuccess
+	if result.Failed > 0 {
+		state = deploy.StateFailure
+	}
+	s.report(ctx, event, state, fmt.Sprintf("%d of %d checks passed", result.Passed, result.Total))
+}
+
+// report posts a commit status when a reporter is configured for the provider
+func (s *DeployService) report(ctx context.Context, event *deploy.Event, state, description string) {
+	reporter, ok := s.reporters[event.Provider]
+	if !ok {
+		return
+	}
+	if err := reporter.Report(ctx, event, state, description); err != nil {
+		slog.Error("Failed to report deployment status",
+			"provider", event.Provider,
+			"repository", event.Repository,
+			"sha", event.SHA,
+			"state", state,
+			"error", err.Error(),
+		)
+	}
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/dandantas/raven/internal/database"
 	"github.com/dandantas/raven/internal/model"
@@ -26,38 +29,73 @@ func (s *ExecutionService) GetByCorrelationID(ctx context.Context, correlationID
 	return s.repo.GetByCorrelationID(ctx, correlationID)
 }
 
+// ExecutionFilter holds the optional execution list filters
+type ExecutionFilter struct {
+	ConfigID    string
+	Status      string
+	From        string
+	To          string
+	TriggerType string
+	TriggeredBy string
+	PodID       string
+	Region      string
+	Sort        string
+}
+
 // List retrieves execution history with filtering
-func (s *ExecutionService) List(ctx context.Context, configID, status, from, to string, page, limit int) ([]model.ExecutionSummary, int64, error) {
+func (s *ExecutionService) List(ctx context.Context, params ExecutionFilter, page, limit int) ([]model.ExecutionSummary, int64, error) {
 	// Build filter
 	filter := bson.M{}
 
-	if configID != "" {
-		objID, err := primitive.ObjectIDFromHex(configID)
+	if params.ConfigID != "" {
+		objID, err := primitive.ObjectIDFromHex(params.ConfigID)
 		if err == nil {
 			filter["config_id"] = objID
 		}
 	}
 
-	if status != "" {
-		filter["status"] = status
+	if params.Status != "" {
+		filter["status"] = params.Status
 	}
 
-	if from != "" {
-		if filter["executed_at"] == nil {
-			filter["executed_at"] = bson.M{}
-		}
-		filter["executed_at"].(bson.M)["$gte"] = from
+	if params.TriggerType != "" {
+		filter["metadata.trigger_type"] = params.TriggerType
 	}
 
-	if to != "" {
-		if filter["executed_at"] == nil {
-			filter["executed_at"] = bson.M{}
+	if params.TriggeredBy != "" {
+		filter["metadata.triggered_by"] = params.TriggeredBy
+	}
+
+	if params.PodID != "" {
+		filter["metadata.pod_id"] = params.PodID
+	}
+
+	if params.Region != "" {
+		filter["metadata.region"] = params.Region
+	}
+
+	from, to, err := model.ParseTimeRange(params.From, params.To)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !from.IsZero() || !to.IsZero() {
+		timeRange := bson.M{}
+		if !from.IsZero() {
+			timeRange["$gte"] = from
+		}
+		if !to.IsZero() {
+			timeRange["$lte"] = to
 		}
-		filter["executed_at"].(bson.M)["$lte"] = to
+		filter["executed_at"] = timeRange
+	}
+
+	sort, err := database.ParseSort(params.Sort, database.ExecutionSortFields)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// Fetch from database
-	executions, total, err := s.repo.List(ctx, filter, page, limit)
+	executions, total, err := s.repo.List(ctx, filter, sort, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -70,3 +108,66 @@ func (s *ExecutionService) List(ctx context.Context, configID, status, from, to
 
 	return summaries, total, nil
 }
+
+// GetBatch retrieves all executions of a batch with an aggregate summary
+func (s *ExecutionService) GetBatch(ctx context.Context, batchID string) (*model.BatchSummary, error) {
+	executions, err := s.repo.ListByBatchID(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(executions) == 0 {
+		return nil, fmt.Errorf("batch not found")
+	}
+
+	summary := &model.BatchSummary{
+		BatchID:      batchID,
+		Total:        len(executions),
+		StatusCounts: make(map[string]int),
+		Executions:   make([]model.ExecutionSummary, len(executions)),
+	}
+
+	for i, exec := range executions {
+		summary.Executions[i] = exec.ToSummary()
+		summary.StatusCounts[exec.Status]++
+		summary.AlertsTriggered += len(exec.AlertsTriggered)
+		summary.TotalDurationMs += exec.DurationMs
+	}
+
+	// Executions are sorted by executed_at ascending
+	summary.FirstExecutedAt = summary.Executions[0].ExecutedAt
+	summary.LastExecutedAt = summary.Executions[len(executions)-1].ExecutedAt
+
+	return summary, nil
+}
+
+// RegionStatus retrieves the latest agent result of a health check in each region
+func (s *ExecutionService) RegionStatus(ctx context.Context, configID string) ([]model.RegionStatus, error) {
+	objID, err := primitive.ObjectIDFromHex(configID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	latest, err := s.repo.LatestByRegion(ctx, objID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]model.RegionStatus, 0, len(latest))
+	for region, execution := range latest {
+		statuses = append(statuses, model.RegionStatus{
+			Region:        region,
+			AgentID:       execution.Metadata.AgentID,
+			CorrelationID: execution.CorrelationID,
+			ExecutedAt:    execution.ExecutedAt.Format(time.RFC3339),
+			Status:        execution.Status,
+			Healthy:       execution.Healthy(),
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Region < statuses[j].Region
+	})
+
+	return statuses, nil
+}
@@ -1,52 +1,150 @@
 package service
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/dandantas/raven/internal/clock"
 	"github.com/dandantas/raven/internal/database"
 	"github.com/dandantas/raven/internal/evaluator"
 	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/probe"
 	"github.com/dandantas/raven/internal/webhook"
+	"github.com/dandantas/raven/pkg/plugins"
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// defaultConfirmDelay is how long to wait before the confirmation re-check when no delay is configured
+const defaultConfirmDelay = 5 * time.Second
+
+// regionQuorumWindow is how far back other regions' results count towards the failing-region quorum
+const regionQuorumWindow = 10 * time.Minute
+
+// Reasons a scheduled run is skipped when its config changed after the tick found it due
+var (
+	ErrDisabled         = errors.New("health check is disabled")
+	ErrScheduleDisabled = errors.New("scheduling is disabled for the health check")
+	ErrNoLongerDue      = errors.New("health check changed and is no longer due")
+)
+
+// ErrInterrupted is the cancellation cause of executions cut short by a shutdown. They are
+// recorded as "interrupted", without evaluating rules, alerting or counting the run.
+var ErrInterrupted = errors.New("execution interrupted by shutdown")
+
 // Executor handles health check execution
 type Executor struct {
-	httpClient        *http.Client
-	evaluator         *evaluator.Evaluator
-	webhookDispatcher *webhook.Dispatcher
-	healthCheckRepo   *database.HealthCheckRepository
-	executionRepo     *database.ExecutionRepository
-	alertRepo         *database.AlertRepository
+	httpClient      *http.Client
+	evaluator       *evaluator.Evaluator
+	alertQueue      *AlertQueue
+	healthCheckRepo *database.HealthCheckRepository
+	executionWriter *database.ExecutionWriter
+	executionRepo   *database.ExecutionRepository
+	alertRepo       *database.AlertRepository
+	webhooks        *webhook.Resolver
+	credentials     *probe.Credentials
+	metrics         *MetricService
+	transitions     *database.StateTransitionRepository
+	downtimes       *database.DowntimeRepository
+	budgets         *database.BudgetRepository
+	globalBudget    int
+	adminChannel    primitive.ObjectID
+	audit           *database.AuditRepository
+	browser         *probe.Browser
+	plugins         *plugins.Registry
+	probeOptions    probe.Options
+	bodySampleRate  int
+	podID           string
+	region          string
+	clock           clock.Clock
 }
 
 // NewExecutor creates a new executor
 func NewExecutor(
 	httpClient *http.Client,
-	webhookDispatcher *webhook.Dispatcher,
+	alertQueue *AlertQueue,
 	healthCheckRepo *database.HealthCheckRepository,
+	executionWriter *database.ExecutionWriter,
 	executionRepo *database.ExecutionRepository,
 	alertRepo *database.AlertRepository,
+	webhooks *webhook.Resolver,
 ) *Executor {
 	return &Executor{
-		httpClient:        httpClient,
-		evaluator:         evaluator.NewEvaluator(),
-		webhookDispatcher: webhookDispatcher,
-		healthCheckRepo:   healthCheckRepo,
-		executionRepo:     executionRepo,
-		alertRepo:         alertRepo,
+		httpClient:      httpClient,
+		evaluator:       evaluator.NewEvaluator(),
+		alertQueue:      alertQueue,
+		healthCheckRepo: healthCheckRepo,
+		executionWriter: executionWriter,
+		executionRepo:   executionRepo,
+		alertRepo:       alertRepo,
+		webhooks:        webhooks,
+		clock:           clock.Real,
 	}
 }
 
+// SetClock replaces the wall clock used for execution timestamps and rule windows
+func (e *Executor) SetClock(c clock.Clock) {
+	e.clock = c
+}
+
+// SetLocation sets the pod ID and region label recorded on executions run by this instance
+func (e *Executor) SetLocation(podID, region string) {
+	e.podID = podID
+	e.region = region
+}
+
+// SetProbeOptions sets the default stage timeouts and network error retries of target calls
+func (e *Executor) SetProbeOptions(opts probe.Options) {
+	e.probeOptions = opts
+}
+
+// SetBodySampleRate sets how many successful runs share one stored response body; checks can override it
+func (e *Executor) SetBodySampleRate(rate int) {
+	e.bodySampleRate = rate
+}
+
+// SetCredentials sets the resolver for auth profiles referenced by health check targets
+func (e *Executor) SetCredentials(credentials *probe.Credentials) {
+	e.credentials = credentials
+}
+
+// SetMetrics sets the service that records values extracted by metric rules
+func (e *Executor) SetMetrics(metrics *MetricService) {
+	e.metrics = metrics
+}
+
+// ResolveTarget returns the config's target with its auth profile reference resolved
+func (e *Executor) ResolveTarget(ctx context.Context, config *model.HealthCheckConfig) (model.Target, error) {
+	return e.credentials.ResolveTarget(ctx, config.Target)
+}
+
+// ExecuteOptions carries optional context about how an execution was triggered
+type ExecuteOptions struct {
+	BatchID              string           // Set when the execution is part of a batch
+	RequestCorrelationID string           // Correlation ID of the API request that started a batch, kept as metadata only
+	SuppressAlerts       bool             // Evaluate rules without sending per-check alerts (e.g. suite members)
+	Dependencies         *DependencyState // Shared parent health for a scheduler tick; loaded on demand when nil
+	TriggerType          string           // "manual", "api" or "scheduled"
+	TriggeredBy          string           // API key name, "scheduler", agent or batch that requested the execution
+	Region               string           // Region of the agent that probed the target; defaults to this instance's region
+	AgentID              string           // Agent that probed the target
+
+	// ScheduledVersion is the updated_at of the config a scheduler tick found due. The run is
+	// skipped if the config was disabled since, or changed and is no longer due.
+	ScheduledVersion time.Time
+}
+
+// NewDependencyState creates a dependency state to share between executions of one tick
+func (e *Executor) NewDependencyState() *DependencyState {
+	return NewDependencyState(e.executionRepo)
+}
+
 // Execute executes a health check by config ID
-func (e *Executor) Execute(ctx context.Context, configID string, correlationID string) (*model.ExecutionHistory, error) {
+func (e *Executor) Execute(ctx context.Context, configID string, correlationID string, opts ExecuteOptions) (*model.ExecutionHistory, error) {
 	slog.Info("Starting health check execution",
 		"correlation_id", correlationID,
 		"config_id", configID,
@@ -54,6 +152,10 @@ func (e *Executor) Execute(ctx context.Context, configID string, correlationID s
 
 	start := time.Now()
 
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+
 	// Parse config ID
 	objID, err := primitive.ObjectIDFromHex(configID)
 	if err != nil {
@@ -68,7 +170,17 @@ func (e *Executor) Execute(ctx context.Context, configID string, correlationID s
 
 	// Check if enabled
 	if !config.Enabled {
-		return nil, fmt.Errorf("health check is disabled")
+		return nil, ErrDisabled
+	}
+
+	// Scheduled runs re-verify the config, which may have changed since the tick fetched it
+	if !opts.ScheduledVersion.IsZero() {
+		if !config.ScheduleEnabled {
+			return nil, ErrScheduleDisabled
+		}
+		if !config.Metadata.UpdatedAt.Equal(opts.ScheduledVersion) && config.NextScheduledRun.After(e.clock.Now()) {
+			return nil, ErrNoLongerDue
+		}
 	}
 
 	slog.Info("Fetched health check configuration",
@@ -77,38 +189,152 @@ func (e *Executor) Execute(ctx context.Context, configID string, correlationID s
 		"target_url", config.Target.URL,
 	)
 
+	// Bound the whole execution so slow targets can't hold scheduler slots
+	if config.MaxExecutionSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(config.MaxExecutionSeconds)*time.Second)
+		defer cancel()
+	}
+
+	// Skip the check while any parent is failing to avoid cascading alerts
+	if len(config.DependsOn) > 0 {
+		blockedBy := e.failingDependencies(ctx, config, correlationID, opts)
+		if len(blockedBy) > 0 {
+			return e.recordBlocked(ctx, config, correlationID, opts, blockedBy, start), nil
+		}
+	}
+
+	// Skip the check once its own or the global daily budget is used up
+	if exceeded := e.consumeBudget(ctx, config, correlationID, start); exceeded != nil {
+		return e.recordBudgetExceeded(ctx, config, correlationID, opts, exceeded, start), nil
+	}
+
+	// Resolve shared credentials at call time so rotated profiles apply immediately
+	target, err := e.ResolveTarget(ctx, config)
+	if err != nil {
+		request := model.ExecutionRequest{URL: config.Target.URL, Method: config.Target.Method}
+		response := model.ExecutionResponse{Error: err.Error()}
+		return e.complete(ctx, config, correlationID, opts, request, response, err, nil, nil, 0, start), nil
+	}
+
+	// Pre hooks can mint tokens or warm caches; their variables fill the target's placeholders
+	hooks, err := e.runPreHooks(ctx, config, correlationID)
+	if err == nil {
+		target, err = hooks.applyTarget(target)
+	}
+	if err != nil {
+		request := model.ExecutionRequest{URL: config.Target.URL, Method: config.Target.Method}
+		response := model.ExecutionResponse{Error: err.Error()}
+		return e.complete(ctx, config, correlationID, opts, request, response, err, nil, hooks, 0, start), nil
+	}
+
 	// Make API call to target
 	apiStart := time.Now()
-	request, response, err := e.callTargetAPI(ctx, config.Target)
+	request, response, call, err := e.callTarget(ctx, config, target, correlationID)
 	apiDuration := time.Since(apiStart)
+	request = hooks.redact(request, config.Target)
+
+	return e.complete(ctx, config, correlationID, opts, request, response, err, call, hooks, apiDuration, start), nil
+}
+
+// RecordResult evaluates, persists and alerts on a target call made by a remote agent
+func (e *Executor) RecordResult(
+	ctx context.Context,
+	config *model.HealthCheckConfig,
+	correlationID string,
+	opts ExecuteOptions,
+	request model.ExecutionRequest,
+	response model.ExecutionResponse,
+	apiDuration time.Duration,
+) *model.ExecutionHistory {
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+
+	// Agents report transport failures through the response error
+	var callErr error
+	if response.Error != "" && response.StatusCode == 0 {
+		callErr = errors.New(response.Error)
+	}
+
+	return e.complete(ctx, config, correlationID, opts, request, response, callErr, nil, nil, apiDuration, time.Now().Add(-apiDuration))
+}
+
+// complete evaluates the target response, persists the execution and hands alerts to the queue
+func (e *Executor) complete(
+	ctx context.Context,
+	config *model.HealthCheckConfig,
+	correlationID string,
+	opts ExecuteOptions,
+	request model.ExecutionRequest,
+	response model.ExecutionResponse,
+	callErr error,
+	call *targetCall,
+	hooks *hookRun,
+	apiDuration time.Duration,
+	start time.Time,
+) *model.ExecutionHistory {
+	if errors.Is(context.Cause(ctx), ErrInterrupted) {
+		reason := fmt.Sprintf("Interrupted by shutdown of pod %s", e.podID)
+		return e.recordNotRun(ctx, config, correlationID, opts, request, "interrupted", reason, start)
+	}
+
+	// Pre-generate the execution ID so alert logs can reference it
+	executionID := primitive.NewObjectID()
 
 	// Evaluate rules
 	var rulesEvaluation []model.RuleEvaluation
 	var alertsTriggered []model.AlertTriggered
+	var alertIntents []AlertIntent
+	var confirmation *model.ConfirmationCheck
+
+	if callErr == nil && evaluable(config, response) {
+		// Detect a non-JSON body once; rules that don't need JSON still evaluate normally
+		response.BodyNotJSON = evaluator.BodyNotJSON(response.Body)
 
-	if err == nil && response.StatusCode >= 200 && response.StatusCode < 300 {
-		// Evaluate all rules
-		rulesEvaluation = e.evaluator.EvaluateRules(config.Rules, response.Body)
+		// Evaluate rules until the execution deadline, if any, passes
+		rulesEvaluation = e.evaluate(ctx, config, &response, apiDuration)
 
-		// Get rules that should trigger alerts
+		// Get rules that should trigger alerts; delivery latency is measured from here
 		matchedAlerts := e.evaluator.GetMatchedRulesForAlert(rulesEvaluation, config.Rules)
+		matchedAt := time.Now().UTC()
 
-		// Trigger alerts
+		if opts.SuppressAlerts {
+			matchedAlerts = nil
+		}
+
+		// Multi-region checks only alert once enough regions agree the check is failing
+		if len(matchedAlerts) > 0 && !e.regionQuorumMet(ctx, config, opts.Region, correlationID) {
+			matchedAlerts = nil
+		}
+
+		// Re-check locally probed targets once to filter out transient failures
+		if len(matchedAlerts) > 0 && config.ConfirmBeforeAlert && opts.AgentID == "" {
+			matchedAlerts, confirmation = e.confirmAlerts(ctx, config, matchedAlerts, hooks, correlationID)
+		}
+
+		// Prepare alerts for asynchronous delivery
 		for _, ruleEval := range matchedAlerts {
-			alertID, alertErr := e.triggerAlert(ctx, config, ruleEval, response.StatusCode, correlationID, apiDuration.Milliseconds())
+			if ctx.Err() != nil {
+				break
+			}
+			intent, alertErr := e.prepareAlert(ctx, config, executionID, ruleEval, response.StatusCode, correlationID, apiDuration.Milliseconds(), matchedAt)
 			if alertErr != nil {
-				slog.Error("Failed to trigger alert",
+				slog.Error("Failed to prepare alert",
 					"correlation_id", correlationID,
 					"rule_name", ruleEval.RuleName,
 					"error", alertErr.Error(),
 				)
-			} else {
-				alertsTriggered = append(alertsTriggered, model.AlertTriggered{
-					AlertID:         alertID,
-					TriggeredByRule: ruleEval.RuleName,
-					WebhookURL:      config.Webhook.URL,
-				})
+				continue
 			}
+
+			alertIntents = append(alertIntents, intent)
+			alertsTriggered = append(alertsTriggered, model.AlertTriggered{
+				AlertID:         intent.AlertLog.ID,
+				TriggeredByRule: ruleEval.RuleName,
+				WebhookURL:      intent.Webhook.URL,
+				DeliveryStatus:  intent.AlertLog.FinalStatus,
+			})
 		}
 	} else {
 		// If API call failed, create empty evaluations
@@ -117,7 +343,12 @@ func (e *Executor) Execute(ctx context.Context, configID string, correlationID s
 
 	// Determine execution status
 	status := "success"
-	if err != nil {
+	if config.MaxExecutionSeconds > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		status = "timeout"
+		if response.Error == "" {
+			response.Error = fmt.Sprintf("Execution exceeded max_execution_seconds (%d)", config.MaxExecutionSeconds)
+		}
+	} else if callErr != nil {
 		status = "failed"
 	} else if len(rulesEvaluation) > 0 {
 		// Check if any rule evaluation had errors
@@ -135,25 +366,75 @@ func (e *Executor) Execute(ctx context.Context, configID string, correlationID s
 
 	// Build execution history
 	execution := &model.ExecutionHistory{
+		ID:              executionID,
 		CorrelationID:   correlationID,
 		ConfigID:        config.ID,
 		ConfigName:      config.Name,
-		ExecutedAt:      time.Now().UTC(),
+		BatchID:         opts.BatchID,
+		ExecutedAt:      e.clock.Now().UTC(),
 		DurationMs:      time.Since(start).Milliseconds(),
 		Request:         request,
 		Response:        response,
 		RulesEvaluation: rulesEvaluation,
 		AlertsTriggered: alertsTriggered,
 		Status:          status,
+		Confirmation:    confirmation,
+		Metadata:        e.metadata(opts),
 	}
+	if call != nil {
+		execution.TargetRetries = call.retries
+		execution.RequestAttempts = call.attempts
+	}
+
+	// Persist partial results even when the execution deadline has passed
+	ctx = context.WithoutCancel(ctx)
+
+	// Post hooks see the outcome, such as the values rules extracted, and are recorded with it
+	e.runPostHooks(ctx, config, hooks, execution, apiDuration)
 
-	// Save execution history
-	if err := e.executionRepo.Create(ctx, execution); err != nil {
+	// Keep storage in check on high-frequency checks; failures always keep their body
+	e.sampleBody(config, execution)
+
+	// Save execution history. Executions with alerts are inserted at once, since alert
+	// delivery updates them, and so are unscheduled ones, whose caller may have reused a
+	// correlation ID: either way the writer settles the final ID before this returns.
+	write := e.executionWriter.Write
+	if len(alertIntents) > 0 || opts.TriggerType != model.TriggerScheduled {
+		write = e.executionWriter.WriteNow
+	}
+	if err := write(ctx, execution); err != nil {
 		slog.Error("Failed to save execution history",
 			"correlation_id", correlationID,
 			"error", err.Error(),
 		)
 	}
+	if execution.CorrelationID != correlationID {
+		correlationID = execution.CorrelationID
+		for _, intent := range alertIntents {
+			intent.AlertLog.CorrelationID = correlationID
+			intent.Payload.Metadata["correlation_id"] = correlationID
+		}
+	}
+
+	// Maintain the rolling counters shown in list views and the check state
+	if !config.ID.IsZero() {
+		if err := e.healthCheckRepo.RecordRun(ctx, config.ID, execution.Healthy(), status, execution.ExecutedAt); err != nil {
+			slog.Error("Failed to record run counters",
+				"correlation_id", correlationID,
+				"error", err.Error(),
+			)
+		}
+		e.trackState(ctx, config, execution)
+		e.trackReachability(ctx, config, execution)
+	}
+
+	// Record values extracted by metric rules
+	if e.metrics != nil {
+		e.metrics.Record(ctx, config, rulesEvaluation, execution.Metadata.Region, execution.ExecutedAt)
+	}
+
+	// Hand alerts over to the alert queue once the execution is persisted
+	e.enqueueAlerts(ctx, alertIntents)
 
 	slog.Info("Health check execution completed",
 		"correlation_id", correlationID,
@@ -163,118 +444,382 @@ func (e *Executor) Execute(ctx context.Context, configID string, correlationID s
 		"alerts_triggered", len(alertsTriggered),
 	)
 
-	return execution, nil
+	return execution
 }
 
-// callTargetAPI makes an HTTP request to the target API
-func (e *Executor) callTargetAPI(ctx context.Context, target model.Target) (model.ExecutionRequest, model.ExecutionResponse, error) {
-	execRequest := model.ExecutionRequest{
-		URL:     target.URL,
-		Method:  target.Method,
-		Headers: make(map[string]string),
+// sampleBody drops the response body of a successful run unless it is the first of every
+// body_sample_rate runs. Rules, trends and change detection don't need stored bodies.
+func (e *Executor) sampleBody(config *model.HealthCheckConfig, execution *model.ExecutionHistory) {
+	rate := config.BodySampleRate
+	if rate == 0 {
+		rate = e.bodySampleRate
+	}
+	if rate <= 1 || !execution.Healthy() || (execution.Response.Body == "" && execution.Response.TransformedBody == "") {
+		return
 	}
 
-	execResponse := model.ExecutionResponse{
-		Headers: make(map[string]string),
+	var runs int64
+	if config.RunStats != nil {
+		runs = config.RunStats.TotalRuns
+	}
+	if runs%int64(rate) == 0 {
+		return
 	}
 
-	// Set timeout
-	timeout := time.Duration(target.Timeout) * time.Second
-	reqCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	execution.Response.Body = ""
+	execution.Response.TransformedBody = ""
+	execution.Response.BodyOmitted = true
+}
 
-	slog.Debug("Making API request",
-		"url", target.URL,
-		"method", target.Method,
-		"timeout_seconds", target.Timeout,
-	)
+// evaluable reports whether rules are evaluated against the response. Envelope
+// configs can assert on any status, others only on 2xx responses.
+func evaluable(config *model.HealthCheckConfig, response model.ExecutionResponse) bool {
+	if config.EvaluateEnvelope {
+		return response.StatusCode > 0
+	}
+	return response.StatusCode >= 200 && response.StatusCode < 300
+}
 
-	// Prepare request body
-	var bodyReader io.Reader
-	if target.Body != "" {
-		bodyReader = bytes.NewBufferString(target.Body)
-		execRequest.Body = target.Body
+// evaluate runs the config's rules against the response body or, when opted in, the synthesized envelope.
+// A configured transform is applied first and its result recorded on the response.
+func (e *Executor) evaluate(ctx context.Context, config *model.HealthCheckConfig, response *model.ExecutionResponse, apiDuration time.Duration) []model.RuleEvaluation {
+	evaluated := *response
+	if config.Transform != nil {
+		body, err := e.evaluator.Transform(ctx, config.Transform, response.Body)
+		if err != nil {
+			// Without the transformed body no rule can be evaluated
+			evaluations := make([]model.RuleEvaluation, len(config.Rules))
+			for i, rule := range config.Rules {
+				evaluations[i] = e.evaluator.FailedEvaluation(rule, "Transform failed: "+err.Error())
+			}
+			return evaluations
+		}
+		response.TransformedBody = body
+		evaluated.Body = body
+		evaluated.BodyNotJSON = false
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(reqCtx, target.Method, target.URL, bodyReader)
+	evaluations := e.evaluator.EvaluateResponse(ctx, config.Rules, evaluated, apiDuration, config.EvaluateEnvelope)
+	e.applyHistory(ctx, config, evaluations)
+	return evaluations
+}
+
+// applyHistory re-evaluates trend, anomaly and change rules against the values they extracted in recent executions
+func (e *Executor) applyHistory(ctx context.Context, config *model.HealthCheckConfig, evaluations []model.RuleEvaluation) {
+	for i, rule := range config.Rules {
+		if i >= len(evaluations) {
+			break
+		}
+
+		if rule.Operator == model.OperatorChanged {
+			previous, err := e.executionRepo.LastRuleFingerprint(ctx, config.ID, rule.Name)
+			if err != nil {
+				evaluations[i].Matched = false
+				evaluations[i].Error = err.Error()
+				continue
+			}
+			evaluations[i] = e.evaluator.EvaluateChange(evaluations[i], previous)
+			continue
+		}
+
+		var window time.Duration
+		var limit int
+		switch {
+		case rule.Trend != nil:
+			window, limit = rule.Trend.WindowDuration(), rule.Trend.LastRuns
+		case rule.Operator == "anomaly" && rule.Baseline != nil:
+			window, limit = rule.Baseline.WindowDuration(), rule.Baseline.LastRuns
+		default:
+			continue
+		}
+
+		var since time.Time
+		if window > 0 {
+			since = e.clock.Now().UTC().Add(-window)
+		}
+
+		previous, err := e.executionRepo.RuleValues(ctx, config.ID, rule.Name, since, limit)
+		if err != nil {
+			evaluations[i].Matched = false
+			evaluations[i].Error = err.Error()
+			continue
+		}
+
+		if rule.Trend != nil {
+			evaluations[i] = e.evaluator.EvaluateTrend(rule, evaluations[i], previous)
+		} else {
+			evaluations[i] = e.evaluator.EvaluateAnomaly(rule, evaluations[i], previous)
+		}
+	}
+}
+
+// confirmAlerts re-checks the target after a short delay and keeps only the alerts whose
+// rules match again. Alerts are kept when the re-check itself can't be evaluated.
+func (e *Executor) confirmAlerts(
+	ctx context.Context,
+	config *model.HealthCheckConfig,
+	matched []model.RuleEvaluation,
+	hooks *hookRun,
+	correlationID string,
+) ([]model.RuleEvaluation, *model.ConfirmationCheck) {
+	delay := defaultConfirmDelay
+	if config.ConfirmDelaySeconds > 0 {
+		delay = time.Duration(config.ConfirmDelaySeconds) * time.Second
+	}
+
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return matched, &model.ConfirmationCheck{Error: "Re-check cancelled: " + ctx.Err().Error()}
+	}
+
+	target, err := e.ResolveTarget(ctx, config)
+	if err == nil {
+		target, err = hooks.applyTarget(target)
+	}
 	if err != nil {
-		execResponse.Error = fmt.Sprintf("Failed to create request: %v", err)
-		return execRequest, execResponse, err
+		return matched, &model.ConfirmationCheck{Error: err.Error()}
 	}
 
-	// Set headers
-	for key, value := range target.Headers {
-		req.Header.Set(key, value)
-		execRequest.Headers[key] = value
+	start := time.Now()
+	_, response, err := e.callOnce(ctx, config, target, e.callOptions(config, correlationID))
+	confirmation := &model.ConfirmationCheck{
+		StatusCode:     response.StatusCode,
+		DurationMs:     time.Since(start).Milliseconds(),
+		Error:          response.Error,
+		ConfirmedRules: make([]string, 0),
+		DroppedRules:   make([]string, 0),
+	}
+	if err != nil || !evaluable(config, response) {
+		for _, eval := range matched {
+			confirmation.ConfirmedRules = append(confirmation.ConfirmedRules, eval.RuleName)
+		}
+		return matched, confirmation
 	}
 
-	// Set authentication
-	if err := e.setAuthentication(req, target.Auth); err != nil {
-		execResponse.Error = fmt.Sprintf("Failed to set authentication: %v", err)
-		return execRequest, execResponse, err
+	response.BodyNotJSON = evaluator.BodyNotJSON(response.Body)
+	rechecked := e.evaluate(ctx, config, &response, time.Duration(confirmation.DurationMs)*time.Millisecond)
+	stillMatched := make(map[string]bool)
+	for _, eval := range e.evaluator.GetMatchedRulesForAlert(rechecked, config.Rules) {
+		stillMatched[eval.RuleName] = true
 	}
 
-	// Make request
-	resp, err := e.httpClient.Do(req)
+	confirmed := make([]model.RuleEvaluation, 0, len(matched))
+	for _, eval := range matched {
+		if stillMatched[eval.RuleName] {
+			confirmed = append(confirmed, eval)
+			confirmation.ConfirmedRules = append(confirmation.ConfirmedRules, eval.RuleName)
+		} else {
+			confirmation.DroppedRules = append(confirmation.DroppedRules, eval.RuleName)
+		}
+	}
+
+	if len(confirmation.DroppedRules) > 0 {
+		slog.Info("Re-check did not confirm alerts",
+			"correlation_id", correlationID,
+			"config_name", config.Name,
+			"dropped_rules", confirmation.DroppedRules,
+		)
+	}
+
+	return confirmed, confirmation
+}
+
+// metadata builds the execution metadata describing where and how the execution ran
+func (e *Executor) metadata(opts ExecuteOptions) model.ExecutionMetadata {
+	region := opts.Region
+	if region == "" {
+		region = e.region
+	}
+
+	return model.ExecutionMetadata{
+		TriggeredBy: opts.TriggeredBy,
+		TriggerType: opts.TriggerType,
+		PodID:       e.podID,
+		Region:      region,
+		AgentID:     opts.AgentID,
+
+		RequestCorrelationID: opts.RequestCorrelationID,
+	}
+}
+
+// regionQuorumMet reports whether at least MinFailingRegions regions, including the
+// current one, have recently failed the check
+func (e *Executor) regionQuorumMet(ctx context.Context, config *model.HealthCheckConfig, region, correlationID string) bool {
+	if config.MinFailingRegions <= 1 || region == "" {
+		return true
+	}
+
+	latest, err := e.executionRepo.LatestByRegion(ctx, config.ID, e.clock.Now().UTC().Add(-regionQuorumWindow))
 	if err != nil {
-		execResponse.Error = fmt.Sprintf("Request failed: %v", err)
-		return execRequest, execResponse, err
+		// Prefer a possibly redundant alert over a missed one
+		slog.Warn("Failed to evaluate region quorum, alerting anyway",
+			"correlation_id", correlationID,
+			"config_name", config.Name,
+			"error", err.Error(),
+		)
+		return true
+	}
+
+	failing := 1 // The current region
+	for otherRegion, execution := range latest {
+		if otherRegion != region && !execution.Healthy() {
+			failing++
+		}
+	}
+
+	if failing < config.MinFailingRegions {
+		slog.Info("Region quorum not met, suppressing alerts",
+			"correlation_id", correlationID,
+			"config_name", config.Name,
+			"region", region,
+			"failing_regions", failing,
+			"min_failing_regions", config.MinFailingRegions,
+		)
+		return false
 	}
-	defer resp.Body.Close()
 
-	// Read response (limit to 1MB)
-	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	return true
+}
+
+// failingDependencies returns the parents of config that are currently failing
+func (e *Executor) failingDependencies(ctx context.Context, config *model.HealthCheckConfig, correlationID string, opts ExecuteOptions) []primitive.ObjectID {
+	deps := opts.Dependencies
+	if deps == nil {
+		deps = e.NewDependencyState()
+	}
+
+	failing, err := deps.FailingParents(ctx, config.DependsOn)
 	if err != nil {
-		execResponse.Error = fmt.Sprintf("Failed to read response: %v", err)
-		return execRequest, execResponse, err
+		// Run the check rather than silently skipping it
+		slog.Warn("Failed to evaluate dependencies, executing anyway",
+			"correlation_id", correlationID,
+			"config_name", config.Name,
+			"error", err.Error(),
+		)
+		return nil
 	}
 
-	// Capture response headers
-	for key := range resp.Header {
-		execResponse.Headers[key] = resp.Header.Get(key)
+	return failing
+}
+
+// recordBlocked persists a "blocked" execution without calling the target
+func (e *Executor) recordBlocked(
+	ctx context.Context,
+	config *model.HealthCheckConfig,
+	correlationID string,
+	opts ExecuteOptions,
+	blockedBy []primitive.ObjectID,
+	start time.Time,
+) *model.ExecutionHistory {
+	execution := &model.ExecutionHistory{
+		ID:              primitive.NewObjectID(),
+		CorrelationID:   correlationID,
+		ConfigID:        config.ID,
+		ConfigName:      config.Name,
+		BatchID:         opts.BatchID,
+		ExecutedAt:      e.clock.Now().UTC(),
+		DurationMs:      time.Since(start).Milliseconds(),
+		Request:         model.ExecutionRequest{URL: config.Target.URL, Method: config.Target.Method},
+		RulesEvaluation: make([]model.RuleEvaluation, 0),
+		AlertsTriggered: make([]model.AlertTriggered, 0),
+		Status:          "blocked",
+		BlockedBy:       blockedBy,
+		Metadata:        e.metadata(opts),
 	}
 
-	execResponse.StatusCode = resp.StatusCode
-	execResponse.Body = string(bodyBytes)
+	if err := e.executionWriter.Write(ctx, execution); err != nil {
+		slog.Error("Failed to save execution history",
+			"correlation_id", correlationID,
+			"error", err.Error(),
+		)
+	}
 
-	slog.Debug("API request completed",
-		"url", target.URL,
-		"status_code", resp.StatusCode,
-		"body_length", len(bodyBytes),
+	slog.Info("Health check blocked by failing dependencies",
+		"correlation_id", correlationID,
+		"config_name", config.Name,
+		"blocked_by", len(blockedBy),
 	)
 
-	return execRequest, execResponse, nil
+	return execution
 }
 
-// setAuthentication sets authentication headers on the request
-func (e *Executor) setAuthentication(req *http.Request, auth model.Auth) error {
-	switch strings.ToLower(auth.Type) {
-	case "basic":
-		req.SetBasicAuth(auth.Username, auth.Password)
-	case "bearer":
-		req.Header.Set("Authorization", "Bearer "+auth.Token)
-	case "none", "":
-		// No authentication
-	default:
-		return fmt.Errorf("unsupported auth type: %s", auth.Type)
-	}
-	return nil
+// RecordNotRun persists an execution that ended before the target was called, such as an
+// "interrupted", "skipped" or "error" scheduled run, with the reason in response.error
+func (e *Executor) RecordNotRun(ctx context.Context, config *model.HealthCheckConfig, correlationID string, opts ExecuteOptions, status, reason string, start time.Time) *model.ExecutionHistory {
+	request := model.ExecutionRequest{URL: config.Target.URL, Method: config.Target.Method}
+	return e.recordNotRun(ctx, config, correlationID, opts, request, status, reason, start)
 }
 
-// triggerAlert sends an alert webhook
-func (e *Executor) triggerAlert(
+// recordNotRun persists an execution without a target result; rules, alerts, run counters
+// and state are left alone
+func (e *Executor) recordNotRun(
 	ctx context.Context,
 	config *model.HealthCheckConfig,
+	correlationID string,
+	opts ExecuteOptions,
+	request model.ExecutionRequest,
+	status string,
+	reason string,
+	start time.Time,
+) *model.ExecutionHistory {
+	execution := &model.ExecutionHistory{
+		ID:              primitive.NewObjectID(),
+		CorrelationID:   correlationID,
+		ConfigID:        config.ID,
+		ConfigName:      config.Name,
+		BatchID:         opts.BatchID,
+		ExecutedAt:      e.clock.Now().UTC(),
+		DurationMs:      time.Since(start).Milliseconds(),
+		Request:         request,
+		Response:        model.ExecutionResponse{Error: reason},
+		RulesEvaluation: make([]model.RuleEvaluation, 0),
+		AlertsTriggered: make([]model.AlertTriggered, 0),
+		Status:          status,
+		Metadata:        e.metadata(opts),
+	}
+
+	if err := e.executionWriter.Write(context.WithoutCancel(ctx), execution); err != nil {
+		slog.Error("Failed to save execution history",
+			"correlation_id", correlationID,
+			"error", err.Error(),
+		)
+	}
+
+	slog.Warn("Health check execution ended without calling the target",
+		"correlation_id", correlationID,
+		"config_name", config.Name,
+		"status", status,
+		"reason", reason,
+	)
+
+	return execution
+}
+
+// prepareAlert formats the webhook payload and persists a pending alert log
+func (e *Executor) prepareAlert(
+	ctx context.Context,
+	config *model.HealthCheckConfig,
+	executionID primitive.ObjectID,
 	ruleEval model.RuleEvaluation,
 	statusCode int,
 	correlationID string,
 	responseTimeMs int64,
-) (primitive.ObjectID, error) {
+	matchedAt time.Time,
+) (AlertIntent, error) {
+	// Resolve profiles and the default webhook at alert time so changes apply immediately
+	destination, err := e.webhooks.Resolve(ctx, config.Webhook)
+	if err != nil {
+		return AlertIntent{}, err
+	}
+
+	severity := config.AlertSeverity(ruleEval, statusCode)
+
 	slog.Info("Triggering alert",
 		"correlation_id", correlationID,
 		"rule_name", ruleEval.RuleName,
-		"webhook_url", config.Webhook.URL,
+		"severity", severity,
+		"webhook_url", destination.URL,
 	)
 
 	// Format webhook payload
@@ -286,27 +831,39 @@ func (e *Executor) triggerAlert(
 		statusCode,
 		correlationID,
 		responseTimeMs,
+		severity,
 	)
 
-	// Send alert
-	alertLog, err := e.webhookDispatcher.SendAlert(ctx, config.Webhook, payload, correlationID)
-	if err != nil {
-		slog.Error("Failed to send alert",
-			"correlation_id", correlationID,
-			"error", err.Error(),
-		)
-	}
-
-	// Set execution ID and config ID
+	// Create pending alert log
+	alertLog := webhook.NewAlertLog(destination, payload, correlationID)
+	alertLog.ExecutionID = executionID
 	alertLog.ConfigID = config.ID
+	alertLog.RuleName = ruleEval.RuleName
+	alertLog.Tags = config.Metadata.Tags
+	alertLog.MatchedAt = matchedAt
 
 	// Save alert log
-	if saveErr := e.alertRepo.Create(ctx, alertLog); saveErr != nil {
-		slog.Error("Failed to save alert log",
-			"correlation_id", correlationID,
-			"error", saveErr.Error(),
-		)
+	if err := e.alertRepo.Create(ctx, alertLog); err != nil {
+		return AlertIntent{}, err
 	}
 
-	return alertLog.ID, err
+	return AlertIntent{
+		AlertLog: alertLog,
+		Webhook:  destination,
+		Payload:  payload,
+	}, nil
+}
+
+// enqueueAlerts submits prepared alerts to the alert queue
+func (e *Executor) enqueueAlerts(ctx context.Context, intents []AlertIntent) {
+	for _, intent := range intents {
+		if err := e.alertQueue.Enqueue(intent); err != nil {
+			slog.Error("Failed to enqueue alert",
+				"correlation_id", intent.AlertLog.CorrelationID,
+				"alert_id", intent.AlertLog.ID.Hex(),
+				"error", err.Error(),
+			)
+			e.alertQueue.MarkFailed(ctx, intent.AlertLog, err.Error())
+		}
+	}
 }
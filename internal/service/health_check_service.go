@@ -2,30 +2,66 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/evaluator"
 	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/probe"
+	"github.com/dandantas/raven/internal/webhook"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ErrAmbiguousName is returned when a name matches several health checks ignoring case and
+// none exactly
+var ErrAmbiguousName = errors.New("name matches several health checks ignoring case")
+
 // HealthCheckService handles health check configuration management
 type HealthCheckService struct {
-	repo *database.HealthCheckRepository
+	repo        *database.HealthCheckRepository
+	webhooks    *webhook.Resolver
+	credentials *probe.Credentials
+	transitions *database.StateTransitionRepository
+	audit       *database.AuditRepository
 }
 
 // NewHealthCheckService creates a new health check service
-func NewHealthCheckService(repo *database.HealthCheckRepository) *HealthCheckService {
+func NewHealthCheckService(repo *database.HealthCheckRepository, webhooks *webhook.Resolver) *HealthCheckService {
 	return &HealthCheckService{
-		repo: repo,
+		repo:     repo,
+		webhooks: webhooks,
 	}
 }
 
+// SetCredentials sets the resolver used to check auth profile references on save
+func (s *HealthCheckService) SetCredentials(credentials *probe.Credentials) {
+	s.credentials = credentials
+}
+
+// SetStateTransitions sets the repository state transition history is read from
+func (s *HealthCheckService) SetStateTransitions(transitions *database.StateTransitionRepository) {
+	s.transitions = transitions
+}
+
+// SetAudit sets the repository the health check audit log is kept in
+func (s *HealthCheckService) SetAudit(audit *database.AuditRepository) {
+	s.audit = audit
+}
+
 // Create creates a new health check configuration
 func (s *HealthCheckService) Create(ctx context.Context, config *model.HealthCheckConfig) error {
-	// Validate configuration
-	if err := config.Validate(); err != nil {
+	// Run counters and state are maintained by the executor only
+	config.RunStats = nil
+	config.State = model.CheckStateUnknown
+	config.StateSince = time.Time{}
+
+	if err := s.validate(ctx, config.ID, config); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -43,8 +79,63 @@ func (s *HealthCheckService) GetByID(ctx context.Context, id string) (*model.Hea
 	return s.repo.GetByID(ctx, objID)
 }
 
+// GetByName retrieves a health check configuration by name ignoring case, preferring the one
+// with the exact name when several differ only in case
+func (s *HealthCheckService) GetByName(ctx context.Context, name string) (*model.HealthCheckConfig, error) {
+	configs, err := s.repo.FindByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(configs) {
+	case 0:
+		return nil, database.ErrHealthCheckNotFound
+	case 1:
+		return &configs[0], nil
+	}
+
+	names := make([]string, len(configs))
+	for i := range configs {
+		if configs[i].Name == name {
+			return &configs[i], nil
+		}
+		names[i] = configs[i].Name
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAmbiguousName, strings.Join(names, ", "))
+}
+
+// SearchByName retrieves up to limit health checks whose name contains query ignoring case.
+// Exact matches come first, then names starting with query, then the rest, each by name.
+func (s *HealthCheckService) SearchByName(ctx context.Context, query string, limit int) ([]model.HealthCheckListItem, error) {
+	configs, err := s.repo.SearchByName(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := func(name string) int {
+		switch name = strings.ToLower(name); {
+		case name == strings.ToLower(query):
+			return 0
+		case strings.HasPrefix(name, strings.ToLower(query)):
+			return 1
+		default:
+			return 2
+		}
+	}
+	slices.SortStableFunc(configs, func(a, b model.HealthCheckConfig) int {
+		return rank(a.Name) - rank(b.Name)
+	})
+
+	items := make([]model.HealthCheckListItem, len(configs))
+	for i, config := range configs {
+		items[i] = config.ToListItem()
+	}
+
+	return items, nil
+}
+
 // List retrieves health check configurations with filtering
-func (s *HealthCheckService) List(ctx context.Context, enabled *bool, tags []string, page, limit int) ([]model.HealthCheckListItem, int64, error) {
+func (s *HealthCheckService) List(ctx context.Context, enabled *bool, tags []string, sortBy string, page, limit int) ([]model.HealthCheckListItem, int64, error) {
 	// Build filter
 	filter := bson.M{}
 	if enabled != nil {
@@ -54,8 +145,13 @@ func (s *HealthCheckService) List(ctx context.Context, enabled *bool, tags []str
 		filter["metadata.tags"] = bson.M{"$in": tags}
 	}
 
+	sort, err := database.ParseSort(sortBy, database.HealthCheckSortFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// Fetch from database
-	configs, total, err := s.repo.List(ctx, filter, page, limit)
+	configs, total, err := s.repo.List(ctx, filter, sort, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -76,14 +172,87 @@ func (s *HealthCheckService) Update(ctx context.Context, id string, config *mode
 		return fmt.Errorf("invalid ID format: %w", err)
 	}
 
-	// Validate configuration
-	if err := config.Validate(); err != nil {
+	existing, err := s.repo.GetByID(ctx, objID)
+	if err != nil {
+		return err
+	}
+
+	// Recompute the next run when the cadence changes; otherwise keep the current one
+	scheduleChanged := existing.Schedule != config.Schedule || (config.ScheduleEnabled && !existing.ScheduleEnabled)
+	if scheduleChanged {
+		config.NextScheduledRun = time.Time{}
+	} else if config.NextScheduledRun.IsZero() {
+		config.NextScheduledRun = existing.NextScheduledRun
+	}
+
+	// Trigger tokens and run counters survive configuration updates
+	config.TriggerNonce = existing.TriggerNonce
+	config.RunStats = existing.RunStats
+	config.State = existing.State
+	config.StateSince = existing.StateSince
+
+	// Re-enabling a check clears why it was disabled and starts a new unreachable streak
+	config.DisabledReason = ""
+	config.DisabledAt = time.Time{}
+	config.Unreachable = nil
+	if !config.Enabled || existing.Enabled {
+		config.DisabledReason = existing.DisabledReason
+		config.DisabledAt = existing.DisabledAt
+		config.Unreachable = existing.Unreachable
+	}
+
+	// Every update is a new version; scheduled runs fetched before it re-verify against it
+	config.Metadata.UpdatedAt = time.Now().UTC()
+
+	if err := s.validate(ctx, objID, config); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
 	return s.repo.Update(ctx, objID, config)
 }
 
+// validate runs every check a config must pass before it is saved under id
+func (s *HealthCheckService) validate(ctx context.Context, id primitive.ObjectID, config *model.HealthCheckConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	// Compile expressions now so runs reuse them and invalid ones are rejected
+	if err := evaluator.Precompile(config.Rules); err != nil {
+		return err
+	}
+
+	if err := s.validateDependencies(ctx, id, config.DependsOn); err != nil {
+		return err
+	}
+
+	if err := s.webhooks.Validate(ctx, config.Webhook); err != nil {
+		return err
+	}
+
+	if config.StateWebhook != nil {
+		if err := s.webhooks.Validate(ctx, *config.StateWebhook); err != nil {
+			return fmt.Errorf("state_webhook: %w", err)
+		}
+	}
+
+	if _, err := s.credentials.ResolveTarget(ctx, config.Target); err != nil {
+		return err
+	}
+	if config.Hooks != nil {
+		for _, hook := range slices.Concat(config.Hooks.Pre, config.Hooks.Post) {
+			if hook.Target == nil {
+				continue
+			}
+			if _, err := s.credentials.ResolveTarget(ctx, *hook.Target); err != nil {
+				return fmt.Errorf("hook %s: %w", hook.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Delete deletes a health check configuration
 func (s *HealthCheckService) Delete(ctx context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -93,3 +262,293 @@ func (s *HealthCheckService) Delete(ctx context.Context, id string) error {
 
 	return s.repo.Delete(ctx, objID)
 }
+
+// SchedulePreview computes the next count run times of a health check's schedule in the given location
+func (s *HealthCheckService) SchedulePreview(ctx context.Context, id string, count int, loc *time.Location) (*model.SchedulePreview, error) {
+	config, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Schedule == "" {
+		return nil, fmt.Errorf("health check has no schedule")
+	}
+
+	runs, err := model.NextRuns(config.Schedule, time.Now().In(loc), count)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	return &model.SchedulePreview{
+		ConfigID:        config.ID.Hex(),
+		Schedule:        config.Schedule,
+		ScheduleEnabled: config.ScheduleEnabled,
+		Timezone:        loc.String(),
+		NextRuns:        runs,
+	}, nil
+}
+
+// validateDependencies ensures every parent exists and that the dependency graph
+// stays acyclic when configID depends on parentIDs
+func (s *HealthCheckService) validateDependencies(ctx context.Context, configID primitive.ObjectID, parentIDs []primitive.ObjectID) error {
+	visited := make(map[primitive.ObjectID]bool)
+	pending := append([]primitive.ObjectID(nil), parentIDs...)
+	direct := len(parentIDs)
+
+	for i := 0; len(pending) > 0; i++ {
+		parentID := pending[0]
+		pending = pending[1:]
+
+		if !configID.IsZero() && parentID == configID {
+			return fmt.Errorf("dependency cycle detected through %s", parentID.Hex())
+		}
+		if visited[parentID] {
+			continue
+		}
+		visited[parentID] = true
+
+		parent, err := s.repo.GetByID(ctx, parentID)
+		if err != nil {
+			// Only direct parents must exist; stale transitive references are ignored
+			if i < direct {
+				return fmt.Errorf("dependency %s: %w", parentID.Hex(), err)
+			}
+			continue
+		}
+
+		pending = append(pending, parent.DependsOn...)
+	}
+
+	return nil
+}
+
+// ListTransitions retrieves the state transition history of a health check
+func (s *HealthCheckService) ListTransitions(ctx context.Context, id string, page, limit int) ([]model.StateTransition, int64, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	if _, err := s.repo.GetByID(ctx, objID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.transitions.List(ctx, bson.M{"config_id": objID}, page, limit)
+}
+
+// Enable re-enables a health check, e.g. one disabled after prolonged unreachability,
+// and records who did it in the audit log
+func (s *HealthCheckService) Enable(ctx context.Context, id, actor string) (*model.HealthCheckConfig, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	existing, err := s.repo.GetByID(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Enable(ctx, objID); err != nil {
+		return nil, err
+	}
+
+	if !existing.Enabled {
+		s.recordAudit(ctx, existing, model.AuditActionEnabled, actor)
+	}
+
+	return s.repo.GetByID(ctx, objID)
+}
+
+// recordAudit adds an entry to a health check's audit log; failures are only logged
+func (s *HealthCheckService) recordAudit(ctx context.Context, config *model.HealthCheckConfig, action, actor string) {
+	entry := &model.AuditEntry{
+		ConfigID:   config.ID,
+		ConfigName: config.Name,
+		Action:     action,
+		Actor:      actor,
+		At:         time.Now().UTC(),
+	}
+	if err := s.audit.Create(ctx, entry); err != nil {
+		slog.Error("Failed to record audit entry",
+			"config_name", config.Name,
+			"error", err.Error(),
+		)
+	}
+}
+
+// Bulk applies an action to each selected health check on its own, as a single atomic
+// update per check, so one failing check doesn't stop or roll back the others
+func (s *HealthCheckService) Bulk(ctx context.Context, request *model.BulkRequest, actor string) (*model.BulkResult, error) {
+	if err := request.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Each check is acted on once, even if listed twice
+	var ids []string
+	seen := make(map[string]bool, len(request.IDs))
+	for _, id := range request.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(request.Tags) > 0 {
+		matched, err := s.repo.FindIDsByTags(ctx, request.Tags, model.MaxBulkItems+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(matched) > model.MaxBulkItems {
+			return nil, fmt.Errorf("validation failed: tags match more than %d health checks", model.MaxBulkItems)
+		}
+		ids = make([]string, len(matched))
+		for i, id := range matched {
+			ids[i] = id.Hex()
+		}
+	}
+
+	result := &model.BulkResult{
+		Action:  request.Action,
+		Total:   len(ids),
+		Results: make([]model.BulkItemResult, 0, len(ids)),
+	}
+	for _, id := range ids {
+		item := s.bulkItem(ctx, request, id, actor)
+		switch item.Status {
+		case model.BulkItemDone:
+			result.Done++
+		case model.BulkItemUnchanged:
+			result.Unchanged++
+		default:
+			result.Failed++
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	slog.Info("Applied bulk action",
+		"action", request.Action,
+		"actor", actor,
+		"total", result.Total,
+		"done", result.Done,
+		"unchanged", result.Unchanged,
+		"failed", result.Failed,
+	)
+
+	return result, nil
+}
+
+// bulkItem applies a bulk action to one health check and reports the outcome
+func (s *HealthCheckService) bulkItem(ctx context.Context, request *model.BulkRequest, id, actor string) model.BulkItemResult {
+	item := model.BulkItemResult{ID: id, Status: model.BulkItemFailed}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		item.Error = "invalid ID format"
+		return item
+	}
+
+	existing, err := s.repo.GetByID(ctx, objID)
+	if errors.Is(err, database.ErrHealthCheckNotFound) {
+		item.Status = model.BulkItemNotFound
+		return item
+	}
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	item.Name = existing.Name
+
+	changed, err := s.applyBulkAction(ctx, request, existing, actor)
+	switch {
+	case err != nil:
+		item.Error = err.Error()
+	case changed:
+		item.Status = model.BulkItemDone
+	default:
+		item.Status = model.BulkItemUnchanged
+	}
+	return item
+}
+
+// applyBulkAction applies a bulk action to a health check, reporting whether it changed it
+func (s *HealthCheckService) applyBulkAction(ctx context.Context, request *model.BulkRequest, existing *model.HealthCheckConfig, actor string) (bool, error) {
+	switch request.Action {
+	case model.BulkActionDelete:
+		if err := s.repo.Delete(ctx, existing.ID); err != nil {
+			return false, err
+		}
+		return true, nil
+	case model.BulkActionEnable:
+		if existing.Enabled {
+			return false, nil
+		}
+		if _, err := s.Enable(ctx, existing.ID.Hex(), actor); err != nil {
+			return false, err
+		}
+		return true, nil
+	case model.BulkActionDisable:
+		disabled, err := s.repo.Disable(ctx, existing.ID)
+		if err != nil || !disabled {
+			return false, err
+		}
+		s.recordAudit(ctx, existing, model.AuditActionDisabled, actor)
+		return true, nil
+	case model.BulkActionSetSchedule:
+		return s.setSchedule(ctx, existing, request.Schedule, request.ScheduleEnabled)
+	}
+	return false, fmt.Errorf("invalid action: %s", request.Action)
+}
+
+// setSchedule changes a health check's schedule and whether it is enabled, keeping the current
+// value of whichever is not given. As with updates, the next run restarts when the cadence
+// changes. A concurrent update of the check fails the change instead of being overwritten.
+func (s *HealthCheckService) setSchedule(ctx context.Context, existing *model.HealthCheckConfig, schedule string, enabled *bool) (bool, error) {
+	newSchedule := existing.Schedule
+	if schedule != "" {
+		newSchedule = schedule
+	}
+	newEnabled := existing.ScheduleEnabled
+	if enabled != nil {
+		newEnabled = *enabled
+	}
+
+	if newSchedule == existing.Schedule && newEnabled == existing.ScheduleEnabled {
+		return false, nil
+	}
+	if newEnabled && newSchedule == "" {
+		return false, errors.New("schedule is required when schedule_enabled is true")
+	}
+
+	now := time.Now().UTC()
+	var nextRun time.Time
+	if newEnabled && (newSchedule != existing.Schedule || !existing.ScheduleEnabled) {
+		parsed, err := model.ParseSchedule(newSchedule)
+		if err != nil {
+			return false, fmt.Errorf("invalid cron expression: %w", err)
+		}
+		nextRun = parsed.Next(now)
+	}
+
+	updated, err := s.repo.SetSchedule(ctx, existing.ID, existing.Metadata.UpdatedAt, newSchedule, newEnabled, nextRun, now)
+	if err != nil {
+		return false, err
+	}
+	if !updated {
+		return false, errors.New("health check was changed or deleted concurrently; retry")
+	}
+	return true, nil
+}
+
+// ListAudit retrieves the audit log of a health check
+func (s *HealthCheckService) ListAudit(ctx context.Context, id string, page, limit int) ([]model.AuditEntry, int64, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	if _, err := s.repo.GetByID(ctx, objID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.audit.List(ctx, bson.M{"config_id": objID}, page, limit)
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/webhook"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HeartbeatService handles heartbeat monitors and alerts on missed pings
+type HeartbeatService struct {
+	repo       *database.HeartbeatRepository
+	alertRepo  *database.AlertRepository
+	alertQueue *AlertQueue
+	webhooks   *webhook.Resolver
+}
+
+// NewHeartbeatService creates a new heartbeat service
+func NewHeartbeatService(
+	repo *database.HeartbeatRepository,
+	alertRepo *database.AlertRepository,
+	alertQueue *AlertQueue,
+	webhooks *webhook.Resolver,
+) *HeartbeatService {
+	return &HeartbeatService{
+		repo:       repo,
+		alertRepo:  alertRepo,
+		alertQueue: alertQueue,
+		webhooks:   webhooks,
+	}
+}
+
+// Create creates a new heartbeat; it starts in the "new" state until the first ping
+func (s *HeartbeatService) Create(ctx context.Context, heartbeat *model.Heartbeat) error {
+	heartbeat.Status = model.HeartbeatStatusNew
+	heartbeat.LastPingAt = time.Time{}
+	heartbeat.ExpectedBy = time.Time{}
+	heartbeat.LastAlertAt = time.Time{}
+
+	if err := heartbeat.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.webhooks.Validate(ctx, heartbeatWebhook(heartbeat)); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	return s.repo.Create(ctx, heartbeat)
+}
+
+// GetByID retrieves a heartbeat by ID
+func (s *HeartbeatService) GetByID(ctx context.Context, id string) (*model.Heartbeat, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	return s.repo.GetByID(ctx, objID)
+}
+
+// List retrieves heartbeats, optionally filtered by enabled flag and status
+func (s *HeartbeatService) List(ctx context.Context, enabled *bool, status string, page, limit int) ([]model.Heartbeat, int64, error) {
+	filter := bson.M{}
+	if enabled != nil {
+		filter["enabled"] = *enabled
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	return s.repo.List(ctx, filter, page, limit)
+}
+
+// Update updates an existing heartbeat, keeping its ping state
+func (s *HeartbeatService) Update(ctx context.Context, id string, heartbeat *model.Heartbeat) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	existing, err := s.repo.GetByID(ctx, objID)
+	if err != nil {
+		return err
+	}
+
+	// Ping state is owned by the server; the deadline follows the new interval
+	heartbeat.Status = existing.Status
+	heartbeat.LastPingAt = existing.LastPingAt
+	heartbeat.LastAlertAt = existing.LastAlertAt
+	heartbeat.Metadata.CreatedAt = existing.Metadata.CreatedAt
+
+	if err := heartbeat.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.webhooks.Validate(ctx, heartbeatWebhook(heartbeat)); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	heartbeat.ExpectedBy = time.Time{}
+	if !heartbeat.LastPingAt.IsZero() {
+		heartbeat.ExpectedBy = heartbeat.LastPingAt.Add(heartbeat.Period())
+	}
+
+	return s.repo.Update(ctx, objID, heartbeat)
+}
+
+// Delete deletes a heartbeat
+func (s *HeartbeatService) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	return s.repo.Delete(ctx, objID)
+}
+
+// Ping records a ping from the monitored job and announces recovery if it was down
+func (s *HeartbeatService) Ping(ctx context.Context, id string) (*model.Heartbeat, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	now := time.Now().UTC()
+	previous, err := s.repo.Ping(ctx, objID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	heartbeat := *previous
+	heartbeat.Status = model.HeartbeatStatusUp
+	heartbeat.LastPingAt = now
+	heartbeat.ExpectedBy = now.Add(heartbeat.Period())
+
+	if previous.Status == model.HeartbeatStatusDown && previous.Enabled {
+		slog.Info("Heartbeat recovered",
+			"heartbeat_id", heartbeat.ID.Hex(),
+			"heartbeat_name", heartbeat.Name,
+		)
+		if _, err := s.sendAlert(ctx, &heartbeat, true); err != nil {
+			slog.Error("Failed to send heartbeat recovery alert",
+				"heartbeat_id", heartbeat.ID.Hex(),
+				"error", err,
+			)
+		}
+	}
+
+	return &heartbeat, nil
+}
+
+// CheckOverdue marks heartbeats whose deadline has passed as down and alerts on each.
+// Heartbeats are claimed atomically, so concurrent pods never alert twice.
+func (s *HeartbeatService) CheckOverdue(ctx context.Context, now time.Time) {
+	heartbeats, err := s.repo.FindOverdue(ctx, now)
+	if err != nil {
+		slog.Error("Failed to find overdue heartbeats", "error", err)
+		return
+	}
+
+	for i := range heartbeats {
+		heartbeat := &heartbeats[i]
+
+		claimed, err := s.repo.MarkDown(ctx, heartbeat.ID, heartbeat.ExpectedBy, now)
+		if err != nil {
+			slog.Error("Failed to mark heartbeat down",
+				"heartbeat_id", heartbeat.ID.Hex(),
+				"error", err,
+			)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		heartbeat.Status = model.HeartbeatStatusDown
+		heartbeat.LastAlertAt = now
+
+		slog.Warn("Heartbeat missed",
+			"heartbeat_id", heartbeat.ID.Hex(),
+			"heartbeat_name", heartbeat.Name,
+			"last_ping_at", heartbeat.LastPingAt,
+			"expected_by", heartbeat.ExpectedBy,
+		)
+
+		if _, err := s.sendAlert(ctx, heartbeat, false); err != nil {
+			slog.Error("Failed to send heartbeat alert",
+				"heartbeat_id", heartbeat.ID.Hex(),
+				"error", err,
+			)
+		}
+	}
+}
+
+// sendAlert persists and enqueues a missed or recovered heartbeat alert
+func (s *HeartbeatService) sendAlert(ctx context.Context, heartbeat *model.Heartbeat, recovered bool) (primitive.ObjectID, error) {
+	destination, err := s.webhooks.Resolve(ctx, heartbeatWebhook(heartbeat))
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	correlationID := uuid.New().String()
+	payload := webhook.FormatHeartbeatPayload(heartbeat, correlationID, recovered)
+
+	alertLog := webhook.NewAlertLog(destination, payload, correlationID)
+	alertLog.HeartbeatID = heartbeat.ID
+	alertLog.Tags = heartbeat.Metadata.Tags
+
+	if err := s.alertRepo.Create(ctx, alertLog); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	intent := AlertIntent{
+		AlertLog: alertLog,
+		Webhook:  destination,
+		Payload:  payload,
+	}
+	if err := s.alertQueue.Enqueue(intent); err != nil {
+		s.alertQueue.MarkFailed(ctx, alertLog, err.Error())
+		return alertLog.ID, err
+	}
+
+	return alertLog.ID, nil
+}
+
+// heartbeatWebhook returns the configured alert webhook, or an empty one to select the default
+func heartbeatWebhook(heartbeat *model.Heartbeat) model.Webhook {
+	if heartbeat.Webhook == nil {
+		return model.Webhook{}
+	}
+	return *heartbeat.Webhook
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/evaluator"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/probe"
+	"github.com/oliveagle/jsonpath"
+)
+
+// hookRun holds the variables and results of an execution's hooks. A nil run belongs to a
+// config without hooks, whose target is used as-is.
+type hookRun struct {
+	variables map[string]interface{} // Hook name -> variables it set
+	results   []model.HookResult
+}
+
+// runPreHooks runs the config's pre hooks in order. It stops at the first failing hook that
+// doesn't continue on error and returns its error.
+func (e *Executor) runPreHooks(ctx context.Context, config *model.HealthCheckConfig, correlationID string) (*hookRun, error) {
+	if config.Hooks == nil {
+		return nil, nil
+	}
+
+	run := &hookRun{variables: make(map[string]interface{})}
+	scope := run.scope(nil, nil, nil)
+	for _, hook := range config.Hooks.Pre {
+		if err := e.runHook(ctx, config, hook, model.HookPhasePre, run, scope, correlationID); err != nil && !hook.ContinueOnError {
+			return run, fmt.Errorf("pre hook %s failed: %w", hook.Name, err)
+		}
+	}
+	return run, nil
+}
+
+// runPostHooks runs the config's post hooks with the execution's outcome and records every
+// hook on the execution. Post hook failures don't change the execution status.
+func (e *Executor) runPostHooks(ctx context.Context, config *model.HealthCheckConfig, run *hookRun, execution *model.ExecutionHistory, apiDuration time.Duration) {
+	if run == nil {
+		return
+	}
+
+	outcome := map[string]interface{}{
+		"status":         execution.Status,
+		"status_code":    execution.Response.StatusCode,
+		"error":          execution.Response.Error,
+		"duration_ms":    execution.DurationMs,
+		"correlation_id": execution.CorrelationID,
+		"config_id":      execution.ConfigID.Hex(),
+		"config_name":    execution.ConfigName,
+		"alerts":         len(execution.AlertsTriggered),
+	}
+	rules := make(map[string]interface{}, len(execution.RulesEvaluation))
+	for _, eval := range execution.RulesEvaluation {
+		if eval.Error == "" {
+			rules[eval.RuleName] = eval.ExtractedValue
+		}
+	}
+	var response map[string]interface{}
+	if execution.Response.StatusCode > 0 {
+		response = evaluator.NewEnvelope(execution.Response, apiDuration)
+	}
+
+	scope := run.scope(outcome, rules, response)
+	for _, hook := range config.Hooks.Post {
+		e.runHook(ctx, config, hook, model.HookPhasePost, run, scope, execution.CorrelationID)
+	}
+	execution.Hooks = run.results
+}
+
+// scope returns the variables hooks can reference, by namespace
+func (r *hookRun) scope(outcome, rules, response map[string]interface{}) map[string]interface{} {
+	if outcome == nil {
+		outcome = map[string]interface{}{}
+	}
+	if rules == nil {
+		rules = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		model.HookNamespaceHooks:     r.variables,
+		model.HookNamespaceExecution: outcome,
+		model.HookNamespaceRules:     rules,
+		"response":                   response,
+	}
+}
+
+// runHook runs a hook and records its result, keeping its variables for the hooks after it
+func (e *Executor) runHook(ctx context.Context, config *model.HealthCheckConfig, hook model.Hook, phase string, run *hookRun, scope map[string]interface{}, correlationID string) error {
+	start := time.Now()
+	variables, statusCode, err := e.callHook(ctx, config, hook, scope, correlationID)
+
+	result := model.HookResult{
+		Name:       hook.Name,
+		Phase:      phase,
+		StatusCode: statusCode,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+		slog.Warn("Hook failed",
+			"correlation_id", correlationID,
+			"config_name", config.Name,
+			"hook", hook.Name,
+			"phase", phase,
+			"error", err.Error(),
+		)
+	} else {
+		run.variables[hook.Name] = variables
+		result.Variables = slices.Sorted(maps.Keys(variables))
+	}
+	run.results = append(run.results, result)
+	return err
+}
+
+// callHook runs the hook's script or makes its call and returns the variables it set
+func (e *Executor) callHook(ctx context.Context, config *model.HealthCheckConfig, hook model.Hook, scope map[string]interface{}, correlationID string) (map[string]interface{}, int, error) {
+	if hook.Script != "" {
+		variables, err := evaluator.RunHookScript(ctx, hook.Name, hook.Script, scope)
+		return variables, 0, err
+	}
+
+	target, err := e.credentials.ResolveTarget(ctx, *hook.Target)
+	if err != nil {
+		return nil, 0, err
+	}
+	if target, err = applyVariables(target, scope); err != nil {
+		return nil, 0, err
+	}
+
+	_, response, err := probe.Call(ctx, e.httpClient, target, e.callOptions(config, correlationID))
+	if err != nil {
+		return nil, response.StatusCode, err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, response.StatusCode, fmt.Errorf("hook returned status %d", response.StatusCode)
+	}
+
+	variables := make(map[string]interface{}, len(hook.Extract))
+	if len(hook.Extract) == 0 {
+		return variables, response.StatusCode, nil
+	}
+	var document interface{}
+	if err := json.Unmarshal([]byte(response.Body), &document); err != nil {
+		return nil, response.StatusCode, fmt.Errorf("hook response is not valid JSON: %w", err)
+	}
+	for variable, expression := range hook.Extract {
+		value, err := jsonpath.JsonPathLookup(document, expression)
+		if err != nil {
+			return nil, response.StatusCode, fmt.Errorf("failed to extract %s: %w", variable, err)
+		}
+		variables[variable] = value
+	}
+	return variables, response.StatusCode, nil
+}
+
+// applyTarget fills the placeholders of the check's target with the pre hooks' variables
+func (r *hookRun) applyTarget(target model.Target) (model.Target, error) {
+	if r == nil {
+		return target, nil
+	}
+	return applyVariables(target, r.scope(nil, nil, nil))
+}
+
+// redact puts the placeholders back into a recorded request, so variable values such as
+// minted tokens aren't stored in the execution history
+func (r *hookRun) redact(request model.ExecutionRequest, target model.Target) model.ExecutionRequest {
+	if r == nil {
+		return request
+	}
+	if model.HookVariablePattern.MatchString(target.URL) {
+		request.URL = target.URL
+	}
+	if model.HookVariablePattern.MatchString(target.Body) {
+		request.Body = target.Body
+	}
+	for _, field := range target.FormFields {
+		if model.HookVariablePattern.MatchString(field.Value) {
+			request.Body = "[redacted: form fields use hook variables]"
+			break
+		}
+	}
+	for key, value := range target.Headers {
+		if _, recorded := request.Headers[key]; recorded && model.HookVariablePattern.MatchString(value) {
+			request.Headers[key] = value
+		}
+	}
+	return request
+}
+
+// applyVariables returns the target with the placeholders in its URL, headers and body replaced
+func applyVariables(target model.Target, scope map[string]interface{}) (model.Target, error) {
+	var err error
+	if target.URL, err = substituteVariables(target.URL, scope); err != nil {
+		return target, err
+	}
+	if target.Body, err = substituteVariables(target.Body, scope); err != nil {
+		return target, err
+	}
+
+	headers := make(map[string]string, len(target.Headers))
+	for key, value := range target.Headers {
+		if headers[key], err = substituteVariables(value, scope); err != nil {
+			return target, err
+		}
+	}
+	target.Headers = headers
+
+	fields := slices.Clone(target.FormFields)
+	for i := range fields {
+		if fields[i].Value, err = substituteVariables(fields[i].Value, scope); err != nil {
+			return target, err
+		}
+	}
+	target.FormFields = fields
+
+	return target, nil
+}
+
+// substituteVariables replaces the {{namespace.path}} placeholders in template
+func substituteVariables(template string, scope map[string]interface{}) (string, error) {
+	var missing error
+	result := model.HookVariablePattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := model.HookVariablePattern.FindStringSubmatch(placeholder)[1]
+		value, ok := lookupVariable(scope, name)
+		if !ok {
+			if missing == nil {
+				missing = fmt.Errorf("variable %s is not set", placeholder)
+			}
+			return placeholder
+		}
+		return formatVariable(value)
+	})
+	return result, missing
+}
+
+// lookupVariable finds a variable by its dotted name; hook variables are nested one level deeper
+func lookupVariable(scope map[string]interface{}, name string) (interface{}, bool) {
+	namespace, path, _ := strings.Cut(name, ".")
+	values, ok := scope[namespace].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if namespace == model.HookNamespaceHooks {
+		var hook string
+		hook, path, _ = strings.Cut(path, ".")
+		if values, ok = values[hook].(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	value, ok := values[path]
+	return value, ok
+}
+
+// formatVariable formats a variable for a placeholder, with structured values as JSON
+func formatVariable(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool, int, int64:
+		return fmt.Sprint(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/dandantas/raven/internal/importer"
+	"github.com/dandantas/raven/internal/model"
+)
+
+// Import generates disabled draft health checks for the selected operations of an OpenAPI
+// spec or Postman collection, saving them when requested. Drafts that fail to save are
+// reported without failing the import.
+func (s *HealthCheckService) Import(ctx context.Context, req *model.ImportRequest) (*model.ImportResult, error) {
+	if len(req.Spec) == 0 {
+		return nil, errors.New("invalid request: spec is required")
+	}
+
+	format := req.Format
+	if format == "" {
+		format = importer.DetectFormat(req.Spec)
+	}
+
+	operations, err := importer.Parse(format, req.Spec, req.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, unmatched := importer.Select(operations, req.Operations)
+
+	result := &model.ImportResult{
+		Format:    format,
+		Drafts:    make([]*model.HealthCheckConfig, 0, len(selected)),
+		Unmatched: unmatched,
+	}
+	for _, op := range selected {
+		draft := op.Draft(req.NamePrefix, req.Tags)
+		draft.Webhook = req.Webhook
+		result.Drafts = append(result.Drafts, draft)
+	}
+
+	if !req.Create {
+		return result, nil
+	}
+
+	for _, draft := range result.Drafts {
+		if err := s.Create(ctx, draft); err != nil {
+			result.Errors = append(result.Errors, model.ImportError{Name: draft.Name, Error: err.Error()})
+			continue
+		}
+		result.Created++
+	}
+
+	slog.Info("Imported health checks",
+		"format", format,
+		"drafts", len(result.Drafts),
+		"created", result.Created,
+	)
+
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// lintMinScheduleInterval is the shortest schedule interval that is not flagged as aggressive
+const lintMinScheduleInterval = 5 * time.Minute
+
+// lintScheduleSamples is the number of upcoming runs checked for the shortest interval
+const lintScheduleSamples = 10
+
+// Lint validates a health check configuration without saving it and reports best-practice
+// warnings alongside any validation error
+func (s *HealthCheckService) Lint(ctx context.Context, config *model.HealthCheckConfig) *model.LintResult {
+	// Warnings look at the config as submitted, before validation fills in defaults
+	findings := lintWarnings(config)
+	if destination, err := s.webhooks.Resolve(ctx, config.Webhook); err == nil && destination.RetryConfig.MaxAttempts == 1 {
+		findings = append(findings, model.LintFinding{
+			Level:   model.LintLevelWarning,
+			Code:    "webhook_without_retries",
+			Field:   "webhook.retry_config.max_attempts",
+			Message: "alerts are delivered once and lost if the webhook is briefly unavailable",
+		})
+	}
+
+	result := &model.LintResult{Valid: true}
+	if err := s.validate(ctx, config.ID, config); err != nil {
+		result.Valid = false
+		result.Findings = append(result.Findings, model.LintFinding{
+			Level:   model.LintLevelError,
+			Code:    "invalid_config",
+			Message: err.Error(),
+		})
+	}
+	result.Findings = append(result.Findings, findings...)
+	if result.Findings == nil {
+		result.Findings = []model.LintFinding{}
+	}
+
+	return result
+}
+
+// lintWarnings reports the best-practice warnings that only depend on the config itself
+func lintWarnings(config *model.HealthCheckConfig) []model.LintFinding {
+	var findings []model.LintFinding
+
+	alerting := false
+	for _, rule := range config.Rules {
+		if rule.AlertOnMatch {
+			alerting = true
+			break
+		}
+	}
+	if len(config.Rules) > 0 && !alerting {
+		findings = append(findings, model.LintFinding{
+			Level:   model.LintLevelWarning,
+			Code:    "no_alerting_rule",
+			Field:   "rules",
+			Message: "no rule has alert_on_match set, so the check never alerts",
+		})
+	}
+
+	if config.Target.Timeout == 0 {
+		findings = append(findings, model.LintFinding{
+			Level:   model.LintLevelWarning,
+			Code:    "missing_timeout",
+			Field:   "target.timeout",
+			Message: "no target timeout is set; the 30 second default applies",
+		})
+	}
+
+	if config.ScheduleEnabled && strings.TrimSpace(config.Schedule) != "" {
+		if interval, ok := shortestScheduleInterval(config.Schedule); ok && interval < lintMinScheduleInterval {
+			findings = append(findings, model.LintFinding{
+				Level:   model.LintLevelWarning,
+				Code:    "aggressive_schedule",
+				Field:   "schedule",
+				Message: fmt.Sprintf("schedule runs as often as every %s; consider %s or more, or set max_executions_per_day", interval, lintMinScheduleInterval),
+			})
+		}
+	}
+
+	return findings
+}
+
+// shortestScheduleInterval returns the shortest gap between the upcoming runs of a schedule
+func shortestScheduleInterval(schedule string) (time.Duration, bool) {
+	runs, err := model.NextRuns(schedule, time.Now().UTC(), lintScheduleSamples)
+	if err != nil || len(runs) < 2 {
+		return 0, false
+	}
+
+	shortest := runs[1].Sub(runs[0])
+	for i := 2; i < len(runs); i++ {
+		if gap := runs[i].Sub(runs[i-1]); gap < shortest {
+			shortest = gap
+		}
+	}
+	return shortest, true
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/evaluator"
+	"github.com/dandantas/raven/internal/metrics"
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// remoteWriteTimeout bounds a single Prometheus remote-write request
+const remoteWriteTimeout = 10 * time.Second
+
+// MetricService records values extracted by metric rules and serves their time series
+type MetricService struct {
+	repo   *database.MetricRepository
+	remote *metrics.RemoteWriter
+	gauges *metrics.Gauges
+}
+
+// NewMetricService creates a new metric service; remote may be nil to disable remote-write
+func NewMetricService(repo *database.MetricRepository, remote *metrics.RemoteWriter, gauges *metrics.Gauges) *MetricService {
+	return &MetricService{
+		repo:   repo,
+		remote: remote,
+		gauges: gauges,
+	}
+}
+
+// Record stores the numeric values extracted by the config's metric rules
+func (s *MetricService) Record(ctx context.Context, config *model.HealthCheckConfig, evaluations []model.RuleEvaluation, region string, at time.Time) {
+	samples := MetricSamples(config, evaluations, region, at)
+	if len(samples) == 0 {
+		return
+	}
+
+	// Publish the latest values for Prometheus scraping
+	s.gauges.Set(samples)
+
+	if err := s.repo.Insert(ctx, samples); err != nil {
+		slog.Error("Failed to store metric samples",
+			"config_id", config.ID.Hex(),
+			"error", err,
+		)
+	}
+
+	if s.remote != nil {
+		go func() {
+			writeCtx, cancel := context.WithTimeout(context.Background(), remoteWriteTimeout)
+			defer cancel()
+
+			if err := s.remote.Write(writeCtx, samples); err != nil {
+				slog.Warn("Failed to remote-write metric samples",
+					"config_id", config.ID.Hex(),
+					"error", err,
+				)
+			}
+		}()
+	}
+}
+
+// Series retrieves the values of one metric of a health check over the window
+func (s *MetricService) Series(ctx context.Context, id, name string, window time.Duration) (*model.MetricSeries, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("invalid metric: name is required")
+	}
+
+	points, err := s.repo.Series(ctx, objID, name, time.Now().UTC().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MetricSeries{
+		ConfigID: id,
+		Name:     name,
+		Window:   window.String(),
+		Points:   points,
+	}, nil
+}
+
+// MetricSamples builds samples from the numeric values extracted by the config's metric rules
+func MetricSamples(config *model.HealthCheckConfig, evaluations []model.RuleEvaluation, region string, at time.Time) []model.MetricSample {
+	metricRules := make(map[string]bool)
+	for _, rule := range config.Rules {
+		if rule.Metric {
+			metricRules[rule.Name] = true
+		}
+	}
+	if len(metricRules) == 0 {
+		return nil
+	}
+
+	samples := make([]model.MetricSample, 0, len(metricRules))
+	for _, eval := range evaluations {
+		if !metricRules[eval.RuleName] || eval.ExtractedValue == nil {
+			continue
+		}
+
+		value, err := evaluator.CoerceToNumber(eval.ExtractedValue)
+		if err != nil {
+			continue
+		}
+
+		samples = append(samples, model.MetricSample{
+			Timestamp: at,
+			Meta: model.MetricMeta{
+				ConfigID:   config.ID,
+				ConfigName: config.Name,
+				Name:       eval.RuleName,
+				Region:     region,
+			},
+			Value: value,
+		})
+	}
+
+	return samples
+}
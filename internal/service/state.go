@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/webhook"
+)
+
+// SetStateTransitions sets the repository state transition events are recorded in
+func (e *Executor) SetStateTransitions(transitions *database.StateTransitionRepository) {
+	e.transitions = transitions
+}
+
+// SetDowntimes sets the repository downtime intervals are recorded in
+func (e *Executor) SetDowntimes(downtimes *database.DowntimeRepository) {
+	e.downtimes = downtimes
+}
+
+// trackState derives the check's state from an execution and, when it changed,
+// records the transition and notifies it if the config subscribed to that state
+func (e *Executor) trackState(ctx context.Context, config *model.HealthCheckConfig, execution *model.ExecutionHistory) {
+	state := config.DeriveState(execution)
+
+	previous, err := e.healthCheckRepo.TransitionState(ctx, config.ID, state, execution.ExecutedAt)
+	if err != nil {
+		slog.Error("Failed to update check state",
+			"correlation_id", execution.CorrelationID,
+			"error", err.Error(),
+		)
+		return
+	}
+	if previous == nil {
+		return
+	}
+
+	transition := &model.StateTransition{
+		ConfigID:      config.ID,
+		ConfigName:    config.Name,
+		From:          previous.State,
+		To:            state,
+		CorrelationID: execution.CorrelationID,
+		At:            execution.ExecutedAt,
+	}
+	if transition.From == "" {
+		transition.From = model.CheckStateUnknown
+	}
+	if !previous.StateSince.IsZero() {
+		transition.DurationMs = execution.ExecutedAt.Sub(previous.StateSince).Milliseconds()
+	}
+
+	slog.Info("Check state changed",
+		"correlation_id", execution.CorrelationID,
+		"config_name", config.Name,
+		"from", transition.From,
+		"to", transition.To,
+	)
+
+	if e.transitions != nil {
+		if err := e.transitions.Create(ctx, transition); err != nil {
+			slog.Error("Failed to record state transition",
+				"correlation_id", execution.CorrelationID,
+				"error", err.Error(),
+			)
+		}
+	}
+
+	e.recordDowntime(ctx, config, transition)
+
+	// The first execution only establishes the state
+	if config.NotifiesState(transition.To) && transition.From != model.CheckStateUnknown {
+		e.notifyStateChange(ctx, config, execution, transition)
+	}
+}
+
+// recordDowntime closes the open downtime when a check leaves the down state and opens one when it enters it
+func (e *Executor) recordDowntime(ctx context.Context, config *model.HealthCheckConfig, transition *model.StateTransition) {
+	if e.downtimes == nil {
+		return
+	}
+
+	if transition.From == model.CheckStateDown {
+		if err := e.downtimes.Close(ctx, config.ID, transition.At, transition.CorrelationID); err != nil {
+			slog.Error("Failed to close downtime",
+				"correlation_id", transition.CorrelationID,
+				"error", err.Error(),
+			)
+		}
+	}
+
+	if transition.To == model.CheckStateDown {
+		downtime := &model.Downtime{
+			ConfigID:           config.ID,
+			ConfigName:         config.Name,
+			StartedAt:          transition.At,
+			StartCorrelationID: transition.CorrelationID,
+		}
+		if err := e.downtimes.Open(ctx, downtime); err != nil {
+			slog.Error("Failed to open downtime",
+				"correlation_id", transition.CorrelationID,
+				"error", err.Error(),
+			)
+		}
+	}
+}
+
+// notifyStateChange sends a state transition through the alert queue, to the state webhook
+// when one is set and to the config webhook otherwise
+func (e *Executor) notifyStateChange(ctx context.Context, config *model.HealthCheckConfig, execution *model.ExecutionHistory, transition *model.StateTransition) {
+	target := config.Webhook
+	if config.StateWebhook != nil {
+		target = *config.StateWebhook
+	}
+
+	destination, err := e.webhooks.Resolve(ctx, target)
+	if err != nil {
+		slog.Error("Failed to resolve state change webhook",
+			"correlation_id", execution.CorrelationID,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	payload := webhook.FormatStateChangePayload(config.Name, config.Target.URL, transition)
+
+	alertLog := webhook.NewAlertLog(destination, payload, execution.CorrelationID)
+	alertLog.ExecutionID = execution.ID
+	alertLog.ConfigID = config.ID
+	alertLog.Tags = config.Metadata.Tags
+
+	if err := e.alertRepo.Create(ctx, alertLog); err != nil {
+		slog.Error("Failed to create state change alert",
+			"correlation_id", execution.CorrelationID,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	e.enqueueAlerts(ctx, []AlertIntent{{
+		AlertLog: alertLog,
+		Webhook:  destination,
+		Payload:  payload,
+	}})
+}
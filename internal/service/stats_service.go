@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/evaluator"
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// statsTopConfigs limits the number of configs returned in per-config breakdowns
+const statsTopConfigs = 20
+
+// defaultDowntimePeriod is the downtime report period when no start is given
+const defaultDowntimePeriod = 30 * 24 * time.Hour
+
+// StatsService computes aggregated execution and alert statistics
+type StatsService struct {
+	executionRepo   *database.ExecutionRepository
+	alertRepo       *database.AlertRepository
+	downtimeRepo    *database.DowntimeRepository
+	healthCheckRepo *database.HealthCheckRepository
+}
+
+// NewStatsService creates a new stats service
+func NewStatsService(executionRepo *database.ExecutionRepository, alertRepo *database.AlertRepository, downtimeRepo *database.DowntimeRepository, healthCheckRepo *database.HealthCheckRepository) *StatsService {
+	return &StatsService{
+		executionRepo:   executionRepo,
+		alertRepo:       alertRepo,
+		downtimeRepo:    downtimeRepo,
+		healthCheckRepo: healthCheckRepo,
+	}
+}
+
+// Overview computes execution and alert counts for the given time window
+func (s *StatsService) Overview(ctx context.Context, configID string, window time.Duration, bucket string) (*model.StatsOverview, error) {
+	if !database.IsValidTimeBucket(bucket) {
+		return nil, fmt.Errorf("invalid bucket: %s (must be 'minute', 'hour', or 'day')", bucket)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be positive")
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-window)
+
+	executionFilter := bson.M{"executed_at": bson.M{"$gte": from, "$lte": to}}
+	alertFilter := bson.M{"created_at": bson.M{"$gte": from, "$lte": to}}
+
+	if configID != "" {
+		objID, err := primitive.ObjectIDFromHex(configID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config ID: %w", err)
+		}
+		executionFilter["config_id"] = objID
+		alertFilter["config_id"] = objID
+	}
+
+	executionStats, err := s.executionRepo.Stats(ctx, executionFilter, bucket, statsTopConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	alertStats, err := s.alertRepo.Stats(ctx, alertFilter, bucket, statsTopConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StatsOverview{
+		From:       from.Format(time.RFC3339),
+		To:         to.Format(time.RFC3339),
+		Bucket:     bucket,
+		Executions: *executionStats,
+		Alerts:     *alertStats,
+	}, nil
+}
+
+// Noisy-check report sort orders
+var noisyCheckSorts = map[string]func(a, b model.NoisyCheck) bool{
+	"alerts":                func(a, b model.NoisyCheck) bool { return a.Alerts > b.Alerts },
+	"false_positive_rate":   func(a, b model.NoisyCheck) bool { return a.FalsePositiveRate > b.FalsePositiveRate },
+	"delivery_failure_rate": func(a, b model.NoisyCheck) bool { return a.DeliveryFailureRate > b.DeliveryFailureRate },
+}
+
+// NoisyChecks ranks health checks by alert volume, false-positive rate or delivery failure
+// rate over the given window, returning at most limit checks
+func (s *StatsService) NoisyChecks(ctx context.Context, window time.Duration, sortBy string, limit int) (*model.NoisyChecksReport, error) {
+	less, ok := noisyCheckSorts[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort: %s (must be 'alerts', 'false_positive_rate', or 'delivery_failure_rate')", sortBy)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be positive")
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-window)
+
+	// Ingested alerts don't belong to a health check
+	checks, err := s.alertRepo.NoiseByConfig(ctx, bson.M{
+		"created_at": bson.M{"$gte": from, "$lte": to},
+		"config_id":  bson.M{"$ne": primitive.NilObjectID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(checks))
+	for i := range checks {
+		check := &checks[i]
+		if check.Alerts > 0 {
+			check.FalsePositiveRate = roundRate(float64(check.Noise) / float64(check.Alerts))
+			check.DeliveryFailureRate = roundRate(float64(check.DeliveryFailed) / float64(check.Alerts))
+		}
+		if id, err := primitive.ObjectIDFromHex(check.ConfigID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	configs, _, err := s.healthCheckRepo.List(ctx, bson.M{"_id": bson.M{"$in": ids}}, nil, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(configs))
+	for _, config := range configs {
+		names[config.ID.Hex()] = config.Name
+	}
+
+	// Ties go to the check with more alerts, then to the lower ID for a stable order
+	sort.SliceStable(checks, func(i, j int) bool {
+		a, b := checks[i], checks[j]
+		if less(a, b) || less(b, a) {
+			return less(a, b)
+		}
+		if a.Alerts != b.Alerts {
+			return a.Alerts > b.Alerts
+		}
+		return a.ConfigID < b.ConfigID
+	})
+	if limit > 0 && len(checks) > limit {
+		checks = checks[:limit]
+	}
+	for i := range checks {
+		checks[i].ConfigName = names[checks[i].ConfigID]
+	}
+
+	return &model.NoisyChecksReport{
+		From:    from.Format(time.RFC3339),
+		To:      to.Format(time.RFC3339),
+		Sort:    sortBy,
+		Results: checks,
+	}, nil
+}
+
+// Threshold suggestions are made for rules whose false-positive rate reaches
+// noisyRuleSuggestRate, from the values of at most noisyRuleSuggestSamples alerts
+const (
+	noisyRuleSuggestRate    = 0.5
+	noisyRuleSuggestSamples = 500
+)
+
+// NoisyRules ranks health check rules by the alerts responders classified as noise over the
+// given window, returning at most limit rules. With suggest set, numeric comparison rules
+// that are mostly noise get a threshold that would not have raised the noise alerts.
+func (s *StatsService) NoisyRules(ctx context.Context, window time.Duration, suggest bool, limit int) (*model.NoisyRulesReport, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be positive")
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-window)
+
+	rules, err := s.alertRepo.NoiseByRule(ctx, bson.M{
+		"created_at": bson.M{"$gte": from, "$lte": to},
+		"config_id":  bson.M{"$ne": primitive.NilObjectID},
+		"rule_name":  bson.M{"$nin": bson.A{nil, ""}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		if classified := rules[i].Real + rules[i].Noise; classified > 0 {
+			rules[i].FalsePositiveRate = roundRate(float64(rules[i].Noise) / float64(classified))
+		}
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.Noise != b.Noise {
+			return a.Noise > b.Noise
+		}
+		if a.FalsePositiveRate != b.FalsePositiveRate {
+			return a.FalsePositiveRate > b.FalsePositiveRate
+		}
+		if a.Alerts != b.Alerts {
+			return a.Alerts > b.Alerts
+		}
+		return a.ConfigID+a.RuleName < b.ConfigID+b.RuleName
+	})
+	if limit > 0 && len(rules) > limit {
+		rules = rules[:limit]
+	}
+
+	configs := make(map[string]*model.HealthCheckConfig)
+	for i := range rules {
+		rule := &rules[i]
+
+		config, ok := configs[rule.ConfigID]
+		if !ok {
+			if id, err := primitive.ObjectIDFromHex(rule.ConfigID); err == nil {
+				// A deleted config keeps its alerts but has nothing left to tune
+				config, _ = s.healthCheckRepo.GetByID(ctx, id)
+			}
+			configs[rule.ConfigID] = config
+		}
+		if config == nil {
+			continue
+		}
+		rule.ConfigName = config.Name
+
+		if !suggest || rule.Noise == 0 || rule.FalsePositiveRate < noisyRuleSuggestRate {
+			continue
+		}
+		suggestion, err := s.suggestThreshold(ctx, config, rule.RuleName, from, to)
+		if err != nil {
+			return nil, err
+		}
+		rule.Suggestion = suggestion
+	}
+
+	return &model.NoisyRulesReport{
+		From:    from.Format(time.RFC3339),
+		To:      to.Format(time.RFC3339),
+		Results: rules,
+	}, nil
+}
+
+// suggestThreshold proposes a threshold for a numeric comparison rule just past the values
+// of its noise alerts, and counts the real alerts it would have missed. It returns nil for
+// other rules and when no noise values were recorded.
+func (s *StatsService) suggestThreshold(ctx context.Context, config *model.HealthCheckConfig, ruleName string, from, to time.Time) (*model.ThresholdSuggestion, error) {
+	var rule *model.Rule
+	for i := range config.Rules {
+		if config.Rules[i].Name == ruleName {
+			rule = &config.Rules[i]
+			break
+		}
+	}
+	if rule == nil || rule.Trend != nil || !rule.AlertOnMatch {
+		return nil, nil
+	}
+	current, err := evaluator.CoerceToNumber(rule.ExpectedValue)
+	if err != nil {
+		return nil, nil
+	}
+
+	// Values above the threshold alert for gt/gte, below it for lt/lte
+	var above bool
+	var operator string
+	switch strings.ToLower(rule.Operator) {
+	case "gt", "gte":
+		above, operator = true, "gt"
+	case "lt", "lte":
+		above, operator = false, "lt"
+	default:
+		return nil, nil
+	}
+
+	correlationIDs, err := s.alertRepo.ClassifiedCorrelationIDs(ctx, bson.M{
+		"config_id":      config.ID,
+		"rule_name":      ruleName,
+		"created_at":     bson.M{"$gte": from, "$lte": to},
+		"classification": bson.M{"$in": bson.A{model.AlertClassificationReal, model.AlertClassificationNoise}},
+	}, noisyRuleSuggestSamples)
+	if err != nil {
+		return nil, err
+	}
+
+	noiseValues, err := s.ruleValues(ctx, ruleName, correlationIDs[model.AlertClassificationNoise])
+	if err != nil || len(noiseValues) == 0 {
+		return nil, err
+	}
+	realValues, err := s.ruleValues(ctx, ruleName, correlationIDs[model.AlertClassificationReal])
+	if err != nil {
+		return nil, err
+	}
+
+	suggested := noiseValues[0]
+	for _, v := range noiseValues {
+		if (above && v > suggested) || (!above && v < suggested) {
+			suggested = v
+		}
+	}
+	// Noise that would not have alerted at the current threshold says nothing about it
+	if (above && suggested < current) || (!above && suggested > current) {
+		return nil, nil
+	}
+
+	suggestion := &model.ThresholdSuggestion{
+		Operator:       operator,
+		ExpectedValue:  rule.ExpectedValue,
+		SuggestedValue: suggested,
+		NoiseSamples:   len(noiseValues),
+		RealSamples:    len(realValues),
+	}
+	for _, v := range realValues {
+		if (above && v <= suggested) || (!above && v >= suggested) {
+			suggestion.RealMissed++
+		}
+	}
+
+	return suggestion, nil
+}
+
+// ruleValues returns the numeric values a rule extracted in the given executions
+func (s *StatsService) ruleValues(ctx context.Context, ruleName string, correlationIDs []string) ([]float64, error) {
+	if len(correlationIDs) == 0 {
+		return nil, nil
+	}
+	return s.executionRepo.RuleValuesByCorrelationIDs(ctx, ruleName, correlationIDs)
+}
+
+// roundRate rounds a ratio to four decimal places
+func roundRate(rate float64) float64 {
+	return math.Round(rate*10000) / 10000
+}
+
+// Downtime reports downtime, MTTR and MTBF per health check between from and to.
+// A zero from covers the last 30 days and a zero to ends now; configID limits the report to one check.
+func (s *StatsService) Downtime(ctx context.Context, configID string, from, to time.Time) (*model.DowntimeReportResponse, error) {
+	now := time.Now().UTC()
+	if to.IsZero() || to.After(now) {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultDowntimePeriod)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid time range: from must be before to")
+	}
+
+	var objID primitive.ObjectID
+	if configID != "" {
+		parsed, err := primitive.ObjectIDFromHex(configID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config ID: %w", err)
+		}
+		objID = parsed
+	}
+
+	configs, err := s.downtimeConfigs(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	downtimes, err := s.downtimeRepo.FindOverlapping(ctx, objID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byConfig := make(map[primitive.ObjectID][]model.Downtime)
+	for _, downtime := range downtimes {
+		byConfig[downtime.ConfigID] = append(byConfig[downtime.ConfigID], downtime)
+	}
+
+	results := make([]model.DowntimeReport, 0, len(configs))
+	for _, config := range configs {
+		// Checks created during the period are only measured from their creation
+		start := from
+		if config.Metadata.CreatedAt.After(start) {
+			start = config.Metadata.CreatedAt
+		}
+		if !start.Before(to) {
+			continue
+		}
+		results = append(results, summarizeDowntime(config, byConfig[config.ID], start, to))
+	}
+
+	return &model.DowntimeReportResponse{
+		From:    from.Format(time.RFC3339),
+		To:      to.Format(time.RFC3339),
+		Results: results,
+	}, nil
+}
+
+// downtimeConfigs returns the health check a downtime report covers, or all of them when id is zero
+func (s *StatsService) downtimeConfigs(ctx context.Context, id primitive.ObjectID) ([]model.HealthCheckConfig, error) {
+	if id.IsZero() {
+		configs, _, err := s.healthCheckRepo.List(ctx, bson.M{}, bson.D{{Key: "name", Value: 1}}, 1, 0)
+		return configs, err
+	}
+
+	config, err := s.healthCheckRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return []model.HealthCheckConfig{*config}, nil
+}
+
+// ListDowntimes retrieves the downtime intervals of a health check
+func (s *StatsService) ListDowntimes(ctx context.Context, configID string, page, limit int) ([]model.Downtime, int64, error) {
+	objID, err := primitive.ObjectIDFromHex(configID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	if _, err := s.healthCheckRepo.GetByID(ctx, objID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.downtimeRepo.List(ctx, bson.M{"config_id": objID}, page, limit)
+}
+
+// summarizeDowntime computes a check's downtime figures over [from, to].
+// Downtimes still open at to are counted as down until to.
+func summarizeDowntime(config model.HealthCheckConfig, downtimes []model.Downtime, from, to time.Time) model.DowntimeReport {
+	report := model.DowntimeReport{
+		ConfigID:   config.ID,
+		ConfigName: config.Name,
+	}
+
+	var down, repair time.Duration
+	for _, downtime := range downtimes {
+		begin := downtime.StartedAt
+		if begin.Before(from) {
+			begin = from
+		} else {
+			report.Incidents++
+		}
+
+		end := to
+		if downtime.EndedAt != nil && downtime.EndedAt.Before(to) {
+			end = *downtime.EndedAt
+		}
+		if end.After(begin) {
+			down += end.Sub(begin)
+		}
+
+		if downtime.EndedAt != nil && downtime.EndedAt.After(from) && !downtime.EndedAt.After(to) {
+			report.Resolved++
+			repair += downtime.EndedAt.Sub(downtime.StartedAt)
+		}
+	}
+
+	period := to.Sub(from)
+	up := period - down
+	report.DowntimeMs = down.Milliseconds()
+	report.UptimePercent = math.Round(float64(up)/float64(period)*10000) / 100
+	if report.Resolved > 0 {
+		report.MTTRMs = (repair / time.Duration(report.Resolved)).Milliseconds()
+	}
+	if report.Incidents > 0 {
+		report.MTBFMs = (up / time.Duration(report.Incidents)).Milliseconds()
+	}
+
+	return report
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/webhook"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// suiteMemberConcurrency limits how many suite members execute at once
+const suiteMemberConcurrency = 5
+
+// SuiteService handles suite management and execution
+type SuiteService struct {
+	repo       *database.SuiteRepository
+	executor   *Executor
+	alertRepo  *database.AlertRepository
+	alertQueue *AlertQueue
+	webhooks   *webhook.Resolver
+}
+
+// NewSuiteService creates a new suite service
+func NewSuiteService(
+	repo *database.SuiteRepository,
+	executor *Executor,
+	alertRepo *database.AlertRepository,
+	alertQueue *AlertQueue,
+	webhooks *webhook.Resolver,
+) *SuiteService {
+	return &SuiteService{
+		repo:       repo,
+		executor:   executor,
+		alertRepo:  alertRepo,
+		alertQueue: alertQueue,
+		webhooks:   webhooks,
+	}
+}
+
+// Create creates a new suite
+func (s *SuiteService) Create(ctx context.Context, suite *model.Suite) error {
+	if err := suite.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.validateWebhook(ctx, suite); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	return s.repo.Create(ctx, suite)
+}
+
+// GetByID retrieves a suite by ID
+func (s *SuiteService) GetByID(ctx context.Context, id string) (*model.Suite, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	return s.repo.GetByID(ctx, objID)
+}
+
+// List retrieves suites
+func (s *SuiteService) List(ctx context.Context, enabled *bool, page, limit int) ([]model.Suite, int64, error) {
+	filter := bson.M{}
+	if enabled != nil {
+		filter["enabled"] = *enabled
+	}
+
+	return s.repo.List(ctx, filter, page, limit)
+}
+
+// Update updates an existing suite
+func (s *SuiteService) Update(ctx context.Context, id string, suite *model.Suite) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	if err := suite.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.validateWebhook(ctx, suite); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	return s.repo.Update(ctx, objID, suite)
+}
+
+// Delete deletes a suite
+func (s *SuiteService) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	return s.repo.Delete(ctx, objID)
+}
+
+// ListResults retrieves the run history of a suite
+func (s *SuiteService) ListResults(ctx context.Context, id string, page, limit int) ([]model.SuiteResult, int64, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	return s.repo.ListResults(ctx, objID, page, limit)
+}
+
+// Run executes a suite by ID
+func (s *SuiteService) Run(ctx context.Context, id, correlationID, triggeredBy string) (*model.SuiteResult, error) {
+	suite, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !suite.Enabled {
+		return nil, fmt.Errorf("suite is disabled")
+	}
+
+	return s.RunSuite(ctx, suite, ExecuteOptions{
+		RequestCorrelationID: correlationID,
+		TriggerType:          model.TriggerAPI,
+		TriggeredBy:          triggeredBy,
+	}), nil
+}
+
+// RunSuite executes all member checks of a suite, persists the combined result and
+// sends a single digest alert when the suite fails. Members are recorded as one batch under
+// opts.BatchID, which is generated when empty and is also the run's correlation ID.
+func (s *SuiteService) RunSuite(ctx context.Context, suite *model.Suite, opts ExecuteOptions) *model.SuiteResult {
+	// Never derived from the request's correlation ID, which the client chooses and may reuse
+	if opts.BatchID == "" {
+		opts.BatchID = uuid.New().String()
+	}
+	correlationID := opts.BatchID
+
+	slog.Info("Starting suite execution",
+		"correlation_id", correlationID,
+		"request_correlation_id", opts.RequestCorrelationID,
+		"suite_id", suite.ID.Hex(),
+		"suite_name", suite.Name,
+		"members", len(suite.ConfigIDs),
+	)
+
+	start := time.Now()
+	members := make([]model.SuiteMemberResult, len(suite.ConfigIDs))
+	// Members alert through the suite digest only
+	opts.SuppressAlerts = true
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, suiteMemberConcurrency)
+	for i, configID := range suite.ConfigIDs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, configID primitive.ObjectID) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			members[i] = s.runMember(ctx, configID, ChildCorrelationID(correlationID, i+1), opts)
+		}(i, configID)
+	}
+	wg.Wait()
+
+	result := &model.SuiteResult{
+		SuiteID:       suite.ID,
+		SuiteName:     suite.Name,
+		CorrelationID: correlationID,
+		ExecutedAt:    start.UTC(),
+		DurationMs:    time.Since(start).Milliseconds(),
+		Total:         len(members),
+		Members:       members,
+		Status:        "passed",
+	}
+	for _, member := range members {
+		if member.Passed {
+			result.Passed++
+		} else {
+			result.Failed++
+		}
+	}
+	if result.Failed > 0 {
+		result.Status = "failed"
+	}
+
+	// Send a single digest alert instead of one alert per member
+	if result.Failed > 0 && suite.AlertOnFailure {
+		alertID, err := s.sendDigest(ctx, suite, result)
+		if err != nil {
+			slog.Error("Failed to send suite digest alert",
+				"correlation_id", correlationID,
+				"suite_name", suite.Name,
+				"error", err.Error(),
+			)
+		} else {
+			result.DigestAlertID = alertID
+		}
+	}
+
+	// Ad-hoc suites (e.g. post-deploy verification) have no ID and keep only their member executions
+	if !suite.ID.IsZero() {
+		if err := s.repo.CreateResult(ctx, result); err != nil {
+			slog.Error("Failed to save suite result",
+				"correlation_id", correlationID,
+				"suite_name", suite.Name,
+				"error", err.Error(),
+			)
+		}
+	}
+
+	slog.Info("Suite execution completed",
+		"correlation_id", correlationID,
+		"suite_name", suite.Name,
+		"status", result.Status,
+		"passed", result.Passed,
+		"failed", result.Failed,
+		"duration_ms", result.DurationMs,
+	)
+
+	return result
+}
+
+// runMember executes a single suite member. A member passes when its execution
+// succeeded and none of its alerting rules matched.
+func (s *SuiteService) runMember(ctx context.Context, configID primitive.ObjectID, correlationID string, opts ExecuteOptions) model.SuiteMemberResult {
+	member := model.SuiteMemberResult{
+		ConfigID:      configID,
+		CorrelationID: correlationID,
+	}
+
+	execution, err := s.executor.Execute(ctx, configID.Hex(), correlationID, opts)
+	if err != nil {
+		member.Status = "failed"
+		member.Error = err.Error()
+		return member
+	}
+
+	member.ConfigName = execution.ConfigName
+	member.CorrelationID = execution.CorrelationID
+	member.Status = execution.Status
+	for _, eval := range execution.RulesEvaluation {
+		if eval.Matched && eval.AlertOnMatch {
+			member.AlertsTriggered++
+		}
+	}
+	member.Passed = execution.Healthy()
+
+	return member
+}
+
+// sendDigest persists and enqueues the suite digest alert
+func (s *SuiteService) sendDigest(ctx context.Context, suite *model.Suite, result *model.SuiteResult) (primitive.ObjectID, error) {
+	destination, err := s.webhooks.Resolve(ctx, suiteWebhook(suite))
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	payload := webhook.FormatSuiteDigestPayload(result)
+
+	alertLog := webhook.NewAlertLog(destination, payload, result.CorrelationID)
+	alertLog.SuiteID = suite.ID
+
+	if err := s.alertRepo.Create(ctx, alertLog); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	intent := AlertIntent{
+		AlertLog: alertLog,
+		Webhook:  destination,
+		Payload:  payload,
+	}
+	if err := s.alertQueue.Enqueue(intent); err != nil {
+		s.alertQueue.MarkFailed(ctx, alertLog, err.Error())
+		return alertLog.ID, err
+	}
+
+	return alertLog.ID, nil
+}
+
+// validateWebhook checks that the suite digest webhook resolves to an allowed destination
+func (s *SuiteService) validateWebhook(ctx context.Context, suite *model.Suite) error {
+	if !suite.AlertOnFailure && suite.Webhook == nil {
+		return nil
+	}
+	return s.webhooks.Validate(ctx, suiteWebhook(suite))
+}
+
+// suiteWebhook returns the configured digest webhook, or an empty one to select the default
+func suiteWebhook(suite *model.Suite) model.Webhook {
+	if suite.Webhook == nil {
+		return model.Webhook{}
+	}
+	return *suite.Webhook
+}
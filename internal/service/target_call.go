@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/internal/probe"
+	"github.com/dandantas/raven/pkg/plugins"
+)
+
+// maxRetryBackoff caps the delay between target retries as it doubles
+const maxRetryBackoff = 30 * time.Second
+
+// targetCall records the retries and individual attempts of a target call
+type targetCall struct {
+	retries  *model.TargetRetries
+	attempts []model.RequestAttempt
+}
+
+// callTarget calls the target, retrying network errors and retryable status codes with backoff
+// while the retry budget lasts. The returned call is nil when retries are disabled for the target.
+func (e *Executor) callTarget(ctx context.Context, config *model.HealthCheckConfig, target model.Target, correlationID string) (model.ExecutionRequest, model.ExecutionResponse, *targetCall, error) {
+	opts := e.callOptions(config, correlationID).ForTarget(target)
+	if !opts.RetriesEnabled() {
+		request, response, err := e.callOnce(ctx, config, target, opts)
+		return request, response, nil, err
+	}
+
+	call := &targetCall{retries: &model.TargetRetries{Budget: opts.RetryBudget}}
+	backoff := opts.RetryBackoff
+	for {
+		attemptStart := time.Now()
+		request, response, err := e.callOnce(ctx, config, target, opts)
+		call.attempts = append(call.attempts, model.RequestAttempt{
+			Attempt:    len(call.attempts) + 1,
+			StartedAt:  attemptStart.UTC(),
+			DurationMs: time.Since(attemptStart).Milliseconds(),
+			StatusCode: response.StatusCode,
+			Error:      response.Error,
+		})
+
+		reason := retryReason(opts, response, err)
+		if reason == "" || call.retries.Used >= call.retries.Budget || ctx.Err() != nil {
+			return request, response, call, err
+		}
+
+		call.retries.Used++
+		slog.Warn("Retrying target call",
+			"correlation_id", correlationID,
+			"url", target.URL,
+			"retry", call.retries.Used,
+			"reason", reason,
+			"backoff_ms", backoff.Milliseconds(),
+		)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return request, response, call, err
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// SetBrowser sets the headless browser browser checks run in; without one they fail
+func (e *Executor) SetBrowser(browser *probe.Browser) {
+	e.browser = browser
+}
+
+// SetPlugins sets the registry holding the executors of plugin check types
+func (e *Executor) SetPlugins(registry *plugins.Registry) {
+	e.plugins = registry
+}
+
+// callOnce makes a single call to the target the way the config's check type requires
+func (e *Executor) callOnce(ctx context.Context, config *model.HealthCheckConfig, target model.Target, opts probe.Options) (model.ExecutionRequest, model.ExecutionResponse, error) {
+	switch config.Type {
+	case model.CheckTypeBrowser:
+		return e.browser.Call(ctx, e.httpClient, target, config.Browser, opts)
+	case model.CheckTypeKafka:
+		return probe.CallKafka(ctx, target, config.Kafka)
+	case model.CheckTypeRabbitMQ:
+		return probe.CallRabbitMQ(ctx, e.httpClient, target, config.RabbitMQ, opts)
+	case model.CheckTypeObject:
+		return probe.CallObject(ctx, e.httpClient, target, config.Object, opts)
+	case "", model.CheckTypeHTTP:
+		return probe.Call(ctx, e.httpClient, target, opts)
+	default:
+		return probe.CallPlugin(ctx, e.plugins.CheckExecutor(config.Type), config, target, opts)
+	}
+}
+
+// callOptions returns the probe options of a check execution, with identification headers unless the check opted out
+func (e *Executor) callOptions(config *model.HealthCheckConfig, correlationID string) probe.Options {
+	opts := e.probeOptions
+	if config.DisableProbeHeaders {
+		return opts
+	}
+	if !config.ID.IsZero() {
+		opts.CheckID = config.ID.Hex()
+	}
+	opts.CorrelationID = correlationID
+	return opts
+}
+
+// retryReason returns why a target call should be retried, or "" when it shouldn't
+func retryReason(opts probe.Options, response model.ExecutionResponse, err error) string {
+	var networkErr *probe.NetworkError
+	if errors.As(err, &networkErr) {
+		if opts.RetryOnNetworkError {
+			return "network error"
+		}
+		return ""
+	}
+	if err == nil && slices.Contains(opts.RetryOnStatus, response.StatusCode) {
+		return fmt.Sprintf("status %d", response.StatusCode)
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dandantas/raven/internal/database"
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// errInvalidTriggerToken is returned for any token that does not verify, without saying why
+var errInvalidTriggerToken = errors.New("invalid trigger token")
+
+// TriggerService issues and verifies signed trigger tokens that let external systems
+// execute a single health check without API credentials
+type TriggerService struct {
+	repo   *database.HealthCheckRepository
+	secret []byte
+}
+
+// NewTriggerService creates a new trigger service; an empty secret disables trigger tokens
+func NewTriggerService(repo *database.HealthCheckRepository, secret string) *TriggerService {
+	return &TriggerService{
+		repo:   repo,
+		secret: []byte(secret),
+	}
+}
+
+// IssueToken creates a new trigger token for a health check, revoking any previous one
+func (s *TriggerService) IssueToken(ctx context.Context, id string) (string, error) {
+	if len(s.secret) == 0 {
+		return "", errors.New("trigger tokens are disabled: TRIGGER_SECRET is not set")
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate trigger nonce: %w", err)
+	}
+	encodedNonce := hex.EncodeToString(nonce)
+
+	if err := s.repo.SetTriggerNonce(ctx, objID, encodedNonce); err != nil {
+		return "", err
+	}
+
+	return objID.Hex() + "." + s.sign(objID, encodedNonce), nil
+}
+
+// Resolve verifies a trigger token and returns the health check it grants access to
+func (s *TriggerService) Resolve(ctx context.Context, token string) (*model.HealthCheckConfig, error) {
+	if len(s.secret) == 0 {
+		return nil, errInvalidTriggerToken
+	}
+
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errInvalidTriggerToken
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errInvalidTriggerToken
+	}
+
+	config, err := s.repo.GetByID(ctx, objID)
+	if err != nil {
+		if err.Error() == "health check not found" {
+			return nil, errInvalidTriggerToken
+		}
+		return nil, err
+	}
+	if config.TriggerNonce == "" {
+		return nil, errInvalidTriggerToken
+	}
+
+	expected := s.sign(objID, config.TriggerNonce)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, errInvalidTriggerToken
+	}
+
+	return config, nil
+}
+
+// sign computes the token signature for a health check and its current nonce
+func (s *TriggerService) sign(id primitive.ObjectID, nonce string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id.Hex() + ":" + nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
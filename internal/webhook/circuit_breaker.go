@@ -1,8 +1,33 @@
 package webhook
 
 import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultCircuitBreakerConfig is used when no CIRCUIT_BREAKER_* settings are given
+var DefaultCircuitBreakerConfig = model.CircuitBreakerConfig{
+	FailureThreshold:    5,
+	SuccessThreshold:    2,
+	TimeoutSeconds:      60,
+	HalfOpenMaxProbes:   1,
+	HalfOpenWaitSeconds: &defaultHalfOpenWaitSeconds,
+}
+
+var defaultHalfOpenWaitSeconds = 10
+
+// Errors returned when a circuit breaker doesn't let a delivery through
+var (
+	ErrCircuitOpen        = errors.New("circuit breaker is open")
+	ErrHalfOpenProbeLimit = errors.New("circuit breaker is half-open and all probe slots are busy")
 )
 
 // CircuitState represents the state of the circuit breaker
@@ -23,50 +48,129 @@ type CircuitBreaker struct {
 	successCount    int
 	lastFailureTime time.Time
 	lastStateChange time.Time
+	probes          int           // Deliveries let through as half-open probes, still under way
+	released        chan struct{} // Closed when a probe finishes, waking deliveries waiting for a slot
 
 	// Configuration
-	failureThreshold int           // Failures before opening circuit
-	successThreshold int           // Successes to close from half-open
-	timeout          time.Duration // Time before trying half-open
+	failureThreshold  int           // Failures before opening circuit
+	successThreshold  int           // Successes to close from half-open
+	timeout           time.Duration // Time before trying half-open
+	halfOpenMaxProbes int           // Concurrent deliveries allowed while half-open
+	halfOpenWait      time.Duration // Time a delivery waits for a free probe slot
+}
+
+// Permit lets one delivery through a circuit breaker. Report its outcome with Success or
+// Failure, or call Cancel when it ended without one.
+type Permit struct {
+	cb    *CircuitBreaker
+	probe bool // Holds one of the half-open probe slots
 }
 
 // NewCircuitBreaker creates a new circuit breaker
-func NewCircuitBreaker() *CircuitBreaker {
-	return &CircuitBreaker{
-		state:            StateClosed,
-		failureThreshold: 5,
-		successThreshold: 2,
-		timeout:          60 * time.Second,
-		lastStateChange:  time.Now(),
+func NewCircuitBreaker(config model.CircuitBreakerConfig) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		state:           StateClosed,
+		lastStateChange: time.Now(),
+		released:        make(chan struct{}),
 	}
+	cb.configure(config)
+	return cb
 }
 
-// CanAttempt checks if a request can be attempted
-func (cb *CircuitBreaker) CanAttempt() bool {
+// configure applies new thresholds and timeout, keeping the current state and counts
+func (cb *CircuitBreaker) configure(config model.CircuitBreakerConfig) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
-	switch cb.state {
-	case StateClosed:
-		return true
-	case StateOpen:
+	cb.failureThreshold = config.FailureThreshold
+	cb.successThreshold = config.SuccessThreshold
+	cb.timeout = config.Timeout()
+	cb.halfOpenMaxProbes = max(config.HalfOpenMaxProbes, 1)
+	cb.halfOpenWait = config.HalfOpenWait()
+}
+
+// Acquire asks to deliver through the breaker. While half-open only a limited number of probe
+// deliveries run at once, so a recovering receiver isn't flooded; the others wait for a probe
+// to finish, up to the half-open wait, and then follow the state the probe left behind.
+func (cb *CircuitBreaker) Acquire(ctx context.Context) (*Permit, error) {
+	var deadline <-chan time.Time
+	for {
+		cb.mu.Lock()
 		// Check if timeout has passed
-		if time.Since(cb.lastStateChange) >= cb.timeout {
+		if cb.state == StateOpen && time.Since(cb.lastStateChange) >= cb.timeout {
 			cb.state = StateHalfOpen
 			cb.successCount = 0
 			cb.failureCount = 0
-			return true
 		}
-		return false
-	case StateHalfOpen:
-		return true
+
+		switch {
+		case cb.state == StateClosed:
+			cb.mu.Unlock()
+			return &Permit{cb: cb}, nil
+		case cb.state == StateOpen:
+			cb.mu.Unlock()
+			return nil, ErrCircuitOpen
+		case cb.probes < cb.halfOpenMaxProbes:
+			cb.probes++
+			cb.mu.Unlock()
+			return &Permit{cb: cb, probe: true}, nil
+		}
+		released, wait := cb.released, cb.halfOpenWait
+		cb.mu.Unlock()
+
+		if wait <= 0 {
+			return nil, ErrHalfOpenProbeLimit
+		}
+		if deadline == nil {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+
+		select {
+		case <-released:
+			// Check the state the probe left behind
+		case <-deadline:
+			return nil, ErrHalfOpenProbeLimit
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
+}
 
-	return false
+// Success records that the delivery succeeded
+func (p *Permit) Success() {
+	p.cb.recordSuccess()
+	p.Cancel()
 }
 
-// RecordSuccess records a successful request
-func (cb *CircuitBreaker) RecordSuccess() {
+// Failure records that the delivery failed
+func (p *Permit) Failure() {
+	p.cb.recordFailure()
+	p.Cancel()
+}
+
+// Cancel frees the permit's probe slot without recording an outcome
+func (p *Permit) Cancel() {
+	if !p.probe {
+		return
+	}
+	p.probe = false
+
+	p.cb.mu.Lock()
+	defer p.cb.mu.Unlock()
+	p.cb.probes--
+	p.cb.wake()
+}
+
+// wake lets deliveries waiting for a probe slot check the breaker again; the caller holds mu
+func (cb *CircuitBreaker) wake() {
+	close(cb.released)
+	cb.released = make(chan struct{})
+}
+
+// recordSuccess records a successful request
+func (cb *CircuitBreaker) recordSuccess() {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
@@ -86,8 +190,8 @@ func (cb *CircuitBreaker) RecordSuccess() {
 	}
 }
 
-// RecordFailure records a failed request
-func (cb *CircuitBreaker) RecordFailure() {
+// recordFailure records a failed request
+func (cb *CircuitBreaker) recordFailure() {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
@@ -116,19 +220,57 @@ func (cb *CircuitBreaker) GetState() CircuitState {
 
 // GetStateName returns a string representation of the state
 func (cb *CircuitBreaker) GetStateName() string {
-	state := cb.GetState()
+	return stateName(cb.GetState())
+}
+
+// stateName returns the name of a circuit state
+func stateName(state CircuitState) string {
 	switch state {
 	case StateClosed:
-		return "closed"
+		return model.CircuitStateClosed
 	case StateOpen:
-		return "open"
+		return model.CircuitStateOpen
 	case StateHalfOpen:
-		return "half-open"
+		return model.CircuitStateHalfOpen
 	default:
 		return "unknown"
 	}
 }
 
+// Status returns the breaker's state, counts and settings
+func (cb *CircuitBreaker) Status(host, channelID string) model.CircuitBreakerStatus {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	halfOpenWaitSeconds := int(cb.halfOpenWait.Seconds())
+	status := model.CircuitBreakerStatus{
+		Host:            host,
+		ChannelID:       channelID,
+		State:           stateName(cb.state),
+		StateSince:      cb.lastStateChange.UTC(),
+		StateAgeSeconds: int64(time.Since(cb.lastStateChange).Seconds()),
+		FailureCount:    cb.failureCount,
+		SuccessCount:    cb.successCount,
+		ProbesInFlight:  cb.probes,
+		Config: model.CircuitBreakerConfig{
+			FailureThreshold:    cb.failureThreshold,
+			SuccessThreshold:    cb.successThreshold,
+			TimeoutSeconds:      int(cb.timeout.Seconds()),
+			HalfOpenMaxProbes:   cb.halfOpenMaxProbes,
+			HalfOpenWaitSeconds: &halfOpenWaitSeconds,
+		},
+	}
+	if !cb.lastFailureTime.IsZero() {
+		lastFailureAt := cb.lastFailureTime.UTC()
+		status.LastFailureAt = &lastFailureAt
+	}
+	if cb.state == StateOpen {
+		retryAt := cb.lastStateChange.Add(cb.timeout).UTC()
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
 // Reset resets the circuit breaker to closed state
 func (cb *CircuitBreaker) Reset() {
 	cb.mu.Lock()
@@ -138,4 +280,100 @@ func (cb *CircuitBreaker) Reset() {
 	cb.failureCount = 0
 	cb.successCount = 0
 	cb.lastStateChange = time.Now()
+	cb.wake()
+}
+
+// CircuitBreakers keeps one circuit breaker per destination host, so a failing receiver doesn't
+// stop alerts to the others. Notification channels with their own settings get a breaker of
+// their own for the host, so they can't overwrite each other's settings.
+type CircuitBreakers struct {
+	mu       sync.Mutex
+	defaults model.CircuitBreakerConfig
+	breakers map[breakerKey]*CircuitBreaker
+}
+
+// breakerKey identifies a breaker; channelID is empty for the host's shared breaker
+type breakerKey struct {
+	host      string
+	channelID string
+}
+
+// NewCircuitBreakers creates an empty set of breakers using the given default settings
+func NewCircuitBreakers(defaults model.CircuitBreakerConfig) *CircuitBreakers {
+	return &CircuitBreakers{
+		defaults: defaults.WithDefaults(DefaultCircuitBreakerConfig),
+		breakers: make(map[breakerKey]*CircuitBreaker),
+	}
+}
+
+// For returns the breaker of a host, created on first use. A delivery through a notification
+// channel that overrides the defaults uses the channel's own breaker for the host.
+func (c *CircuitBreakers) For(host string, channelID primitive.ObjectID, override *model.CircuitBreakerConfig) *CircuitBreaker {
+	key := breakerKey{host: host}
+	config := c.defaults
+	if override != nil {
+		key.channelID = channelID.Hex()
+		config = override.WithDefaults(c.defaults)
+	}
+
+	c.mu.Lock()
+	cb, ok := c.breakers[key]
+	if !ok {
+		cb = NewCircuitBreaker(config)
+		c.breakers[key] = cb
+	}
+	c.mu.Unlock()
+
+	// Only the channel uses its breaker, so apply edits to its settings
+	if ok && override != nil {
+		cb.configure(config)
+	}
+	return cb
+}
+
+// List returns the status of every breaker, sorted by host with the shared breaker first
+func (c *CircuitBreakers) List() []model.CircuitBreakerStatus {
+	c.mu.Lock()
+	breakers := maps.Clone(c.breakers)
+	c.mu.Unlock()
+
+	statuses := make([]model.CircuitBreakerStatus, 0, len(breakers))
+	for _, key := range sortedKeys(breakers) {
+		statuses = append(statuses, breakers[key].Status(key.host, key.channelID))
+	}
+	return statuses
+}
+
+// Reset closes every breaker of a host, reporting false if no delivery to the host was made yet
+func (c *CircuitBreakers) Reset(host string) ([]model.CircuitBreakerStatus, bool) {
+	host = strings.ToLower(host)
+
+	c.mu.Lock()
+	breakers := make(map[breakerKey]*CircuitBreaker)
+	for key, cb := range c.breakers {
+		if key.host == host {
+			breakers[key] = cb
+		}
+	}
+	c.mu.Unlock()
+	if len(breakers) == 0 {
+		return nil, false
+	}
+
+	statuses := make([]model.CircuitBreakerStatus, 0, len(breakers))
+	for _, key := range sortedKeys(breakers) {
+		breakers[key].Reset()
+		statuses = append(statuses, breakers[key].Status(key.host, key.channelID))
+	}
+	return statuses, true
+}
+
+// sortedKeys returns the keys of breakers ordered by host, then channel
+func sortedKeys(breakers map[breakerKey]*CircuitBreaker) []breakerKey {
+	return slices.SortedFunc(maps.Keys(breakers), func(a, b breakerKey) int {
+		if c := strings.Compare(a.host, b.host); c != 0 {
+			return c
+		}
+		return strings.Compare(a.channelID, b.channelID)
+	})
 }
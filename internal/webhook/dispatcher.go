@@ -1,40 +1,125 @@
 package webhook
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/pkg/plugins"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Headers identifying the execution and alert a delivery belongs to
+const (
+	HeaderCorrelationID = "X-Correlation-ID"
+	HeaderAlertID       = "X-Raven-Alert-ID"
+)
+
 // Dispatcher handles webhook delivery with retry logic
 type Dispatcher struct {
-	httpClient     *http.Client
-	circuitBreaker *CircuitBreaker
+	httpClient      *http.Client
+	senders         map[string]Sender
+	plugins         *plugins.Registry
+	circuitBreakers *CircuitBreakers
+	policy          *DestinationPolicy
+	ackLinks        *AckLinks
 }
 
 // NewDispatcher creates a new webhook dispatcher
 func NewDispatcher(timeout time.Duration) *Dispatcher {
+	httpClient := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
 	return &Dispatcher{
-		httpClient: &http.Client{
-			Timeout: timeout,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-			},
+		httpClient: httpClient,
+		senders: map[string]Sender{
+			model.WebhookTypeWebhook: &httpSender{client: httpClient},
+			model.WebhookTypeSlack:   &slackSender{client: httpClient},
 		},
-		circuitBreaker: NewCircuitBreaker(),
+		circuitBreakers: NewCircuitBreakers(DefaultCircuitBreakerConfig),
+	}
+}
+
+// SetSMTP enables email webhooks, sent through the given server
+func (d *Dispatcher) SetSMTP(config SMTPConfig) {
+	d.senders[model.WebhookTypeEmail] = &emailSender{config: config, timeout: d.httpClient.Timeout}
+}
+
+// SetPlugins sets the registry holding the senders of plugin webhook types
+func (d *Dispatcher) SetPlugins(registry *plugins.Registry) {
+	d.plugins = registry
+}
+
+// SetCircuitBreakerDefaults sets the circuit breaker settings of hosts whose channel doesn't
+// override them
+func (d *Dispatcher) SetCircuitBreakerDefaults(config model.CircuitBreakerConfig) {
+	d.circuitBreakers = NewCircuitBreakers(config)
+}
+
+// SetPolicy restricts webhook deliveries to the destinations allowed by policy, checking
+// every connection the HTTP senders make
+func (d *Dispatcher) SetPolicy(policy *DestinationPolicy) {
+	d.policy = policy
+	if transport, ok := d.httpClient.Transport.(*http.Transport); ok {
+		transport.DialContext = policy.DialContext(&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		})
 	}
 }
 
+// SetAckLinks includes a signed one-click acknowledge link in delivered alerts
+func (d *Dispatcher) SetAckLinks(links *AckLinks) {
+	d.ackLinks = links
+}
+
+// NewAlertLog creates a pending alert log for a webhook delivery
+func NewAlertLog(webhook model.Webhook, payload AlertPayloadData, correlationID string) *model.AlertLog {
+	now := time.Now().UTC()
+	return &model.AlertLog{
+		ID:            primitive.NewObjectID(),
+		CorrelationID: correlationID,
+		ChannelID:     webhook.ChannelID,
+		WebhookURL:    webhook.URL,
+		WebhookHost:   webhookHost(webhook.URL),
+		Severity:      payloadSeverity(payload),
+		Payload: model.AlertPayload{
+			Text: payload.Text,
+		},
+		Attempts:    make([]model.AlertAttempt, 0),
+		FinalStatus: "pending",
+		MatchedAt:   now,
+		CreatedAt:   now,
+	}
+}
+
+// webhookHost returns the lower-cased host of a webhook URL, or the domain of the first
+// recipient of a mailto: URL
+func webhookHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	if parsed.Scheme == "mailto" {
+		if recipients, err := model.EmailRecipients(rawURL); err == nil {
+			return model.EmailDomain(recipients[0])
+		}
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
 // SendAlert sends an alert to a webhook with retry logic
 func (d *Dispatcher) SendAlert(
 	ctx context.Context,
@@ -42,32 +127,57 @@ func (d *Dispatcher) SendAlert(
 	payload AlertPayloadData,
 	correlationID string,
 ) (*model.AlertLog, error) {
+	alertLog := NewAlertLog(webhook, payload, correlationID)
+	err := d.Deliver(ctx, alertLog, webhook, payload)
+	return alertLog, err
+}
+
+// Deliver delivers an alert to a webhook with retry logic, recording attempts
+// and the final status on the given alert log
+func (d *Dispatcher) Deliver(
+	ctx context.Context,
+	alertLog *model.AlertLog,
+	webhook model.Webhook,
+	payload AlertPayloadData,
+) error {
+	correlationID := alertLog.CorrelationID
+
 	// Set timestamp in metadata
 	payload.Metadata["timestamp"] = time.Now().UTC().Format(time.RFC3339)
+	alertLog.FinalStatus = "retrying"
 
-	// Create alert log
-	alertLog := &model.AlertLog{
-		ID:            primitive.NewObjectID(),
-		CorrelationID: correlationID,
-		WebhookURL:    webhook.URL,
-		Payload: model.AlertPayload{
-			Text: payload.Text,
-		},
-		Attempts:    make([]model.AlertAttempt, 0),
-		FinalStatus: "retrying",
-		CreatedAt:   time.Now().UTC(),
+	// Enforce the destination allow-list in case it changed after the config was saved
+	if err := d.policy.AllowedWebhook(ctx, webhook); err != nil {
+		slog.Warn("Webhook destination not allowed, skipping delivery",
+			"correlation_id", correlationID,
+			"webhook_url", webhook.URL,
+			"error", err,
+		)
+		alertLog.Attempts = append(alertLog.Attempts, model.AlertAttempt{
+			AttemptNumber: 1,
+			Timestamp:     time.Now().UTC(),
+			Error:         err.Error(),
+		})
+		alertLog.FinalStatus = "failed"
+		alertLog.CompletedAt = time.Now().UTC()
+		return err
 	}
 
-	// Check circuit breaker
-	if !d.circuitBreaker.CanAttempt() {
-		slog.Warn("Circuit breaker is open, skipping webhook delivery",
+	// Check the circuit breaker of the destination host
+	host := breakerHost(alertLog, webhook)
+	circuitBreaker := d.circuitBreakers.For(host, webhook.ChannelID, webhook.CircuitBreaker)
+	permit, err := circuitBreaker.Acquire(ctx)
+	if err != nil {
+		slog.Warn("Circuit breaker rejected webhook delivery",
 			"correlation_id", correlationID,
 			"webhook_url", webhook.URL,
-			"circuit_state", d.circuitBreaker.GetStateName(),
+			"host", host,
+			"circuit_state", circuitBreaker.GetStateName(),
+			"error", err,
 		)
 		alertLog.FinalStatus = "failed"
 		alertLog.CompletedAt = time.Now().UTC()
-		return alertLog, fmt.Errorf("circuit breaker is open")
+		return fmt.Errorf("%s: %w", host, err)
 	}
 
 	// Create retry strategy
@@ -82,22 +192,22 @@ func (d *Dispatcher) SendAlert(
 			"max_attempts", retryStrategy.GetMaxAttempts(),
 		)
 
-		attemptResult, err := d.deliverWebhook(ctx, webhook, payload)
+		attemptResult, err := d.deliverAttempt(ctx, alertLog, webhook, payload)
 		alertLog.Attempts = append(alertLog.Attempts, attemptResult)
 
 		// Check if delivery was successful
-		if err == nil && attemptResult.StatusCode >= 200 && attemptResult.StatusCode < 300 {
+		if err == nil {
+			alertLog.MarkDelivered(time.Now().UTC())
 			slog.Info("Webhook delivered successfully",
 				"correlation_id", correlationID,
 				"webhook_url", webhook.URL,
 				"attempt", attempt,
 				"status_code", attemptResult.StatusCode,
+				"delivery_latency_ms", alertLog.DeliveryLatencyMs,
 			)
 
-			alertLog.FinalStatus = "delivered"
-			alertLog.CompletedAt = time.Now().UTC()
-			d.circuitBreaker.RecordSuccess()
-			return alertLog, nil
+			permit.Success()
+			return nil
 		}
 
 		// Check if we should retry
@@ -112,8 +222,8 @@ func (d *Dispatcher) SendAlert(
 
 			alertLog.FinalStatus = "failed"
 			alertLog.CompletedAt = time.Now().UTC()
-			d.circuitBreaker.RecordFailure()
-			return alertLog, fmt.Errorf("webhook delivery failed after %d attempts", attempt)
+			permit.Failure()
+			return fmt.Errorf("webhook delivery failed after %d attempts", attempt)
 		}
 
 		// Calculate delay before next retry
@@ -131,9 +241,10 @@ func (d *Dispatcher) SendAlert(
 			case <-time.After(delay):
 				// Continue to next attempt
 			case <-ctx.Done():
+				permit.Cancel()
 				alertLog.FinalStatus = "failed"
 				alertLog.CompletedAt = time.Now().UTC()
-				return alertLog, ctx.Err()
+				return ctx.Err()
 			}
 		}
 	}
@@ -147,13 +258,14 @@ func (d *Dispatcher) SendAlert(
 
 	alertLog.FinalStatus = "failed"
 	alertLog.CompletedAt = time.Now().UTC()
-	d.circuitBreaker.RecordFailure()
-	return alertLog, fmt.Errorf("webhook delivery failed after %d attempts", retryStrategy.GetMaxAttempts())
+	permit.Failure()
+	return fmt.Errorf("webhook delivery failed after %d attempts", retryStrategy.GetMaxAttempts())
 }
 
-// deliverWebhook performs a single webhook delivery attempt
-func (d *Dispatcher) deliverWebhook(
+// deliverAttempt performs a single delivery attempt through the sender of the webhook's type
+func (d *Dispatcher) deliverAttempt(
 	ctx context.Context,
+	alertLog *model.AlertLog,
 	webhook model.Webhook,
 	payload AlertPayloadData,
 ) (model.AlertAttempt, error) {
@@ -162,58 +274,75 @@ func (d *Dispatcher) deliverWebhook(
 		Timestamp: start.UTC(),
 	}
 
-	// Marshal payload
-	payloadBytes, err := json.Marshal(map[string]interface{}{
-		"text": payload.Text,
-	})
+	sender, err := d.sender(webhook.Type)
 	if err != nil {
-		attempt.Error = fmt.Sprintf("Failed to marshal payload: %v", err)
-		attempt.DurationMs = time.Since(start).Milliseconds()
+		attempt.Error = err.Error()
 		return attempt, err
 	}
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, webhook.Method, webhook.URL, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		attempt.Error = fmt.Sprintf("Failed to create request: %v", err)
-		attempt.DurationMs = time.Since(start).Milliseconds()
-		return attempt, err
+	// The link is added at delivery time so its token is never stored with the alert log
+	text := payload.Text
+	if d.ackLinks != nil && alertLog.Severity != model.SeverityInfo {
+		text += "\n\nAcknowledge: " + d.ackLinks.URL(alertLog.ID.Hex(), start)
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	for key, value := range webhook.Headers {
-		req.Header.Set(key, value)
+	alert := plugins.Alert{
+		Destination:   pluginDestination(webhook),
+		CorrelationID: alertLog.CorrelationID,
+		Severity:      alertLog.Severity,
+		Text:          text,
+		Metadata:      payload.Metadata,
+		Details:       payload.Details,
 	}
-
-	// Send request
-	resp, err := d.httpClient.Do(req)
-	if err != nil {
-		attempt.Error = fmt.Sprintf("Request failed: %v", err)
-		attempt.DurationMs = time.Since(start).Milliseconds()
-		return attempt, err
+	if !alertLog.ID.IsZero() {
+		alert.AlertID = alertLog.ID.Hex()
 	}
-	defer resp.Body.Close()
 
-	// Read response body (limit to 1KB to prevent memory issues)
-	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	delivery, err := sender.Send(ctx, alert)
+	attempt.StatusCode = delivery.StatusCode
+	attempt.ResponseBody = delivery.Response
+	attempt.DurationMs = time.Since(start).Milliseconds()
 	if err != nil {
-		slog.Warn("Failed to read webhook response body", "error", err)
+		attempt.Error = err.Error()
+		return attempt, err
 	}
 
-	attempt.StatusCode = resp.StatusCode
-	attempt.ResponseBody = string(bodyBytes)
-	attempt.DurationMs = time.Since(start).Milliseconds()
+	return attempt, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		attempt.Error = fmt.Sprintf("Webhook returned status %d", resp.StatusCode)
-		return attempt, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+// sender returns the sender of a webhook type: a built-in one or a plugin's
+func (d *Dispatcher) sender(webhookType string) (Sender, error) {
+	if webhookType == "" {
+		webhookType = model.WebhookTypeWebhook
 	}
+	if sender, ok := d.senders[webhookType]; ok {
+		return sender, nil
+	}
+	if sender := d.plugins.AlertSender(webhookType); sender != nil {
+		return sender, nil
+	}
+	if webhookType == model.WebhookTypeEmail {
+		return nil, errors.New("email alerts are disabled; set SMTP_HOST")
+	}
+	return nil, fmt.Errorf("no plugin is loaded for webhook type %s", webhookType)
+}
 
-	return attempt, nil
+// CircuitBreakers returns the circuit breakers of the destination hosts
+func (d *Dispatcher) CircuitBreakers() *CircuitBreakers {
+	return d.circuitBreakers
 }
 
-// GetCircuitBreakerState returns the current circuit breaker state
-func (d *Dispatcher) GetCircuitBreakerState() string {
-	return d.circuitBreaker.GetStateName()
+// breakerHost returns the host whose circuit breaker guards a delivery; destinations without
+// a host, such as some plugin types, share one breaker per type
+func breakerHost(alertLog *model.AlertLog, webhook model.Webhook) string {
+	if alertLog.WebhookHost != "" {
+		return alertLog.WebhookHost
+	}
+	if host := webhookHost(webhook.URL); host != "" {
+		return host
+	}
+	if webhook.Type != "" {
+		return webhook.Type
+	}
+	return model.WebhookTypeWebhook
 }
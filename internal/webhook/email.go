@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/pkg/plugins"
+)
+
+// maxSubjectLength limits the email subject taken from the first line of the alert text
+const maxSubjectLength = 150
+
+// SMTPConfig configures the server email alerts are sent through
+type SMTPConfig struct {
+	Host     string
+	Port     int // 465 uses implicit TLS; other ports upgrade with STARTTLS when offered
+	Username string
+	Password string
+	From     string
+}
+
+// emailSender sends alerts as plain-text email
+type emailSender struct {
+	config  SMTPConfig
+	timeout time.Duration
+}
+
+// Send mails the alert to the recipients of the destination's mailto: URL
+func (s *emailSender) Send(ctx context.Context, alert plugins.Alert) (plugins.Delivery, error) {
+	var delivery plugins.Delivery
+
+	recipients, err := model.EmailRecipients(alert.Destination.URL)
+	if err != nil {
+		return delivery, err
+	}
+	message := emailMessage(s.config.From, recipients, alert, time.Now())
+
+	address := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	tlsConfig := &tls.Config{ServerName: s.config.Host}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var conn net.Conn
+	if s.config.Port == 465 {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return delivery, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		return delivery, fmt.Errorf("SMTP handshake failed: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && s.config.Port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return delivery, fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+	if s.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			return delivery, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.config.From); err != nil {
+		return delivery, fmt.Errorf("SMTP sender rejected: %w", err)
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return delivery, fmt.Errorf("SMTP recipient %s rejected: %w", recipient, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return delivery, fmt.Errorf("SMTP data rejected: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		return delivery, fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return delivery, fmt.Errorf("SMTP message rejected: %w", err)
+	}
+	if err := client.Quit(); err != nil && !errors.Is(err, net.ErrClosed) {
+		// The message was accepted before QUIT, so it counts as delivered
+		delivery.Response = fmt.Sprintf("QUIT failed: %v", err)
+	}
+
+	return delivery, nil
+}
+
+// emailMessage formats the alert as a plain-text email whose subject is the first line of the text
+func emailMessage(from string, recipients []string, alert plugins.Alert, now time.Time) []byte {
+	subject, _, _ := strings.Cut(alert.Text, "\n")
+	if len(subject) > maxSubjectLength {
+		subject = subject[:maxSubjectLength] + "..."
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	if alert.AlertID != "" {
+		fmt.Fprintf(&message, "%s: %s\r\n", HeaderAlertID, alert.AlertID)
+	}
+	if alert.CorrelationID != "" {
+		fmt.Fprintf(&message, "%s: %s\r\n", HeaderCorrelationID, alert.CorrelationID)
+	}
+	message.WriteString("\r\n")
+
+	// The SMTP data writer turns the text's line feeds into CRLF
+	message.WriteString(alert.Text)
+	message.WriteString("\r\n")
+	if details := formatDetails(alert.Details); len(details) > 0 {
+		message.WriteString("\r\n")
+		if alert.Severity != "" {
+			fmt.Fprintf(&message, "severity: %s\r\n", alert.Severity)
+		}
+		for _, detail := range details {
+			fmt.Fprintf(&message, "%s: %s\r\n", detail.name, detail.value)
+		}
+	}
+	return message.Bytes()
+}
@@ -2,6 +2,8 @@ package webhook
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/dandantas/raven/internal/model"
 )
@@ -22,6 +24,7 @@ func FormatAlertPayload(
 	statusCode int,
 	correlationID string,
 	responseTimeMs int64,
+	severity string,
 ) AlertPayloadData {
 	// Create a user-friendly message
 	var message string
@@ -46,7 +49,7 @@ func FormatAlertPayload(
 			"rule_name":      ruleName,
 			"correlation_id": correlationID,
 			"timestamp":      "", // Will be set by dispatcher
-			"severity":       determineSeverity(evaluation),
+			"severity":       severity,
 		},
 		Details: map[string]interface{}{
 			"target_url":          targetURL,
@@ -60,13 +63,214 @@ func FormatAlertPayload(
 	}
 }
 
-// determineSeverity determines the alert severity based on the evaluation
-func determineSeverity(evaluation model.RuleEvaluation) string {
-	if evaluation.Error != "" {
-		return "error"
+// FormatSuiteDigestPayload creates a single digest payload summarizing a suite run
+func FormatSuiteDigestPayload(result *model.SuiteResult) AlertPayloadData {
+	var failing []string
+	members := make([]map[string]interface{}, 0, len(result.Members))
+	for _, member := range result.Members {
+		if !member.Passed {
+			name := member.ConfigName
+			if name == "" {
+				name = member.ConfigID.Hex()
+			}
+			failing = append(failing, name)
+		}
+		members = append(members, map[string]interface{}{
+			"config_id":        member.ConfigID.Hex(),
+			"config_name":      member.ConfigName,
+			"correlation_id":   member.CorrelationID,
+			"status":           member.Status,
+			"alerts_triggered": member.AlertsTriggered,
+			"passed":           member.Passed,
+			"error":            member.Error,
+		})
+	}
+
+	message := fmt.Sprintf(
+		"🚨 Suite Alert: %s - %d of %d checks failed: %s",
+		result.SuiteName,
+		result.Failed,
+		result.Total,
+		strings.Join(failing, ", "),
+	)
+
+	return AlertPayloadData{
+		Text: message,
+		Metadata: map[string]interface{}{
+			"service":        "raven-alert",
+			"suite_name":     result.SuiteName,
+			"correlation_id": result.CorrelationID,
+			"timestamp":      "", // Will be set by dispatcher
+			"severity":       model.SeverityWarning,
+		},
+		Details: map[string]interface{}{
+			"status":      result.Status,
+			"total":       result.Total,
+			"passed":      result.Passed,
+			"failed":      result.Failed,
+			"duration_ms": result.DurationMs,
+			"members":     members,
+		},
+	}
+}
+
+// FormatStateChangePayload creates a payload announcing a check's state transition
+func FormatStateChangePayload(configName, targetURL string, transition *model.StateTransition) AlertPayloadData {
+	return AlertPayloadData{
+		Text: fmt.Sprintf("%s State Change: %s - %s → %s", stateEmoji(transition.To), configName, transition.From, transition.To),
+		Metadata: map[string]interface{}{
+			"service":        "raven-alert",
+			"config_name":    configName,
+			"correlation_id": transition.CorrelationID,
+			"timestamp":      "", // Will be set by dispatcher
+			"severity":       model.StateSeverity(transition.To),
+			"event":          "state_change",
+		},
+		Details: map[string]interface{}{
+			"target_url":     targetURL,
+			"previous_state": transition.From,
+			"new_state":      transition.To,
+			"duration_ms":    transition.DurationMs,
+			"changed_at":     transition.At,
+		},
+	}
+}
+
+// stateEmoji returns the marker prefixed to state change messages
+func stateEmoji(state string) string {
+	switch state {
+	case model.CheckStateDown:
+		return "🚨"
+	case model.CheckStateDegraded:
+		return "⚠️"
+	default:
+		return "✅"
+	}
+}
+
+// FormatHeartbeatPayload creates a payload announcing a missed or recovered heartbeat
+func FormatHeartbeatPayload(heartbeat *model.Heartbeat, correlationID string, recovered bool) AlertPayloadData {
+	severity := heartbeat.AlertSeverity()
+	message := fmt.Sprintf(
+		"🚨 Heartbeat Alert: %s - no ping received within %s",
+		heartbeat.Name,
+		heartbeat.Period(),
+	)
+	if recovered {
+		severity = model.SeverityInfo
+		message = fmt.Sprintf("✅ Heartbeat Recovered: %s - ping received", heartbeat.Name)
+	}
+
+	return AlertPayloadData{
+		Text: message,
+		Metadata: map[string]interface{}{
+			"service":        "raven-alert",
+			"heartbeat_name": heartbeat.Name,
+			"correlation_id": correlationID,
+			"timestamp":      "", // Will be set by dispatcher
+			"severity":       severity,
+		},
+		Details: map[string]interface{}{
+			"status":           heartbeat.Status,
+			"interval_seconds": heartbeat.IntervalSeconds,
+			"grace_seconds":    heartbeat.GraceSeconds,
+			"last_ping_at":     heartbeat.LastPingAt,
+			"expected_by":      heartbeat.ExpectedBy,
+		},
+	}
+}
+
+// FormatBudgetExceededPayload creates a payload announcing that a check was skipped because
+// its own or the global daily execution budget was used up
+func FormatBudgetExceededPayload(configName, scope string, limit int, correlationID string) AlertPayloadData {
+	message := fmt.Sprintf("⚠️ Budget Exceeded: %s - daily limit of %d executions reached, skipping until tomorrow (UTC)", configName, limit)
+	if scope == "global" {
+		message = fmt.Sprintf("⚠️ Budget Exceeded: global daily limit of %d executions reached, skipping checks until tomorrow (UTC); first skipped: %s", limit, configName)
+	}
+
+	return AlertPayloadData{
+		Text: message,
+		Metadata: map[string]interface{}{
+			"service":        "raven-alert",
+			"config_name":    configName,
+			"correlation_id": correlationID,
+			"timestamp":      "", // Will be set by dispatcher
+			"severity":       model.SeverityWarning,
+			"event":          "budget_exceeded",
+		},
+		Details: map[string]interface{}{
+			"scope": scope,
+			"limit": limit,
+		},
 	}
+}
+
+// FormatSchedulerBacklogPayload creates a payload warning that due scheduled checks are
+// running late because the scheduler can't keep up
+func FormatSchedulerBacklogPayload(podID string, backlog, threshold int, lag time.Duration, correlationID string) AlertPayloadData {
+	return AlertPayloadData{
+		Text: fmt.Sprintf("⚠️ Scheduler Behind: %d scheduled checks are more than a tick late (threshold %d), the oldest by %s; add pods or raise SCHEDULER_CONCURRENCY", backlog, threshold, lag.Round(time.Second)),
+		Metadata: map[string]interface{}{
+			"service":        "raven-alert",
+			"correlation_id": correlationID,
+			"timestamp":      "", // Will be set by dispatcher
+			"severity":       model.SeverityWarning,
+			"event":          "scheduler_backlog",
+		},
+		Details: map[string]interface{}{
+			"backlog":     backlog,
+			"threshold":   threshold,
+			"lag_seconds": int64(lag.Seconds()),
+			"pod_id":      podID,
+		},
+	}
+}
+
+// FormatAutoDisabledPayload creates a payload announcing that a check was disabled after its
+// target stayed unreachable, with the endpoint that re-enables it
+func FormatAutoDisabledPayload(configID, configName, targetURL string, streak *model.UnreachableStreak, correlationID string) AlertPayloadData {
+	return AlertPayloadData{
+		Text: fmt.Sprintf("⚠️ Check Disabled: %s - target unreachable for %d consecutive runs; re-enable with POST /api/v1/health-checks/%s/enable", configName, streak.Runs, configID),
+		Metadata: map[string]interface{}{
+			"service":        "raven-alert",
+			"config_name":    configName,
+			"correlation_id": correlationID,
+			"timestamp":      "", // Will be set by dispatcher
+			"severity":       model.SeverityWarning,
+			"event":          "auto_disabled",
+		},
+		Details: map[string]interface{}{
+			"target_url":        targetURL,
+			"unreachable_runs":  streak.Runs,
+			"unreachable_since": streak.Since,
+			"enable_endpoint":   "/api/v1/health-checks/" + configID + "/enable",
+		},
+	}
+}
+
+// FormatIngestedAlertPayload creates a payload for an alert pushed by an external system
+func FormatIngestedAlertPayload(request *model.AlertIngestRequest, correlationID string) AlertPayloadData {
+	details := request.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+
+	return AlertPayloadData{
+		Text: fmt.Sprintf("🚨 Alert from %s: %s", request.Source, request.Summary),
+		Metadata: map[string]interface{}{
+			"service":        "raven-alert",
+			"source":         request.Source,
+			"dedup_key":      request.DedupKey,
+			"correlation_id": correlationID,
+			"timestamp":      "", // Will be set by dispatcher
+			"severity":       request.Severity,
+		},
+		Details: details,
+	}
+}
 
-	// Could be extended with more sophisticated logic
-	// For now, all matched rules are warnings
-	return "warning"
+// payloadSeverity returns the severity recorded in a payload's metadata
+func payloadSeverity(payload AlertPayloadData) string {
+	severity, _ := payload.Metadata["severity"].(string)
+	return severity
 }
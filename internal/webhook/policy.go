@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/dandantas/raven/internal/model"
+)
+
+// DestinationPolicy restricts the hosts webhooks may be delivered to. Entries are
+// exact domains ("hooks.slack.com"), wildcard subdomains ("*.example.com"), IP
+// addresses or CIDR ranges. A nil or empty policy allows every destination.
+type DestinationPolicy struct {
+	domains   []string
+	wildcards []string // Suffixes including the leading dot
+	networks  []*net.IPNet
+	resolver  *net.Resolver
+}
+
+// NewDestinationPolicy parses an allow-list of webhook destinations
+func NewDestinationPolicy(entries []string) (*DestinationPolicy, error) {
+	policy := &DestinationPolicy{
+		resolver: net.DefaultResolver,
+	}
+
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid webhook destination CIDR %q: %w", entry, err)
+			}
+			policy.networks = append(policy.networks, network)
+			continue
+		}
+
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			policy.networks = append(policy.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		if strings.HasPrefix(entry, "*.") {
+			policy.wildcards = append(policy.wildcards, entry[1:])
+			continue
+		}
+
+		policy.domains = append(policy.domains, entry)
+	}
+
+	return policy, nil
+}
+
+// Enabled reports whether the policy restricts destinations
+func (p *DestinationPolicy) Enabled() bool {
+	return p != nil && (len(p.domains) > 0 || len(p.wildcards) > 0 || len(p.networks) > 0)
+}
+
+// Allowed returns an error when the webhook URL points outside the allow-list.
+// Hostnames not matched by a domain entry are resolved and every address must
+// fall within an allowed network. The answer may change before delivery, so
+// deliveries are checked again on the dialed address by DialContext.
+func (p *DestinationPolicy) Allowed(ctx context.Context, rawURL string) error {
+	if !p.Enabled() {
+		return nil
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	host := strings.ToLower(parsedURL.Hostname())
+	if host == "" {
+		return fmt.Errorf("webhook URL has no host")
+	}
+
+	if p.domainAllowed(host) {
+		return nil
+	}
+
+	if len(p.networks) > 0 {
+		ips, err := p.resolve(ctx, host)
+		if err != nil {
+			return fmt.Errorf("failed to resolve webhook host %s: %w", host, err)
+		}
+		if len(ips) > 0 && p.networksAllowed(ips) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("webhook destination %s is not in the allow-list", host)
+}
+
+// AllowedWebhook returns an error when the webhook's destination is outside the allow-list.
+// Email recipients must be in an allowed domain. Plugins decide where their alerts go, so
+// their destinations aren't checked.
+func (p *DestinationPolicy) AllowedWebhook(ctx context.Context, w model.Webhook) error {
+	if !p.Enabled() || w.IsPlugin() {
+		return nil
+	}
+
+	if w.Type == model.WebhookTypeEmail {
+		recipients, err := model.EmailRecipients(w.URL)
+		if err != nil {
+			return err
+		}
+		for _, recipient := range recipients {
+			if domain := model.EmailDomain(recipient); !p.domainAllowed(domain) {
+				return fmt.Errorf("email recipient domain %s is not in the allow-list", domain)
+			}
+		}
+		return nil
+	}
+
+	return p.Allowed(ctx, w.URL)
+}
+
+// DialContext returns a dial function for the webhook transport that checks the address
+// actually connected to, so a hostname can't be rebound to a disallowed address after it
+// passed Allowed. Hosts matched by a domain entry may connect to any address.
+func (p *DestinationPolicy) DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if !p.Enabled() {
+		return dialer.DialContext
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		host = strings.ToLower(host)
+		if p.domainAllowed(host) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		checked := *dialer
+		checked.Control = p.controlDial(host)
+		return checked.DialContext(ctx, network, addr)
+	}
+}
+
+// controlDial rejects connections to addresses outside the allowed networks before they are made
+func (p *DestinationPolicy) controlDial(host string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		ipStr, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(ipStr)
+		if ip == nil || !p.networksAllowed([]net.IP{ip}) {
+			return fmt.Errorf("webhook destination %s (%s) is not in the allow-list", host, ipStr)
+		}
+		return nil
+	}
+}
+
+// domainAllowed checks the host against the exact and wildcard domain entries
+func (p *DestinationPolicy) domainAllowed(host string) bool {
+	for _, domain := range p.domains {
+		if host == domain {
+			return true
+		}
+	}
+	for _, suffix := range p.wildcards {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// networksAllowed reports whether every address is inside an allowed network
+func (p *DestinationPolicy) networksAllowed(ips []net.IP) bool {
+	for _, ip := range ips {
+		allowed := false
+		for _, network := range p.networks {
+			if network.Contains(ip) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve returns the addresses of host, or host itself when it is an IP literal
+func (p *DestinationPolicy) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+
+	addrs, err := p.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	return ips, nil
+}
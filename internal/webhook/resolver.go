@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dandantas/raven/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNoWebhook is returned when a config has no webhook and no default is configured
+var ErrNoWebhook = errors.New("no webhook configured and no default webhook is set")
+
+// ChannelStore looks up notification channels by ID
+type ChannelStore interface {
+	GetByID(ctx context.Context, id primitive.ObjectID) (*model.NotificationChannel, error)
+}
+
+// Resolver turns the webhook of a config into the destination alerts are sent to:
+// a notification channel, a named profile, the config's own URL, or the global default webhook
+type Resolver struct {
+	defaultWebhook *model.Webhook
+	profiles       map[string]model.Webhook
+	policy         *DestinationPolicy
+	channels       ChannelStore
+}
+
+// NewResolver creates a webhook resolver. defaultWebhook may be nil.
+func NewResolver(defaultWebhook *model.Webhook, profiles map[string]model.Webhook, policy *DestinationPolicy) (*Resolver, error) {
+	if defaultWebhook != nil {
+		if err := defaultWebhook.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid default webhook: %w", err)
+		}
+		if defaultWebhook.URL == "" {
+			return nil, errors.New("invalid default webhook: URL is required")
+		}
+	}
+
+	for name, profile := range profiles {
+		if profile.Profile != "" || profile.URL == "" {
+			return nil, fmt.Errorf("invalid webhook profile %q: URL is required", name)
+		}
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid webhook profile %q: %w", name, err)
+		}
+		profiles[name] = profile
+	}
+
+	return &Resolver{
+		defaultWebhook: defaultWebhook,
+		profiles:       profiles,
+		policy:         policy,
+	}, nil
+}
+
+// SetChannelStore enables resolving webhooks that reference notification channels
+func (r *Resolver) SetChannelStore(channels ChannelStore) {
+	r.channels = channels
+}
+
+// LoadProfiles reads webhook profiles from a JSON file mapping profile names to webhooks
+func LoadProfiles(path string) (map[string]model.Webhook, error) {
+	profiles := make(map[string]model.Webhook)
+	if path == "" {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook profiles: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook profiles: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// Resolve returns the effective webhook for w
+func (r *Resolver) Resolve(ctx context.Context, w model.Webhook) (model.Webhook, error) {
+	switch {
+	case !w.ChannelID.IsZero():
+		if r.channels == nil {
+			return model.Webhook{}, errors.New("notification channels are not available")
+		}
+		channel, err := r.channels.GetByID(ctx, w.ChannelID)
+		if err != nil {
+			return model.Webhook{}, fmt.Errorf("channel %s: %w", w.ChannelID.Hex(), err)
+		}
+		resolved := channel.Webhook
+		resolved.ChannelID = channel.ID
+		resolved.CircuitBreaker = channel.CircuitBreaker
+		return resolved, nil
+	case w.Profile != "":
+		profile, ok := r.profiles[w.Profile]
+		if !ok {
+			return model.Webhook{}, fmt.Errorf("webhook profile %q not found", w.Profile)
+		}
+		profile.Profile = w.Profile
+		return profile, nil
+	case w.URL != "":
+		return w, nil
+	case r.defaultWebhook != nil:
+		return *r.defaultWebhook, nil
+	default:
+		return model.Webhook{}, ErrNoWebhook
+	}
+}
+
+// Validate checks that w resolves to a destination allowed by the destination policy
+func (r *Resolver) Validate(ctx context.Context, w model.Webhook) error {
+	resolved, err := r.Resolve(ctx, w)
+	if err != nil {
+		return err
+	}
+	return r.policy.AllowedWebhook(ctx, resolved)
+}
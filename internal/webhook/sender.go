@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/dandantas/raven/internal/model"
+	"github.com/dandantas/raven/pkg/plugins"
+)
+
+// maxDetailLength limits how much of a detail value is shown in Slack and email alerts
+const maxDetailLength = 500
+
+// Sender makes one delivery attempt of an alert to a destination of its type. Plugin alert
+// senders implement it too.
+type Sender interface {
+	Send(ctx context.Context, alert plugins.Alert) (plugins.Delivery, error)
+}
+
+// pluginValidateTimeout limits how long a plugin may take to validate a webhook
+const pluginValidateTimeout = 10 * time.Second
+
+// PluginValidator returns the validator of a webhook type delivered by sender
+func PluginValidator(sender plugins.AlertSender) model.WebhookTypeValidator {
+	return func(w *model.Webhook) error {
+		ctx, cancel := context.WithTimeout(context.Background(), pluginValidateTimeout)
+		defer cancel()
+
+		if err := sender.Validate(ctx, pluginDestination(*w)); err != nil {
+			return fmt.Errorf("invalid %s webhook: %w", w.Type, err)
+		}
+		return nil
+	}
+}
+
+// pluginDestination describes a webhook to a sender
+func pluginDestination(w model.Webhook) plugins.Destination {
+	return plugins.Destination{
+		Type:     w.Type,
+		URL:      w.URL,
+		Method:   w.Method,
+		Headers:  w.Headers,
+		Settings: w.Settings,
+	}
+}
+
+// httpSender posts the alert text as {"text": ...} to the webhook URL
+type httpSender struct {
+	client *http.Client
+}
+
+// Send posts the alert
+func (s *httpSender) Send(ctx context.Context, alert plugins.Alert) (plugins.Delivery, error) {
+	return postJSON(ctx, s.client, alert, map[string]any{"text": alert.Text})
+}
+
+// slackColors maps alert severities to Slack attachment colors
+var slackColors = map[string]string{
+	model.SeverityInfo:     "#439fe0",
+	model.SeverityWarning:  "warning",
+	model.SeverityError:    "danger",
+	model.SeverityCritical: "#7a0012",
+}
+
+// slackSender posts alerts to Slack incoming webhooks, with the details as attachment fields
+// colored by severity
+type slackSender struct {
+	client *http.Client
+}
+
+// Send posts the alert
+func (s *slackSender) Send(ctx context.Context, alert plugins.Alert) (plugins.Delivery, error) {
+	var fields []map[string]any
+	if alert.Severity != "" {
+		fields = append(fields, map[string]any{"title": "severity", "value": alert.Severity, "short": true})
+	}
+	for _, detail := range formatDetails(alert.Details) {
+		fields = append(fields, map[string]any{"title": detail.name, "value": detail.value, "short": len(detail.value) <= 40})
+	}
+
+	attachment := map[string]any{"fields": fields}
+	if color, ok := slackColors[alert.Severity]; ok {
+		attachment["color"] = color
+	}
+	if alert.CorrelationID != "" {
+		attachment["footer"] = "Raven · " + alert.CorrelationID
+	}
+
+	return postJSON(ctx, s.client, alert, map[string]any{
+		"text":        alert.Text,
+		"attachments": []map[string]any{attachment},
+	})
+}
+
+// postJSON sends payload to the destination URL and fails on non-2xx responses; receivers can
+// link the notification back to the execution and alert through its headers
+func postJSON(ctx context.Context, client *http.Client, alert plugins.Alert, payload any) (plugins.Delivery, error) {
+	var delivery plugins.Delivery
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return delivery, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, alert.Destination.Method, alert.Destination.URL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return delivery, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if alert.CorrelationID != "" {
+		req.Header.Set(HeaderCorrelationID, alert.CorrelationID)
+	}
+	if alert.AlertID != "" {
+		req.Header.Set(HeaderAlertID, alert.AlertID)
+	}
+	for key, value := range alert.Destination.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return delivery, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body (limit to 1KB to prevent memory issues)
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		slog.Warn("Failed to read webhook response body", "error", err)
+	}
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Response = string(bodyBytes)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return delivery, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return delivery, nil
+}
+
+// detail is an alert detail formatted for display
+type detail struct {
+	name  string
+	value string
+}
+
+// formatDetails returns the alert details in name order, with structured values as JSON
+func formatDetails(details map[string]any) []detail {
+	formatted := make([]detail, 0, len(details))
+	for _, name := range slices.Sorted(maps.Keys(details)) {
+		var value string
+		switch v := details[name].(type) {
+		case nil:
+			continue
+		case string:
+			value = v
+		case bool, int, int32, int64, float32, float64:
+			value = fmt.Sprint(v)
+		default:
+			data, _ := json.Marshal(v)
+			value = string(data)
+		}
+		if value == "" {
+			continue
+		}
+		if len(value) > maxDetailLength {
+			value = value[:maxDetailLength] + "..."
+		}
+		formatted = append(formatted, detail{name: name, value: value})
+	}
+	return formatted
+}
@@ -8,15 +8,23 @@ import (
 
 // Job represents a health check execution job
 type Job struct {
-	ConfigID      string
-	CorrelationID string
-	Context       context.Context
-	Async         bool // If true, result won't be sent to results channel
+	ConfigID             string
+	CorrelationID        string
+	BatchID              string // Set when the job is part of a batch
+	TriggeredBy          string // Principal that requested the job
+	RequestCorrelationID string // Correlation ID of the API request that submitted the job
+	Index                int    // Position of the job within its batch
+	Context              context.Context
+	Async                bool          // If true, result won't be sent to results channel
+	Results              chan<- Result // Optional per-request results channel, used instead of the pool's; needs room for all its jobs
 }
 
 // Result represents the result of a health check execution
 type Result struct {
-	Execution *model.ExecutionHistory
-	Error     error
-	JobID     string // For async jobs
+	Execution     *model.ExecutionHistory
+	Error         error
+	JobID         string // For async jobs
+	ConfigID      string
+	CorrelationID string
+	Index         int
 }
@@ -2,14 +2,18 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
 
 	"github.com/dandantas/raven/internal/model"
 )
 
+// ErrPoolStopped is returned when a job is submitted after the pool was stopped
+var ErrPoolStopped = errors.New("worker pool is stopped")
+
 // ExecutorFunc is a function that executes a health check job
-type ExecutorFunc func(ctx context.Context, configID, correlationID string) (interface{}, error)
+type ExecutorFunc func(ctx context.Context, job Job) (interface{}, error)
 
 // WorkerPool manages a pool of worker goroutines for concurrent job execution
 type WorkerPool struct {
@@ -20,6 +24,8 @@ type WorkerPool struct {
 	wg         sync.WaitGroup
 	ctx        context.Context
 	cancel     context.CancelFunc
+	mu         sync.RWMutex // Held for reading while submitting, so jobs isn't closed mid-send
+	stopped    bool
 }
 
 // NewWorkerPool creates a new worker pool
@@ -50,12 +56,23 @@ func (wp *WorkerPool) Start() {
 	}
 }
 
-// Stop stops the worker pool gracefully
+// Stop stops the worker pool gracefully. Running jobs finish; queued jobs are not run and
+// report ErrPoolStopped, so a caller waiting on a job's Results channel always gets a result.
 func (wp *WorkerPool) Stop() {
 	slog.Info("Stopping worker pool")
 
+	// Cancel first so blocked submits give up and release the lock
+	wp.cancel()
+
 	// Close jobs channel to signal workers to stop
+	wp.mu.Lock()
+	if wp.stopped {
+		wp.mu.Unlock()
+		return
+	}
+	wp.stopped = true
 	close(wp.jobs)
+	wp.mu.Unlock()
 
 	// Wait for all workers to finish
 	wp.wg.Wait()
@@ -63,14 +80,18 @@ func (wp *WorkerPool) Stop() {
 	// Close results channel
 	close(wp.results)
 
-	// Cancel context
-	wp.cancel()
-
 	slog.Info("Worker pool stopped")
 }
 
 // Submit submits a job to the worker pool
 func (wp *WorkerPool) Submit(job Job) error {
+	wp.mu.RLock()
+	defer wp.mu.RUnlock()
+
+	if wp.stopped {
+		return ErrPoolStopped
+	}
+
 	select {
 	case wp.jobs <- job:
 		slog.Debug("Job submitted to worker pool",
@@ -80,7 +101,7 @@ func (wp *WorkerPool) Submit(job Job) error {
 		)
 		return nil
 	case <-wp.ctx.Done():
-		return wp.ctx.Err()
+		return ErrPoolStopped
 	}
 }
 
@@ -102,8 +123,12 @@ func (wp *WorkerPool) worker(id int) {
 			"correlation_id", job.CorrelationID,
 		)
 
-		// Execute the job
-		result, err := wp.executorFn(job.Context, job.ConfigID, job.CorrelationID)
+		// Jobs still queued when the pool stops are reported without running
+		var result interface{}
+		err := ErrPoolStopped
+		if wp.ctx.Err() == nil {
+			result, err = wp.executorFn(job.Context, job)
+		}
 
 		// For async jobs, we don't send results to the channel
 		if job.Async {
@@ -116,7 +141,10 @@ func (wp *WorkerPool) worker(id int) {
 
 		// Send result to results channel (for sync jobs)
 		jobResult := Result{
-			Error: err,
+			Error:         err,
+			ConfigID:      job.ConfigID,
+			CorrelationID: job.CorrelationID,
+			Index:         job.Index,
 		}
 
 		if result != nil {
@@ -125,6 +153,13 @@ func (wp *WorkerPool) worker(id int) {
 			}
 		}
 
+		// A job's own results channel is sized to its batch, so the send never blocks and
+		// the result isn't dropped when the pool stops
+		if job.Results != nil {
+			job.Results <- jobResult
+			continue
+		}
+
 		select {
 		case wp.results <- jobResult:
 			slog.Debug("Job result sent",
@@ -132,7 +167,6 @@ func (wp *WorkerPool) worker(id int) {
 				"correlation_id", job.CorrelationID,
 			)
 		case <-wp.ctx.Done():
-			return
 		}
 	}
 
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+// PrincipalKey is the context key for the name of the API key used by the caller
+const PrincipalKey contextKey = "principal"
+
+// APIKeys middleware identifies callers by the X-API-Key header. keys maps key names
+// to secrets. Requests without a key pass through anonymously; unknown keys are rejected.
+func APIKeys(keys map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get("X-API-Key")
+			if provided == "" || len(keys) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for name, secret := range keys {
+				if subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) == 1 {
+					ctx := context.WithValue(r.Context(), PrincipalKey, name)
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+				}
+			}
+
+			slog.Warn("Rejected invalid API key",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"client_ip", GetClientIP(r.Context()),
+				"correlation_id", GetCorrelationID(r.Context()),
+			)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":   http.StatusText(http.StatusUnauthorized),
+				"message": "Invalid API key",
+			})
+		})
+	}
+}
+
+// GetPrincipal extracts the API key name from context
+func GetPrincipal(ctx context.Context) string {
+	if name, ok := ctx.Value(PrincipalKey).(string); ok {
+		return name
+	}
+	return ""
+}
+
+// RequireAdmin only lets callers identified by one of the named API keys through
+func RequireAdmin(names []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := GetPrincipal(r.Context())
+			if principal != "" && slices.Contains(names, principal) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			status, message := http.StatusForbidden, "API key is not allowed to use admin endpoints"
+			if principal == "" {
+				status, message = http.StatusUnauthorized, "Admin endpoints require an API key"
+			}
+
+			slog.Warn("Rejected admin request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"principal", principal,
+				"client_ip", GetClientIP(r.Context()),
+				"correlation_id", GetCorrelationID(r.Context()),
+			)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":   http.StatusText(status),
+				"message": message,
+			})
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize is the smallest response worth compressing
+const gzipMinSize = 1024
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// gzipResponseWriter buffers the start of a response and compresses it once it
+// grows past gzipMinSize
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	buf         []byte
+	statusCode  int
+	wroteHeader bool
+	decided     bool
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (gw *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
+
+func (gw *gzipResponseWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+	gw.statusCode = code
+
+	// Responses without a body or already encoded pass through untouched
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified ||
+		gw.Header().Get("Content-Encoding") != "" {
+		gw.decided = true
+		gw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (gw *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !gw.wroteHeader {
+		gw.WriteHeader(http.StatusOK)
+	}
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+	if gw.decided {
+		return gw.ResponseWriter.Write(b)
+	}
+
+	gw.buf = append(gw.buf, b...)
+	if len(gw.buf) >= gzipMinSize {
+		if err := gw.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// start sends the headers and buffered bytes, compressed or not
+func (gw *gzipResponseWriter) start(compress bool) error {
+	gw.decided = true
+	if compress {
+		header := gw.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		gz := gzipWriterPool.Get().(*gzip.Writer)
+		gz.Reset(gw.ResponseWriter)
+		gw.gz = gz
+	}
+	gw.ResponseWriter.WriteHeader(gw.statusCode)
+
+	buf := gw.buf
+	gw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if gw.gz != nil {
+		_, err := gw.gz.Write(buf)
+		return err
+	}
+	_, err := gw.ResponseWriter.Write(buf)
+	return err
+}
+
+// close flushes buffered output and returns the gzip writer to the pool
+func (gw *gzipResponseWriter) close() {
+	if !gw.decided {
+		if !gw.wroteHeader {
+			return
+		}
+		gw.start(false)
+	}
+	if gw.gz != nil {
+		gw.gz.Close()
+		gzipWriterPool.Put(gw.gz)
+		gw.gz = nil
+	}
+}
+
+// Flush sends any buffered data to the client, compressing if it was already started
+func (gw *gzipResponseWriter) Flush() {
+	gw.FlushError()
+}
+
+// FlushError flushes like Flush and reports failures; http.ResponseController prefers it.
+// The writers beneath, such as the logging middleware's, are reached through their Unwrap.
+func (gw *gzipResponseWriter) FlushError() error {
+	if !gw.decided && gw.wroteHeader {
+		if err := gw.start(len(gw.buf) >= gzipMinSize); err != nil {
+			return err
+		}
+	}
+	if gw.gz != nil {
+		if err := gw.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(gw.ResponseWriter).Flush()
+}
+
+// Hijack lets connection upgrades bypass compression
+func (gw *gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(gw.ResponseWriter).Hijack()
+}
+
+// Gzip middleware compresses responses for clients that accept gzip encoding
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		defer gw.close()
+
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		params = strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		if params == "q=0" || params == "q=0.0" || params == "q=0.00" || params == "q=0.000" {
+			return false
+		}
+		return true
+	}
+	return false
+}
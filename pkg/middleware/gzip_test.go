@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGzipFlushThroughMiddlewareChain checks that a flush from a streaming handler reaches the
+// client through the same middleware stack as the API router, where the gzip writer wraps the
+// logging middleware's writer
+func TestGzipFlushThroughMiddlewareChain(t *testing.T) {
+	// Long enough to be compressed rather than sent as is
+	first := `{"line":1,"padding":"` + strings.Repeat("x", gzipMinSize) + `"}`
+
+	release := make(chan struct{})
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(first + "\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+		<-release
+		w.Write([]byte(`{"line":2}` + "\n"))
+	})
+
+	var handler http.Handler = stream
+	handler = Gzip(handler)
+	handler = Recovery(handler)
+	handler = LoggingWithConfig(LoggingConfig{})(handler)
+	handler = CorrelationID(handler)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	defer close(release) // Runs before Close, which waits for the handler
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Set explicitly so the transport leaves the body compressed
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	// Without the flush even the headers wait for the handler, so bound the whole exchange
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("response was not flushed to the client: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+
+	lines := make(chan string, 1)
+	go func() {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			lines <- "error: " + err.Error()
+			return
+		}
+		line, err := bufio.NewReader(gz).ReadString('\n')
+		if err != nil {
+			line = "error: " + err.Error()
+		}
+		lines <- line
+	}()
+
+	// The handler is still blocked, so the first line can only arrive through the flush
+	select {
+	case line := <-lines:
+		if strings.TrimSpace(line) != first {
+			t.Fatalf("first line = %.40q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("first line was not flushed to the client")
+	}
+}
@@ -1,11 +1,30 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// redactedValue replaces sensitive values in captured bodies
+const redactedValue = "[REDACTED]"
+
+// defaultRedactFields are always redacted from captured bodies. Keys match when they contain a
+// field ignoring case, dashes and underscores, so "apikey" also covers "X-API-Key" and
+// "cookie" covers "Set-Cookie".
+var defaultRedactFields = []string{"password", "secret", "token", "authorization", "apikey", "credential", "cookie", "xauth", "privatekey"}
+
+// LoggingConfig holds request logging configuration
+type LoggingConfig struct {
+	CaptureBodies bool     // Log request bodies of mutating requests
+	MaxBodyBytes  int      // Bodies larger than this are not captured
+	RedactFields  []string // Extra JSON keys to redact in addition to the defaults
+}
+
 // responseWriter wraps http.ResponseWriter to capture status code
 type responseWriter struct {
 	http.ResponseWriter
@@ -13,6 +32,11 @@ type responseWriter struct {
 	written    int64
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
@@ -26,37 +50,130 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 
 // Logging middleware logs HTTP requests and responses
 func Logging(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+	return LoggingWithConfig(LoggingConfig{})(next)
+}
+
+// LoggingWithConfig logs HTTP requests and responses, optionally capturing request bodies
+func LoggingWithConfig(config LoggingConfig) func(http.Handler) http.Handler {
+	redact := append(append([]string{}, defaultRedactFields...), config.RedactFields...)
+	for i, field := range redact {
+		redact[i] = normalizeKey(field)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// Wrap response writer
+			rw := &responseWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+
+			// Get correlation ID from context
+			correlationID := GetCorrelationID(r.Context())
+
+			attrs := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"remote_addr", r.RemoteAddr,
+				"client_ip", GetClientIP(r.Context()),
+				"correlation_id", correlationID,
+			}
+			if config.CaptureBodies && hasMutatingMethod(r) {
+				attrs = append(attrs, captureBody(r, config.MaxBodyBytes, redact)...)
+			}
+			slog.Info("HTTP request received", attrs...)
+
+			// Call next handler
+			next.ServeHTTP(rw, r)
+
+			// Log response
+			duration := time.Since(start)
+			slog.Info("HTTP request completed",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status_code", rw.statusCode,
+				"duration_ms", duration.Milliseconds(),
+				"bytes_written", rw.written,
+				"correlation_id", correlationID,
+			)
+		})
+	}
+}
 
-		// Wrap response writer
-		rw := &responseWriter{
-			ResponseWriter: w,
-			statusCode:     http.StatusOK,
+// hasMutatingMethod reports whether the request may change server state
+func hasMutatingMethod(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// captureBody reads up to maxBytes of the request body, restores it for the handler,
+// and returns log attributes with the redacted body. Oversized or non-JSON bodies are
+// reported by size only so nothing sensitive leaks unredacted.
+func captureBody(r *http.Request, maxBytes int, redact []string) []any {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+	if err != nil {
+		return []any{"body_error", err.Error()}
+	}
+
+	if len(buf) > maxBytes {
+		return []any{"body_truncated", true}
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(buf, &body); err != nil {
+		return []any{"body_bytes", len(buf)}
+	}
+	redactValue(body, redact)
+
+	return []any{"body", body}
+}
+
+// redactValue replaces values of sensitive keys in a decoded JSON value
+func redactValue(value interface{}, redact []string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if isSensitiveKey(key, redact) {
+				v[key] = redactedValue
+				continue
+			}
+			redactValue(child, redact)
+		}
+	case []interface{}:
+		for _, child := range v {
+			redactValue(child, redact)
 		}
+	}
+}
+
+// isSensitiveKey reports whether a JSON key contains one of the normalized redacted field names
+func isSensitiveKey(key string, redact []string) bool {
+	key = normalizeKey(key)
+	for _, field := range redact {
+		if field != "" && strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// keySeparators are ignored when matching keys, so header and JSON spellings match alike
+var keySeparators = strings.NewReplacer("-", "", "_", "")
 
-		// Get correlation ID from context
-		correlationID := GetCorrelationID(r.Context())
-
-		slog.Info("HTTP request received",
-			"method", r.Method,
-			"path", r.URL.Path,
-			"remote_addr", r.RemoteAddr,
-			"correlation_id", correlationID,
-		)
-
-		// Call next handler
-		next.ServeHTTP(rw, r)
-
-		// Log response
-		duration := time.Since(start)
-		slog.Info("HTTP request completed",
-			"method", r.Method,
-			"path", r.URL.Path,
-			"status_code", rw.statusCode,
-			"duration_ms", duration.Milliseconds(),
-			"bytes_written", rw.written,
-			"correlation_id", correlationID,
-		)
-	})
+// normalizeKey lower-cases a key and strips its separators
+func normalizeKey(key string) string {
+	return keySeparators.Replace(strings.ToLower(key))
 }
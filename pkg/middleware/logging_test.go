@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestCaptureBodyRedactsHeaders checks that credentials in the header maps of a health check
+// are redacted whatever the spelling of their names
+func TestCaptureBodyRedactsHeaders(t *testing.T) {
+	body := `{
+		"name": "orders-api",
+		"target": {
+			"url": "https://orders.example.com/health",
+			"headers": {
+				"X-API-Key": "k1",
+				"x_api_key": "k2",
+				"Authorization": "Bearer t1",
+				"Cookie": "session=s1",
+				"Set-Cookie": "session=s2",
+				"X-Auth-Token": "t2",
+				"X-Auth-User": "u1",
+				"Accept": "application/json"
+			},
+			"auth": {"type": "oauth2", "client_secret": "s3"}
+		},
+		"webhook": {"url": "https://hooks.example.com", "headers": {"X-Api-Key": "k3"}},
+		"signing": {"private_key": "pk1", "privateKey": "pk2", "Private-Key": "pk3"},
+		"session_id": "sid1"
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/health-checks", strings.NewReader(body))
+
+	redact := append([]string{}, defaultRedactFields...)
+	redact = append(redact, normalizeKey("Session_ID"))
+
+	attrs := captureBody(req, 1<<20, redact)
+	if len(attrs) != 2 || attrs[0] != "body" {
+		t.Fatalf("captureBody = %v, want a body attribute", attrs)
+	}
+	got := attrs[1].(map[string]interface{})
+
+	target := got["target"].(map[string]interface{})
+	headers := target["headers"].(map[string]interface{})
+	for _, name := range []string{"X-API-Key", "x_api_key", "Authorization", "Cookie", "Set-Cookie", "X-Auth-Token", "X-Auth-User"} {
+		if headers[name] != redactedValue {
+			t.Errorf("target.headers[%q] = %v, want redacted", name, headers[name])
+		}
+	}
+	if headers["Accept"] != "application/json" {
+		t.Errorf("target.headers[Accept] = %v, want it kept", headers["Accept"])
+	}
+	if auth := target["auth"].(map[string]interface{}); auth["client_secret"] != redactedValue {
+		t.Errorf("target.auth.client_secret = %v, want redacted", auth["client_secret"])
+	}
+
+	webhookHeaders := got["webhook"].(map[string]interface{})["headers"].(map[string]interface{})
+	if webhookHeaders["X-Api-Key"] != redactedValue {
+		t.Errorf("webhook.headers[X-Api-Key] = %v, want redacted", webhookHeaders["X-Api-Key"])
+	}
+
+	signing := got["signing"].(map[string]interface{})
+	for _, name := range []string{"private_key", "privateKey", "Private-Key"} {
+		if signing[name] != redactedValue {
+			t.Errorf("signing[%q] = %v, want redacted", name, signing[name])
+		}
+	}
+
+	if got["session_id"] != redactedValue {
+		t.Errorf("session_id = %v, want redacted by the extra field", got["session_id"])
+	}
+	if got["name"] != "orders-api" {
+		t.Errorf("name = %v, want it kept", got["name"])
+	}
+}
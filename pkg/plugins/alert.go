@@ -0,0 +1,39 @@
+package plugins
+
+import "context"
+
+// AlertSender delivers alerts to destinations of a custom notification type, such as a
+// proprietary paging system. Destinations are validated by it before they are saved.
+type AlertSender interface {
+	// Validate checks a destination; the returned error is shown to the user
+	Validate(ctx context.Context, dest Destination) error
+	// Send makes one delivery attempt. An error fails the attempt, which is retried with backoff
+	// as configured on the destination.
+	Send(ctx context.Context, alert Alert) (Delivery, error)
+}
+
+// Destination is where an alert is sent: a health check, channel or profile webhook
+type Destination struct {
+	Type     string            `json:"type"`
+	URL      string            `json:"url"`
+	Method   string            `json:"method,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Settings map[string]string `json:"settings,omitempty"` // The destination's type-specific settings
+}
+
+// Alert is one notification and where to send it
+type Alert struct {
+	Destination   Destination    `json:"destination"`
+	AlertID       string         `json:"alert_id,omitempty"`
+	CorrelationID string         `json:"correlation_id,omitempty"`
+	Severity      string         `json:"severity,omitempty"` // info, warning, error or critical
+	Text          string         `json:"text"`               // Human-readable message, including the acknowledge link when enabled
+	Metadata      map[string]any `json:"metadata,omitempty"` // Check, rule and timing information
+	Details       map[string]any `json:"details,omitempty"`  // What triggered the alert, e.g. the extracted and expected values
+}
+
+// Delivery describes a delivery attempt, for the alert log
+type Delivery struct {
+	StatusCode int    `json:"status_code,omitempty"` // Response status of the destination, when it has one
+	Response   string `json:"response,omitempty"`    // Start of the destination's response
+}
@@ -0,0 +1,50 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// CheckExecutor runs the checks of a custom check type. Health checks of the type are
+// validated by it before they are saved, and their target calls are made by it.
+type CheckExecutor interface {
+	// Validate checks the target and settings of a health check; the returned error is shown to the user
+	Validate(ctx context.Context, req CheckRequest) error
+	// Execute calls the target once. Rules are evaluated against the result body; an error
+	// fails the execution without evaluating them.
+	Execute(ctx context.Context, req CheckRequest) (CheckResult, error)
+}
+
+// CheckRequest describes a health check of a custom type and the call to make
+type CheckRequest struct {
+	Type           string            `json:"type"`
+	CheckID        string            `json:"check_id,omitempty"` // Empty for checks not saved yet
+	CheckName      string            `json:"check_name"`
+	CorrelationID  string            `json:"correlation_id,omitempty"` // Only set on execution
+	URL            string            `json:"url"`
+	Method         string            `json:"method,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	TimeoutSeconds int               `json:"timeout_seconds"`
+	Auth           *Auth             `json:"auth,omitempty"`
+	Settings       map[string]string `json:"settings,omitempty"` // The health check's type-specific settings
+}
+
+// Auth holds target credentials; auth profiles are resolved before execution
+type Auth struct {
+	Type         string   `json:"type"`
+	Username     string   `json:"username,omitempty"`
+	Password     string   `json:"password,omitempty"`
+	Token        string   `json:"token,omitempty"`
+	TokenURL     string   `json:"token_url,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+// CheckResult is the outcome of a successful target call
+type CheckResult struct {
+	StatusCode int               `json:"status_code,omitempty"` // Defaults to 200
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       json.RawMessage   `json:"body,omitempty"` // Usually a JSON document for rules to evaluate
+}
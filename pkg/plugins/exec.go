@@ -0,0 +1,126 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// maxExecStderr limits how much of an exec plugin's stderr is quoted in errors
+const maxExecStderr = 512
+
+// execMessage is written to an exec plugin's stdin
+type execMessage struct {
+	Action  string `json:"action"`
+	Request any    `json:"request"`
+}
+
+// execReply is read from an exec plugin's stdout
+type execReply struct {
+	Error  string          `json:"error,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// ExecCheckExecutor runs an executable for every validation and execution. The executable
+// reads {"action": "validate" or "execute", "request": <CheckRequest>} from stdin and writes
+// {"result": <CheckResult>} or {"error": "..."} to stdout.
+type ExecCheckExecutor struct {
+	Command []string // Executable and its arguments
+}
+
+// NewExecCheckExecutor creates an executor running command, an executable path optionally
+// followed by space-separated arguments
+func NewExecCheckExecutor(command string) (*ExecCheckExecutor, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("exec plugin command is required")
+	}
+	return &ExecCheckExecutor{Command: args}, nil
+}
+
+// Validate asks the executable to validate the request
+func (e *ExecCheckExecutor) Validate(ctx context.Context, req CheckRequest) error {
+	return runExec(ctx, e.Command, "validate", req, nil)
+}
+
+// Execute asks the executable to call the target
+func (e *ExecCheckExecutor) Execute(ctx context.Context, req CheckRequest) (CheckResult, error) {
+	var result CheckResult
+	err := runExec(ctx, e.Command, "execute", req, &result)
+	return result, err
+}
+
+// ExecAlertSender runs an executable for every validation and delivery attempt. The executable
+// reads {"action": "validate", "request": <Destination>} or {"action": "send", "request": <Alert>}
+// from stdin and writes {"result": <Delivery>} or {"error": "..."} to stdout.
+type ExecAlertSender struct {
+	Command []string // Executable and its arguments
+}
+
+// NewExecAlertSender creates a sender running command, an executable path optionally
+// followed by space-separated arguments
+func NewExecAlertSender(command string) (*ExecAlertSender, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("exec plugin command is required")
+	}
+	return &ExecAlertSender{Command: args}, nil
+}
+
+// Validate asks the executable to validate the destination
+func (s *ExecAlertSender) Validate(ctx context.Context, dest Destination) error {
+	return runExec(ctx, s.Command, "validate", dest, nil)
+}
+
+// Send asks the executable to deliver the alert
+func (s *ExecAlertSender) Send(ctx context.Context, alert Alert) (Delivery, error) {
+	var delivery Delivery
+	err := runExec(ctx, s.Command, "send", alert, &delivery)
+	return delivery, err
+}
+
+// runExec runs command with the action and request on stdin and decodes the result it
+// writes to stdout into result, when not nil
+func runExec(ctx context.Context, command []string, action string, request, result any) error {
+	input, err := json.Marshal(execMessage{Action: action, Request: request})
+	if err != nil {
+		return fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("plugin %s: %w", command[0], ctx.Err())
+		}
+		message := strings.TrimSpace(stderr.String())
+		if len(message) > maxExecStderr {
+			message = message[:maxExecStderr] + "..."
+		}
+		if message != "" {
+			return fmt.Errorf("plugin %s: %w: %s", command[0], err, message)
+		}
+		return fmt.Errorf("plugin %s: %w", command[0], err)
+	}
+
+	var reply execReply
+	if err := json.Unmarshal(stdout.Bytes(), &reply); err != nil {
+		return fmt.Errorf("plugin %s wrote an invalid reply: %w", command[0], err)
+	}
+	if reply.Error != "" {
+		return errors.New(reply.Error)
+	}
+	if result != nil && len(reply.Result) > 0 {
+		if err := json.Unmarshal(reply.Result, result); err != nil {
+			return fmt.Errorf("plugin %s wrote an invalid result: %w", command[0], err)
+		}
+	}
+	return nil
+}
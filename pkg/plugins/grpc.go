@@ -0,0 +1,147 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Method names of the gRPC services described in plugins.proto
+const (
+	grpcCheckValidate = "/raven.plugins.v1.CheckExecutor/Validate"
+	grpcCheckExecute  = "/raven.plugins.v1.CheckExecutor/Execute"
+	grpcAlertValidate = "/raven.plugins.v1.AlertSender/Validate"
+	grpcAlertSend     = "/raven.plugins.v1.AlertSender/Send"
+)
+
+// GRPCCheckExecutor calls a gRPC service for every validation and execution. Messages are
+// google.protobuf.Struct values holding the same JSON as exec plugins use: the CheckRequest
+// goes in, and {"result": <CheckResult>} or {"error": "..."} comes back.
+type GRPCCheckExecutor struct {
+	conn *grpc.ClientConn
+}
+
+// NewGRPCCheckExecutor creates an executor for the service at target, a gRPC target such as
+// "localhost:50051" or "unix:///run/raven/ldap.sock". Plugins are expected to run beside
+// Raven, so the connection is not encrypted.
+func NewGRPCCheckExecutor(target string) (*GRPCCheckExecutor, error) {
+	conn, err := dialGRPC(target)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCCheckExecutor{conn: conn}, nil
+}
+
+// Validate asks the service to validate the request
+func (e *GRPCCheckExecutor) Validate(ctx context.Context, req CheckRequest) error {
+	return invokeGRPC(ctx, e.conn, grpcCheckValidate, req, nil)
+}
+
+// Execute asks the service to call the target
+func (e *GRPCCheckExecutor) Execute(ctx context.Context, req CheckRequest) (CheckResult, error) {
+	var result CheckResult
+	err := invokeGRPC(ctx, e.conn, grpcCheckExecute, req, &result)
+	return result, err
+}
+
+// Close closes the connection to the service
+func (e *GRPCCheckExecutor) Close() error {
+	return e.conn.Close()
+}
+
+// GRPCAlertSender calls a gRPC service for every validation and delivery attempt, with the
+// same Struct messages as GRPCCheckExecutor: a Destination or an Alert goes in, and
+// {"result": <Delivery>} or {"error": "..."} comes back.
+type GRPCAlertSender struct {
+	conn *grpc.ClientConn
+}
+
+// NewGRPCAlertSender creates a sender for the service at target, over an unencrypted
+// connection like NewGRPCCheckExecutor
+func NewGRPCAlertSender(target string) (*GRPCAlertSender, error) {
+	conn, err := dialGRPC(target)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCAlertSender{conn: conn}, nil
+}
+
+// Validate asks the service to validate the destination
+func (s *GRPCAlertSender) Validate(ctx context.Context, dest Destination) error {
+	return invokeGRPC(ctx, s.conn, grpcAlertValidate, dest, nil)
+}
+
+// Send asks the service to deliver the alert
+func (s *GRPCAlertSender) Send(ctx context.Context, alert Alert) (Delivery, error) {
+	var delivery Delivery
+	err := invokeGRPC(ctx, s.conn, grpcAlertSend, alert, &delivery)
+	return delivery, err
+}
+
+// Close closes the connection to the service
+func (s *GRPCAlertSender) Close() error {
+	return s.conn.Close()
+}
+
+// dialGRPC creates an unencrypted client connection to a plugin service
+func dialGRPC(target string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to plugin %s: %w", target, err)
+	}
+	return conn, nil
+}
+
+// invokeGRPC calls method with request as a Struct and decodes the reply's result into
+// result, when not nil
+func invokeGRPC(ctx context.Context, conn *grpc.ClientConn, method string, request, result any) error {
+	in, err := toStruct(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	out := &structpb.Struct{}
+	if err := conn.Invoke(ctx, method, in, out); err != nil {
+		if s, ok := status.FromError(err); ok {
+			return fmt.Errorf("plugin %s: %s", s.Code(), s.Message())
+		}
+		return err
+	}
+
+	data, err := out.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("invalid plugin reply: %w", err)
+	}
+	var reply execReply
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return fmt.Errorf("invalid plugin reply: %w", err)
+	}
+	if reply.Error != "" {
+		return errors.New(reply.Error)
+	}
+	if result != nil && len(reply.Result) > 0 {
+		if err := json.Unmarshal(reply.Result, result); err != nil {
+			return fmt.Errorf("invalid plugin result: %w", err)
+		}
+	}
+	return nil
+}
+
+// toStruct converts a JSON-encodable value to a Struct
+func toStruct(value any) (*structpb.Struct, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
@@ -0,0 +1,153 @@
+// Package plugins lets custom check types and notification types be added to Raven without
+// changing it. Plugins are Go plugins built against this package, executables speaking JSON over
+// stdin and stdout, or gRPC services; each registers its executors and senders in a Registry.
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"plugin"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// typePattern restricts custom type names so they are safe in URLs, logs and config files
+var typePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,63}$`)
+
+// Registry holds the executors of custom check types and the senders of custom notification types
+type Registry struct {
+	mu      sync.RWMutex
+	checks  map[string]CheckExecutor
+	senders map[string]AlertSender
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		checks:  make(map[string]CheckExecutor),
+		senders: make(map[string]AlertSender),
+	}
+}
+
+// RegisterCheckExecutor makes executor run the checks of checkType
+func (r *Registry) RegisterCheckExecutor(checkType string, executor CheckExecutor) error {
+	if !typePattern.MatchString(checkType) {
+		return fmt.Errorf("invalid check type name: %q", checkType)
+	}
+	if executor == nil {
+		return errors.New("check executor is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.checks[checkType]; exists {
+		return fmt.Errorf("check type %s is already registered", checkType)
+	}
+	r.checks[checkType] = executor
+	return nil
+}
+
+// CheckExecutor returns the executor of checkType, or nil if none is registered
+func (r *Registry) CheckExecutor(checkType string) CheckExecutor {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.checks[checkType]
+}
+
+// CheckTypes returns the registered check types in name order
+func (r *Registry) CheckTypes() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.checks))
+}
+
+// RegisterAlertSender makes sender deliver the alerts of notification type senderType
+func (r *Registry) RegisterAlertSender(senderType string, sender AlertSender) error {
+	if !typePattern.MatchString(senderType) {
+		return fmt.Errorf("invalid notification type name: %q", senderType)
+	}
+	if sender == nil {
+		return errors.New("alert sender is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.senders[senderType]; exists {
+		return fmt.Errorf("notification type %s is already registered", senderType)
+	}
+	r.senders[senderType] = sender
+	return nil
+}
+
+// AlertSender returns the sender of senderType, or nil if none is registered
+func (r *Registry) AlertSender(senderType string) AlertSender {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.senders[senderType]
+}
+
+// AlertSenderTypes returns the registered notification types in name order
+func (r *Registry) AlertSenderTypes() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.senders))
+}
+
+// Load opens a Go plugin and calls its exported Register function, which must have the
+// signature func(*plugins.Registry) error. Go plugins only load into a binary built with cgo
+// and the same versions of Go and of this module.
+func (r *Registry) Load(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+	symbol, err := p.Lookup("Register")
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+	register, ok := symbol.(func(*Registry) error)
+	if !ok {
+		return fmt.Errorf("plugin %s: Register must be a func(*plugins.Registry) error", path)
+	}
+	if err := register(r); err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+	return nil
+}
+
+// Close releases the connections held by executors and senders, such as those of gRPC plugins
+func (r *Registry) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs []error
+	for _, executor := range r.checks {
+		if closer, ok := executor.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	for _, sender := range r.senders {
+		if closer, ok := sender.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}